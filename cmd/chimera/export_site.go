@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"chimera/internal/archive"
+	"chimera/internal/bookmarks"
+	"chimera/internal/export"
+	"chimera/internal/scraper"
+)
+
+// runExportSite implements `chimera export-site -o dir [filter...]`.
+// Filters select bookmarks whose URL or title contains any of the given substrings.
+// Bookmarks saved for offline reading are exported from the archive; the
+// others are scraped.
+func runExportSite(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export-site", flag.ContinueOnError)
+	out := fs.String("o", "", "output directory")
+	title := fs.String("title", "Chimera Clippings", "site title")
+	robots := fs.String("robots", "enforce", "robots.txt handling: ignore, warn or enforce")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("output directory is required (-o dir)")
+	}
+
+	configData, cacheData, closeStorage := openStorage("chimera")
+	defer closeStorage()
+	if configData == nil {
+		return errors.New("bookmarks are unavailable")
+	}
+	saved, err := bookmarks.NewStoreWith(configData).List()
+	if err != nil {
+		return fmt.Errorf("load bookmarks: %w", err)
+	}
+
+	selected := filterBookmarks(saved, fs.Args())
+	if len(selected) == 0 {
+		return errors.New("no bookmarks match")
+	}
+
+	rules, err := loadRules("chimera")
+	if err != nil {
+		log.Printf("warning: %v", err)
+	}
+	sc := scraper.New(scraper.Config{
+		MinDelay:     time.Second,
+		Robots:       scraper.ParseRobotsMode(*robots),
+		Retries:      2,
+		RetryBackoff: 2 * time.Second,
+		RetryJitter:  0.2,
+		Proxy:        os.Getenv("CHIMERA_PROXY"),
+		Rules:        rules,
+	})
+	ctx = scraper.WithPriority(ctx, scraper.PriorityBackground)
+	archived := archive.NewStoreWith(cacheData)
+	pages := make([]export.SitePage, 0, len(selected))
+	offline := 0
+	for _, b := range selected {
+		if page, ok, err := archived.Load(b.URL); err != nil {
+			log.Printf("warning: reading archived %s: %v", b.URL, err)
+		} else if ok && page.Result != nil {
+			pages = append(pages, export.SitePage{URL: b.URL, Title: b.Title, AddedAt: b.AddedAt, Result: page.Result})
+			offline++
+			continue
+		}
+
+		result, err := sc.Scrape(ctx, b.URL)
+		if err != nil {
+			log.Printf("warning: skipping %s: %v", b.URL, err)
+			continue
+		}
+		if result.Robots.Checked && !result.Robots.Allowed {
+			log.Printf("warning: robots.txt disallows %s (%s)", b.URL, result.Robots.Rule)
+		}
+		pages = append(pages, export.SitePage{URL: b.URL, Title: b.Title, AddedAt: b.AddedAt, Result: result})
+	}
+
+	if err := export.WriteSite(*out, *title, pages); err != nil {
+		return err
+	}
+	log.Printf("exported %d pages to %s, %d of them from the offline archive", len(pages), *out, offline)
+	return nil
+}
+
+func filterBookmarks(saved []bookmarks.Bookmark, filters []string) []bookmarks.Bookmark {
+	if len(filters) == 0 {
+		return saved
+	}
+
+	var selected []bookmarks.Bookmark
+	for _, b := range saved {
+		for _, f := range filters {
+			f = strings.ToLower(f)
+			if strings.Contains(strings.ToLower(b.URL), f) || strings.Contains(strings.ToLower(b.Title), f) {
+				selected = append(selected, b)
+				break
+			}
+		}
+	}
+	return selected
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"chimera/internal/archive"
+	"chimera/internal/render"
+	"chimera/internal/scraper"
+	"chimera/internal/search"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// savedFromPattern matches the comment browsers write at the top of a saved
+// page, e.g. <!-- saved from url=(0042)https://example.com/ -->.
+var savedFromPattern = regexp.MustCompile(`<!--\s*saved from url=\(\d+\)(\S+?)\s*-->`)
+
+// runImportHTML implements `chimera import-html dir...`.
+// Every .html and .htm file below the directories is run through the
+// extractor and added to the offline archive and the search index, under
+// the URL it was saved from when the file names one.
+func runImportHTML(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("a directory of saved pages is required")
+	}
+
+	_, cacheData, closeStorage := openStorage("chimera")
+	defer closeStorage()
+	archived := archive.NewStoreWith(cacheData)
+	if archived == nil {
+		return errors.New("the offline archive is unavailable")
+	}
+	index := search.NewIndexWith(cacheData)
+
+	rules, err := loadRules("chimera")
+	if err != nil {
+		log.Printf("warning: %v", err)
+	}
+	sc := scraper.New(scraper.Config{Rules: rules})
+
+	imported, skipped := 0, 0
+	for _, dir := range args {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if d.IsDir() || !isHTMLFile(path) {
+				return nil
+			}
+			if err := importHTMLFile(sc, archived, index, path); err != nil {
+				log.Printf("warning: skipping %s: %v", path, err)
+				skipped++
+				return nil
+			}
+			imported++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("read %s: %w", dir, err)
+		}
+	}
+
+	log.Printf("imported %d pages, skipped %d", imported, skipped)
+	return nil
+}
+
+func isHTMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return true
+	}
+	return false
+}
+
+// importHTMLFile extracts the saved page at path and stores it in the
+// archive and the search index.
+func importHTMLFile(sc *scraper.Scraper, archived *archive.Store, index *search.Index, path string) error {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	target, err := savedPageURL(body, path)
+	if err != nil {
+		return err
+	}
+
+	result, err := sc.Rendered(&scraper.Result{SourceURL: target, FinalURL: target}, string(body))
+	if err != nil {
+		return fmt.Errorf("extract: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		result.FetchedAt = info.ModTime()
+	}
+	if result.Title == "" {
+		result.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	html, err := render.Simple(result, render.Options{})
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	page := archive.Page{URL: target, Title: result.Title, SavedAt: time.Now(), Result: result, HTML: html}
+	if err := archived.Save(page); err != nil {
+		return err
+	}
+	return index.Add(target, result)
+}
+
+// savedPageURL returns the URL the page in body was saved from: the one in
+// the browser's "saved from" comment, its canonical link or its og:url. A
+// page naming none of them is filed under its file:// URL.
+func savedPageURL(body []byte, path string) (string, error) {
+	if m := savedFromPattern.FindSubmatch(body); m != nil {
+		if target := webURL(string(m[1])); target != "" {
+			return target, nil
+		}
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("parse document: %w", err)
+	}
+	for _, candidate := range []string{
+		doc.Find("link[rel='canonical']").First().AttrOr("href", ""),
+		doc.Find("meta[property='og:url']").First().AttrOr("content", ""),
+	} {
+		if target := webURL(candidate); target != "" {
+			return target, nil
+		}
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String(), nil
+}
+
+// webURL returns raw without its fragment if it is an absolute http(s) URL.
+func webURL(raw string) string {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ""
+	}
+	parsed.Fragment = ""
+	return parsed.String()
+}
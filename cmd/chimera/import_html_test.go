@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestImportHTML_SavedPageURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "saved from comment",
+			body: "<!DOCTYPE html>\n<!-- saved from url=(0029)https://example.com/a#top -->\n<html><head><link rel=canonical href=https://example.com/b></head></html>",
+			want: "https://example.com/a",
+		},
+		{
+			name: "canonical link",
+			body: `<html><head><link rel="canonical" href="https://example.com/b"><meta property="og:url" content="https://example.com/c"></head></html>`,
+			want: "https://example.com/b",
+		},
+		{
+			name: "og:url",
+			body: `<html><head><link rel="canonical" href="/relative"><meta property="og:url" content="https://example.com/c"></head></html>`,
+			want: "https://example.com/c",
+		},
+		{
+			name: "local file",
+			body: "<!-- saved from url=(0014)about:internet --><html><body>text</body></html>",
+			want: "file://" + filepath.ToSlash(path),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := savedPageURL([]byte(tt.body), path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("savedPageURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
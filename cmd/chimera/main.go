@@ -1,17 +1,35 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	"chimera/internal/archive"
+	"chimera/internal/bookmarks"
 	"chimera/internal/browser"
+	"chimera/internal/cache"
+	"chimera/internal/examples"
+	"chimera/internal/history"
 	"chimera/internal/llm"
+	"chimera/internal/logs"
+	"chimera/internal/notes"
+	"chimera/internal/render"
 	"chimera/internal/scraper"
+	"chimera/internal/search"
+	"chimera/internal/session"
 	"chimera/internal/settings"
+	"chimera/internal/storage"
+	"chimera/internal/watch"
+	"chimera/internal/workspace"
 )
 
 func main() {
@@ -20,22 +38,72 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	scraperClient := scraper.New(scraper.Config{})
+	if len(os.Args) > 1 && os.Args[1] == "export-site" {
+		if err := runExportSite(ctx, os.Args[2:]); err != nil {
+			log.Fatalf("export-site: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-html" {
+		if err := runImportHTML(ctx, os.Args[2:]); err != nil {
+			log.Fatalf("import-html: %v", err)
+		}
+		return
+	}
 
-	var (
-		settingsStore *settings.Store
-		stored        settings.Data
-	)
+	logBuffer := logs.NewBuffer(1000)
+	slog.SetDefault(slog.New(logs.NewHandler(slog.NewTextHandler(os.Stderr, nil), logBuffer)))
 
-	if store, err := settings.NewStore("chimera"); err != nil {
-		log.Printf("warning: unable to prepare settings store: %v", err)
-	} else {
-		settingsStore = store
-		if data, err := settingsStore.Load(); err != nil {
-			log.Printf("warning: unable to load settings: %v", err)
-		} else {
-			stored = data
-		}
+	configData, cacheData, closeStorage := openStorage("chimera")
+	defer closeStorage()
+
+	settingsStore := settings.NewStoreWith(configData, "chimera")
+	stored, err := settingsStore.Load()
+	if err != nil {
+		slog.Warn("unable to load settings", "err", err)
+	}
+
+	proxyURL := firstNonEmpty(os.Getenv("CHIMERA_PROXY"), stored.Proxy)
+	rules, err := loadRules("chimera")
+	if err != nil {
+		slog.Warn("unable to load extraction rules", "err", err)
+	}
+	maxRedirects := envInt("CHIMERA_MAX_REDIRECTS")
+	if maxRedirects == 0 {
+		maxRedirects = stored.MaxRedirects
+	}
+	crossSite := stored.CrossSiteRedirects
+	if os.Getenv("CHIMERA_SAME_ORIGIN_REDIRECTS") == "1" {
+		crossSite = settings.RedirectsBlock
+	}
+	scraperClient := scraper.New(scraper.Config{
+		Robots:       scraper.ParseRobotsMode(os.Getenv("CHIMERA_ROBOTS")),
+		Retries:      2,
+		RetryBackoff: time.Second,
+		RetryJitter:  0.2,
+
+		MaxRedirects:        maxRedirects,
+		SameOriginRedirects: crossSite == settings.RedirectsBlock,
+		Proxy:               proxyURL,
+		Rules:               rules,
+		MaxInFlight:         envInt("CHIMERA_MAX_IN_FLIGHT"),
+	})
+
+	compositions := cache.NewStoreWith(cacheData, 5)
+	translations := cache.NewTranslationsWith(cacheData)
+	bookmarkStore := bookmarks.NewStoreWith(configData)
+	archiveStore := archive.NewStoreWith(cacheData)
+
+	exampleStore := examples.NewStoreWith(configData)
+	historyStore := history.NewStoreWith(configData)
+	searchIndex := search.NewIndexWith(cacheData)
+	noteStore := notes.NewStoreWith(configData)
+	watchStore := watch.NewStoreWith(configData)
+
+	// The running session has always been kept under the GTK application ID.
+	sessionStore, err := session.NewStore("com.example.chimera")
+	if err != nil {
+		slog.Warn("unable to prepare session recovery", "err", err)
 	}
 
 	envBase := firstNonEmpty(os.Getenv("CHIMERA_LLM_BASE_URL"), os.Getenv("CHIMERA_LLM_ENDPOINT"), stored.BaseURL)
@@ -47,12 +115,41 @@ func main() {
 		useLLM = strings.EqualFold(override, "1")
 	}
 
+	promptStore := promptFiles("chimera")
+	prompts, err := llm.LoadPrompts(promptStore)
+	if err != nil {
+		slog.Warn("unable to load prompt templates", "err", err)
+	}
+
 	llmCfg := llm.Config{
 		BaseURL:    envBase,
+		Endpoint:   llm.ParseEndpoint(firstNonEmpty(os.Getenv("CHIMERA_LLM_API"), stored.Endpoint)),
 		Model:      envModel,
 		APIKey:     envKey,
 		HTTPClient: nil,
 		Timeout:    60 * time.Second,
+
+		Retries:       2,
+		RetryBackoff:  2 * time.Second,
+		ContextTokens: stored.ContextTokens,
+		SystemPrompt:  stored.SystemPrompt,
+		Prompts:       prompts,
+		Examples:      exampleStore,
+		Proxy:         proxyURL,
+
+		EmbeddingModel: firstNonEmpty(os.Getenv("CHIMERA_EMBEDDING_MODEL"), stored.EmbeddingModel),
+		InputPrice:     stored.InputPrice,
+		VisionModels:   stored.VisionModels,
+		Fallbacks:      fallbackProviders(stored.Fallbacks),
+		AppURL:         os.Getenv("CHIMERA_OPENROUTER_REFERER"),
+		Routing: llm.Routing{
+			Order:              stored.RoutingOrder,
+			Sort:               stored.RoutingSort,
+			Only:               stored.RoutingOnly,
+			DenyDataCollection: stored.RoutingPrivate,
+		},
+		PromptFormat: stored.PromptFormat,
+		MaxInFlight:  cmp.Or(envInt("CHIMERA_LLM_MAX_IN_FLIGHT"), stored.MaxInFlight),
 	}
 
 	llmClient := llm.NewClient(llmCfg)
@@ -63,8 +160,71 @@ func main() {
 		LLMConfig:     llmCfg,
 		UseLLM:        useLLM,
 		SettingsStore: settingsStore,
+		Compositions:  compositions,
+		Translations:  translations,
+		Bookmarks:     bookmarkStore,
+		Archive:       archiveStore,
+		Examples:      exampleStore,
+		Notes:         noteStore,
+		Watches:       watchStore,
+		Sessions:      sessionStore,
+		Workspaces:    workspace.NewStoreWith(configData),
+		History:       historyStore,
+		Search:        searchIndex,
+		Logs:          logBuffer,
+		Rendering:     stored.Rendering,
 		AppID:         "com.example.chimera",
 		AppTitle:      "Chimera Browser",
+
+		SummaryLanguage:     stored.SummaryLanguage,
+		TranslationLanguage: stored.TranslationLanguage,
+		PlaintextAPIKey:     stored.PlaintextAPIKey,
+		Sites:               stored.Sites,
+		EnrichCompose:       stored.EnrichCompose,
+		FetchLinks:          stored.FetchLinks,
+		PersonalDigest:      stored.PersonalDigest,
+		Proxy:               proxyURL,
+		WarmUp:              stored.WarmUp,
+		CleanLinks:          stored.CleanLinks,
+		UserAgent:           stored.UserAgent,
+		AcceptLanguage:      stored.AcceptLanguage,
+		NoScriptRendering:   stored.NoScriptRendering,
+		SkipConsentWalls:    stored.SkipConsentWalls,
+		KeepBoilerplate:     stored.KeepBoilerplate,
+		JoinPages:           stored.JoinPages,
+		VisitCommand:        stored.VisitCommand,
+		VaultDir:            stored.VaultDir,
+		VaultTags:           stored.VaultTags,
+		CitationFormat:      stored.CitationFormat,
+		CitationFile:        stored.CitationFile,
+		MaxRedirects:        maxRedirects,
+		CrossSiteRedirects:  crossSite,
+		LowVision:           stored.LowVision,
+		MinFontSize:         stored.MinFontSize,
+		MinContrast:         stored.MinContrast,
+		Startup:             stored.Startup,
+		StartupURL:          stored.StartupURL,
+		StartupCompose:      stored.StartupCompose,
+		StylesDir:           stylesDir("chimera"),
+		BlockExternal:       stored.BlockExternal,
+		SourceBase:          stored.SourceBase,
+		Prompts:             stored.Prompts,
+		PromptFiles:         promptStore,
+		Typography: render.Typography{
+			FontFamily: stored.ReaderFont,
+			FontSize:   stored.ReaderFontSize,
+			LineHeight: stored.ReaderLineHeight,
+			Width:      stored.ReaderWidth,
+			Justify:    stored.ReaderJustify,
+		},
+		Limits: scraper.Limits{
+			Headings:     stored.MaxHeadings,
+			Paragraphs:   stored.MaxParagraphs,
+			CodeBlocks:   stored.MaxCodeBlocks,
+			Links:        stored.MaxLinks,
+			HeadingDepth: stored.HeadingDepth,
+			MinParagraph: stored.MinParagraph,
+		},
 	})
 	if err != nil {
 		log.Fatalf("failed to initialize app: %v", err)
@@ -75,6 +235,99 @@ func main() {
 	}
 }
 
+// loadRules reads the per-domain extraction rules from rules.json in the
+// user's configuration directory.
+func loadRules(appID string) (scraper.Rules, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("locate config dir: %w", err)
+	}
+	return scraper.LoadRules(filepath.Join(dir, appID, "rules.json"))
+}
+
+// stylesDir returns the styles folder in the user's configuration
+// directory, which holds the stylesheets injected into pages; empty when
+// the directory cannot be located.
+func stylesDir(appID string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		slog.Warn("unable to locate user stylesheets", "err", err)
+		return ""
+	}
+	return filepath.Join(dir, appID, "styles")
+}
+
+// promptFiles returns the backend holding the editable prompt templates,
+// in the prompts folder of the user's configuration directory. They stay
+// plain files with SQLite storage too; nil when the directory cannot be
+// located.
+func promptFiles(appID string) storage.Backend {
+	files, err := storage.ConfigFiles(appID)
+	if err != nil {
+		slog.Warn("unable to locate prompt templates", "err", err)
+		return nil
+	}
+	return files
+}
+
+// openStorage returns where history, bookmarks, the caches and the offline
+// archive keep their data: plain files below the configuration and cache
+// directories, or with CHIMERA_STORAGE=sqlite one database file, chimera.db
+// in the configuration directory unless given as sqlite:PATH. A backend is
+// nil when it could not be prepared.
+func openStorage(appID string) (configData, cacheData storage.Backend, closeStorage func()) {
+	closeStorage = func() {}
+	if kind, dbPath, _ := strings.Cut(strings.TrimSpace(os.Getenv("CHIMERA_STORAGE")), ":"); kind == "sqlite" {
+		db, err := openDatabase(appID, dbPath)
+		if err == nil {
+			closeStorage = func() {
+				if err := db.Close(); err != nil {
+					slog.Warn("close storage", "err", err)
+				}
+			}
+			return db.Bucket("config"), db.Bucket("cache"), closeStorage
+		}
+		slog.Warn("unable to open SQLite storage; using plain files", "err", err)
+	}
+
+	if files, err := storage.ConfigFiles(appID); err != nil {
+		slog.Warn("unable to prepare storage for history and bookmarks", "err", err)
+	} else {
+		configData = files
+	}
+	if files, err := storage.CacheFiles(appID); err != nil {
+		slog.Warn("unable to prepare storage for caches and the offline archive", "err", err)
+	} else {
+		cacheData = files
+	}
+	return configData, cacheData, closeStorage
+}
+
+// openDatabase opens the SQLite database at path, by default chimera.db in
+// appID's configuration directory.
+func openDatabase(appID, path string) (*storage.SQLite, error) {
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("locate config dir: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, appID), 0o700); err != nil {
+			return nil, fmt.Errorf("create config dir: %w", err)
+		}
+		path = filepath.Join(dir, appID, "chimera.db")
+	}
+	return storage.OpenSQLite(path)
+}
+
+// fallbackProviders converts the stored fallback providers for the client.
+func fallbackProviders(stored []settings.Provider) []llm.Provider {
+	var providers []llm.Provider
+	for _, p := range stored {
+		providers = append(providers, llm.Provider{Name: p.Name, BaseURL: p.BaseURL, Model: p.Model, APIKey: p.APIKey})
+	}
+	return providers
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if strings.TrimSpace(v) != "" {
@@ -83,3 +336,17 @@ func firstNonEmpty(values ...string) string {
 	}
 	return ""
 }
+
+// envInt returns the integer value of the named variable, or zero when unset or invalid.
+func envInt(name string) int {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("ignoring invalid integer", "var", name, "value", value)
+		return 0
+	}
+	return n
+}
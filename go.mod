@@ -4,12 +4,12 @@ go 1.23.4
 
 require (
 	github.com/PuerkitoBio/goquery v1.9.1
+	github.com/andybalholm/brotli v1.1.0
 	github.com/gotk3/gotk3 v0.6.4
+	github.com/mattn/go-sqlite3 v1.14.22
+	golang.org/x/net v0.21.0
 )
 
-require (
-	github.com/andybalholm/cascadia v1.3.2 // indirect
-	golang.org/x/net v0.21.0 // indirect
-)
+require github.com/andybalholm/cascadia v1.3.2 // indirect
 
 replace github.com/gotk3/gotk3 => ./third_party/gotk3
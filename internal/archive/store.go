@@ -0,0 +1,285 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"chimera/internal/scraper"
+	"chimera/internal/storage"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Page is an archived copy of a page.
+type Page struct {
+	URL     string    `json:"url"`
+	Title   string    `json:"title"`
+	SavedAt time.Time `json:"saved_at"`
+	// Result is the scraped page, kept so it can be rendered or composed again.
+	Result *scraper.Result `json:"result,omitempty"`
+	// HTML is the page as it was shown, composed or in reader mode. It is
+	// stored beside the metadata rather than in it.
+	HTML   string  `json:"-"`
+	Images []Image `json:"images,omitempty"`
+}
+
+// Image is an image referenced by an archived page.
+type Image struct {
+	// Src is the image URL, resolved against the page URL.
+	Src         string `json:"src"`
+	ContentType string `json:"content_type"`
+	// File is where Data is stored, relative to the page's directory.
+	File string `json:"file"`
+	Data []byte `json:"-"`
+}
+
+// Store keeps one archived copy per URL in a storage.Backend, by default
+// below the user's cache directory. Each copy is a page.json, a page.html
+// and its images under a key prefix of its own.
+type Store struct {
+	backend storage.Backend
+	mu      sync.RWMutex
+}
+
+// NewStore builds a Store below the user's cache directory.
+func NewStore(appID string) (*Store, error) {
+	backend, err := storage.CacheFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend), nil
+}
+
+// NewStoreWith builds a Store keeping its copies in backend; a nil backend gives a nil Store,
+// which stores nothing.
+func NewStoreWith(backend storage.Backend) *Store {
+	if backend == nil {
+		return nil
+	}
+	return &Store{backend: backend}
+}
+
+// Save replaces the archived copy of page.URL.
+func (s *Store) Save(page Page) error {
+	if s == nil {
+		return nil
+	}
+	if page.URL == "" {
+		return errors.New("archived page URL is empty")
+	}
+	if page.SavedAt.IsZero() {
+		page.SavedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := prefixFor(page.URL)
+	stale, err := s.backend.List(prefix)
+	if err != nil {
+		return fmt.Errorf("list archive: %w", err)
+	}
+	written := map[string]bool{prefix + "page.html": true, prefix + "page.json": true}
+
+	for i := range page.Images {
+		page.Images[i].File = fmt.Sprintf("image-%03d", i)
+		written[prefix+page.Images[i].File] = true
+		if err := s.backend.Put(prefix+page.Images[i].File, page.Images[i].Data); err != nil {
+			return fmt.Errorf("write archived image: %w", err)
+		}
+	}
+	if err := s.backend.Put(prefix+"page.html", []byte(page.HTML)); err != nil {
+		return fmt.Errorf("write archived html: %w", err)
+	}
+	encoded, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode archived page: %w", err)
+	}
+	// page.json goes last: the copy is complete once it names the new images.
+	if err := s.backend.Put(prefix+"page.json", encoded); err != nil {
+		return fmt.Errorf("write archived page: %w", err)
+	}
+
+	for _, key := range stale {
+		if written[key] {
+			continue
+		}
+		if err := s.backend.Delete(key); err != nil {
+			return fmt.Errorf("remove old archived image: %w", err)
+		}
+	}
+	return nil
+}
+
+// Load returns the archived copy of url with its HTML and image data.
+func (s *Store) Load(url string) (Page, bool, error) {
+	if s == nil {
+		return Page{}, false, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefix := prefixFor(url)
+	bytes, err := s.backend.Get(prefix + "page.json")
+	if errors.Is(err, storage.ErrNotFound) {
+		return Page{}, false, nil
+	}
+	if err != nil {
+		return Page{}, false, fmt.Errorf("read archived page: %w", err)
+	}
+	var page Page
+	if err := json.Unmarshal(bytes, &page); err != nil {
+		return Page{}, false, fmt.Errorf("decode archived page: %w", err)
+	}
+
+	html, err := s.backend.Get(prefix + "page.html")
+	if err != nil {
+		return Page{}, false, fmt.Errorf("read archived html: %w", err)
+	}
+	page.HTML = string(html)
+	for i := range page.Images {
+		data, err := s.backend.Get(prefix + path.Base(page.Images[i].File))
+		if err != nil {
+			return Page{}, false, fmt.Errorf("read archived image: %w", err)
+		}
+		page.Images[i].Data = data
+	}
+	return page, true, nil
+}
+
+// SavedAt reports when url was archived, without loading the HTML and
+// images.
+func (s *Store) SavedAt(url string) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bytes, err := s.backend.Get(prefixFor(url) + "page.json")
+	if err != nil {
+		return time.Time{}, false
+	}
+	var meta struct {
+		SavedAt time.Time `json:"saved_at"`
+	}
+	if err := json.Unmarshal(bytes, &meta); err != nil {
+		return time.Time{}, false
+	}
+	return meta.SavedAt, true
+}
+
+// Remove deletes the archived copy of url, if there is one.
+func (s *Store) Remove(url string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := prefixFor(url)
+	keys, err := s.backend.List(prefix)
+	if err != nil {
+		return fmt.Errorf("list archive: %w", err)
+	}
+	// Without page.json the rest is no longer a copy, should removing it fail
+	// half-way.
+	if err := s.backend.Delete(prefix + "page.json"); err != nil {
+		return fmt.Errorf("remove archive: %w", err)
+	}
+	for _, key := range keys {
+		if err := s.backend.Delete(key); err != nil {
+			return fmt.Errorf("remove archive: %w", err)
+		}
+	}
+	return nil
+}
+
+// Offline returns the page's HTML with its archived images inlined as data
+// URIs, so it shows without a network connection.
+func (p Page) Offline() string {
+	if len(p.Images) == 0 {
+		return p.HTML
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(p.HTML))
+	if err != nil {
+		return p.HTML
+	}
+
+	inline := make(map[string]string, len(p.Images))
+	for _, img := range p.Images {
+		inline[img.Src] = "data:" + img.ContentType + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+	}
+	doc.Find("img[src]").Each(func(_ int, img *goquery.Selection) {
+		src, _ := img.Attr("src")
+		data, ok := inline[resolve(p.URL, src)]
+		if !ok {
+			return
+		}
+		img.SetAttr("src", data)
+		// The other candidates were not archived and would be preferred.
+		img.RemoveAttr("srcset")
+		img.Parent().Filter("picture").Find("source").Remove()
+	})
+
+	html, err := doc.Html()
+	if err != nil {
+		return p.HTML
+	}
+	return html
+}
+
+// ImageSources returns the distinct image URLs referenced by html, resolved
+// against base, up to limit. Inline data URIs are skipped.
+func ImageSources(html, base string, limit int) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var sources []string
+	doc.Find("img[src]").EachWithBreak(func(_ int, img *goquery.Selection) bool {
+		src, _ := img.Attr("src")
+		resolved := resolve(base, src)
+		if !strings.HasPrefix(resolved, "http://") && !strings.HasPrefix(resolved, "https://") {
+			return true
+		}
+		if !seen[resolved] {
+			seen[resolved] = true
+			sources = append(sources, resolved)
+		}
+		return len(sources) < limit
+	})
+	return sources
+}
+
+func resolve(base, ref string) string {
+	ref = strings.TrimSpace(ref)
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// prefixFor returns the key prefix of url's archived copy.
+func prefixFor(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "archive/" + hex.EncodeToString(sum[:]) + "/"
+}
@@ -0,0 +1,184 @@
+package bookmarks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"chimera/internal/storage"
+)
+
+// Bookmark is a saved page.
+type Bookmark struct {
+	URL     string    `json:"url"`
+	Title   string    `json:"title"`
+	AddedAt time.Time `json:"added_at"`
+	// Health is the outcome of the last link check; nil until one has run.
+	Health *Health `json:"health,omitempty"`
+}
+
+// Health records whether a bookmarked URL still answered when last checked.
+type Health struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// Status is the HTTP status of the final response, if one arrived.
+	Status int `json:"status,omitempty"`
+	// MovedTo is where the URL now redirects, when that is another page.
+	MovedTo string `json:"moved_to,omitempty"`
+	// Problem describes why the check failed; empty when it succeeded.
+	Problem string `json:"problem,omitempty"`
+	// Dead marks failures that will not go away by retrying, such as a
+	// 404 or a host that no longer resolves.
+	Dead bool `json:"dead,omitempty"`
+}
+
+// Store persists bookmarks as JSON in a storage.Backend, by default below
+// the user's configuration directory.
+type Store struct {
+	backend storage.Backend
+	mu      sync.RWMutex
+}
+
+// NewStore builds a Store below the user's configuration directory.
+func NewStore(appID string) (*Store, error) {
+	backend, err := storage.ConfigFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend), nil
+}
+
+// NewStoreWith builds a Store keeping its data in backend; a nil backend gives a nil Store,
+// which stores nothing.
+func NewStoreWith(backend storage.Backend) *Store {
+	if backend == nil {
+		return nil
+	}
+	return &Store{backend: backend}
+}
+
+// List returns all bookmarks in the order they were added.
+func (s *Store) List() ([]Bookmark, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.read()
+}
+
+// Add saves b, replacing any existing bookmark with the same URL.
+func (s *Store) Add(b Bookmark) error {
+	if s == nil {
+		return nil
+	}
+	b.URL = strings.TrimSpace(b.URL)
+	if b.URL == "" {
+		return errors.New("bookmark URL is empty")
+	}
+	if b.AddedAt.IsZero() {
+		b.AddedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range entries {
+		if entries[i].URL == b.URL {
+			entries[i] = b
+			replaced = true
+		}
+	}
+	if !replaced {
+		entries = append(entries, b)
+	}
+
+	return s.write(entries)
+}
+
+// Remove deletes the bookmark for url, if present.
+func (s *Store) Remove(url string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	kept := entries[:0]
+	for _, b := range entries {
+		if b.URL != url {
+			kept = append(kept, b)
+		}
+	}
+
+	return s.write(kept)
+}
+
+// SetHealth records link check results, keyed by bookmark URL. Results for
+// URLs that are no longer bookmarked are ignored.
+func (s *Store) SetHealth(results map[string]Health) error {
+	if s == nil || len(results) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		if health, ok := results[entries[i].URL]; ok {
+			entries[i].Health = &health
+		}
+	}
+
+	return s.write(entries)
+}
+
+func (s *Store) read() ([]Bookmark, error) {
+	bytes, err := s.backend.Get("bookmarks.json")
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read bookmarks: %w", err)
+	}
+
+	var entries []Bookmark
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return nil, fmt.Errorf("decode bookmarks: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) write(entries []Bookmark) error {
+	encoded, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode bookmarks: %w", err)
+	}
+
+	if err := s.backend.Put("bookmarks.json", encoded); err != nil {
+		return fmt.Errorf("write bookmarks: %w", err)
+	}
+
+	return nil
+}
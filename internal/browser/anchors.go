@@ -0,0 +1,49 @@
+package browser
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// splitFragment separates the #fragment from target; the fragment is
+// scrolled to once the page is rendered rather than sent to the server.
+func splitFragment(target string) (string, string) {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Fragment == "" {
+		return target, ""
+	}
+	fragment := parsed.Fragment
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String(), fragment
+}
+
+// withFragment makes html scroll to the element with id fragment once loaded.
+func withFragment(html, fragment string) string {
+	if fragment == "" {
+		return html
+	}
+	// json.Marshal escapes <, > and & so the id cannot close the script.
+	id, _ := json.Marshal(fragment)
+	return withScript(html, `<script>(function(){var e=document.getElementById(`+string(id)+`);if(e)e.scrollIntoView();})();</script>`)
+}
+
+// withScroll makes html scroll down by y CSS pixels once loaded.
+func withScroll(html string, y int) string {
+	if y <= 0 {
+		return html
+	}
+	return withScript(html, `<script>window.addEventListener("load",function(){window.scrollTo(0,`+strconv.Itoa(y)+`);});</script>`)
+}
+
+// withScript adds script at the end of the body of html, with the nonce
+// that lets it run in compositions.
+func withScript(html, script string) string {
+	script = strings.Replace(script, "<script>", `<script nonce="`+scriptNonce+`">`, 1)
+	if i := strings.LastIndex(strings.ToLower(html), "</body>"); i >= 0 {
+		return html[:i] + script + html[i:]
+	}
+	return html + script
+}
@@ -2,18 +2,35 @@ package browser
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"html/template"
-	"log"
+	"log/slog"
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"chimera/internal/archive"
+	"chimera/internal/bookmarks"
 	"chimera/internal/browser/webkit"
+	"chimera/internal/cache"
+	"chimera/internal/examples"
+	"chimera/internal/history"
 	"chimera/internal/llm"
+	"chimera/internal/locale"
+	"chimera/internal/logs"
+	"chimera/internal/navigation"
+	"chimera/internal/notes"
+	"chimera/internal/render"
 	"chimera/internal/scraper"
+	"chimera/internal/search"
+	"chimera/internal/session"
 	persist "chimera/internal/settings"
+	"chimera/internal/storage"
+	"chimera/internal/tracking"
+	"chimera/internal/watch"
+	"chimera/internal/workspace"
 
 	"github.com/gotk3/gotk3/gdk"
 	"github.com/gotk3/gotk3/glib"
@@ -27,23 +44,143 @@ type Config struct {
 	LLMConfig     llm.Config
 	UseLLM        bool
 	SettingsStore *persist.Store
-	AppID         string
-	AppTitle      string
+	Compositions  *cache.Store
+	// Translations is the translation memory reused by the translate task.
+	Translations *cache.Translations
+	Bookmarks    *bookmarks.Store
+	Archive      *archive.Store
+	Examples     *examples.Store
+	Notes        *notes.Store
+	Watches      *watch.Store
+	// Sessions keeps the running session for crash recovery and
+	// StartupLastSession.
+	Sessions  *session.Store
+	History   *history.Store
+	Search    *search.Index
+	Logs      *logs.Buffer
+	Rendering string
+	AppID     string
+	AppTitle  string
+
+	// SummaryLanguage and TranslationLanguage override the UI locale when set.
+	SummaryLanguage     string
+	TranslationLanguage string
+	// PlaintextAPIKey keeps the API key in settings.json instead of the keyring.
+	PlaintextAPIKey bool
+	// Sites holds remembered per-domain preferences keyed by settings.SiteKey.
+	Sites map[string]persist.Site
+	// EnrichCompose scrapes a few linked pages to give compositions more context.
+	EnrichCompose bool
+	// FetchLinks lets the model read same-site linked pages while composing.
+	FetchLinks bool
+	// PersonalDigest opts into the LLM digest on the start page.
+	PersonalDigest bool
+	// Proxy routes page fetches and LLM requests; empty uses the environment.
+	Proxy string
+	// WarmUp pings the model at startup and while the window is focused.
+	WarmUp bool
+	// CleanLinks removes tracking parameters from clicked links before scraping.
+	CleanLinks bool
+	// UserAgent and AcceptLanguage customise scraper requests.
+	UserAgent      string
+	AcceptLanguage string
+	// NoScriptRendering skips the offscreen render of JavaScript-only pages.
+	NoScriptRendering bool
+	// SkipConsentWalls gets past cookie consent interstitials when scraping.
+	SkipConsentWalls bool
+	// Limits caps what the scraper extracts from each page.
+	Limits scraper.Limits
+	// LowVision enforces MinFontSize and MinContrast on rendered pages.
+	LowVision   bool
+	MinFontSize int
+	MinContrast float64
+	// KeepBoilerplate extracts from the whole page, including menus,
+	// footers, cookie banners and ads.
+	KeepBoilerplate bool
+	// JoinPages merges continuation pages of multi-page articles.
+	JoinPages bool
+	// VisitCommand receives each visited page as JSON; see runVisitHook.
+	VisitCommand string
+	// VaultDir and VaultTags configure "Clip to vault"; see settings.Data.
+	VaultDir  string
+	VaultTags string
+	// CitationFormat and CitationFile configure "Copy citation"; see settings.Data.
+	CitationFormat string
+	CitationFile   string
+	// MaxRedirects and CrossSiteRedirects set the redirect policy; see settings.Data.
+	MaxRedirects       int
+	CrossSiteRedirects string
+	// Startup, StartupURL and StartupCompose choose what opens at launch;
+	// see settings.Data.
+	Startup        string
+	StartupURL     string
+	StartupCompose bool
+	// Typography styles reader mode and is suggested to the LLM.
+	Typography render.Typography
+	// BlockExternal stops rendered pages loading anything from the web.
+	BlockExternal bool
+	// SourceBase renders pages with their address as base URI.
+	SourceBase bool
+	// Prompts maps actions to the prompt template each uses; see
+	// settings.Data.
+	Prompts map[string]string
+	// PromptFiles holds the editable prompt templates; nil keeps the
+	// built-in ones.
+	PromptFiles storage.Backend
+	// StylesDir holds the user stylesheets injected into every page; empty
+	// injects none.
+	StylesDir string
+	// Workspaces keeps the named workspaces and the pages open in each.
+	Workspaces *workspace.Store
 }
 
 // App wires the GTK UI with the scraping and LLM pipeline.
 type App struct {
 	cfg Config
 
-	mu            sync.RWMutex
-	llmClient     *llm.Client
-	llmSettings   appLLMSettings
-	llmPreferred  bool
-	llmTimeout    time.Duration
-	llmLastMode   bool
-	llmLastSet    bool
-	lastSource    string
+	mu           sync.RWMutex
+	llmClient    *llm.Client
+	llmSettings  appLLMSettings
+	prefs        appPreferences
+	lite         bool
+	llmPreferred bool
+	llmTimeout   time.Duration
+	llmLastMode  bool
+	llmLastSet   bool
+	lastSource   string
+	// rawMode is set while pages load straight into WebKit, so links on
+	// them are followed there instead of being scraped.
+	rawMode      bool
+	lastWarmUp   time.Time
+	reduceMotion bool
+	watching     atomic.Bool
+	embedding    atomic.Bool
+	// healthCheck counts LLM health checks, so only the latest one
+	// updates the LLM button.
+	healthCheck atomic.Uint64
+	// nav scrapes and composes pages; handleScrape shows what it produces.
+	nav *navigation.Controller
+	// allowedRedirects holds host pairs, as keyed by redirectHosts, that
+	// may be followed this session without asking.
+	allowedRedirects map[string]bool
+	// navCancel cancels the navigation running for the page being shown;
+	// navID tells navigations apart, so one that finished clears only its own.
+	navCancel     context.CancelFunc
+	navID         uint64
+	page          renderedPage
 	settingsStore *persist.Store
+	// genCancel stops the LLM generation running for the page being
+	// shown; genID tells generations apart, so only the latest one hides
+	// stopBtn when it ends.
+	genCancel context.CancelCauseFunc
+	genID     uint64
+	stopBtn   *gtk.Button
+	// cost shows what composing the page in front would cost; nil until
+	// the window is built.
+	cost *costPreview
+	// workspace is the current workspace; its model and mode override the
+	// LLM settings.
+	workspace workspace.Workspace
 }
 
 // NewApp validates the configuration and returns a ready application.
@@ -69,14 +206,72 @@ func NewApp(cfg Config) (*App, error) {
 		llmTimeout:    timeout,
 		settingsStore: cfg.SettingsStore,
 	}
+	app.nav = &navigation.Controller{
+		Scraper:      cfg.Scraper,
+		Compositions: cfg.Compositions,
+		LLM:          func() navigation.Composer { return app.currentLLM() },
+	}
 
 	app.mu.Lock()
 	app.llmClient = cfg.LLM
 	app.llmPreferred = cfg.UseLLM
+	app.prefs = appPreferences{
+		Rendering:           cfg.Rendering,
+		SummaryLanguage:     cfg.SummaryLanguage,
+		TranslationLanguage: cfg.TranslationLanguage,
+		PlaintextAPIKey:     cfg.PlaintextAPIKey,
+		Sites:               cfg.Sites,
+		EnrichCompose:       cfg.EnrichCompose,
+		FetchLinks:          cfg.FetchLinks,
+		PersonalDigest:      cfg.PersonalDigest,
+		Proxy:               cfg.Proxy,
+		WarmUp:              cfg.WarmUp,
+		CleanLinks:          cfg.CleanLinks,
+		UserAgent:           cfg.UserAgent,
+		AcceptLanguage:      cfg.AcceptLanguage,
+		NoScriptRendering:   cfg.NoScriptRendering,
+		SkipConsentWalls:    cfg.SkipConsentWalls,
+		Limits:              cfg.Limits,
+		KeepBoilerplate:     cfg.KeepBoilerplate,
+		JoinPages:           cfg.JoinPages,
+		VisitCommand:        cfg.VisitCommand,
+		VaultDir:            cfg.VaultDir,
+		VaultTags:           cfg.VaultTags,
+		CitationFormat:      cfg.CitationFormat,
+		CitationFile:        cfg.CitationFile,
+		MaxRedirects:        cfg.MaxRedirects,
+		CrossSiteRedirects:  cfg.CrossSiteRedirects,
+		LowVision:           cfg.LowVision,
+		MinFontSize:         cfg.MinFontSize,
+		MinContrast:         cfg.MinContrast,
+		Startup:             cfg.Startup,
+		StartupURL:          cfg.StartupURL,
+		StartupCompose:      cfg.StartupCompose,
+		Typography:          cfg.Typography,
+		BlockExternal:       cfg.BlockExternal,
+		SourceBase:          cfg.SourceBase,
+		Prompts:             cfg.Prompts,
+	}
+	cfg.Scraper.SetRequestOptions(requestOptions(app.prefs))
+	cfg.Scraper.SetLimits(app.prefs.Limits)
+	cfg.Scraper.SetStripBoilerplate(!app.prefs.KeepBoilerplate)
+	cfg.Scraper.SetJoinPages(app.prefs.JoinPages)
+	app.lite = render.ResolveLite(cfg.Rendering)
 	app.llmSettings = appLLMSettings{
-		BaseURL: strings.TrimSpace(cfg.LLMConfig.BaseURL),
-		Model:   strings.TrimSpace(cfg.LLMConfig.Model),
-		APIKey:  strings.TrimSpace(cfg.LLMConfig.APIKey),
+		BaseURL:  strings.TrimSpace(cfg.LLMConfig.BaseURL),
+		Endpoint: cfg.LLMConfig.Endpoint,
+		Model:    strings.TrimSpace(cfg.LLMConfig.Model),
+		APIKey:   strings.TrimSpace(cfg.LLMConfig.APIKey),
+
+		ContextTokens:  cfg.LLMConfig.ContextTokens,
+		SystemPrompt:   strings.TrimSpace(cfg.LLMConfig.SystemPrompt),
+		EmbeddingModel: strings.TrimSpace(cfg.LLMConfig.EmbeddingModel),
+		InputPrice:     cfg.LLMConfig.InputPrice,
+		VisionModels:   cfg.LLMConfig.VisionModels,
+		Fallbacks:      cfg.LLMConfig.Fallbacks,
+		Routing:        cfg.LLMConfig.Routing,
+		PromptFormat:   cfg.LLMConfig.PromptFormat,
+		MaxInFlight:    cfg.LLMConfig.MaxInFlight,
 	}
 	app.mu.Unlock()
 
@@ -92,10 +287,12 @@ func (a *App) Run(ctx context.Context) error {
 
 	application.Connect("activate", func() {
 		if err := a.activate(ctx, application); err != nil {
-			log.Printf("activate error: %v", err)
+			slog.Error("activate", "err", err)
 		}
 	})
 
+	application.Connect("shutdown", a.markSessionClosed)
+
 	go func() {
 		<-ctx.Done()
 		glib.IdleAdd(func() bool {
@@ -110,6 +307,9 @@ func (a *App) Run(ctx context.Context) error {
 
 func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 	ensureTheme()
+	setLiteTheme(a.liteRendering())
+	applyWebProxy(a.preferences().Proxy)
+	a.watchReducedMotion()
 
 	window, err := gtk.ApplicationWindowNew(app)
 	if err != nil {
@@ -166,6 +366,14 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 		ctx.AddClass("suggested-action")
 	}
 
+	stopBtn, err := newStopButton()
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.stopBtn = stopBtn
+	a.mu.Unlock()
+
 	settingsBtn, err := gtk.ButtonNewWithLabel("LLM Settings")
 	if err != nil {
 		return fmt.Errorf("create settings button: %w", err)
@@ -176,6 +384,40 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 	}
 	settingsBtn.SetTooltipText("Adjust endpoint, model, and defaults")
 
+	menuBtn, err := gtk.MenuButtonNew()
+	if err != nil {
+		return fmt.Errorf("create menu button: %w", err)
+	}
+	menuBtn.SetName("chimera-btn-ghost")
+	if icon, err := gtk.ImageNewFromIconName("open-menu-symbolic", gtk.ICON_SIZE_BUTTON); err == nil {
+		menuBtn.SetImage(icon)
+	}
+	menuBtn.SetTooltipText("More actions")
+	menu := glib.MenuNew()
+	menu.Append("Start page", "app.home")
+	menu.Append("Summarize page", "app.summarize")
+	menu.Append("Translate page", "app.translate")
+	menu.Append("Explain simply", "app.eli5")
+	menu.Append("Page note…", "app.note")
+	menu.Append("Site preferences…", "app.site-settings")
+	menu.Append("Save as few-shot example", "app.save-example")
+	menu.Append("Bookmark this page", "app.bookmark")
+	menu.Append("Bookmarks", "app.bookmarks")
+	menu.Append("Search history", "app.search")
+	menu.Append("Watch this page", "app.watch")
+	menu.Append("Watched pages", "app.watches")
+	menu.Append("Clip to vault", "app.clip")
+	menu.Append("Copy citation", "app.cite")
+	menu.Append("Site map", "app.sitemap")
+	menu.Append("Original page", "app.raw")
+	menu.Append("Export composed page…", "app.export")
+	menu.Append("Export session…", "app.export-session")
+	menu.Append("Import session…", "app.import-session")
+	menu.Append("New workspace…", "app.workspace-new")
+	menu.Append("Edit workspace…", "app.workspace-edit")
+	menu.Append("Remove workspace", "app.workspace-remove")
+	menuBtn.SetMenuModel(&menu.MenuModel)
+
 	buttonRow, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 8)
 	if err != nil {
 		return fmt.Errorf("create action row: %w", err)
@@ -183,9 +425,19 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 	buttonRow.SetName("chimera-action-row")
 	buttonRow.SetHAlign(gtk.ALIGN_END)
 	buttonRow.SetVAlign(gtk.ALIGN_CENTER)
+	cost, err := newCostPreview()
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.cost = cost
+	a.mu.Unlock()
+	buttonRow.PackStart(cost.label, false, false, 0)
 	buttonRow.PackStart(scrapeBtn, false, false, 0)
 	buttonRow.PackStart(llmBtn, false, false, 0)
+	buttonRow.PackStart(stopBtn, false, false, 0)
 	buttonRow.PackStart(settingsBtn, false, false, 0)
+	buttonRow.PackStart(menuBtn, false, false, 0)
 
 	infoLabel, err := gtk.LabelNew("Ready")
 	if err != nil {
@@ -203,6 +455,18 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 	statusBar.SetMarginBottom(10)
 	statusBar.PackStart(infoLabel, true, true, 0)
 
+	versions, err := newVersionPicker()
+	if err != nil {
+		return err
+	}
+	statusBar.PackEnd(versions.box, false, false, 0)
+
+	audio, err := newAudioToggle()
+	if err != nil {
+		return err
+	}
+	statusBar.PackEnd(audio.button, false, false, 0)
+
 	toolbar.PackStart(entry, true, true, 0)
 	toolbar.PackStart(buttonRow, false, false, 0)
 
@@ -214,6 +478,13 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 	headerBar.SetTitle(a.cfg.AppTitle)
 	headerBar.SetName("chimera-header")
 	headerBar.SetCustomTitle(toolbar)
+	a.loadWorkspace()
+	workspaces, err := newWorkspacePicker()
+	if err != nil {
+		return err
+	}
+	a.refreshWorkspaces(workspaces)
+	headerBar.PackStart(workspaces.combo)
 	window.SetTitlebar(headerBar)
 
 	scroll, err := gtk.ScrolledWindowNew(nil, nil)
@@ -222,12 +493,6 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 	}
 	scroll.SetName("chimera-scroll")
 
-	webView, err := webkit.NewWebView()
-	if err != nil {
-		return fmt.Errorf("create webview: %w", err)
-	}
-	webView.Widget().SetName("chimera-webview")
-
 	spinner, err := gtk.SpinnerNew()
 	if err != nil {
 		return fmt.Errorf("create spinner: %w", err)
@@ -241,8 +506,35 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 	if err != nil {
 		return fmt.Errorf("create overlay: %w", err)
 	}
-	overlay.Add(webView.Widget())
+
+	var (
+		onNavigate     func(string) bool
+		onTerminated   func(string)
+		onAudioChanged func()
+		onURIChanged   func(string)
+	)
+	webView, err := newViewHost(overlay, func(view *webkit.WebView) {
+		view.OnNavigate(func(target string) bool {
+			return onNavigate(target)
+		})
+		view.OnProcessTerminated(func(reason string) {
+			onTerminated(reason)
+		})
+		view.SetMuted(audio.muted)
+		view.OnAudioChanged(func() {
+			onAudioChanged()
+		})
+		view.OnURIChanged(func(uri string) {
+			onURIChanged(uri)
+		})
+		a.applyLowVision(view)
+	})
+	if err != nil {
+		return err
+	}
 	overlay.AddOverlay(spinner)
+	onAudioChanged = func() { audio.update(webView) }
+	audio.button.Connect("toggled", func() { audio.toggle(webView) })
 
 	scroll.Add(overlay)
 
@@ -251,29 +543,65 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 
 	window.Add(root)
 	window.ShowAll()
+	versions.box.Hide()
 
 	a.updateLLMButton(llmBtn)
 
-	webView.OnNavigate(func(target string) bool {
-		resolved, ok := a.resolveTarget(target)
-		if !ok {
-			return false
+	navigateTask := func(target string, useLLM bool, task llm.Task) {
+		a.setStatus(infoLabel, "Scraping...")
+		a.setLastMode(useLLM)
+		navCtx := a.withRedirectPrompt(a.beginNavigation(ctx), window)
+		go a.handleScrape(navCtx, target, webView, infoLabel, spinner, versions, useLLM, task)
+	}
+	// openTab reopens a page saved in a session, in the mode and at the
+	// scroll position it had.
+	openTab := func(tab session.Tab) {
+		entry.SetText(tab.URL)
+		if isInternalURL(tab.URL) {
+			a.openInternal(a.beginNavigation(ctx), tab.URL, webView, infoLabel, spinner)
+			return
 		}
-
-		glib.IdleAdd(func() bool {
-			entry.SetText(resolved)
-			return false
-		})
-
+		useLLM, task := sessionTask(tab.Mode)
+		useLLM = useLLM && a.llmAvailable()
 		a.setStatus(infoLabel, "Scraping...")
-
-		useLLM := a.navigationMode()
 		a.setLastMode(useLLM)
+		navCtx := withScrollRestore(a.withRedirectPrompt(a.beginNavigation(ctx), window), tab.ScrollY)
+		go a.handleScrape(navCtx, tab.URL, webView, infoLabel, spinner, versions, useLLM, task)
+	}
 
-		go a.handleScrape(ctx, resolved, webView, infoLabel, spinner, useLLM)
+	// openWorkspace shows the page that was in front when w was left, or
+	// the start page.
+	openWorkspace := func(w workspace.Workspace) {
+		a.updateLLMButton(llmBtn)
+		if tab, ok := w.ActiveTab(); ok {
+			openTab(tab)
+			return
+		}
+		entry.SetText(startURL)
+		a.openInternal(a.beginNavigation(ctx), startURL, webView, infoLabel, spinner)
+	}
+	workspaces.combo.Connect("changed", func() {
+		if name, ok := workspaces.selected(); ok {
+			a.switchWorkspace(webView, infoLabel, name, openWorkspace)
+		}
+	})
+
+	// Closing the window first saves the page in front, with its scroll
+	// position, as the session to reopen.
+	closing := false
+	window.Connect("delete-event", func() bool {
+		if closing {
+			return false
+		}
+		closing = true
+		a.saveSessionOnExit(webView, window.Close)
 		return true
 	})
 
+	navigate := func(target string, useLLM bool) {
+		navigateTask(target, useLLM, a.siteTask(target))
+	}
+
 	scrape := func(useLLM bool) {
 		urlText, err := entry.GetText()
 		if err != nil {
@@ -285,10 +613,101 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 			a.setStatus(infoLabel, "Please provide a URL")
 			return
 		}
+		if strings.HasPrefix(trimmed, rawURI) {
+			a.openRaw(webView, entry, infoLabel, trimmed)
+			return
+		}
+		if isInternalURL(trimmed) {
+			a.openInternal(a.beginNavigation(ctx), trimmed, webView, infoLabel, spinner)
+			return
+		}
 
-		a.setStatus(infoLabel, "Scraping...")
-		a.setLastMode(useLLM)
-		go a.handleScrape(ctx, trimmed, webView, infoLabel, spinner, useLLM)
+		navigate(trimmed, useLLM)
+	}
+
+	reload := func(useLLM bool) {
+		if a.rawBrowsing() {
+			webView.current().Reload()
+			return
+		}
+		current := a.lastSourceURL()
+		if current == "" {
+			scrape(useLLM)
+			return
+		}
+		navigate(current, useLLM)
+	}
+
+	onNavigate = func(target string) bool {
+		if strings.HasPrefix(target, rawURI) {
+			a.openRaw(webView, entry, infoLabel, target)
+			return true
+		}
+		if target == reloadURI {
+			reload(a.navigationMode(a.lastSourceURL()))
+			return true
+		}
+		if isInternalURL(target) {
+			glib.IdleAdd(func() bool {
+				entry.SetText(target)
+				return false
+			})
+			a.openInternal(a.beginNavigation(ctx), target, webView, infoLabel, spinner)
+			return true
+		}
+		if a.rawBrowsing() || sameDocument(target, webView.current().URI()) {
+			return false
+		}
+
+		resolved, ok := a.resolveTarget(target)
+		if !ok {
+			return false
+		}
+		if a.preferences().CleanLinks {
+			if cleaned := tracking.Clean(resolved); cleaned != resolved {
+				slog.Info("cleaned link", "from", resolved, "to", cleaned)
+				resolved = cleaned
+			}
+		}
+
+		glib.IdleAdd(func() bool {
+			entry.SetText(resolved)
+			return false
+		})
+
+		navigate(resolved, a.navigationMode(resolved))
+		return true
+	}
+
+	// In raw mode the address bar follows the pages WebKit loads, so the
+	// Reader Mode and Compose buttons act on the page in front.
+	onURIChanged = func(uri string) {
+		if !a.rawBrowsing() || !(strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")) {
+			return
+		}
+		entry.SetText(uri)
+		a.setLastSource(uri)
+	}
+
+	runTask := func(task llm.Task) {
+		if !a.llmAvailable() {
+			a.setStatus(infoLabel, "Configure an LLM endpoint first")
+			return
+		}
+		current := a.lastSourceURL()
+		if current == "" {
+			a.setStatus(infoLabel, "Open a page first")
+			return
+		}
+		navigateTask(current, true, task)
+	}
+
+	openSettings := func() {
+		if err := a.openSettingsDialog(window, llmBtn, infoLabel); err != nil {
+			a.setStatus(infoLabel, fmt.Sprintf("Settings error: %v", err))
+		}
+		a.applyLowVision(webView.current())
+		a.refreshCost()
 	}
 
 	scrapeBtn.Connect("clicked", func() {
@@ -298,56 +717,467 @@ func (a *App) activate(ctx context.Context, app *gtk.Application) error {
 		scrape(true)
 	})
 
+	stopBtn.Connect("clicked", func() {
+		if a.stopGeneration() {
+			a.setStatus(infoLabel, "Stopping the LLM...")
+		}
+	})
+
 	entry.Connect("activate", func() {
-		scrape(a.prefersLLM())
+		text, _ := entry.GetText()
+		scrape(a.defaultMode(strings.TrimSpace(text)))
 	})
 
-	settingsBtn.Connect("clicked", func() {
-		if err := a.openSettingsDialog(window, llmBtn, infoLabel); err != nil {
-			a.setStatus(infoLabel, fmt.Sprintf("Settings error: %v", err))
+	settingsBtn.Connect("clicked", openSettings)
+
+	versions.combo.Connect("changed", func() {
+		if versions.updating {
+			return
+		}
+		selected, ok := versions.selected()
+		if !ok {
+			return
 		}
+		versions.updating = true
+		versions.pin.SetActive(selected.Pinned)
+		versions.pin.SetSensitive(true)
+		versions.refresh.SetSensitive(true)
+		versions.updating = false
+		page := a.currentPage()
+		webView.current().LoadHTML(a.calmPage(a.composedHTML(selected.HTML, withDarkMode)), a.pageBase(page.Result))
+		a.rememberPage(renderedPage{SourceURL: versions.url, Title: page.Title, HTML: selected.HTML, Model: selected.Model, Source: page.Source, Result: page.Result})
+		infoLabel.SetText(fmt.Sprintf("Showing composition from %s (%s)", versionLabel(selected), versionAge(selected, time.Now())))
 	})
 
+	versions.pin.Connect("toggled", func() {
+		if versions.updating {
+			return
+		}
+		selected, ok := versions.selected()
+		if !ok {
+			return
+		}
+		id := ""
+		if versions.pin.GetActive() {
+			id = selected.ID
+		}
+		if err := a.cfg.Compositions.Pin(versions.url, id); err != nil {
+			a.setStatus(infoLabel, fmt.Sprintf("Pin failed: %v", err))
+			return
+		}
+		a.showVersions(versions, versions.url, selected.ID)
+	})
+
+	versions.refresh.Connect("clicked", func() {
+		a.revalidateVersion(ctx, versions, infoLabel, func(target string, repin bool) {
+			a.setStatus(infoLabel, "The page changed — composing it again...")
+			a.setLastMode(true)
+			navCtx := withRecompose(a.withRedirectPrompt(a.beginNavigation(ctx), window), repin)
+			go a.handleScrape(navCtx, target, webView, infoLabel, spinner, versions, true, llm.TaskCompose)
+		})
+	})
+
+	onTerminated = func(reason string) {
+		a.stopNavigation()
+		a.recoverWebView(webView, infoLabel, reason)
+	}
+	webView.watch()
+
+	installShortcuts(app, []shortcut{
+		{name: "focus-url", accels: []string{"<Primary>l"}, run: func() {
+			entry.GrabFocus()
+		}},
+		{name: "reload", accels: []string{"<Primary>r"}, run: func() {
+			reload(a.navigationMode(a.lastSourceURL()))
+		}},
+		{name: "stop", accels: []string{"Escape"}, run: func() {
+			switch {
+			case a.stopGeneration():
+				a.setStatus(infoLabel, "Stopping the LLM...")
+			case a.stopNavigation():
+				a.setStatus(infoLabel, "Stopped")
+			}
+		}},
+		{name: "rescrape", accels: []string{"F5"}, run: func() {
+			reload(false)
+		}},
+		{name: "compose", accels: []string{"<Primary>Return", "<Primary>KP_Enter"}, run: func() {
+			if a.llmAvailable() {
+				scrape(true)
+			}
+		}},
+		{name: "settings", accels: []string{"<Primary>comma"}, run: openSettings},
+		{name: "home", accels: []string{"<Alt>Home"}, run: func() {
+			onNavigate(startURL)
+		}},
+		{name: "summarize", accels: []string{"<Primary><Shift>s"}, run: func() {
+			runTask(llm.TaskSummarize)
+		}},
+		{name: "translate", accels: []string{"<Primary><Shift>t"}, run: func() {
+			runTask(llm.TaskTranslate)
+		}},
+		{name: "eli5", accels: []string{"<Primary><Shift>e"}, run: func() {
+			runTask(llm.TaskELI5)
+		}},
+		{name: "note", accels: []string{"<Primary><Shift>n"}, run: func() {
+			if err := a.openNoteDialog(window, infoLabel); err != nil {
+				a.setStatus(infoLabel, fmt.Sprintf("Note error: %v", err))
+			}
+			a.refreshCost()
+		}},
+		{name: "site-settings", run: func() {
+			if err := a.openSiteDialog(window, webView, infoLabel); err != nil {
+				a.setStatus(infoLabel, fmt.Sprintf("Site preferences error: %v", err))
+			}
+			a.refreshCost()
+		}},
+		{name: "zoom-in", accels: []string{"<Primary>plus", "<Primary>equal", "<Primary>KP_Add"}, run: func() {
+			a.zoomBy(webView, infoLabel, zoomStep)
+		}},
+		{name: "zoom-out", accels: []string{"<Primary>minus", "<Primary>KP_Subtract"}, run: func() {
+			a.zoomBy(webView, infoLabel, -zoomStep)
+		}},
+		{name: "zoom-reset", accels: []string{"<Primary>0", "<Primary>KP_0"}, run: func() {
+			a.zoomBy(webView, infoLabel, 0)
+		}},
+		{name: "save-example", run: func() {
+			a.saveExample(infoLabel)
+		}},
+		{name: "raw", accels: []string{"<Primary>u"}, run: func() {
+			current := a.lastSourceURL()
+			if current == "" {
+				a.setStatus(infoLabel, "Open a page first")
+				return
+			}
+			if a.rawBrowsing() {
+				entry.SetText(current)
+				navigate(current, a.navigationMode(current))
+				return
+			}
+			a.openRaw(webView, entry, infoLabel, rawURI+"?url="+url.QueryEscape(current))
+		}},
+		{name: "bookmark", accels: []string{"<Primary>d"}, run: func() {
+			a.bookmarkPage(ctx, infoLabel)
+		}},
+		{name: "bookmarks", run: func() {
+			onNavigate(bookmarksURI)
+		}},
+		{name: "search", accels: []string{"<Primary>h"}, run: func() {
+			onNavigate(searchURI)
+		}},
+		{name: "clip", accels: []string{"<Primary><Shift>m"}, run: func() {
+			a.clipPage(infoLabel)
+		}},
+		{name: "watch", run: func() {
+			a.watchPage(infoLabel)
+		}},
+		{name: "watches", run: func() {
+			onNavigate(watchesURI)
+		}},
+		{name: "cite", run: func() {
+			a.citePage(infoLabel)
+		}},
+		{name: "sitemap", run: func() {
+			a.openSitemap(infoLabel, onNavigate)
+		}},
+		{name: "export", accels: []string{"<Primary>s"}, run: func() {
+			if err := a.exportPage(ctx, window, infoLabel); err != nil {
+				a.setStatus(infoLabel, fmt.Sprintf("Export error: %v", err))
+			}
+		}},
+		{name: "export-session", run: func() {
+			if err := a.exportSession(window, webView, infoLabel); err != nil {
+				a.setStatus(infoLabel, fmt.Sprintf("Session export error: %v", err))
+			}
+		}},
+		{name: "import-session", run: func() {
+			tab, ok, err := a.importSession(window, infoLabel)
+			if err != nil {
+				a.setStatus(infoLabel, fmt.Sprintf("Session import failed: %v", err))
+				return
+			}
+			if !ok {
+				return
+			}
+			openTab(tab)
+		}},
+		{name: "workspace-new", run: func() {
+			w, ok, err := a.openWorkspaceDialog(window, false)
+			if err != nil {
+				a.setStatus(infoLabel, fmt.Sprintf("Workspace error: %v", err))
+				return
+			}
+			if ok {
+				a.refreshWorkspaces(workspaces)
+				workspaces.combo.SetActiveID(w.Name)
+			}
+		}},
+		{name: "workspace-edit", run: func() {
+			_, ok, err := a.openWorkspaceDialog(window, true)
+			if err != nil {
+				a.setStatus(infoLabel, fmt.Sprintf("Workspace error: %v", err))
+				return
+			}
+			if ok {
+				a.refreshWorkspaces(workspaces)
+				a.updateLLMButton(llmBtn)
+				a.refreshCost()
+			}
+		}},
+		{name: "workspace-remove", run: func() {
+			removed := a.currentWorkspace().Name
+			next, err := a.removeWorkspace()
+			if err != nil {
+				a.setStatus(infoLabel, fmt.Sprintf("Workspace error: %v", err))
+				return
+			}
+			a.refreshWorkspaces(workspaces)
+			a.setStatus(infoLabel, fmt.Sprintf("Removed workspace %s", removed))
+			openWorkspace(next)
+		}},
+	})
+
+	previous, crashed, restorable := a.lastSession()
+	switch target, compose := a.startupTarget(); {
+	case restorable && !crashed && a.preferences().Startup == persist.StartupLastSession:
+		openTab(previous.ActiveTab())
+	case target == "":
+		a.setStatus(infoLabel, "Ready")
+	case isInternalURL(target):
+		entry.SetText(target)
+		a.openInternal(a.beginNavigation(ctx), target, webView, infoLabel, spinner)
+	default:
+		entry.SetText(target)
+		navigate(target, compose || a.navigationMode(target))
+	}
+	if restorable && crashed {
+		glib.IdleAdd(func() bool {
+			restore, err := a.confirmRestore(window, previous.ActiveTab())
+			switch {
+			case err != nil:
+				slog.Warn("ask to restore session", "err", err)
+			case restore:
+				openTab(previous.ActiveTab())
+			default:
+				a.forgetSession()
+			}
+			return false
+		})
+	}
+	a.warmUp(ctx)
+	a.keepModelWarm(ctx, window)
+	a.keepWatching(ctx, app)
+
 	return nil
 }
 
-func (a *App) handleScrape(ctx context.Context, target string, view *webkit.WebView, info *gtk.Label, spinner *gtk.Spinner, useLLM bool) {
+func (a *App) handleScrape(ctx context.Context, target string, view *viewHost, info *gtk.Label, spinner *gtk.Spinner, versions *versionPicker, useLLM bool, task llm.Task) {
+	defer a.endNavigation(ctx)
 	a.startSpinner(spinner)
 	defer a.stopSpinner(spinner)
 
-	result, err := a.cfg.Scraper.Scrape(ctx, target)
+	target, fragment := splitFragment(target)
+	scrollY := restoredScroll(ctx)
+	// land positions the rendered page at the requested #fragment, or where
+	// an imported session left it.
+	land := func(html string) string {
+		return withScroll(withFragment(html, fragment), scrollY)
+	}
+
+	status := func(text string) { a.setStatus(info, text) }
+	result, err := a.nav.Fetch(ctx, target, status)
+	if ctx.Err() != nil {
+		return
+	}
 	if err != nil {
-		a.renderError(view, info, fmt.Sprintf("Scrape failed: %v", err))
+		if a.showArchived(view, info, target, err) {
+			return
+		}
+		a.renderScrapeFailure(view, info, target, err)
 		return
 	}
 
-	a.setLastSource(result.SourceURL)
+	if result.NeedsScripts && !a.preferences().NoScriptRendering {
+		a.setStatus(info, "Running page scripts...")
+		rendered, err := a.renderWithScripts(ctx, result)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Warn("script rendering failed", "url", result.FinalURL, "err", err)
+		} else {
+			result = rendered
+		}
+	}
+
+	slog.Info("scraped", "url", result.SourceURL, "attempts", result.Attempts, "rendered", result.Rendered)
+	a.setLastSource(result.FinalURL)
+	visited := a.recordVisit(result)
+	a.runVisitHook(result)
+	key := pageKey(result)
+	a.applyZoom(view, result.SourceURL)
+	a.applySiteStyle(view, result.SourceURL)
 
 	client := a.currentLLM()
 
+	// genCtx carries the LLM requests, so the Stop button can end them and
+	// leave the page in reader mode.
+	genCtx := ctx
 	if useLLM && client != nil && client.Available() {
-		html, err := client.GeneratePage(ctx, result)
-		if err == nil {
-			a.renderHTML(view, info, html)
+		var endGeneration func()
+		genCtx, endGeneration = a.beginGeneration(ctx)
+		defer endGeneration()
+	}
+
+	if useLLM && client != nil && client.Available() && task == llm.TaskTranslate {
+		language := a.outputLanguage(task)
+		a.setStatus(info, fmt.Sprintf("Translating into %s...", language))
+		translated, reused, err := a.translatePage(genCtx, client, result, language)
+		if ctx.Err() != nil {
 			return
 		}
-
-		if llm.IsRateLimited(err) {
-			log.Printf("llm rate limited; falling back to scraped view: %v", err)
-			a.setStatus(info, "LLM rate limited — showing reader mode")
-			a.setLastMode(false)
-		} else {
-			a.renderError(view, info, fmt.Sprintf("LLM fallback: %v", err))
+		switch {
+		case generationStopped(genCtx):
+			useLLM = false
+		case err != nil:
+			a.renderError(view, info, fmt.Sprintf("Translation failed: %v", err))
+			return
+		default:
+			html, err := render.Simple(translated, a.readerOptions())
+			if err != nil {
+				a.renderError(view, info, fmt.Sprintf("Render error: %v", err))
+				return
+			}
+			a.renderPage(view, info, a.withPolicy(land(html)), a.pageBase(result))
+			a.rememberPage(renderedPage{SourceURL: result.SourceURL, Title: translated.Title, HTML: html, Task: task, Result: translated})
+			if reused > 0 {
+				a.setStatus(info, fmt.Sprintf("Translated into %s — %d of %d blocks from translation memory", language, reused, len(textFields(result))))
+			}
 			return
 		}
 	}
 
-	html, err := renderSimple(result)
-	if err != nil {
-		a.renderError(view, info, fmt.Sprintf("Render error: %v", err))
+	req := navigation.Request{Key: key, UseLLM: useLLM, Reader: a.readerOptions(), Status: status}
+	req.Recompose, req.Repin = recomposing(ctx)
+	req.Options = a.pageOptions(result.SourceURL, task)
+	if task == llm.TaskCompose && a.preferences().EnrichCompose {
+		req.EnrichLinks = enrichLinks
+	}
+	if task == llm.TaskCompose {
+		req.Screenshot = a.screenshotFor(result)
+		req.FetchLinks = a.preferences().FetchLinks
+	}
+
+	outcome, err := a.nav.Show(genCtx, req, result)
+	if ctx.Err() != nil {
 		return
 	}
-	a.renderHTML(view, info, html)
+	stopped := generationStopped(genCtx)
+	if stopped && req.UseLLM {
+		req.UseLLM = false
+		outcome, err = a.nav.Show(ctx, req, result)
+	}
+	var composeErr *navigation.ComposeError
+	switch {
+	case errors.As(err, &composeErr) && task != llm.TaskCompose:
+		a.renderError(view, info, fmt.Sprintf("LLM request failed: %v", composeErr.Err))
+		return
+	case errors.As(err, &composeErr):
+		a.renderError(view, info, fmt.Sprintf("LLM fallback: %v", composeErr.Err))
+		return
+	case err != nil:
+		a.renderError(view, info, fmt.Sprintf("Render error: %v", errors.Unwrap(err)))
+		return
+	}
+
+	page := renderedPage{SourceURL: result.SourceURL, Title: result.Title, HTML: outcome.HTML, Result: result}
+	if outcome.Model != "" {
+		page.Model, page.Source = outcome.Model, llm.SourceText(result)
+	}
+	if outcome.Model != "" && !outcome.FromPin {
+		page.Task = task
+	}
+	shown := a.withPolicy(land(outcome.HTML))
+	if outcome.Model != "" {
+		shown = a.composedHTML(outcome.HTML, func(html string) string {
+			return land(withDarkMode(withFidelityWarning(html, outcome.Fidelity)))
+		})
+	}
+	a.renderPage(view, info, shown, a.pageBase(result))
+	a.rememberPage(page)
+	if task != llm.TaskCompose && outcome.Model != "" {
+		if served := servedStatus(outcome); served != "" {
+			a.setStatus(info, served)
+		}
+		return
+	}
+	if outcome.FromPin {
+		a.showVersions(versions, key, outcome.Composition.ID)
+		a.setStatus(info, fmt.Sprintf("Showing pinned composition from %s (%s)", versionLabel(outcome.Composition), versionAge(outcome.Composition, time.Now())))
+		return
+	}
+	a.reportScrape(info, result, visited)
+	a.showVersions(versions, key, outcome.Composition.ID)
+	switch {
+	case stopped:
+		a.setStatus(info, "LLM stopped — showing reader mode")
+	case outcome.RateLimited:
+		a.setStatus(info, "LLM rate limited — showing reader mode")
+		a.setLastMode(false)
+	case useLLM && outcome.Paused > 0:
+		a.setStatus(info, fmt.Sprintf("LLM paused, retry in %ds — showing reader mode", int(outcome.Paused.Round(time.Second)/time.Second)))
+	case outcome.Fidelity.Suspect():
+		a.setStatus(info, fidelityStatus(outcome.Fidelity))
+	case servedStatus(outcome) != "":
+		a.setStatus(info, servedStatus(outcome))
+	}
+}
+
+func (a *App) reportScrape(info *gtk.Label, result *scraper.Result, visited string) {
+	var notes []string
+	if result.Words > 0 {
+		reading := fmt.Sprintf("%d words, %d min read", result.Words, result.ReadingMinutes)
+		if result.Language != "" {
+			reading += ", " + locale.Name(result.Language)
+		}
+		notes = append(notes, reading)
+	}
+	if visited != "" {
+		notes = append(notes, visited)
+	}
+	if result.Attempts > 1 {
+		notes = append(notes, fmt.Sprintf("fetched after %d attempts", result.Attempts))
+	}
+	if result.Rendered {
+		notes = append(notes, "rendered with JavaScript")
+	}
+	if result.ConsentWall != "" {
+		notes = append(notes, fmt.Sprintf("skipped %s consent wall", result.ConsentWall))
+	}
+	if len(result.JoinedPages) > 0 {
+		notes = append(notes, fmt.Sprintf("joined %d pages", len(result.JoinedPages)+1))
+	}
+	if len(result.Redirects) > 0 {
+		notes = append(notes, fmt.Sprintf("redirected %s → %s", strings.Join(result.Redirects, " → "), result.FinalURL))
+	}
+	if result.Robots.Checked && !result.Robots.Allowed {
+		notes = append(notes, fmt.Sprintf("robots.txt disallows this page (%s)", result.Robots.Rule))
+	}
+	if len(notes) == 0 {
+		return
+	}
+	a.setStatus(info, "Done — "+strings.Join(notes, "; "))
+}
+
+func (a *App) showVersions(versions *versionPicker, url, activeID string) {
+	entries, err := a.cfg.Compositions.List(url)
+	if err != nil {
+		slog.Warn("list compositions", "url", url, "err", err)
+	}
+	glib.IdleAdd(func() bool {
+		versions.show(url, entries, activeID)
+		return false
+	})
 }
 
 func (a *App) setStatus(label *gtk.Label, text string) {
@@ -357,86 +1187,28 @@ func (a *App) setStatus(label *gtk.Label, text string) {
 	})
 }
 
-func (a *App) renderHTML(view *webkit.WebView, info *gtk.Label, html string) {
+func (a *App) renderHTML(view *viewHost, info *gtk.Label, html string) {
+	a.renderPage(view, info, html, "")
+}
+
+// renderPage shows html with relative URLs resolved against base.
+func (a *App) renderPage(view *viewHost, info *gtk.Label, html, base string) {
 	glib.IdleAdd(func() bool {
-		view.LoadHTML(html, "")
+		view.current().LoadHTML(a.calmPage(html), base)
 		info.SetText("Done")
 		return false
 	})
 }
 
-func (a *App) renderError(view *webkit.WebView, info *gtk.Label, msg string) {
-	log.Println(msg)
+func (a *App) renderError(view *viewHost, info *gtk.Label, msg string) {
+	slog.Error(msg)
 	glib.IdleAdd(func() bool {
-		view.InjectStatusBubble("Something went wrong", msg)
+		view.current().InjectStatusBubble("Something went wrong", msg)
 		info.SetText("Error")
 		return false
 	})
 }
 
-var simpleTmpl = template.Must(template.New("simple").Funcs(template.FuncMap{
-	"formatTime": func(t time.Time) string {
-		if t.IsZero() {
-			return ""
-		}
-		return t.Format("02 Jan 2006 15:04 MST")
-	},
-}).Parse(`<!DOCTYPE html>
-<html lang="en">
-<head>
-<meta charset="utf-8" />
-<title>{{ if .Title }}{{ .Title }} — Chimera{{ else }}Chimera Summary{{ end }}</title>
-<style>
-body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
-header { border-bottom: 1px solid #d4d9e2; margin-bottom: 1.5rem; padding-bottom: 1rem; }
-h1 { margin: 0 0 .5rem 0; font-size: 2.4rem; }
-section { margin-bottom: 2rem; background: #fff; border-radius: 12px; padding: 1.5rem; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
-h2 { font-size: 1.5rem; margin-top: 0; }
-ul { padding-left: 1.2rem; }
-a { color: #2b5dcc; text-decoration: none; }
-a:hover { text-decoration: underline; }
-small { color: #5b6576; }
-</style>
-</head>
-<body>
-<header>
-  <h1>{{ if .Title }}{{ .Title }}{{ else }}Scraped Summary{{ end }}</h1>
-  <small>Source: <a href="{{ .SourceURL }}">{{ .SourceURL }}</a>{{ if .FetchedAt }} • {{ formatTime .FetchedAt }}{{ end }}</small>
-  {{ if .Description }}<p>{{ .Description }}</p>{{ end }}
-</header>
-<section>
-  <h2>Key Headings</h2>
-  {{ if .Headings }}
-  <ul>
-    {{ range .Headings }}<li><strong>H{{ .Level }}</strong> — {{ .Text }}</li>{{ end }}
-  </ul>
-  {{ else }}<p>No major headings detected.</p>{{ end }}
-</section>
-<section>
-  <h2>Highlights</h2>
-  {{ if .Paragraphs }}
-  {{ range .Paragraphs }}<p>{{ . }}</p>{{ end }}
-  {{ else }}<p>Not enough textual content found.</p>{{ end }}
-</section>
-<section>
-  <h2>Links</h2>
-  {{ if .Links }}
-  <ul>
-    {{ range .Links }}<li><a href="{{ .Href }}" target="_blank" rel="noopener">{{ .Text }}</a></li>{{ end }}
-  </ul>
-  {{ else }}<p>No links captured.</p>{{ end }}
-</section>
-</body>
-</html>`))
-
-func renderSimple(data *scraper.Result) (string, error) {
-	var builder strings.Builder
-	if err := simpleTmpl.Execute(&builder, data); err != nil {
-		return "", err
-	}
-	return builder.String(), nil
-}
-
 func (a *App) currentLLM() *llm.Client {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -460,7 +1232,17 @@ func (a *App) prefersLLM() bool {
 	return a.llmClient.Available()
 }
 
-func (a *App) navigationMode() bool {
+// enrichLinks is how many linked pages an enriched composition reads.
+const enrichLinks = 3
+
+// navigationMode picks the mode for following a link to target: the mode
+// remembered for its domain, else the mode last used, else the workspace's
+// or the default.
+func (a *App) navigationMode(target string) bool {
+	if use, ok := a.siteMode(target); ok {
+		return use
+	}
+
 	a.mu.RLock()
 	use := a.llmLastMode
 	set := a.llmLastSet
@@ -475,6 +1257,9 @@ func (a *App) navigationMode() bool {
 		}
 		return false
 	}
+	if use, ok := a.workspaceMode(); ok {
+		return use
+	}
 
 	return preferred && available
 }
@@ -486,11 +1271,66 @@ func (a *App) setLastMode(use bool) {
 	a.mu.Unlock()
 }
 
+// navIDKey holds the id beginNavigation gave a navigation's context.
+type navIDKey struct{}
+
+func (a *App) beginNavigation(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+	a.mu.Lock()
+	if a.navCancel != nil {
+		a.navCancel()
+	}
+	a.navCancel = cancel
+	a.navID++
+	ctx = context.WithValue(ctx, navIDKey{}, a.navID)
+	a.rawMode = false
+	a.mu.Unlock()
+	return ctx
+}
+
+// endNavigation releases the navigation ctx, from beginNavigation, once
+// its page is shown, so stopNavigation has nothing left to stop. A newer
+// navigation is left running.
+func (a *App) endNavigation(ctx context.Context) {
+	id, _ := ctx.Value(navIDKey{}).(uint64)
+	a.mu.Lock()
+	cancel := a.navCancel
+	current := id != 0 && a.navID == id
+	if current {
+		a.navCancel = nil
+	}
+	a.mu.Unlock()
+	if current && cancel != nil {
+		cancel()
+	}
+}
+
+func (a *App) stopNavigation() bool {
+	a.mu.Lock()
+	cancel := a.navCancel
+	a.navCancel = nil
+	a.mu.Unlock()
+
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	return true
+}
+
 func (a *App) startSpinner(spinner *gtk.Spinner) {
 	if spinner == nil {
 		return
 	}
 	glib.IdleAdd(func() bool {
+		// With reduced motion the spinner shows a still icon instead of spinning.
+		if style, err := spinner.GetStyleContext(); err == nil {
+			if a.reducedMotion() {
+				style.AddClass("static")
+			} else {
+				style.RemoveClass("static")
+			}
+		}
 		spinner.Show()
 		spinner.Start()
 		return false
@@ -560,198 +1400,112 @@ func (a *App) resolveTarget(target string) (string, bool) {
 	}
 }
 
-func (a *App) updateLLMButton(button *gtk.Button) {
-	available := a.llmAvailable()
-	button.SetSensitive(available)
-	if available {
-		button.SetTooltipText("Generate a composed page via the configured LLM")
-	} else {
-		button.SetTooltipText("Configure an OpenAI-compatible endpoint to enable")
-	}
+func (a *App) settingsSnapshot() (appLLMSettings, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.llmSettings, a.llmPreferred
 }
 
-func (a *App) openSettingsDialog(parent *gtk.ApplicationWindow, llmBtn *gtk.Button, status *gtk.Label) error {
-	dialog, err := gtk.DialogNew()
-	if err != nil {
-		return fmt.Errorf("create dialog: %w", err)
-	}
-	defer dialog.Destroy()
-
-	dialog.SetTitle("LLM Settings")
-	dialog.SetModal(true)
-	dialog.SetTransientFor(parent)
-	dialog.AddButton("Cancel", gtk.RESPONSE_CANCEL)
-	dialog.AddButton("Save", gtk.RESPONSE_OK)
-
-	content, err := dialog.GetContentArea()
-	if err != nil {
-		return fmt.Errorf("access content area: %w", err)
-	}
-
-	grid, err := gtk.GridNew()
-	if err != nil {
-		return fmt.Errorf("create grid: %w", err)
-	}
-	grid.SetRowSpacing(10)
-	grid.SetColumnSpacing(14)
-	grid.SetMarginTop(14)
-	grid.SetMarginBottom(14)
-	grid.SetMarginStart(18)
-	grid.SetMarginEnd(18)
-
-	snapshot, prefer := a.settingsSnapshot()
-
-	baseLabel, err := gtk.LabelNew("Base URL")
-	if err != nil {
-		return fmt.Errorf("create base label: %w", err)
-	}
-	baseLabel.SetXAlign(0)
-	grid.Attach(baseLabel, 0, 0, 1, 1)
-
-	baseEntry, err := gtk.EntryNew()
-	if err != nil {
-		return fmt.Errorf("create base entry: %w", err)
-	}
-	baseEntry.SetPlaceholderText("https://api.openai.com")
-	baseEntry.SetWidthChars(42)
-	baseEntry.SetText(snapshot.BaseURL)
-	grid.Attach(baseEntry, 1, 0, 1, 1)
-
-	modelLabel, err := gtk.LabelNew("Model")
-	if err != nil {
-		return fmt.Errorf("create model label: %w", err)
-	}
-	modelLabel.SetXAlign(0)
-	grid.Attach(modelLabel, 0, 1, 1, 1)
-
-	modelEntry, err := gtk.EntryNew()
-	if err != nil {
-		return fmt.Errorf("create model entry: %w", err)
-	}
-	modelEntry.SetPlaceholderText("gpt-4o-mini, llama3, mistral-nemo...")
-	modelEntry.SetText(snapshot.Model)
-	grid.Attach(modelEntry, 1, 1, 1, 1)
+func (a *App) preferences() appPreferences {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.prefs
+}
 
-	keyLabel, err := gtk.LabelNew("API Key")
-	if err != nil {
-		return fmt.Errorf("create key label: %w", err)
+func (a *App) outputLanguage(task llm.Task) string {
+	prefs := a.preferences()
+	override := prefs.SummaryLanguage
+	if task == llm.TaskTranslate {
+		override = prefs.TranslationLanguage
 	}
-	keyLabel.SetXAlign(0)
-	grid.Attach(keyLabel, 0, 2, 1, 1)
-
-	keyEntry, err := gtk.EntryNew()
-	if err != nil {
-		return fmt.Errorf("create key entry: %w", err)
+	if strings.TrimSpace(override) != "" {
+		return strings.TrimSpace(override)
 	}
-	keyEntry.SetVisibility(false)
-	keyEntry.SetInputPurpose(gtk.INPUT_PURPOSE_PASSWORD)
-	keyEntry.SetText(snapshot.APIKey)
-	grid.Attach(keyEntry, 1, 2, 1, 1)
+	return locale.Language()
+}
 
-	preferCheck, err := gtk.CheckButtonNewWithLabel("Use LLM by default when pressing Enter")
-	if err != nil {
-		return fmt.Errorf("create preference checkbox: %w", err)
-	}
-	preferCheck.SetActive(prefer)
-	grid.Attach(preferCheck, 0, 3, 2, 1)
+func (a *App) liteRendering() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lite
+}
 
-	content.Add(grid)
-	dialog.ShowAll()
+// appPreferences holds persisted options that are not part of the LLM endpoint configuration.
+type appPreferences struct {
+	Rendering string
 
-	response := dialog.Run()
-	if response != gtk.RESPONSE_OK {
-		return nil
-	}
+	// SummaryLanguage and TranslationLanguage override the UI locale for the respective action.
+	SummaryLanguage     string
+	TranslationLanguage string
 
-	base, err := baseEntry.GetText()
-	if err != nil {
-		return fmt.Errorf("read base URL: %w", err)
-	}
-	model, err := modelEntry.GetText()
-	if err != nil {
-		return fmt.Errorf("read model: %w", err)
-	}
-	key, err := keyEntry.GetText()
-	if err != nil {
-		return fmt.Errorf("read API key: %w", err)
-	}
+	PlaintextAPIKey bool
 
-	updated := appLLMSettings{
-		BaseURL: strings.TrimSpace(base),
-		Model:   strings.TrimSpace(model),
-		APIKey:  strings.TrimSpace(key),
-	}
+	Sites map[string]persist.Site
 
-	preferLLM := preferCheck.GetActive()
+	EnrichCompose  bool
+	FetchLinks     bool
+	PersonalDigest bool
 
-	if err := a.applySettings(updated, preferLLM); err != nil {
-		return fmt.Errorf("apply settings: %w", err)
-	}
+	Proxy      string
+	WarmUp     bool
+	CleanLinks bool
 
-	a.updateLLMButton(llmBtn)
+	UserAgent      string
+	AcceptLanguage string
 
-	switch {
-	case preferLLM && !a.llmAvailable():
-		a.setStatus(status, "LLM preference saved but endpoint unavailable")
-	case a.llmAvailable():
-		a.setStatus(status, "LLM configured")
-	default:
-		a.setStatus(status, "LLM disabled")
-	}
+	NoScriptRendering bool
+	SkipConsentWalls  bool
 
-	return nil
-}
+	Limits          scraper.Limits
+	KeepBoilerplate bool
+	JoinPages       bool
+	VisitCommand    string
+	VaultDir        string
+	VaultTags       string
+	CitationFormat  string
+	CitationFile    string
 
-func (a *App) applySettings(settings appLLMSettings, prefer bool) error {
-	settings = appLLMSettings{
-		BaseURL: strings.TrimSpace(settings.BaseURL),
-		Model:   strings.TrimSpace(settings.Model),
-		APIKey:  strings.TrimSpace(settings.APIKey),
-	}
+	MaxRedirects       int
+	CrossSiteRedirects string
 
-	cfg := llm.Config{
-		BaseURL: settings.BaseURL,
-		Model:   settings.Model,
-		APIKey:  settings.APIKey,
-		Timeout: a.llmTimeout,
-	}
+	LowVision   bool
+	MinFontSize int
+	MinContrast float64
 
-	client := llm.NewClient(cfg)
+	Startup        string
+	StartupURL     string
+	StartupCompose bool
 
-	a.mu.Lock()
-	a.llmClient = client
-	a.llmPreferred = prefer
-	a.llmSettings = settings
-	a.cfg.LLM = client
-	a.cfg.UseLLM = prefer
-	a.cfg.LLMConfig = cfg
-	a.mu.Unlock()
+	Typography render.Typography
 
-	if a.settingsStore != nil {
-		data := persist.Data{
-			BaseURL: settings.BaseURL,
-			Model:   settings.Model,
-			APIKey:  settings.APIKey,
-			UseLLM:  prefer,
-		}
-		if err := a.settingsStore.Save(data); err != nil {
-			return fmt.Errorf("save settings: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func (a *App) settingsSnapshot() (appLLMSettings, bool) {
-	a.mu.RLock()
-	defer a.mu.RUnlock()
-	return a.llmSettings, a.llmPreferred
+	BlockExternal bool
+	SourceBase    bool
+	Prompts       map[string]string
 }
 
 type appLLMSettings struct {
 	BaseURL string
-	Model   string
-	APIKey  string
+	// Endpoint is the kind of API at BaseURL.
+	Endpoint llm.Endpoint
+	Model    string
+	APIKey   string
+
+	ContextTokens  int
+	SystemPrompt   string
+	EmbeddingModel string
+	// InputPrice is the endpoint's price per 1000 prompt tokens.
+	InputPrice float64
+	// VisionModels are the models sent screenshots of visually complex
+	// pages.
+	VisionModels []string
+	// Fallbacks are tried in order when the endpoint fails a request.
+	Fallbacks []llm.Provider
+	// Routing picks OpenRouter's upstream providers.
+	Routing llm.Routing
+	// PromptFormat is the chat template used with llama.cpp's /completion.
+	PromptFormat string
+	// MaxInFlight is how many requests each provider is sent at once.
+	MaxInFlight int
 }
 
 var cssOnce sync.Once
@@ -773,6 +1527,55 @@ func ensureTheme() {
 	})
 }
 
+var (
+	liteProvider *gtk.CssProvider
+	liteApplied  bool
+)
+
+// setLiteTheme layers flat styling over appCSS. Must be called on the GTK main loop.
+func setLiteTheme(enabled bool) {
+	if enabled == liteApplied {
+		return
+	}
+	screen, err := gdk.ScreenGetDefault()
+	if err != nil || screen == nil {
+		return
+	}
+	if liteProvider == nil {
+		provider, err := gtk.CssProviderNew()
+		if err != nil {
+			return
+		}
+		if err := provider.LoadFromData(liteCSS); err != nil {
+			return
+		}
+		liteProvider = provider
+	}
+
+	if enabled {
+		gtk.AddProviderForScreen(screen, liteProvider, gtk.STYLE_PROVIDER_PRIORITY_APPLICATION+1)
+	} else {
+		gtk.RemoveProviderForScreen(screen, liteProvider)
+	}
+	liteApplied = enabled
+}
+
+const liteCSS = `
+#chimera-toolbar, #chimera-status-bar, #chimera-webview {
+    box-shadow: none;
+    border-radius: 6px;
+}
+
+#chimera-btn-primary {
+    background: #4f6ef7;
+}
+
+#chimera-url-entry {
+    background: #ffffff;
+    border-radius: 6px;
+}
+`
+
 const appCSS = `
 #chimera-window {
     background: #eef1f8;
@@ -864,4 +1667,9 @@ const appCSS = `
     background: rgba(239, 242, 255, 0.86);
     padding: 12px;
 }
+
+#chimera-spinner.static {
+    animation: none;
+    -gtk-icon-source: -gtk-icontheme("content-loading-symbolic");
+}
 `
@@ -0,0 +1,64 @@
+package browser
+
+import (
+	"fmt"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// audioToggle is the speaker in the status bar. It shows while the page
+// plays audio, or stays muted, and mutes or unmutes it. Its methods must be
+// called on the GTK main loop.
+type audioToggle struct {
+	button *gtk.ToggleButton
+	icon   *gtk.Image
+	// muted outlives the view, so a view recreated after a crash stays
+	// silent.
+	muted    bool
+	updating bool
+}
+
+func newAudioToggle() (*audioToggle, error) {
+	button, err := gtk.ToggleButtonNew()
+	if err != nil {
+		return nil, fmt.Errorf("create audio button: %w", err)
+	}
+	button.SetName("chimera-btn-ghost")
+	icon, err := gtk.ImageNewFromIconName("audio-volume-high-symbolic", gtk.ICON_SIZE_BUTTON)
+	if err != nil {
+		return nil, fmt.Errorf("create audio icon: %w", err)
+	}
+	button.SetImage(icon)
+	button.SetNoShowAll(true)
+	return &audioToggle{button: button, icon: icon}, nil
+}
+
+// update shows the speaker for the page in host and whether it is muted.
+func (t *audioToggle) update(host *viewHost) {
+	view := host.current()
+	playing := view.IsPlayingAudio()
+	t.muted = view.IsMuted()
+
+	t.updating = true
+	t.button.SetActive(t.muted)
+	t.updating = false
+
+	switch {
+	case t.muted:
+		t.icon.SetFromIconName("audio-volume-muted-symbolic", gtk.ICON_SIZE_BUTTON)
+		t.button.SetTooltipText("Page muted — click to play its sound")
+	default:
+		t.icon.SetFromIconName("audio-volume-high-symbolic", gtk.ICON_SIZE_BUTTON)
+		t.button.SetTooltipText("Page is playing audio — click to mute")
+	}
+	t.button.SetVisible(playing || t.muted)
+}
+
+// toggle mutes the page in host, or unmutes it.
+func (t *audioToggle) toggle(host *viewHost) {
+	if t.updating {
+		return
+	}
+	host.current().SetMuted(t.button.GetActive())
+	t.update(host)
+}
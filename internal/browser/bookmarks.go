@@ -0,0 +1,221 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"chimera/internal/bookmarks"
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// bookmarksURI lists the bookmarks with the outcome of their last link check.
+const bookmarksURI = "chimera://bookmarks"
+
+// healthTTL is how long a link check result is trusted before "Check links"
+// asks the server again.
+const healthTTL = 24 * time.Hour
+
+// maxHealthChecks caps how many bookmarks are checked at once.
+const maxHealthChecks = 4
+
+// bookmarkPage bookmarks the current page and saves it, with its images,
+// for offline reading.
+func (a *App) bookmarkPage(ctx context.Context, info *gtk.Label) {
+	page := a.currentPage()
+	if page.SourceURL == "" {
+		a.setStatus(info, "Nothing to bookmark yet")
+		return
+	}
+
+	if err := a.cfg.Bookmarks.Add(bookmarks.Bookmark{URL: page.SourceURL, Title: page.Title}); err != nil {
+		a.setStatus(info, fmt.Sprintf("Bookmark failed: %v", err))
+		return
+	}
+	if a.cfg.Archive == nil || page.HTML == "" || page.Archived {
+		a.setStatus(info, "Bookmarked")
+		return
+	}
+
+	a.setStatus(info, "Bookmarked — saving an offline copy...")
+	go func() {
+		saved, err := a.archivePage(ctx, page)
+		if err != nil {
+			slog.Warn("archive bookmarked page", "url", page.SourceURL, "err", err)
+			a.setStatus(info, fmt.Sprintf("Bookmarked, but the offline copy failed: %v", err))
+			return
+		}
+		a.setStatus(info, fmt.Sprintf("Bookmarked and saved for offline reading (%d images)", len(saved.Images)))
+	}()
+}
+
+func (a *App) bookmarksPage(ctx context.Context) (string, error) {
+	saved, err := a.cfg.Bookmarks.List()
+	if err != nil {
+		return "", err
+	}
+
+	dead, checked := 0, 0
+	for _, b := range saved {
+		if b.Health != nil {
+			checked++
+			if b.Health.Dead {
+				dead++
+			}
+		}
+	}
+
+	offline := make(map[string]string)
+	for _, b := range saved {
+		if savedAt, ok := a.cfg.Archive.SavedAt(b.URL); ok {
+			offline[b.URL] = savedAt.Local().Format("2006-01-02 15:04")
+		}
+	}
+
+	var builder strings.Builder
+	err = bookmarksTmpl.Execute(&builder, struct {
+		Bookmarks []bookmarks.Bookmark
+		Dead      int
+		Checked   int
+		TTLHours  int
+		// Offline maps bookmarks with an archived copy to when it was saved.
+		Offline map[string]string
+	}{saved, dead, checked, int(healthTTL.Hours()), offline})
+	return builder.String(), err
+}
+
+// checkBookmarksPage checks the links of all bookmarks whose last check is
+// older than healthTTL, or of every bookmark when force is set, and lists them.
+func (a *App) checkBookmarksPage(force bool) internalPage {
+	return func(ctx context.Context) (string, error) {
+		saved, err := a.cfg.Bookmarks.List()
+		if err != nil {
+			return "", err
+		}
+
+		var (
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+			results = make(map[string]bookmarks.Health)
+			slots   = make(chan struct{}, maxHealthChecks)
+		)
+		for _, b := range saved {
+			if !force && b.Health != nil && time.Since(b.Health.CheckedAt) < healthTTL {
+				continue
+			}
+			wg.Add(1)
+			go func(target string) {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+
+				result, err := a.cfg.Scraper.Check(ctx, target)
+				if ctx.Err() != nil {
+					return
+				}
+				mu.Lock()
+				results[target] = bookmarkHealth(target, result, err)
+				mu.Unlock()
+			}(b.URL)
+		}
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		if err := a.cfg.Bookmarks.SetHealth(results); err != nil {
+			return "", err
+		}
+		return a.bookmarksPage(ctx)
+	}
+}
+
+// removeBookmarkPage deletes the bookmark for target and lists the rest.
+func (a *App) removeBookmarkPage(target string) internalPage {
+	return func(ctx context.Context) (string, error) {
+		if err := a.cfg.Bookmarks.Remove(target); err != nil {
+			return "", err
+		}
+		if err := a.cfg.Archive.Remove(target); err != nil {
+			return "", err
+		}
+		return a.bookmarksPage(ctx)
+	}
+}
+
+// bookmarkHealth turns the outcome of a link check into a Health record. A
+// missing page or a host that no longer resolves is dead; timeouts, server
+// errors and the like may pass, so they are only reported.
+func bookmarkHealth(target string, result scraper.CheckResult, err error) bookmarks.Health {
+	health := bookmarks.Health{CheckedAt: time.Now()}
+	if err == nil {
+		health.Status = result.Status
+		if result.FinalURL != "" && strings.TrimSuffix(result.FinalURL, "/") != strings.TrimSuffix(target, "/") {
+			health.MovedTo = result.FinalURL
+		}
+		return health
+	}
+
+	var fetchErr *scraper.FetchError
+	if !errors.As(err, &fetchErr) {
+		health.Problem = err.Error()
+		health.Dead = true
+		return health
+	}
+	health.Status = fetchErr.Status()
+	health.Problem = fetchErr.Kind.String()
+	if health.Status != 0 {
+		health.Problem = fmt.Sprintf("HTTP %d %s", health.Status, http.StatusText(health.Status))
+	}
+	health.Dead = fetchErr.Kind == scraper.FailureDNS ||
+		health.Status == http.StatusNotFound || health.Status == http.StatusGone
+	return health
+}
+
+var bookmarksTmpl = template.Must(template.New("bookmarks").Funcs(template.FuncMap{
+	"archive": func(target string) string { return archivePrefix + target },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Bookmarks — Chimera</title>
+<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+h1 { margin: 0 0 .5rem 0; }
+table { width: 100%; border-collapse: collapse; background: #fff; border-radius: 12px; overflow: hidden; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+th, td { text-align: left; padding: .6rem 1rem; border-bottom: 1px solid #e5e8ef; }
+tr.dead td { background: #fdf1f1; }
+.dead-label { color: #b3261e; font-weight: 600; }
+a { color: #2b5dcc; text-decoration: none; }
+small { color: #5b6576; }
+code { word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>Bookmarks</h1>
+<p><small>{{ len .Bookmarks }} bookmarks • {{ .Checked }} checked • {{ .Dead }} dead •
+<a href="chimera://bookmarks/check">Check links</a> (results are reused for {{ .TTLHours }} hours) •
+<a href="chimera://bookmarks/check?force=1">Recheck all</a></small></p>
+{{ if .Bookmarks }}
+<table>
+<thead><tr><th>Page</th><th>Link</th><th></th></tr></thead>
+<tbody>
+{{ range $b := .Bookmarks }}<tr{{ if and .Health .Health.Dead }} class="dead"{{ end }}>
+<td><a href="{{ .URL }}">{{ if .Title }}{{ .Title }}{{ else }}{{ .URL }}{{ end }}</a><br /><small><code>{{ .URL }}</code></small></td>
+<td>{{ with .Health }}{{ if .Dead }}<span class="dead-label">Dead</span> — {{ .Problem }}{{ else if .Problem }}Unreachable — {{ .Problem }}{{ else if .MovedTo }}Moved to <a href="{{ .MovedTo }}">{{ .MovedTo }}</a>{{ else }}OK{{ end }}<br /><small>checked {{ .CheckedAt.Format "2006-01-02 15:04" }}</small>{{ else }}<small>Not checked</small>{{ end }}</td>
+<td>{{ with index $.Offline $b.URL }}<a href="chimera://offline?url={{ $b.URL }}">Offline copy</a><br /><small>saved {{ . }}</small><br />{{ end }}{{ if and .Health .Health.Problem }}<a href="{{ archive .URL }}">Archived copy</a><br />{{ end }}<a href="chimera://bookmarks/remove?url={{ .URL }}">Remove</a></td>
+</tr>
+{{ end }}
+</tbody>
+</table>
+{{ else }}<p>No bookmarks yet. Bookmark a page with Ctrl+D.</p>{{ end }}
+</body>
+</html>`))
@@ -0,0 +1,54 @@
+package browser
+
+import (
+	"fmt"
+	"time"
+
+	"chimera/internal/export"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// citePage copies a citation of the current page to the clipboard in the
+// format from the settings, and appends it to the bibliography file if one
+// is set.
+func (a *App) citePage(info *gtk.Label) {
+	page := a.currentPage()
+	if page.Result == nil {
+		a.setStatus(info, "Nothing to cite yet")
+		return
+	}
+	prefs := a.preferences()
+	citation := export.Citation{
+		URL:      pageKey(page.Result),
+		Accessed: time.Now(),
+		Result:   page.Result,
+	}
+
+	text, err := export.FormatCitation(prefs.CitationFormat, citation)
+	if err != nil {
+		a.setStatus(info, fmt.Sprintf("Citation failed: %v", err))
+		return
+	}
+	clipboard, err := gtk.ClipboardGet(gdk.SELECTION_CLIPBOARD)
+	if err != nil {
+		a.setStatus(info, fmt.Sprintf("Citation failed: %v", err))
+		return
+	}
+	clipboard.SetText(text)
+
+	path := expandHome(prefs.CitationFile)
+	if path == "" {
+		a.setStatus(info, "Citation copied")
+		return
+	}
+	switch existing, err := export.AppendCitation(path, prefs.CitationFormat, citation); {
+	case err != nil:
+		a.setStatus(info, fmt.Sprintf("Citation copied, but not added to %s: %v", path, err))
+	case existing:
+		a.setStatus(info, fmt.Sprintf("Citation copied; already in %s", path))
+	default:
+		a.setStatus(info, fmt.Sprintf("Citation copied and added to %s", path))
+	}
+}
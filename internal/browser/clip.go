@@ -0,0 +1,65 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"chimera/internal/export"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// clipPage writes the current page into the Markdown vault folder from the
+// settings, unless it was clipped there before.
+func (a *App) clipPage(info *gtk.Label) {
+	page := a.currentPage()
+	if page.Result == nil {
+		a.setStatus(info, "Nothing to clip yet")
+		return
+	}
+	prefs := a.preferences()
+	dir := expandHome(prefs.VaultDir)
+	if dir == "" {
+		a.setStatus(info, "Choose a vault folder in LLM Settings first")
+		return
+	}
+
+	path, existing, err := export.ClipToVault(dir, export.Clip{
+		URL:    pageKey(page.Result),
+		Tags:   splitTags(prefs.VaultTags),
+		Result: page.Result,
+	}, time.Now())
+	switch {
+	case err != nil:
+		a.setStatus(info, fmt.Sprintf("Clip failed: %v", err))
+	case existing:
+		a.setStatus(info, fmt.Sprintf("Already clipped as %s", filepath.Base(path)))
+	default:
+		a.setStatus(info, fmt.Sprintf("Clipped to %s", path))
+	}
+}
+
+// expandHome resolves a leading "~/" against the home directory.
+func expandHome(path string) string {
+	path = strings.TrimSpace(path)
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// splitTags reads a comma- or space-separated tag list, dropping leading '#'.
+func splitTags(text string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if tag = strings.TrimLeft(tag, "#"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
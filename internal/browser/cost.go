@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"fmt"
+
+	"chimera/internal/llm"
+	"chimera/internal/session"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// costPreview is the estimate shown next to the Compose button while a
+// page is in reader mode and the endpoint has a price.
+type costPreview struct {
+	label *gtk.Label
+}
+
+func newCostPreview() (*costPreview, error) {
+	label, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, fmt.Errorf("create cost label: %w", err)
+	}
+	label.SetName("chimera-cost-estimate")
+	label.SetNoShowAll(true)
+	return &costPreview{label: label}, nil
+}
+
+// pageOptions returns the prompt options for doing task with target.
+func (a *App) pageOptions(target string, task llm.Task) llm.PageOptions {
+	opts := llm.PageOptions{
+		Task:       task,
+		Template:   a.promptTemplate(task),
+		Lite:       a.liteRendering(),
+		Note:       a.pageNote(target),
+		Typography: a.readerOptions().Typography.Describe(),
+	}
+	if task != llm.TaskCompose {
+		opts.Language = a.outputLanguage(task)
+	}
+	return opts
+}
+
+// refreshCost updates the estimate for the page in front; the page, its
+// site's prompt template, its note and the settings all change it. It can
+// be called from any goroutine.
+func (a *App) refreshCost() {
+	a.mu.RLock()
+	preview := a.cost
+	a.mu.RUnlock()
+	if preview == nil {
+		return
+	}
+
+	text, tooltip := a.costEstimate(a.currentPage())
+	glib.IdleAdd(func() bool {
+		preview.label.SetText(text)
+		preview.label.SetTooltipText(tooltip)
+		preview.label.SetVisible(text != "")
+		return false
+	})
+}
+
+// costEstimate describes what asking the LLM for page would cost, with a
+// longer explanation for the tooltip. Both are empty unless page is shown
+// in reader mode and the endpoint has a price.
+func (a *App) costEstimate(page renderedPage) (string, string) {
+	client := a.currentLLM()
+	price := client.InputPrice()
+	if price <= 0 || !client.Available() || page.Result == nil || sessionMode(page) != session.ModeReader {
+		return "", ""
+	}
+
+	task := a.siteTask(page.SourceURL)
+	estimate := client.EstimatePage(page.Result, a.pageOptions(page.SourceURL, task))
+	text := fmt.Sprintf("≈ %s tokens · %s", formatTokens(estimate.InputTokens), formatCost(estimate.Cost))
+	tooltip := fmt.Sprintf("Estimated cost of the %s prompt for this page: about %d input tokens at $%g per 1K. The reply is billed on top.", task, estimate.InputTokens, price)
+	return text, tooltip
+}
+
+func formatTokens(tokens int) string {
+	if tokens < 1000 {
+		return fmt.Sprintf("%d", tokens)
+	}
+	return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+}
+
+// formatCost shows cents for amounts of a cent or more, and enough digits
+// to tell small amounts apart otherwise.
+func formatCost(cost float64) string {
+	if cost >= 0.01 {
+		return fmt.Sprintf("$%.2f", cost)
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
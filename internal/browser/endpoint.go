@@ -0,0 +1,34 @@
+package browser
+
+// promptFormatLabel names an llm.PromptFormats value for the settings
+// dialog.
+func promptFormatLabel(name string) string {
+	switch name {
+	case "chatml":
+		return "ChatML (Qwen, Hermes, many fine-tunes)"
+	case "llama3":
+		return "Llama 3"
+	case "mistral":
+		return "Mistral [INST]"
+	case "gemma":
+		return "Gemma"
+	case "alpaca":
+		return "Alpaca ### Instruction"
+	default:
+		return name
+	}
+}
+
+// routingSortLabel names an llm.RoutingSorts value for the settings dialog.
+func routingSortLabel(sort string) string {
+	switch sort {
+	case "price":
+		return "Cheapest first"
+	case "throughput":
+		return "Fastest first"
+	case "latency":
+		return "Quickest to answer first"
+	default:
+		return "OpenRouter's choice"
+	}
+}
@@ -0,0 +1,91 @@
+package browser
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"chimera/internal/examples"
+	"chimera/internal/llm"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+func (a *App) saveExample(info *gtk.Label) {
+	page := a.currentPage()
+	if page.Source == "" || page.Model == "" {
+		a.setStatus(info, "Only LLM output can be saved as an example")
+		return
+	}
+
+	_, err := a.cfg.Examples.Add(examples.Example{
+		Template:  cmp.Or(a.promptTemplate(page.Task), page.Task.String()),
+		SourceURL: page.SourceURL,
+		Input:     page.Source,
+		Output:    page.HTML,
+	})
+	if err != nil {
+		a.setStatus(info, fmt.Sprintf("Saving example failed: %v", err))
+		return
+	}
+	a.setStatus(info, fmt.Sprintf("Saved as a %s example", page.Task))
+}
+
+func (a *App) examplesPage(ctx context.Context) (string, error) {
+	saved, err := a.cfg.Examples.List("")
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	err = examplesTmpl.Execute(&builder, struct {
+		Examples []examples.Example
+		Limit    int
+	}{saved, llm.MaxExamples})
+	return builder.String(), err
+}
+
+// removeExamplePage deletes the example with id and lists the rest.
+func (a *App) removeExamplePage(id string) internalPage {
+	return func(ctx context.Context) (string, error) {
+		if err := a.cfg.Examples.Remove(id); err != nil {
+			return "", err
+		}
+		return a.examplesPage(ctx)
+	}
+}
+
+var examplesTmpl = template.Must(template.New("examples").Funcs(template.FuncMap{
+	"size": func(s string) string { return formatBytes(int64(len(s))) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Few-shot examples — Chimera</title>
+<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+h1 { margin: 0 0 .5rem 0; }
+table { width: 100%; border-collapse: collapse; background: #fff; border-radius: 12px; overflow: hidden; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+th, td { text-align: left; padding: .6rem 1rem; border-bottom: 1px solid #e5e8ef; }
+td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+a { color: #2b5dcc; text-decoration: none; }
+small { color: #5b6576; }
+code { word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>Few-shot examples</h1>
+<p><small>The {{ .Limit }} most recent examples of a template are sent ahead of each request. Save one from the menu after a good composition.</small></p>
+{{ if .Examples }}
+<table>
+<thead><tr><th>Template</th><th>Source</th><th>Added</th><th class="num">Input</th><th class="num">Output</th><th></th></tr></thead>
+<tbody>
+{{ range .Examples }}<tr><td>{{ .Template }}</td><td><a href="{{ .SourceURL }}">{{ .SourceURL }}</a></td><td>{{ .AddedAt.Format "2006-01-02 15:04" }}</td><td class="num">{{ size .Input }}</td><td class="num">{{ size .Output }}</td><td><a href="chimera://examples/remove/{{ .ID }}">Remove</a></td></tr>
+{{ end }}
+</tbody>
+</table>
+{{ else }}<p>No examples saved yet.</p>{{ end }}
+</body>
+</html>`))
@@ -0,0 +1,103 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"chimera/internal/export"
+	"chimera/internal/llm"
+	"chimera/internal/render"
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// renderedPage remembers what is currently displayed so it can be exported.
+type renderedPage struct {
+	SourceURL string
+	Title     string
+	HTML      string
+	Model     string
+
+	// Source and Task record the prompt input behind LLM output so it can be saved as an example.
+	Source string
+	Task   llm.Task
+	// Result is the scraped page behind HTML, for clipping it as Markdown.
+	Result *scraper.Result
+	// Archived marks a page shown from the offline archive.
+	Archived bool
+}
+
+func (a *App) rememberPage(page renderedPage) {
+	a.mu.Lock()
+	a.page = page
+	a.mu.Unlock()
+	a.refreshArchive(page)
+	a.saveRunningSession(page, 0, false)
+	a.refreshCost()
+}
+
+func (a *App) currentPage() renderedPage {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.page
+}
+
+func (a *App) exportPage(ctx context.Context, parent *gtk.ApplicationWindow, info *gtk.Label) error {
+	page := a.currentPage()
+	if page.HTML == "" {
+		a.setStatus(info, "Nothing to export yet")
+		return nil
+	}
+
+	dialog, err := gtk.FileChooserDialogNewWith2Buttons("Export composed page", parent, gtk.FILE_CHOOSER_ACTION_SAVE,
+		"Cancel", gtk.RESPONSE_CANCEL, "Export", gtk.RESPONSE_ACCEPT)
+	if err != nil {
+		return fmt.Errorf("create file chooser: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetDoOverwriteConfirmation(true)
+	dialog.SetCurrentName(exportFileName(page.SourceURL))
+
+	if dialog.Run() != gtk.RESPONSE_ACCEPT {
+		return nil
+	}
+	path := dialog.GetFilename()
+	if path == "" {
+		return nil
+	}
+
+	a.setStatus(info, "Exporting...")
+	go func() {
+		// Compositions are exported as they are shown, without scripts.
+		source := page.HTML
+		var err error
+		if page.Model != "" {
+			source, err = render.Sanitize(source)
+		}
+		var html string
+		if err == nil {
+			html, err = export.Standalone(ctx, source, export.Options{
+				Fetch:     a.cfg.Scraper.Asset,
+				SourceURL: page.SourceURL,
+				Model:     page.Model,
+			})
+		}
+		if err == nil {
+			err = os.WriteFile(path, []byte(html), 0o644)
+		}
+		if err != nil {
+			a.setStatus(info, fmt.Sprintf("Export failed: %v", err))
+			return
+		}
+		a.setStatus(info, fmt.Sprintf("Exported to %s", path))
+	}()
+
+	return nil
+}
+
+func exportFileName(source string) string {
+	return export.Slug(source) + ".html"
+}
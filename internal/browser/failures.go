@@ -0,0 +1,139 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"chimera/internal/browser/webkit"
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// rawURI asks WebKit to load the "url" query parameter directly, skipping the scraper.
+const rawURI = "chimera://raw"
+
+const archivePrefix = "https://web.archive.org/web/"
+
+// renderScrapeFailure shows an error page for a failed scrape, leading with
+// the recovery action most likely to help for the kind of failure.
+func (a *App) renderScrapeFailure(view *viewHost, info *gtk.Label, target string, err error) {
+	var fetchErr *scraper.FetchError
+	if !errors.As(err, &fetchErr) {
+		a.renderError(view, info, fmt.Sprintf("Scrape failed: %v", err))
+		return
+	}
+
+	slog.Error("scrape failed", "url", target, "kind", fetchErr.Kind.String(), "err", err)
+	title, message, actions := failureAdvice(fetchErr, target)
+	glib.IdleAdd(func() bool {
+		view.current().InjectActionsBubble(title, message, actions)
+		info.SetText(fmt.Sprintf("Error — %s", fetchErr.Kind))
+		return false
+	})
+}
+
+func failureAdvice(err *scraper.FetchError, target string) (string, string, []webkit.Action) {
+	host := target
+	if parsed, parseErr := url.Parse(target); parseErr == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	retry := webkit.Action{Label: "Retry", Href: target}
+	raw := webkit.Action{Label: "Open original page", Href: rawURI + "?url=" + url.QueryEscape(target)}
+	archive := webkit.Action{Label: "Open archived copy", Href: archivePrefix + target}
+
+	switch err.Kind {
+	case scraper.FailureDNS:
+		return "Site not found",
+			fmt.Sprintf("%s could not be resolved. Check the address for typos; if the site has gone away, an archived copy may still exist.", host),
+			[]webkit.Action{retry, archive}
+	case scraper.FailureConnect:
+		return fmt.Sprintf("Can't reach %s", host),
+			"The server refused the connection or is unreachable from this network. It may be down for a moment.",
+			[]webkit.Action{retry, archive}
+	case scraper.FailureTLS:
+		return "Secure connection failed",
+			fmt.Sprintf("The certificate of %s could not be verified (%v). Chimera does not scrape pages over an untrusted connection.", host, err.Err),
+			[]webkit.Action{archive, retry}
+	case scraper.FailureTimeout:
+		return fmt.Sprintf("%s is taking too long", host),
+			"The server did not answer in time. It may be overloaded; retrying often helps.",
+			[]webkit.Action{retry, archive}
+	case scraper.FailureStatus:
+		code := err.Status()
+		status := fmt.Sprintf("HTTP %d %s", code, http.StatusText(code))
+		switch {
+		case code == http.StatusNotFound || code == http.StatusGone:
+			return "Page not found",
+				fmt.Sprintf("%s answered %s. The page may have moved; an archived copy may still exist.", host, status),
+				[]webkit.Action{archive, retry}
+		case code == http.StatusUnauthorized || code == http.StatusForbidden || code == http.StatusUnavailableForLegalReasons:
+			return "Access refused",
+				fmt.Sprintf("%s answered %s to the scraper. Opening the original page directly may still work.", host, status),
+				[]webkit.Action{raw, archive}
+		case code == http.StatusTooManyRequests || code >= 500:
+			return "Server unavailable",
+				fmt.Sprintf("%s answered %s. Wait a moment and retry.", host, status),
+				[]webkit.Action{retry, archive}
+		}
+		return "Page could not be loaded",
+			fmt.Sprintf("%s answered %s.", host, status),
+			[]webkit.Action{raw, retry}
+	case scraper.FailureRedirect:
+		var redirErr *scraper.RedirectError
+		if errors.As(err, &redirErr) && errors.Is(err, scraper.ErrCrossOriginRedirect) {
+			return "Redirect to another site",
+				fmt.Sprintf("%s redirects to %s, and cross-origin redirects are blocked.", host, redirErr.To),
+				[]webkit.Action{{Label: "Open redirect target", Href: redirErr.To}, raw}
+		}
+		if errors.As(err, &redirErr) && errors.Is(err, scraper.ErrRedirectDeclined) {
+			return "Redirect declined",
+				fmt.Sprintf("%s redirects to %s, which you chose not to follow.", host, redirErr.To),
+				[]webkit.Action{{Label: "Open redirect target", Href: redirErr.To}, raw}
+		}
+		return "Too many redirects",
+			fmt.Sprintf("%s kept redirecting (%v). The site may be stuck in a redirect loop.", host, err.Err),
+			[]webkit.Action{raw, archive}
+	}
+	return "Page could not be loaded", err.Error(), []webkit.Action{retry, raw}
+}
+
+// openRaw loads the page named by a chimera://raw link straight into WebKit
+// and stays in raw mode, following its links there too, until the next
+// page is scraped.
+func (a *App) openRaw(view *viewHost, entry *gtk.Entry, info *gtk.Label, link string) {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		a.setStatus(info, fmt.Sprintf("Invalid link: %v", err))
+		return
+	}
+	target, err := url.Parse(parsed.Query().Get("url"))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		a.setStatus(info, "Only http and https pages can be opened directly")
+		return
+	}
+
+	a.stopNavigation()
+	a.mu.Lock()
+	a.rawMode = true
+	a.mu.Unlock()
+	a.setLastSource(target.String())
+
+	glib.IdleAdd(func() bool {
+		entry.SetText(target.String())
+		view.current().LoadURI(target.String())
+		info.SetText("Showing the original page — Ctrl+U goes back to reader or LLM mode")
+		return false
+	})
+}
+
+// rawBrowsing reports whether pages are loaded straight into WebKit.
+func (a *App) rawBrowsing() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.rawMode
+}
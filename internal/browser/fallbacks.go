@@ -0,0 +1,98 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"chimera/internal/llm"
+	"chimera/internal/navigation"
+	persist "chimera/internal/settings"
+)
+
+// savedKey stands in for a fallback provider's API key in the settings
+// dialog, so keys are not shown in plain text.
+const savedKey = "(saved)"
+
+// parseFallbacks reads "Name | base URL | model | API key" lines, one
+// provider each, ignoring blank lines; the name and key may be left empty.
+// A savedKey keeps the key of the provider in previous with the same name,
+// base URL and model.
+func parseFallbacks(text string, previous []llm.Provider) ([]llm.Provider, error) {
+	var providers []llm.Provider
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, fmt.Errorf("invalid fallback line %q (expected Name | base URL | model | API key)", line)
+		}
+		if parsed, err := url.Parse(fields[1]); err != nil || parsed.Host == "" || parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return nil, fmt.Errorf("invalid fallback base URL %q", fields[1])
+		}
+		p := llm.Provider{Name: fields[0], BaseURL: fields[1], Model: fields[2]}
+		if len(fields) == 4 {
+			p.APIKey = fields[3]
+		}
+		if p.APIKey == savedKey {
+			p.APIKey = savedFallbackKey(p, previous)
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+func savedFallbackKey(p llm.Provider, previous []llm.Provider) string {
+	for _, old := range previous {
+		if old.Name == p.Name && old.BaseURL == p.BaseURL && old.Model == p.Model {
+			return old.APIKey
+		}
+	}
+	return ""
+}
+
+// formatFallbacks writes providers as parseFallbacks reads them, with
+// their keys replaced by savedKey.
+func formatFallbacks(providers []llm.Provider) string {
+	lines := make([]string, 0, len(providers))
+	for _, p := range providers {
+		line := p.Name + " | " + p.BaseURL + " | " + p.Model
+		if p.APIKey != "" {
+			line += " | " + savedKey
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// storedFallbacks converts providers for the settings file.
+func storedFallbacks(providers []llm.Provider) []persist.Provider {
+	var stored []persist.Provider
+	for _, p := range providers {
+		stored = append(stored, persist.Provider{Name: p.Name, BaseURL: p.BaseURL, Model: p.Model, APIKey: p.APIKey})
+	}
+	return stored
+}
+
+// servedStatus tells who wrote a page when it was not simply the
+// configured model: a fallback provider after the endpoint failed, or the
+// upstream provider OpenRouter routed it to. It is empty otherwise.
+func servedStatus(outcome navigation.Outcome) string {
+	model := outcome.Model
+	if outcome.Upstream != "" {
+		model += " via " + outcome.Upstream
+	}
+	switch {
+	case outcome.Provider != "":
+		return fmt.Sprintf("Written by %s on %s — the configured endpoint failed", model, outcome.Provider)
+	case outcome.Upstream != "":
+		return "Written by " + model
+	default:
+		return ""
+	}
+}
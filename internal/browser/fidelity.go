@@ -0,0 +1,49 @@
+package browser
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"chimera/internal/llm"
+)
+
+// fidelityBar is the warning shown above a composition that strays from
+// the page it was composed from. It is closed with its button, by
+// fidelityDismiss.
+const fidelityBar = `<div id="chimera-fidelity" role="alert" style="position:sticky;top:0;z-index:2147483647;display:flex;gap:12px;align-items:flex-start;margin:0;padding:10px 16px;background:#fff4d6;color:#5c4400;border-bottom:1px solid #e8c666;font:14px/1.45 'Inter','Segoe UI',sans-serif;filter:none;">` +
+	`<div style="flex:1"><strong>This composition may not match the page.</strong><ul style="margin:4px 0 0;padding-left:18px">%s</ul></div>` +
+	`<button type="button" style="border:0;background:none;color:inherit;font-size:18px;cursor:pointer" aria-label="Dismiss">×</button></div>`
+
+const fidelityDismiss = `<script>document.querySelector("#chimera-fidelity button").addEventListener("click",function(){this.parentNode.remove();});</script>`
+
+// withFidelityWarning puts a warning bar listing what f found at the top
+// of html when the composition is suspect.
+func withFidelityWarning(html string, f llm.Fidelity) string {
+	if !f.Suspect() {
+		return html
+	}
+	var items strings.Builder
+	for _, problem := range f.Problems() {
+		items.WriteString("<li>" + template.HTMLEscapeString(problem) + "</li>")
+	}
+	bar := fmt.Sprintf(fidelityBar, items.String())
+
+	lower := strings.ToLower(html)
+	if i := strings.Index(lower, "<body"); i >= 0 {
+		if end := strings.Index(lower[i:], ">"); end >= 0 {
+			at := i + end + 1
+			return withScript(html[:at]+bar+html[at:], fidelityDismiss)
+		}
+	}
+	return withScript(bar+html, fidelityDismiss)
+}
+
+// fidelityStatus summarises f for the status bar; empty when the
+// composition is not suspect.
+func fidelityStatus(f llm.Fidelity) string {
+	if !f.Suspect() {
+		return ""
+	}
+	return "Check this composition against the original — " + strings.Join(f.Problems(), "; ")
+}
@@ -0,0 +1,71 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+const (
+	// healthTimeout bounds one health check of the LLM endpoint.
+	healthTimeout = 10 * time.Second
+	// healthRecheck is how long to wait before checking an endpoint that
+	// failed its check again, so a local server that is still starting
+	// enables the button once it is up.
+	healthRecheck = 30 * time.Second
+)
+
+// updateLLMButton enables the LLM button when the endpoint answers and
+// accepts the API key, or has fallback providers, and shows the measured
+// latency or the problem in its tooltip. The check runs in the background;
+// until it finishes the button stays as it was configured.
+func (a *App) updateLLMButton(button *gtk.Button) {
+	check := a.healthCheck.Add(1)
+	client := a.currentLLM()
+	if client == nil || !client.Available() {
+		button.SetSensitive(false)
+		button.SetTooltipText("Configure an OpenAI-compatible endpoint to enable")
+		return
+	}
+	button.SetSensitive(true)
+	button.SetTooltipText("Generate a composed page via the configured LLM (checking the endpoint...)")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+		defer cancel()
+		health := client.Ping(ctx)
+		if !health.OK() {
+			slog.Warn("llm health check", "model", client.Model(), "err", health.Err)
+		}
+		fallbacks := client.Fallbacks()
+		glib.IdleAdd(func() bool {
+			if a.healthCheck.Load() != check {
+				return false
+			}
+			switch {
+			case health.OK():
+				button.SetSensitive(true)
+				button.SetTooltipText("Generate a composed page via the configured LLM\n" + health.String())
+				return false
+			case len(fallbacks) > 0:
+				// The fallback providers may still answer.
+				button.SetSensitive(true)
+				button.SetTooltipText(fmt.Sprintf("Generate a composed page via the configured LLM\n%s — falling back to %s", health, fallbacks[0].Label()))
+			default:
+				button.SetSensitive(false)
+				button.SetTooltipText(health.String())
+			}
+			glib.TimeoutAdd(uint(healthRecheck/time.Millisecond), func() bool {
+				if a.healthCheck.Load() == check {
+					a.updateLLMButton(button)
+				}
+				return false
+			})
+			return false
+		})
+	}()
+}
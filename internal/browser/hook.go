@@ -0,0 +1,64 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"chimera/internal/scraper"
+)
+
+const (
+	// visitHookTimeout stops a post-visit command that hangs.
+	visitHookTimeout = time.Minute
+	// hookOutputBytes caps the command output quoted in the log.
+	hookOutputBytes = 500
+)
+
+// runVisitHook pipes result as JSON to the post-visit command, if one is
+// set, without waiting for it. The command runs through sh -c, so it may be
+// a pipeline, and gets CHIMERA_URL and CHIMERA_TITLE in its environment.
+// Failures are logged.
+func (a *App) runVisitHook(result *scraper.Result) {
+	command := strings.TrimSpace(a.preferences().VisitCommand)
+	if command == "" {
+		return
+	}
+	payload, err := json.Marshal(result)
+	if err != nil {
+		slog.Warn("encode page for post-visit command", "url", result.SourceURL, "err", err)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), visitHookTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Env = append(os.Environ(), "CHIMERA_URL="+result.SourceURL, "CHIMERA_TITLE="+result.Title)
+		started := time.Now()
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			slog.Warn("post-visit command failed", "url", result.SourceURL, "err", err, "output", excerpt(output))
+			return
+		}
+		slog.Info("post-visit command", "url", result.SourceURL, "elapsed", time.Since(started).Round(time.Millisecond))
+	}()
+}
+
+func excerpt(output []byte) string {
+	text := strings.TrimSpace(string(output))
+	if len(text) > hookOutputBytes {
+		text = text[:hookOutputBytes] + "…"
+	}
+	return text
+}
@@ -0,0 +1,192 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+
+	"chimera/internal/procstat"
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+const internalScheme = "chimera://"
+
+// internalPage renders a built-in chimera:// page.
+type internalPage func(ctx context.Context) (string, error)
+
+func (a *App) internalPage(target string) (internalPage, bool) {
+	rest, rawQuery, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(target), internalScheme), "?")
+	name := strings.Trim(rest, "/")
+	query, _ := url.ParseQuery(rawQuery)
+	switch name {
+	case "processes":
+		return a.processesPage, true
+	case "examples":
+		return a.examplesPage, true
+	case "start":
+		return a.startPage, true
+	case "start/digest":
+		return a.digestPage, true
+	case "logs":
+		return a.logsPage(query), true
+	case "bookmarks":
+		return a.bookmarksPage, true
+	case "bookmarks/check":
+		return a.checkBookmarksPage(query.Get("force") == "1"), true
+	case "bookmarks/remove":
+		return a.removeBookmarkPage(query.Get("url")), true
+	case "search":
+		return a.searchPage(query.Get("q"), query.Get("by")), true
+	case "offline":
+		return a.offlinePage(query.Get("url")), true
+	case "watches":
+		return a.watchesPage, true
+	case "watches/check":
+		return a.checkWatchesPage, true
+	case "watches/diff":
+		return a.watchDiffPage(query.Get("url")), true
+	case "watches/remove":
+		return a.removeWatchPage(query.Get("url")), true
+	case "sitemap":
+		return a.sitemapPage(query.Get("site")), true
+	}
+	if id, ok := strings.CutPrefix(name, "examples/remove/"); ok && id != "" {
+		return a.removeExamplePage(id), true
+	}
+	return nil, false
+}
+
+func isInternalURL(target string) bool {
+	return strings.HasPrefix(strings.TrimSpace(target), internalScheme)
+}
+
+func (a *App) openInternal(ctx context.Context, target string, view *viewHost, info *gtk.Label, spinner *gtk.Spinner) {
+	page, ok := a.internalPage(target)
+	if !ok {
+		a.endNavigation(ctx)
+		a.renderError(view, info, fmt.Sprintf("Unknown internal page %s", target))
+		return
+	}
+
+	a.setStatus(info, "Loading...")
+	go func() {
+		defer a.endNavigation(ctx)
+		a.startSpinner(spinner)
+		defer a.stopSpinner(spinner)
+
+		html, err := page(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			a.renderError(view, info, fmt.Sprintf("%s: %v", target, err))
+			return
+		}
+		// Chimera's own pages get the global user stylesheet only.
+		a.applySiteStyle(view, "")
+		a.renderHTML(view, info, html)
+	}()
+}
+
+func (a *App) processesPage(ctx context.Context) (string, error) {
+	procs, err := procstat.WebKitProcesses(ctx, 500*time.Millisecond)
+	if err != nil {
+		return "", err
+	}
+
+	webCount := 0
+	for _, p := range procs {
+		if p.Kind() == "web" {
+			webCount++
+		}
+	}
+
+	type row struct {
+		procstat.Process
+		Kind   string
+		Owner  string
+		Memory string
+		CPU    string
+	}
+	rows := make([]row, 0, len(procs))
+	var total int64
+	for _, p := range procs {
+		owner := "Shared"
+		if p.Kind() == "web" {
+			owner = "Main view"
+			if webCount > 1 {
+				owner = "Main view or a discarded page"
+			}
+		}
+		total += p.RSSBytes
+		rows = append(rows, row{
+			Process: p,
+			Kind:    p.Kind(),
+			Owner:   owner,
+			Memory:  formatBytes(p.RSSBytes),
+			CPU:     fmt.Sprintf("%.1f%%", p.CPUPercent),
+		})
+	}
+
+	var builder strings.Builder
+	err = processesTmpl.Execute(&builder, struct {
+		Rows    []row
+		Total   string
+		Sampled time.Time
+		Load    scraper.Load
+	}{rows, formatBytes(total), time.Now(), a.cfg.Scraper.Load()})
+	return builder.String(), err
+}
+
+func formatBytes(n int64) string {
+	const mb = 1024 * 1024
+	if n >= 1024*mb {
+		return fmt.Sprintf("%.2f GiB", float64(n)/(1024*mb))
+	}
+	return fmt.Sprintf("%.1f MiB", float64(n)/mb)
+}
+
+var processesTmpl = template.Must(template.New("processes").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Processes — Chimera</title>
+<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+h1 { margin: 0 0 .5rem 0; }
+h2 { margin: 2rem 0 .5rem 0; }
+table { width: 100%; border-collapse: collapse; background: #fff; border-radius: 12px; overflow: hidden; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+th, td { text-align: left; padding: .6rem 1rem; border-bottom: 1px solid #e5e8ef; }
+td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+a { color: #2b5dcc; text-decoration: none; }
+small { color: #5b6576; }
+</style>
+</head>
+<body>
+<h1>WebKit processes</h1>
+<p><small>Sampled {{ .Sampled.Format "15:04:05" }} over 0.5s • total resident memory {{ .Total }} • <a href="chimera://processes">Refresh</a></small></p>
+{{ if .Rows }}
+<table>
+<thead><tr><th>PID</th><th>Process</th><th>Type</th><th>Used by</th><th class="num">Memory</th><th class="num">CPU</th></tr></thead>
+<tbody>
+{{ range .Rows }}<tr><td>{{ .PID }}</td><td>{{ .Name }}</td><td>{{ .Kind }}</td><td>{{ .Owner }}</td><td class="num">{{ .Memory }}</td><td class="num">{{ .CPU }}</td></tr>
+{{ end }}
+</tbody>
+</table>
+{{ else }}<p>No WebKit helper processes found.</p>{{ end }}
+<h2>Network requests</h2>
+<p><small>At most {{ .Load.Limit }} requests in flight. Background work such as watched pages and offline copies waits while a page you opened does, and leaves one slot free for it.</small></p>
+<table>
+<thead><tr><th>Priority</th><th class="num">In flight</th><th class="num">Waiting</th><th class="num">Sent</th><th class="num">Average wait</th><th class="num">Longest wait</th></tr></thead>
+<tbody>
+{{ with .Load.Interactive }}<tr><td>Interactive</td><td class="num">{{ .InFlight }}</td><td class="num">{{ .Waiting }}</td><td class="num">{{ .Sent }}</td><td class="num">{{ .AverageWait.Round 1000000 }}</td><td class="num">{{ .LongestWait.Round 1000000 }}</td></tr>{{ end }}
+{{ with .Load.Background }}<tr><td>Background</td><td class="num">{{ .InFlight }}</td><td class="num">{{ .Waiting }}</td><td class="num">{{ .Sent }}</td><td class="num">{{ .AverageWait.Round 1000000 }}</td><td class="num">{{ .LongestWait.Round 1000000 }}</td></tr>{{ end }}
+</tbody>
+</table>
+</body>
+</html>`))
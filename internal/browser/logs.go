@@ -0,0 +1,114 @@
+package browser
+
+import (
+	"context"
+	"html/template"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"chimera/internal/logs"
+)
+
+// maxLogRows caps how many records the logs page shows.
+const maxLogRows = 500
+
+var logLevels = []struct {
+	Name  string
+	Level slog.Level
+}{
+	{"debug", slog.LevelDebug},
+	{"info", slog.LevelInfo},
+	{"warn", slog.LevelWarn},
+	{"error", slog.LevelError},
+}
+
+// logsPage lists buffered log records, newest first, filtered by the
+// "level" (minimum severity) and "q" (case-insensitive text) query parameters.
+func (a *App) logsPage(query url.Values) internalPage {
+	return func(ctx context.Context) (string, error) {
+		minLevel, levelName := slog.LevelInfo, "info"
+		for _, l := range logLevels {
+			if strings.EqualFold(query.Get("level"), l.Name) {
+				minLevel, levelName = l.Level, l.Name
+			}
+		}
+		search := strings.TrimSpace(query.Get("q"))
+		needle := strings.ToLower(search)
+
+		all := a.cfg.Logs.Records()
+		var rows []logs.Record
+		for i := len(all) - 1; i >= 0 && len(rows) < maxLogRows; i-- {
+			r := all[i]
+			if r.Level < minLevel {
+				continue
+			}
+			if needle != "" && !strings.Contains(strings.ToLower(r.Message+" "+r.Attrs), needle) {
+				continue
+			}
+			rows = append(rows, r)
+		}
+
+		var builder strings.Builder
+		err := logsTmpl.Execute(&builder, struct {
+			Rows   []logs.Record
+			Total  int
+			Level  string
+			Search string
+			Levels []string
+		}{rows, len(all), levelName, search, []string{"debug", "info", "warn", "error"}})
+		return builder.String(), err
+	}
+}
+
+var logsTmpl = template.Must(template.New("logs").Funcs(template.FuncMap{
+	"levelClass": func(l slog.Level) string {
+		switch {
+		case l >= slog.LevelError:
+			return "error"
+		case l >= slog.LevelWarn:
+			return "warn"
+		default:
+			return "info"
+		}
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Logs — Chimera</title>
+<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 1100px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+h1 { margin: 0 0 .5rem 0; }
+form { display: flex; gap: .5rem; margin: 1rem 0; }
+input[type=search] { flex: 1; padding: .4rem .6rem; border: 1px solid #cfd5e1; border-radius: 8px; }
+select, button { padding: .4rem .6rem; border-radius: 8px; border: 1px solid #cfd5e1; background: #fff; }
+table { width: 100%; border-collapse: collapse; background: #fff; border-radius: 12px; overflow: hidden; box-shadow: 0 1px 3px rgba(0,0,0,.08); font-size: .9rem; }
+th, td { text-align: left; padding: .45rem .8rem; border-bottom: 1px solid #e5e8ef; vertical-align: top; }
+td.time { white-space: nowrap; font-variant-numeric: tabular-nums; color: #5b6576; }
+td.attrs { font-family: monospace; font-size: .8rem; color: #5b6576; word-break: break-all; }
+tr.warn td.level { color: #a86400; font-weight: 600; }
+tr.error td.level { color: #c0262d; font-weight: 600; }
+a { color: #2b5dcc; text-decoration: none; }
+small { color: #5b6576; }
+</style>
+</head>
+<body>
+<h1>Logs</h1>
+<p><small>Showing {{ len .Rows }} of {{ .Total }} records from this session, newest first. <a href="chimera://logs?level={{ .Level }}&amp;q={{ .Search }}">Refresh</a></small></p>
+<form action="chimera://logs" method="get">
+<select name="level">{{ $level := .Level }}{{ range .Levels }}<option value="{{ . }}"{{ if eq . $level }} selected{{ end }}>{{ . }} and above</option>{{ end }}</select>
+<input type="search" name="q" value="{{ .Search }}" placeholder="Search messages and fields" />
+<button type="submit">Filter</button>
+</form>
+{{ if .Rows }}
+<table>
+<thead><tr><th>Time</th><th>Level</th><th>Message</th><th>Fields</th></tr></thead>
+<tbody>
+{{ range .Rows }}<tr class="{{ levelClass .Level }}"><td class="time">{{ .Time.Format "15:04:05.000" }}</td><td class="level">{{ .Level }}</td><td>{{ .Message }}</td><td class="attrs">{{ .Attrs }}</td></tr>
+{{ end }}
+</tbody>
+</table>
+{{ else }}<p>No matching records.</p>{{ end }}
+</body>
+</html>`))
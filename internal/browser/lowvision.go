@@ -0,0 +1,68 @@
+package browser
+
+import "chimera/internal/browser/webkit"
+
+// Low-vision defaults, used when the mode is on and no value was chosen.
+const (
+	defaultMinFontSize = 18
+	defaultMinContrast = 7.0
+)
+
+// lowVisionStyle keeps links and focus visible without relying on colour.
+const lowVisionStyle = `a { text-decoration: underline !important; }
+:focus { outline: 3px solid #ffbf47 !important; outline-offset: 2px !important; }
+`
+
+// lowVisionScript recolours text whose contrast against its background is
+// below the ratio substituted for %g, using black or white, whichever
+// contrasts more. It runs when the document is parsed and again once images
+// and styles have loaded.
+const lowVisionScript = `(() => {
+  const minRatio = %g;
+  const parse = (c) => (c.match(/[\d.]+/g) || ['0', '0', '0', '0']).map(Number);
+  const luminance = (c) => {
+    const [r, g, b] = c.slice(0, 3).map((v) => {
+      v /= 255;
+      return v <= 0.03928 ? v / 12.92 : Math.pow((v + 0.055) / 1.055, 2.4);
+    });
+    return 0.2126 * r + 0.7152 * g + 0.0722 * b;
+  };
+  const ratio = (a, b) => {
+    const [hi, lo] = [luminance(a), luminance(b)].sort((x, y) => y - x);
+    return (hi + 0.05) / (lo + 0.05);
+  };
+  const background = (el) => {
+    for (; el; el = el.parentElement) {
+      const c = parse(getComputedStyle(el).backgroundColor);
+      if (c.length < 4 || c[3] > 0.5) return c;
+    }
+    return [255, 255, 255];
+  };
+  const fix = () => {
+    if (!document.body) return;
+    for (const el of document.body.querySelectorAll('*')) {
+      if (![...el.childNodes].some((n) => n.nodeType === 3 && n.textContent.trim())) continue;
+      const bg = background(el);
+      if (ratio(parse(getComputedStyle(el).color), bg) >= minRatio) continue;
+      const color = ratio([0, 0, 0], bg) >= ratio([255, 255, 255], bg) ? '#000' : '#fff';
+      el.style.setProperty('color', color, 'important');
+    }
+  };
+  fix();
+  window.addEventListener('load', fix);
+})();`
+
+// applyLowVision enforces the low-vision preferences on view, or lifts them
+// when the mode is off. Pages loaded from then on are affected.
+func (a *App) applyLowVision(view *webkit.WebView) {
+	prefs := a.preferences()
+	size := 0
+	if prefs.LowVision {
+		size = prefs.MinFontSize
+		if size <= 0 {
+			size = defaultMinFontSize
+		}
+	}
+	view.SetMinimumFontSize(size)
+	a.applyUserContent(view, a.currentPage().SourceURL)
+}
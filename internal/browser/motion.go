@@ -0,0 +1,49 @@
+package browser
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// noMotionStyle stops CSS animations, transitions and smooth scrolling in
+// generated pages.
+const noMotionStyle = `<style>*, *::before, *::after { animation: none !important; transition: none !important; scroll-behavior: auto !important; }</style>`
+
+// watchReducedMotion follows the desktop's animation preference. GNOME's
+// "Reduce animation" and similar switches turn off gtk-enable-animations.
+func (a *App) watchReducedMotion() {
+	settings, err := gtk.SettingsGetDefault()
+	if err != nil {
+		slog.Warn("read desktop settings", "err", err)
+		return
+	}
+	update := func() {
+		value, err := settings.GetProperty("gtk-enable-animations")
+		enabled, ok := value.(bool)
+		reduce := err == nil && ok && !enabled
+		a.mu.Lock()
+		a.reduceMotion = reduce
+		a.mu.Unlock()
+	}
+	update()
+	settings.Connect("notify::gtk-enable-animations", update)
+}
+
+func (a *App) reducedMotion() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.reduceMotion
+}
+
+// calmPage strips animations from html when the desktop asks for reduced motion.
+func (a *App) calmPage(html string) string {
+	if !a.reducedMotion() {
+		return html
+	}
+	if i := strings.Index(strings.ToLower(html), "</head>"); i >= 0 {
+		return html[:i] + noMotionStyle + html[i:]
+	}
+	return noMotionStyle + html
+}
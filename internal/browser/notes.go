@@ -0,0 +1,103 @@
+package browser
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// pageNote returns the user's note for url, or "" if there is none.
+func (a *App) pageNote(url string) string {
+	note, ok, err := a.cfg.Notes.Get(url)
+	if err != nil {
+		slog.Warn("load note", "url", url, "err", err)
+		return ""
+	}
+	if !ok {
+		return ""
+	}
+	return note.Text
+}
+
+func (a *App) openNoteDialog(parent *gtk.ApplicationWindow, info *gtk.Label) error {
+	target := a.lastSourceURL()
+	if target == "" {
+		a.setStatus(info, "Open a page first")
+		return nil
+	}
+
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return fmt.Errorf("create dialog: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle("Page note")
+	dialog.SetModal(true)
+	dialog.SetTransientFor(parent)
+	dialog.SetDefaultSize(460, -1)
+	dialog.AddButton("Cancel", gtk.RESPONSE_CANCEL)
+	dialog.AddButton("Save", gtk.RESPONSE_OK)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		return fmt.Errorf("access content area: %w", err)
+	}
+
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 8)
+	if err != nil {
+		return fmt.Errorf("create box: %w", err)
+	}
+	box.SetMarginTop(14)
+	box.SetMarginBottom(14)
+	box.SetMarginStart(18)
+	box.SetMarginEnd(18)
+
+	hint, err := gtk.LabelNew(fmt.Sprintf("Added to every LLM request for %s. Leave empty to remove.", target))
+	if err != nil {
+		return fmt.Errorf("create hint label: %w", err)
+	}
+	hint.SetXAlign(0)
+	hint.SetLineWrap(true)
+	box.PackStart(hint, false, false, 0)
+
+	scroll, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return fmt.Errorf("create note scroller: %w", err)
+	}
+	scroll.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
+	scroll.SetShadowType(gtk.SHADOW_IN)
+	scroll.SetSizeRequest(-1, 110)
+
+	view, err := gtk.TextViewNew()
+	if err != nil {
+		return fmt.Errorf("create note view: %w", err)
+	}
+	view.SetWrapMode(gtk.WRAP_WORD)
+	buffer, err := view.GetBuffer()
+	if err != nil {
+		return fmt.Errorf("access note buffer: %w", err)
+	}
+	buffer.SetText(a.pageNote(target))
+	scroll.Add(view)
+	box.PackStart(scroll, true, true, 0)
+
+	content.Add(box)
+	dialog.ShowAll()
+
+	if dialog.Run() != gtk.RESPONSE_OK {
+		return nil
+	}
+
+	start, end := buffer.GetBounds()
+	text, err := buffer.GetText(start, end, false)
+	if err != nil {
+		return fmt.Errorf("read note: %w", err)
+	}
+	if err := a.cfg.Notes.Set(target, text); err != nil {
+		return fmt.Errorf("save note: %w", err)
+	}
+	a.setStatus(info, "Note saved; it applies to the next composition")
+	return nil
+}
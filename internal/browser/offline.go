@@ -0,0 +1,181 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"mime"
+	"strings"
+	"sync"
+	"time"
+
+	"chimera/internal/archive"
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+const (
+	// maxArchivedImages caps how many images are saved with a page.
+	maxArchivedImages = 40
+	// maxArchivedImageBytes skips images larger than this.
+	maxArchivedImageBytes = 5 << 20
+	// maxArchiveFetches caps how many images are downloaded at once.
+	maxArchiveFetches = 4
+	// archiveRefresh is how old an archived copy may get before visiting
+	// the page saves it again.
+	archiveRefresh = 24 * time.Hour
+	// archiveTimeout bounds saving a page and its images.
+	archiveTimeout = 2 * time.Minute
+)
+
+// archivePage saves page with its images for offline reading. Images that
+// cannot be downloaded now are kept from the previous copy, if any. The
+// downloads yield to pages being opened.
+func (a *App) archivePage(ctx context.Context, page renderedPage) (archive.Page, error) {
+	ctx, cancel := context.WithTimeout(scraper.WithPriority(ctx, scraper.PriorityBackground), archiveTimeout)
+	defer cancel()
+
+	previous := make(map[string]archive.Image)
+	if old, ok, err := a.cfg.Archive.Load(page.SourceURL); err == nil && ok {
+		for _, img := range old.Images {
+			previous[img.Src] = img
+		}
+	}
+
+	sources := archive.ImageSources(page.HTML, page.SourceURL, maxArchivedImages)
+	images := make([]archive.Image, len(sources))
+	var (
+		wg    sync.WaitGroup
+		slots = make(chan struct{}, maxArchiveFetches)
+	)
+	for i, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			data, contentType, err := a.cfg.Scraper.Asset(ctx, src, maxArchivedImageBytes)
+			mediaType, _, _ := mime.ParseMediaType(contentType)
+			if err == nil && strings.HasPrefix(mediaType, "image/") {
+				images[i] = archive.Image{Src: src, ContentType: mediaType, Data: data}
+				return
+			}
+			if err == nil {
+				err = fmt.Errorf("not an image: %q", contentType)
+			}
+			slog.Debug("archive image", "url", src, "err", err)
+			images[i] = previous[src]
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return archive.Page{}, err
+	}
+
+	saved := archive.Page{URL: page.SourceURL, Title: page.Title, SavedAt: time.Now(), Result: page.Result, HTML: page.HTML}
+	for _, img := range images {
+		if img.Src != "" {
+			saved.Images = append(saved.Images, img)
+		}
+	}
+	if err := a.cfg.Archive.Save(saved); err != nil {
+		return archive.Page{}, err
+	}
+	slog.Info("archived page", "url", page.SourceURL, "images", len(saved.Images), "referenced", len(sources))
+	return saved, nil
+}
+
+// refreshArchive saves page again in the background when it has an archived
+// copy older than archiveRefresh, so bookmarked pages stay current.
+func (a *App) refreshArchive(page renderedPage) {
+	if page.Archived || page.SourceURL == "" || page.HTML == "" {
+		return
+	}
+	savedAt, ok := a.cfg.Archive.SavedAt(page.SourceURL)
+	if !ok || time.Since(savedAt) < archiveRefresh {
+		return
+	}
+	go func() {
+		if _, err := a.archivePage(context.Background(), page); err != nil {
+			slog.Warn("refresh archived page", "url", page.SourceURL, "err", err)
+		}
+	}()
+}
+
+// showArchived renders the archived copy of target in place of a failed
+// scrape, when the failure means the network or the site is unreachable.
+// It reports whether a copy was shown.
+func (a *App) showArchived(view *viewHost, info *gtk.Label, target string, err error) bool {
+	if !unreachable(err) {
+		return false
+	}
+	page, ok, loadErr := a.cfg.Archive.Load(target)
+	if loadErr != nil {
+		slog.Warn("load archived page", "url", target, "err", loadErr)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	slog.Info("showing archived page", "url", target, "saved", page.SavedAt, "err", err)
+	html := a.archivedHTML(page)
+	a.applySiteStyle(view, page.URL)
+	a.renderHTML(view, info, html)
+	a.rememberPage(renderedPage{SourceURL: page.URL, Title: page.Title, HTML: html, Result: page.Result, Archived: true})
+	a.setStatus(info, fmt.Sprintf("Offline — showing the archived copy from %s", page.SavedAt.Local().Format("02 Jan 2006 15:04")))
+	return true
+}
+
+// offlinePage shows the archived copy of target, whether or not the site
+// can be reached.
+func (a *App) offlinePage(target string) internalPage {
+	return func(ctx context.Context) (string, error) {
+		page, ok, err := a.cfg.Archive.Load(target)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("no archived copy of %s", target)
+		}
+		return a.archivedHTML(page), nil
+	}
+}
+
+// unreachable reports whether err means the page could not be reached at
+// all, as opposed to the site answering with an error.
+func unreachable(err error) bool {
+	var fetchErr *scraper.FetchError
+	if !errors.As(err, &fetchErr) {
+		return false
+	}
+	switch fetchErr.Kind {
+	case scraper.FailureDNS, scraper.FailureConnect, scraper.FailureTimeout:
+		return true
+	}
+	return false
+}
+
+// archivedHTML is the archived page with its images inlined and a banner
+// saying when it was saved.
+func (a *App) archivedHTML(page archive.Page) string {
+	banner := `<div role="note" style="position:sticky;top:0;z-index:2147483647;margin:0;padding:.5rem 1rem;background:#fff4d6;color:#5c4400;border-bottom:1px solid #e8cf8a;font:14px/1.4 sans-serif">` +
+		html.EscapeString(fmt.Sprintf("Archived copy from %s", page.SavedAt.Local().Format("02 Jan 2006 15:04"))) +
+		` — <a href="` + html.EscapeString(page.URL) + `" style="color:inherit">try the live page</a></div>`
+
+	// Archived pages may be compositions, so they are sanitised as such.
+	return a.composedHTML(page.Offline(), func(doc string) string {
+		lower := strings.ToLower(doc)
+		if start := strings.Index(lower, "<body"); start >= 0 {
+			if end := strings.Index(lower[start:], ">"); end >= 0 {
+				at := start + end + 1
+				return doc[:at] + banner + doc[at:]
+			}
+		}
+		return banner + doc
+	})
+}
@@ -0,0 +1,69 @@
+package browser
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"html/template"
+	"log/slog"
+	"net/url"
+
+	"chimera/internal/render"
+	"chimera/internal/scraper"
+)
+
+// scriptNonce marks the scripts Chimera adds to the pages it shows. The
+// content security policy of a page lets only those run; the nonce is new
+// for every run, so a model cannot write it into a page.
+var scriptNonce = newNonce()
+
+func newNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("read random nonce: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// withPolicy gives html, a scraped page in reader mode or a composition,
+// a content security policy that runs only Chimera's scripts and, with
+// BlockExternal set, loads nothing from the web.
+func (a *App) withPolicy(html string) string {
+	return render.WithPolicy(html, render.Policy(scriptNonce, a.preferences().BlockExternal))
+}
+
+// composedHTML prepares html, a page written by the LLM, to be shown: it
+// is sanitised, passed through decorate, and given the content security
+// policy of withPolicy.
+func (a *App) composedHTML(html string, decorate func(string) string) string {
+	clean, err := render.Sanitize(html)
+	if err != nil {
+		slog.Warn("sanitize composition", "err", err)
+		clean = "<!DOCTYPE html><html><head></head><body><pre>" + template.HTMLEscapeString(html) + "</pre></body></html>"
+	}
+	return a.withPolicy(decorate(clean))
+}
+
+// pageBase is the base URI to render result with: its address when
+// SourceBase is set, so relative links and images resolve against the
+// site, and empty otherwise.
+func (a *App) pageBase(result *scraper.Result) string {
+	if result == nil || !a.preferences().SourceBase {
+		return ""
+	}
+	return result.FinalURL
+}
+
+// sameDocument reports whether target only jumps to a #fragment of the
+// page loaded from base, which the view scrolls to itself.
+func sameDocument(target, base string) bool {
+	page, fragment := splitFragment(target)
+	if fragment == "" || base == "" {
+		return false
+	}
+	current, err := url.Parse(base)
+	if err != nil {
+		return false
+	}
+	current.Fragment, current.RawFragment = "", ""
+	return page == current.String()
+}
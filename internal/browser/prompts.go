@@ -0,0 +1,38 @@
+package browser
+
+import (
+	"log/slog"
+
+	"chimera/internal/llm"
+)
+
+// taskLabel names task in menus and settings.
+func taskLabel(task llm.Task) string {
+	switch task {
+	case llm.TaskSummarize:
+		return "Summarize"
+	case llm.TaskTranslate:
+		return "Translate"
+	case llm.TaskELI5:
+		return "Explain simply"
+	default:
+		return "Compose"
+	}
+}
+
+// loadPrompts reads the prompt templates again, so edits to their files
+// take effect. Templates that fail to parse are logged and replaced by
+// the built-in ones.
+func (a *App) loadPrompts() *llm.Prompts {
+	prompts, err := llm.LoadPrompts(a.cfg.PromptFiles)
+	if err != nil {
+		slog.Warn("load prompt templates", "err", err)
+	}
+	return prompts
+}
+
+// promptTemplate returns the template chosen in settings for task; empty
+// uses the one named after it.
+func (a *App) promptTemplate(task llm.Task) string {
+	return a.preferences().Prompts[task.String()]
+}
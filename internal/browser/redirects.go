@@ -0,0 +1,146 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"chimera/internal/scraper"
+	persist "chimera/internal/settings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/gotk3/gotk3/pango"
+)
+
+// defaultMaxRedirects mirrors the scraper's limit when none is configured.
+const defaultMaxRedirects = 10
+
+// withRedirectPrompt makes fetches under ctx ask before following a
+// redirect to another site, when the settings say to.
+func (a *App) withRedirectPrompt(ctx context.Context, parent *gtk.ApplicationWindow) context.Context {
+	if a.preferences().CrossSiteRedirects != persist.RedirectsAsk {
+		return ctx
+	}
+	return scraper.WithRedirectApprover(ctx, func(ctx context.Context, chain []string, to string) bool {
+		return a.approveRedirect(ctx, parent, chain, to)
+	})
+}
+
+// approveRedirect shows the redirect chain and asks whether to continue to
+// to. Hops between the same two hosts that were allowed with "Always" this
+// session are followed without asking. It blocks until the user answers or
+// ctx is done, so it must not run on the GTK main loop.
+func (a *App) approveRedirect(ctx context.Context, parent *gtk.ApplicationWindow, chain []string, to string) bool {
+	key := redirectHosts(chain[len(chain)-1], to)
+	a.mu.RLock()
+	allowed := a.allowedRedirects[key]
+	a.mu.RUnlock()
+	if allowed {
+		return true
+	}
+
+	answer := make(chan gtk.ResponseType, 1)
+	var dialog *gtk.Dialog
+	glib.IdleAdd(func() bool {
+		var err error
+		dialog, err = redirectDialog(parent, chain, to)
+		if err != nil {
+			answer <- gtk.RESPONSE_CANCEL
+			return false
+		}
+		answer <- dialog.Run()
+		dialog.Destroy()
+		return false
+	})
+
+	select {
+	case response := <-answer:
+		if response == gtk.RESPONSE_APPLY {
+			a.mu.Lock()
+			if a.allowedRedirects == nil {
+				a.allowedRedirects = make(map[string]bool)
+			}
+			a.allowedRedirects[key] = true
+			a.mu.Unlock()
+		}
+		return response == gtk.RESPONSE_OK || response == gtk.RESPONSE_APPLY
+	case <-ctx.Done():
+		glib.IdleAdd(func() bool {
+			if dialog != nil {
+				dialog.Response(gtk.RESPONSE_CANCEL)
+			}
+			return false
+		})
+		return false
+	}
+}
+
+func redirectDialog(parent *gtk.ApplicationWindow, chain []string, to string) (*gtk.Dialog, error) {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return nil, fmt.Errorf("create dialog: %w", err)
+	}
+	dialog.SetTitle("Redirect to another site")
+	dialog.SetModal(true)
+	dialog.SetTransientFor(parent)
+	dialog.SetDefaultSize(520, -1)
+	dialog.AddButton("Stay", gtk.RESPONSE_CANCEL)
+	dialog.AddButton("Always for these sites", gtk.RESPONSE_APPLY)
+	dialog.AddButton("Continue", gtk.RESPONSE_OK)
+	dialog.SetDefaultResponse(gtk.RESPONSE_CANCEL)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		return nil, fmt.Errorf("access content area: %w", err)
+	}
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 8)
+	if err != nil {
+		return nil, fmt.Errorf("create box: %w", err)
+	}
+	box.SetMarginTop(14)
+	box.SetMarginBottom(14)
+	box.SetMarginStart(18)
+	box.SetMarginEnd(18)
+
+	from := chain[len(chain)-1]
+	hint, err := gtk.LabelNew(fmt.Sprintf("%s sends you to %s. Link shorteners and tracking links do this; check the destination before continuing.", hostOf(from), hostOf(to)))
+	if err != nil {
+		return nil, fmt.Errorf("create hint label: %w", err)
+	}
+	hint.SetXAlign(0)
+	hint.SetLineWrap(true)
+	box.PackStart(hint, false, false, 0)
+
+	lines := make([]string, 0, len(chain)+1)
+	for i, hop := range chain {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, hop))
+	}
+	lines = append(lines, fmt.Sprintf("→ %s", to))
+	hops, err := gtk.LabelNew(strings.Join(lines, "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("create chain label: %w", err)
+	}
+	hops.SetXAlign(0)
+	hops.SetSelectable(true)
+	hops.SetLineWrap(true)
+	hops.SetLineWrapMode(pango.WRAP_WORD_CHAR)
+	box.PackStart(hops, false, false, 0)
+
+	content.Add(box)
+	dialog.ShowAll()
+	return dialog, nil
+}
+
+// redirectHosts keys a hop from one host to another.
+func redirectHosts(from, to string) string {
+	return hostOf(from) + " → " + hostOf(to)
+}
+
+func hostOf(target string) string {
+	if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+		return parsed.Hostname()
+	}
+	return target
+}
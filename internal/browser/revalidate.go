@@ -0,0 +1,65 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+type recomposeKey struct{}
+
+// withRecompose marks a navigation that composes the page again instead of
+// showing its pinned composition; with repin, the new composition takes
+// over the pin.
+func withRecompose(ctx context.Context, repin bool) context.Context {
+	return context.WithValue(ctx, recomposeKey{}, repin)
+}
+
+// recomposing reports whether ctx was marked by withRecompose, and whether
+// the new composition should be pinned.
+func recomposing(ctx context.Context) (recompose, repin bool) {
+	repin, recompose = ctx.Value(recomposeKey{}).(bool)
+	return recompose, repin
+}
+
+// revalidateVersion asks the site whether the page behind the selected
+// composition changed since it was composed. An unchanged page only has
+// its check time recorded; a changed one is handed to recompose.
+func (a *App) revalidateVersion(ctx context.Context, versions *versionPicker, info *gtk.Label, recompose func(target string, repin bool)) {
+	selected, ok := versions.selected()
+	target := a.currentPage().SourceURL
+	if !ok || target == "" {
+		return
+	}
+	key := versions.url
+	known := scraper.Validators{ETag: selected.ETag, LastModified: selected.LastModified}
+
+	a.setStatus(info, "Asking the site whether the page changed...")
+	go func() {
+		changed, current, err := a.cfg.Scraper.Revalidate(ctx, target, known)
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case errors.Is(err, scraper.ErrNoValidators):
+			a.setStatus(info, "The site gives no ETag or Last-Modified date for this page; reload with the LLM to compose it again")
+		case err != nil:
+			a.setStatus(info, fmt.Sprintf("Revalidation failed: %v", err))
+		case changed:
+			slog.Info("page changed since composition", "url", target, "composed", selected.CreatedAt)
+			recompose(target, selected.Pinned)
+		default:
+			if err := a.cfg.Compositions.MarkCurrent(key, selected.ID, time.Now(), current.ETag, current.LastModified); err != nil {
+				slog.Warn("record revalidation", "url", key, "err", err)
+			}
+			a.showVersions(versions, key, selected.ID)
+			a.setStatus(info, fmt.Sprintf("Still current — the page has not changed since this version was composed %s ago", roughDuration(time.Since(selected.CreatedAt))))
+		}
+	}()
+}
@@ -0,0 +1,50 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"chimera/internal/browser/webkit"
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/glib"
+)
+
+const (
+	// scriptSettle is how long the rendered markup must stay unchanged.
+	scriptSettle = time.Second
+	// scriptTimeout bounds the offscreen render; the DOM so far is used after it.
+	scriptTimeout = 15 * time.Second
+)
+
+// renderWithScripts loads result's page in an offscreen WebKit view so its
+// scripts can build the content, and extracts that instead of the empty shell.
+func (a *App) renderWithScripts(ctx context.Context, result *scraper.Result) (*scraper.Result, error) {
+	type rendered struct {
+		html string
+		err  error
+	}
+	done := make(chan rendered, 1)
+
+	var cancel func()
+	glib.IdleAdd(func() bool {
+		cancel = webkit.RenderDOM(result.FinalURL, scriptSettle, scriptTimeout, func(html string, err error) {
+			done <- rendered{html, err}
+		})
+		return false
+	})
+
+	select {
+	case <-ctx.Done():
+		glib.IdleAdd(func() bool {
+			cancel()
+			return false
+		})
+		return nil, ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return a.cfg.Scraper.Rendered(result, r.html)
+	}
+}
@@ -0,0 +1,169 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"strings"
+	"time"
+
+	"chimera/internal/scraper"
+	"chimera/internal/search"
+)
+
+// searchURI searches the text of visited pages for the "q" query parameter.
+const searchURI = "chimera://search"
+
+// searchResults caps how many pages a search lists.
+const searchResults = 50
+
+const (
+	// embedTimeout bounds embedding one page.
+	embedTimeout = 30 * time.Second
+	// maxEmbedRun caps the pages embedded after one visit, so configuring
+	// an embedding model catches up on the history a bit at a time.
+	maxEmbedRun = 25
+)
+
+// indexVisit adds the text of result to the full-text index under key and,
+// when an embedding model is set, its vector for searching by meaning.
+func (a *App) indexVisit(key string, result *scraper.Result) {
+	if err := a.cfg.Search.Add(key, result); err != nil {
+		slog.Warn("index page", "url", key, "err", err)
+		return
+	}
+	a.embedPages()
+}
+
+// embedPages embeds indexed pages that have no vector from the current
+// embedding model yet, most recent first, in the background.
+func (a *App) embedPages() {
+	client := a.currentLLM()
+	if !client.CanEmbed() || !a.embedding.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer a.embedding.Store(false)
+		pending, err := a.cfg.Search.Unembedded(client)
+		if err != nil {
+			slog.Warn("list pages to embed", "err", err)
+			return
+		}
+		for _, url := range pending[:min(len(pending), maxEmbedRun)] {
+			ctx, cancel := context.WithTimeout(context.Background(), embedTimeout)
+			err := a.cfg.Search.Embed(ctx, url, client)
+			cancel()
+			if err != nil {
+				// The next visit tries again.
+				slog.Warn("embed page", "url", url, "model", client.EmbeddingModel(), "err", err)
+				return
+			}
+		}
+	}()
+}
+
+// searchPage lists visited pages whose text matches query or, with by set
+// to "meaning", that are about query. Searching by meaning falls back to
+// matching words when no embedding model is set or it fails.
+func (a *App) searchPage(query, by string) internalPage {
+	return func(ctx context.Context) (string, error) {
+		query = strings.TrimSpace(query)
+		var (
+			hits      []search.Hit
+			err       error
+			byMeaning bool
+			notice    string
+			embedded  int
+		)
+		client := a.currentLLM()
+		if by == "meaning" && query != "" {
+			switch {
+			case !client.CanEmbed():
+				notice = "Set an embedding model in LLM Settings to search by meaning. These pages contain the words instead."
+			default:
+				hits, err = a.cfg.Search.About(ctx, query, searchResults, client)
+				if err != nil {
+					slog.Warn("search by meaning", "model", client.EmbeddingModel(), "err", err)
+					notice = fmt.Sprintf("Searching by meaning failed (%v). These pages contain the words instead.", err)
+				} else {
+					byMeaning = true
+				}
+			}
+		}
+		if !byMeaning {
+			hits, err = a.cfg.Search.Search(query, searchResults)
+			if err != nil {
+				return "", err
+			}
+		}
+		indexed, err := a.cfg.Search.Len()
+		if err != nil {
+			return "", err
+		}
+		if client.CanEmbed() {
+			pending, err := a.cfg.Search.Unembedded(client)
+			if err != nil {
+				return "", err
+			}
+			embedded = indexed - len(pending)
+		}
+
+		var builder strings.Builder
+		err = searchTmpl.Execute(&builder, struct {
+			Query     string
+			Hits      []search.Hit
+			Indexed   int
+			ByMeaning bool
+			Notice    string
+			Model     string
+			Embedded  int
+		}{query, hits, indexed, byMeaning, notice, client.EmbeddingModel(), embedded})
+		return builder.String(), err
+	}
+}
+
+var searchTmpl = template.Must(template.New("search").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>{{ if .Query }}{{ .Query }} — {{ end }}Search history — Chimera</title>
+<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+h1 { margin: 0 0 .5rem 0; }
+form { display: flex; gap: .5rem; margin: 1rem 0; }
+input[type=search] { flex: 1; padding: .5rem .7rem; border: 1px solid #cfd5e1; border-radius: 8px; font-size: 1rem; }
+button { padding: .4rem .9rem; border-radius: 8px; border: 1px solid #cfd5e1; background: #fff; }
+ol { list-style: none; padding: 0; }
+li { background: #fff; border-radius: 12px; padding: .8rem 1.1rem; margin: .6rem 0; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+li p { margin: .35rem 0 0 0; line-height: 1.45; }
+mark { background: #fff1b8; color: inherit; border-radius: 3px; }
+a { color: #2b5dcc; text-decoration: none; }
+small { color: #5b6576; }
+code { word-break: break-all; }
+.notice { background: #fff7e0; border-radius: 8px; padding: .6rem .9rem; }
+</style>
+</head>
+<body>
+<h1>Search history</h1>
+<form action="chimera://search" method="get">
+<input type="search" name="q" value="{{ .Query }}" placeholder="Words from pages you have visited, or a topic" autofocus />
+<button type="submit">Search</button>
+<button type="submit" name="by" value="meaning" title="Rank pages by how close they are in meaning, using the embedding model">Pages about this</button>
+</form>
+<p><small>{{ .Indexed }} pages indexed on this computer.{{ if .Model }} {{ .Embedded }} of them can be found by meaning with {{ .Model }}.{{ end }}
+{{ if .ByMeaning }}Closest in meaning first.{{ else }}Every word must appear; the last one may be the start of a word.{{ end }}</small></p>
+{{ with .Notice }}<p class="notice">{{ . }}</p>{{ end }}
+{{ if .Hits }}
+<ol>
+{{ range .Hits }}<li>
+<a href="{{ .URL }}">{{ if .Title }}{{ .Title }}{{ else }}{{ .URL }}{{ end }}</a> <small>visited {{ .VisitedAt.Format "Jan 2 2006 15:04" }}</small>
+{{ with .Snippet }}<p>{{ range . }}{{ if .Match }}<mark>{{ .Text }}</mark>{{ else }}{{ .Text }}{{ end }}{{ end }}</p>{{ end }}
+<small><code>{{ .URL }}</code></small>
+</li>
+{{ end }}
+</ol>
+{{ else if .ByMeaning }}<p>No visited page has been embedded yet; they are as you browse.</p>
+{{ else if .Query }}<p>No visited page contains all of these words.</p>{{ end }}
+</body>
+</html>`))
@@ -0,0 +1,253 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"chimera/internal/export"
+	"chimera/internal/llm"
+	"chimera/internal/session"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// exitScrollTimeout bounds how long closing the window waits for the page
+// to report how far it is scrolled.
+const exitScrollTimeout = 500 * time.Millisecond
+
+type scrollRestoreKey struct{}
+
+// withScrollRestore makes the page rendered under ctx open scrolled down by
+// y CSS pixels, as it was when its session was exported.
+func withScrollRestore(ctx context.Context, y int) context.Context {
+	return context.WithValue(ctx, scrollRestoreKey{}, y)
+}
+
+func restoredScroll(ctx context.Context) int {
+	y, _ := ctx.Value(scrollRestoreKey{}).(int)
+	return y
+}
+
+// scrollPosition asks view how far down its page is scrolled and passes it
+// to done on the GTK main loop; 0 when the page does not say.
+func scrollPosition(view *viewHost, done func(y int)) {
+	view.current().RunJavaScript("String(window.scrollY)", func(value string, err error) {
+		y := 0
+		if err == nil {
+			if parsed, parseErr := strconv.ParseFloat(value, 64); parseErr == nil {
+				y = int(math.Round(parsed))
+			}
+		}
+		done(y)
+	})
+}
+
+// exportSession asks where to save the session and writes the displayed
+// page, its mode and scroll position there as JSON.
+func (a *App) exportSession(parent *gtk.ApplicationWindow, view *viewHost, info *gtk.Label) error {
+	page := a.currentPage()
+	if page.SourceURL == "" {
+		a.setStatus(info, "Nothing to export yet")
+		return nil
+	}
+
+	dialog, err := gtk.FileChooserDialogNewWith2Buttons("Export session", parent, gtk.FILE_CHOOSER_ACTION_SAVE,
+		"Cancel", gtk.RESPONSE_CANCEL, "Export", gtk.RESPONSE_ACCEPT)
+	if err != nil {
+		return fmt.Errorf("create file chooser: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetDoOverwriteConfirmation(true)
+	dialog.SetCurrentName("chimera-session-" + export.Slug(page.SourceURL) + ".json")
+	if dialog.Run() != gtk.RESPONSE_ACCEPT {
+		return nil
+	}
+	path := dialog.GetFilename()
+	if path == "" {
+		return nil
+	}
+
+	tab := session.Tab{URL: page.SourceURL, Title: page.Title, Mode: sessionMode(page)}
+	scrollPosition(view, func(y int) {
+		tab.ScrollY = y
+		go func() {
+			if err := session.Write(path, session.Session{Tabs: []session.Tab{tab}}); err != nil {
+				a.setStatus(info, fmt.Sprintf("Session export failed: %v", err))
+				return
+			}
+			a.setStatus(info, fmt.Sprintf("Session exported to %s", path))
+		}()
+	})
+	return nil
+}
+
+// importSession asks for a session file and returns its page in front.
+// ok is false when the user cancelled.
+func (a *App) importSession(parent *gtk.ApplicationWindow, info *gtk.Label) (tab session.Tab, ok bool, err error) {
+	dialog, err := gtk.FileChooserDialogNewWith2Buttons("Import session", parent, gtk.FILE_CHOOSER_ACTION_OPEN,
+		"Cancel", gtk.RESPONSE_CANCEL, "Import", gtk.RESPONSE_ACCEPT)
+	if err != nil {
+		return session.Tab{}, false, fmt.Errorf("create file chooser: %w", err)
+	}
+	defer dialog.Destroy()
+
+	filter, err := gtk.FileFilterNew()
+	if err != nil {
+		return session.Tab{}, false, fmt.Errorf("create file filter: %w", err)
+	}
+	filter.SetName("Chimera sessions (*.json)")
+	filter.AddPattern("*.json")
+	dialog.AddFilter(filter)
+
+	if dialog.Run() != gtk.RESPONSE_ACCEPT {
+		return session.Tab{}, false, nil
+	}
+	path := dialog.GetFilename()
+	if path == "" {
+		return session.Tab{}, false, nil
+	}
+
+	restored, err := session.Read(path)
+	if err != nil {
+		return session.Tab{}, false, err
+	}
+	if len(restored.Tabs) > 1 {
+		a.setStatus(info, fmt.Sprintf("The session has %d pages; opening the one that was in front", len(restored.Tabs)))
+	}
+	return restored.ActiveTab(), true, nil
+}
+
+// sessionMode names how page was rendered, for session.Tab.Mode.
+func sessionMode(page renderedPage) string {
+	if page.Model == "" && page.Task == llm.TaskCompose {
+		return session.ModeReader
+	}
+	return page.Task.String()
+}
+
+// sessionTask maps a session.Tab.Mode back to whether to use the LLM and
+// for which task.
+func sessionTask(mode string) (bool, llm.Task) {
+	if mode = strings.TrimSpace(mode); mode == "" || mode == session.ModeReader {
+		return false, llm.TaskCompose
+	}
+	return true, llm.ParseTask(mode)
+}
+
+// saveRunningSession keeps page as the running session, so it can be
+// restored after a crash or reopened at the next launch. clean marks the
+// window as closed normally.
+func (a *App) saveRunningSession(page renderedPage, scrollY int, clean bool) {
+	if page.SourceURL == "" {
+		return
+	}
+	tab := session.Tab{URL: page.SourceURL, Title: page.Title, Mode: sessionMode(page), ScrollY: scrollY}
+	if err := a.cfg.Sessions.Save(session.Session{Tabs: []session.Tab{tab}, CleanExit: clean}); err != nil {
+		slog.Warn("save session", "err", err)
+	}
+}
+
+// saveSessionOnExit records the page in front and how far it is scrolled
+// as a cleanly closed session, then calls done. It waits at most
+// exitScrollTimeout for the scroll position. It runs on the GTK main loop.
+func (a *App) saveSessionOnExit(view *viewHost, done func()) {
+	page := a.currentPage()
+	if page.SourceURL == "" {
+		a.markSessionClosed()
+		done()
+		return
+	}
+
+	finished := false
+	finish := func(y int) {
+		if finished {
+			return
+		}
+		finished = true
+		a.saveRunningSession(page, y, true)
+		a.leaveWorkspace(page, y)
+		done()
+	}
+	scrollPosition(view, finish)
+	glib.TimeoutAdd(uint(exitScrollTimeout/time.Millisecond), func() bool {
+		finish(0)
+		return false
+	})
+}
+
+// markSessionClosed marks the saved session as closed normally without
+// changing its page. It covers quitting without closing the window, such
+// as on Ctrl+C in the terminal.
+func (a *App) markSessionClosed() {
+	if err := a.cfg.Sessions.MarkClosed(); err != nil {
+		slog.Warn("save session", "err", err)
+	}
+}
+
+// lastSession returns the session saved by the previous run, and whether
+// that run ended without closing the window, which means it crashed.
+func (a *App) lastSession() (saved session.Session, crashed bool, ok bool) {
+	saved, ok, err := a.cfg.Sessions.Load()
+	if err != nil {
+		slog.Warn("load last session", "err", err)
+	}
+	if err != nil || !ok {
+		return session.Session{}, false, false
+	}
+	return saved, !saved.CleanExit, true
+}
+
+// forgetSession removes the saved session, so the next launch does not ask
+// about it again.
+func (a *App) forgetSession() {
+	if err := a.cfg.Sessions.Remove(); err != nil {
+		slog.Warn("remove session", "err", err)
+	}
+}
+
+// confirmRestore asks whether to reopen the page that was open when the
+// previous run crashed.
+func (a *App) confirmRestore(parent *gtk.ApplicationWindow, tab session.Tab) (bool, error) {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return false, fmt.Errorf("create dialog: %w", err)
+	}
+	defer dialog.Destroy()
+	dialog.SetTitle("Restore previous session?")
+	dialog.SetModal(true)
+	dialog.SetTransientFor(parent)
+	dialog.SetDefaultSize(460, -1)
+	dialog.AddButton("Start fresh", gtk.RESPONSE_CANCEL)
+	dialog.AddButton("Restore", gtk.RESPONSE_OK)
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		return false, fmt.Errorf("access content area: %w", err)
+	}
+	title := tab.Title
+	if title == "" {
+		title = tab.URL
+	}
+	hint, err := gtk.LabelNew(fmt.Sprintf("Chimera did not close properly last time. Reopen %s?", title))
+	if err != nil {
+		return false, fmt.Errorf("create hint label: %w", err)
+	}
+	hint.SetXAlign(0)
+	hint.SetLineWrap(true)
+	hint.SetMarginTop(14)
+	hint.SetMarginBottom(14)
+	hint.SetMarginStart(18)
+	hint.SetMarginEnd(18)
+	content.PackStart(hint, false, false, 0)
+	content.ShowAll()
+
+	return dialog.Run() == gtk.RESPONSE_OK, nil
+}
@@ -0,0 +1,384 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"chimera/internal/keyring"
+	"chimera/internal/llm"
+	"chimera/internal/render"
+	persist "chimera/internal/settings"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// settingsSection is one tab of the settings dialog.
+type settingsSection struct {
+	title string
+	form  *settingsForm
+	// apply copies what was entered into draft, or reports why it cannot
+	// be saved.
+	apply func(draft *settingsDraft) error
+}
+
+// settingsDraft collects the settings entered in the dialog before they are
+// applied.
+type settingsDraft struct {
+	llm    appLLMSettings
+	prefer bool
+	prefs  appPreferences
+}
+
+// settingsForm lays out a settings section as labelled rows of a two-column
+// grid, added top to bottom.
+type settingsForm struct {
+	grid *gtk.Grid
+	row  int
+}
+
+func newSettingsForm() (*settingsForm, error) {
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, fmt.Errorf("create grid: %w", err)
+	}
+	grid.SetRowSpacing(10)
+	grid.SetColumnSpacing(14)
+	grid.SetMarginTop(14)
+	grid.SetMarginBottom(14)
+	grid.SetMarginStart(18)
+	grid.SetMarginEnd(18)
+	return &settingsForm{grid: grid}, nil
+}
+
+// add puts widget in a row of its own beside a label reading name.
+func (f *settingsForm) add(name string, widget gtk.IWidget) error {
+	return f.addLabelled(name, widget, false)
+}
+
+// addTall is add for widgets several lines high, whose label stays at the
+// top of the row.
+func (f *settingsForm) addTall(name string, widget gtk.IWidget) error {
+	return f.addLabelled(name, widget, true)
+}
+
+func (f *settingsForm) addLabelled(name string, widget gtk.IWidget, top bool) error {
+	label, err := gtk.LabelNew(name)
+	if err != nil {
+		return fmt.Errorf("create %s label: %w", strings.ToLower(name), err)
+	}
+	label.SetXAlign(0)
+	if top {
+		label.SetYAlign(0)
+	}
+	f.grid.Attach(label, 0, f.row, 1, 1)
+	f.grid.Attach(widget, 1, f.row, 1, 1)
+	f.row++
+	return nil
+}
+
+// indent puts widget in a row of its own below the previous one's widget.
+func (f *settingsForm) indent(widget gtk.IWidget) {
+	f.grid.Attach(widget, 1, f.row, 1, 1)
+	f.row++
+}
+
+// span puts widget in a row of its own across both columns.
+func (f *settingsForm) span(widget gtk.IWidget) {
+	f.grid.Attach(widget, 0, f.row, 2, 1)
+	f.row++
+}
+
+// check adds a checkbox across both columns.
+func (f *settingsForm) check(label, tooltip string, active bool) (*gtk.CheckButton, error) {
+	check, err := gtk.CheckButtonNewWithLabel(label)
+	if err != nil {
+		return nil, fmt.Errorf("create %q checkbox: %w", label, err)
+	}
+	if tooltip != "" {
+		check.SetTooltipText(tooltip)
+	}
+	check.SetActive(active)
+	f.span(check)
+	return check, nil
+}
+
+// entry adds a text entry labelled name.
+func (f *settingsForm) entry(name, placeholder, tooltip, text string) (*gtk.Entry, error) {
+	entry, err := gtk.EntryNew()
+	if err != nil {
+		return nil, fmt.Errorf("create %s entry: %w", strings.ToLower(name), err)
+	}
+	entry.SetPlaceholderText(placeholder)
+	if tooltip != "" {
+		entry.SetTooltipText(tooltip)
+	}
+	entry.SetText(text)
+	return entry, f.add(name, entry)
+}
+
+// entryText returns the trimmed text of entry; name says what it holds in
+// the error.
+func entryText(entry *gtk.Entry, name string) (string, error) {
+	text, err := entry.GetText()
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", name, err)
+	}
+	return strings.TrimSpace(text), nil
+}
+
+func (a *App) openSettingsDialog(parent *gtk.ApplicationWindow, llmBtn *gtk.Button, status *gtk.Label) error {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return fmt.Errorf("create dialog: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle("LLM Settings")
+	dialog.SetModal(true)
+	dialog.SetTransientFor(parent)
+	dialog.AddButton("Cancel", gtk.RESPONSE_CANCEL)
+	dialog.AddButton("Save", gtk.RESPONSE_OK)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		return fmt.Errorf("access content area: %w", err)
+	}
+
+	snapshot, prefer := a.settingsSnapshot()
+	prefs := a.preferences()
+
+	dialogCtx, cancelDialog := context.WithCancel(context.Background())
+	defer cancelDialog()
+
+	network, proxyEntry, err := networkSection(prefs)
+	if err != nil {
+		return err
+	}
+	llmTab, err := a.llmSection(dialogCtx, snapshot, prefer, prefs, proxyEntry)
+	if err != nil {
+		return err
+	}
+	reader, err := a.readerSection(prefs)
+	if err != nil {
+		return err
+	}
+	storage, err := storageSection(prefs)
+	if err != nil {
+		return err
+	}
+	privacy, err := privacySection(prefs)
+	if err != nil {
+		return err
+	}
+	sections := []*settingsSection{llmTab, network, reader, storage, privacy}
+
+	notebook, err := gtk.NotebookNew()
+	if err != nil {
+		return fmt.Errorf("create settings tabs: %w", err)
+	}
+	for _, section := range sections {
+		tab, err := gtk.LabelNew(section.title)
+		if err != nil {
+			return fmt.Errorf("create %s tab: %w", section.title, err)
+		}
+		scroll, err := gtk.ScrolledWindowNew(nil, nil)
+		if err != nil {
+			return fmt.Errorf("create %s scroller: %w", section.title, err)
+		}
+		scroll.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
+		scroll.SetMinContentHeight(480)
+		scroll.Add(section.form.grid)
+		notebook.AppendPage(scroll, tab)
+	}
+
+	content.Add(notebook)
+	dialog.ShowAll()
+
+	response := dialog.Run()
+	if response != gtk.RESPONSE_OK {
+		return nil
+	}
+
+	draft := settingsDraft{llm: snapshot, prefer: prefer, prefs: prefs}
+	for _, section := range sections {
+		if err := section.apply(&draft); err != nil {
+			return err
+		}
+	}
+
+	applyErr := a.applySettings(draft.llm, draft.prefer, draft.prefs)
+	if applyErr != nil && !errors.Is(applyErr, keyring.ErrUnavailable) {
+		return fmt.Errorf("apply settings: %w", applyErr)
+	}
+
+	a.updateLLMButton(llmBtn)
+	setLiteTheme(a.liteRendering())
+	// Saving replaces the client, possibly with a different model.
+	a.warmUp(context.Background())
+
+	switch {
+	case applyErr != nil:
+		a.setStatus(status, "Settings saved, but the API key was not: no keyring available. Enable plain-text storage to keep it.")
+	case draft.prefer && !a.llmAvailable():
+		a.setStatus(status, "LLM preference saved but endpoint unavailable")
+	case a.llmAvailable():
+		a.setStatus(status, "LLM configured")
+	default:
+		a.setStatus(status, "LLM disabled")
+	}
+
+	return nil
+}
+
+func (a *App) applySettings(settings appLLMSettings, prefer bool, prefs appPreferences) error {
+	settings = settings.normalized()
+
+	a.mu.RLock()
+	cfg := a.cfg.LLMConfig
+	a.mu.RUnlock()
+	settings.configure(&cfg)
+	cfg.Timeout = a.llmTimeout
+	cfg.Proxy = prefs.Proxy
+	cfg.Prompts = a.loadPrompts()
+
+	if err := a.cfg.Scraper.SetProxy(prefs.Proxy); err != nil {
+		return fmt.Errorf("set proxy: %w", err)
+	}
+	applyWebProxy(prefs.Proxy)
+	a.cfg.Scraper.SetRequestOptions(requestOptions(prefs))
+	a.cfg.Scraper.SetLimits(prefs.Limits)
+	a.cfg.Scraper.SetStripBoilerplate(!prefs.KeepBoilerplate)
+	a.cfg.Scraper.SetJoinPages(prefs.JoinPages)
+	a.cfg.Scraper.SetRedirects(prefs.MaxRedirects, prefs.CrossSiteRedirects == persist.RedirectsBlock)
+
+	client := llm.NewClient(workspaceLLMConfig(cfg, a.currentWorkspace()))
+
+	a.mu.Lock()
+	a.llmClient = client
+	a.llmPreferred = prefer
+	a.llmSettings = settings
+	a.prefs = prefs
+	a.lite = render.ResolveLite(prefs.Rendering)
+	a.cfg.LLM = client
+	a.cfg.UseLLM = prefer
+	a.cfg.LLMConfig = cfg
+	a.mu.Unlock()
+
+	if a.settingsStore != nil {
+		if err := a.settingsStore.Save(storedSettings(settings, prefer, prefs)); err != nil {
+			return fmt.Errorf("save settings: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// normalized returns s with surrounding spaces trimmed and negative numbers
+// raised to zero.
+func (s appLLMSettings) normalized() appLLMSettings {
+	s.BaseURL = strings.TrimSpace(s.BaseURL)
+	s.Model = strings.TrimSpace(s.Model)
+	s.APIKey = strings.TrimSpace(s.APIKey)
+	s.SystemPrompt = strings.TrimSpace(s.SystemPrompt)
+	s.EmbeddingModel = strings.TrimSpace(s.EmbeddingModel)
+	s.InputPrice = max(s.InputPrice, 0)
+	s.MaxInFlight = max(s.MaxInFlight, 0)
+	return s
+}
+
+// configure sets the fields of cfg that s holds.
+func (s appLLMSettings) configure(cfg *llm.Config) {
+	cfg.BaseURL = s.BaseURL
+	cfg.Endpoint = s.Endpoint
+	cfg.Model = s.Model
+	cfg.APIKey = s.APIKey
+	cfg.ContextTokens = s.ContextTokens
+	cfg.SystemPrompt = s.SystemPrompt
+	cfg.EmbeddingModel = s.EmbeddingModel
+	cfg.InputPrice = s.InputPrice
+	cfg.VisionModels = s.VisionModels
+	cfg.Fallbacks = s.Fallbacks
+	cfg.Routing = s.Routing
+	cfg.PromptFormat = s.PromptFormat
+	cfg.MaxInFlight = s.MaxInFlight
+}
+
+// storedSettings returns the settings file contents for settings, prefer
+// and prefs.
+func storedSettings(settings appLLMSettings, prefer bool, prefs appPreferences) persist.Data {
+	return persist.Data{
+		BaseURL:  settings.BaseURL,
+		Endpoint: string(settings.Endpoint),
+		Model:    settings.Model,
+		APIKey:   settings.APIKey,
+		UseLLM:   prefer,
+
+		ContextTokens:  settings.ContextTokens,
+		SystemPrompt:   settings.SystemPrompt,
+		EmbeddingModel: settings.EmbeddingModel,
+		InputPrice:     settings.InputPrice,
+		VisionModels:   settings.VisionModels,
+		Fallbacks:      storedFallbacks(settings.Fallbacks),
+		RoutingOrder:   settings.Routing.Order,
+		RoutingSort:    settings.Routing.Sort,
+		RoutingOnly:    settings.Routing.Only,
+		RoutingPrivate: settings.Routing.DenyDataCollection,
+		PromptFormat:   settings.PromptFormat,
+		MaxInFlight:    settings.MaxInFlight,
+		Rendering:      prefs.Rendering,
+
+		PlaintextAPIKey: prefs.PlaintextAPIKey,
+		Sites:           prefs.Sites,
+		EnrichCompose:   prefs.EnrichCompose,
+		FetchLinks:      prefs.FetchLinks,
+		PersonalDigest:  prefs.PersonalDigest,
+		Proxy:           prefs.Proxy,
+		WarmUp:          prefs.WarmUp,
+		CleanLinks:      prefs.CleanLinks,
+		UserAgent:       prefs.UserAgent,
+		AcceptLanguage:  prefs.AcceptLanguage,
+
+		NoScriptRendering: prefs.NoScriptRendering,
+		SkipConsentWalls:  prefs.SkipConsentWalls,
+
+		MaxHeadings:   prefs.Limits.Headings,
+		MaxParagraphs: prefs.Limits.Paragraphs,
+		MaxCodeBlocks: prefs.Limits.CodeBlocks,
+		MaxLinks:      prefs.Limits.Links,
+		HeadingDepth:  prefs.Limits.HeadingDepth,
+		MinParagraph:  prefs.Limits.MinParagraph,
+
+		KeepBoilerplate: prefs.KeepBoilerplate,
+		JoinPages:       prefs.JoinPages,
+		VisitCommand:    prefs.VisitCommand,
+		VaultDir:        prefs.VaultDir,
+		VaultTags:       prefs.VaultTags,
+		CitationFormat:  prefs.CitationFormat,
+		CitationFile:    prefs.CitationFile,
+
+		MaxRedirects:       prefs.MaxRedirects,
+		CrossSiteRedirects: prefs.CrossSiteRedirects,
+
+		LowVision:   prefs.LowVision,
+		MinFontSize: prefs.MinFontSize,
+		MinContrast: prefs.MinContrast,
+
+		Startup:        prefs.Startup,
+		StartupURL:     prefs.StartupURL,
+		StartupCompose: prefs.StartupCompose,
+
+		ReaderFont:       prefs.Typography.FontFamily,
+		ReaderFontSize:   prefs.Typography.FontSize,
+		ReaderLineHeight: prefs.Typography.LineHeight,
+		ReaderWidth:      prefs.Typography.Width,
+		ReaderJustify:    prefs.Typography.Justify,
+		BlockExternal:    prefs.BlockExternal,
+		SourceBase:       prefs.SourceBase,
+		Prompts:          prefs.Prompts,
+
+		SummaryLanguage:     prefs.SummaryLanguage,
+		TranslationLanguage: prefs.TranslationLanguage,
+	}
+}
@@ -0,0 +1,508 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"chimera/internal/llm"
+	"chimera/internal/locale"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// llmSection builds the settings for the LLM endpoint and what is sent to
+// it. Testing the endpoint as entered goes through the proxy in proxyEntry.
+func (a *App) llmSection(ctx context.Context, snapshot appLLMSettings, prefer bool, prefs appPreferences, proxyEntry *gtk.Entry) (*settingsSection, error) {
+	form, err := newSettingsForm()
+	if err != nil {
+		return nil, err
+	}
+
+	baseEntry, err := gtk.EntryNew()
+	if err != nil {
+		return nil, fmt.Errorf("create base entry: %w", err)
+	}
+	baseEntry.SetPlaceholderText("https://api.openai.com")
+	baseEntry.SetWidthChars(42)
+	baseEntry.SetText(snapshot.BaseURL)
+
+	testButton, err := gtk.ButtonNewWithLabel("Test connection")
+	if err != nil {
+		return nil, fmt.Errorf("create test button: %w", err)
+	}
+	testButton.SetTooltipText("Check that the endpoint answers and accepts the API key")
+	testResult, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, fmt.Errorf("create test result label: %w", err)
+	}
+	testResult.SetXAlign(0)
+	testResult.SetLineWrap(true)
+
+	baseRow, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, fmt.Errorf("create base row: %w", err)
+	}
+	endpointCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create endpoint combo: %w", err)
+	}
+	for _, endpoint := range llm.Endpoints {
+		endpointCombo.Append(string(endpoint), endpoint.Label())
+	}
+	endpointCombo.SetActiveID(string(snapshot.Endpoint))
+	endpointCombo.SetTooltipText("The kind of API at the base URL. Automatic picks Azure OpenAI for *.openai.azure.com addresses. For Azure OpenAI, enter the resource address as base URL, optionally with ?api-version=, and the deployment name as model.")
+	baseRow.PackStart(endpointCombo, false, false, 0)
+	baseRow.PackStart(baseEntry, true, true, 0)
+	baseRow.PackStart(testButton, false, false, 0)
+	baseBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
+	if err != nil {
+		return nil, fmt.Errorf("create base box: %w", err)
+	}
+	baseBox.PackStart(baseRow, false, false, 0)
+	baseBox.PackStart(testResult, false, false, 0)
+	if err := form.add("Base URL", baseBox); err != nil {
+		return nil, err
+	}
+
+	modelBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, fmt.Errorf("create model box: %w", err)
+	}
+	modelCombo, err := gtk.ComboBoxTextNewWithEntry()
+	if err != nil {
+		return nil, fmt.Errorf("create model combo: %w", err)
+	}
+	modelEntry, err := modelCombo.GetEntry()
+	if err != nil {
+		return nil, fmt.Errorf("access model entry: %w", err)
+	}
+	modelEntry.SetPlaceholderText("gpt-4o-mini, llama3, mistral-nemo...")
+	modelEntry.SetText(snapshot.Model)
+	modelBox.PackStart(modelCombo, true, true, 0)
+
+	refreshModels, err := gtk.ButtonNewFromIconName("view-refresh-symbolic", gtk.ICON_SIZE_BUTTON)
+	if err != nil {
+		return nil, fmt.Errorf("create refresh button: %w", err)
+	}
+	refreshModels.SetTooltipText("Fetch the models offered by the endpoint")
+	modelBox.PackStart(refreshModels, false, false, 0)
+	if err := form.add("Model", modelBox); err != nil {
+		return nil, err
+	}
+
+	keyEntry, err := gtk.EntryNew()
+	if err != nil {
+		return nil, fmt.Errorf("create key entry: %w", err)
+	}
+	keyEntry.SetVisibility(false)
+	keyEntry.SetInputPurpose(gtk.INPUT_PURPOSE_PASSWORD)
+	keyEntry.SetText(snapshot.APIKey)
+
+	plaintextCheck, err := gtk.CheckButtonNewWithLabel("Store the key in plain text instead of the system keyring")
+	if err != nil {
+		return nil, fmt.Errorf("create plaintext checkbox: %w", err)
+	}
+	plaintextCheck.SetActive(prefs.PlaintextAPIKey)
+
+	keyBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
+	if err != nil {
+		return nil, fmt.Errorf("create key box: %w", err)
+	}
+	keyBox.PackStart(keyEntry, false, false, 0)
+	keyBox.PackStart(plaintextCheck, false, false, 0)
+	if err := form.add("API Key", keyBox); err != nil {
+		return nil, err
+	}
+
+	contextSpin, err := gtk.SpinButtonNewWithRange(0, 1048576, 1024)
+	if err != nil {
+		return nil, fmt.Errorf("create context spin: %w", err)
+	}
+	contextSpin.SetValue(float64(snapshot.ContextTokens))
+	contextSpin.SetTooltipText("Model context size in tokens; longer pages are composed in parts. 0 disables splitting.")
+	if err := form.add("Context window", contextSpin); err != nil {
+		return nil, err
+	}
+
+	preferCheck, err := form.check("Use LLM by default when pressing Enter", "", prefer)
+	if err != nil {
+		return nil, err
+	}
+	warmCheck, err := form.check("Warm up the model at startup and keep it loaded",
+		"Sends a one-token request at startup and every few minutes while Chimera is focused, so local servers such as Ollama keep the model in memory", prefs.WarmUp)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultLanguage := fmt.Sprintf("Default: %s (UI language)", locale.Language())
+	summaryLangEntry, err := form.entry("Summary language", defaultLanguage, "", prefs.SummaryLanguage)
+	if err != nil {
+		return nil, err
+	}
+	translateLangEntry, err := form.entry("Translation language", defaultLanguage, "", prefs.TranslationLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	prompts := a.loadPrompts()
+
+	promptScroll, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create prompt scroller: %w", err)
+	}
+	promptScroll.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
+	promptScroll.SetShadowType(gtk.SHADOW_IN)
+	promptScroll.SetSizeRequest(-1, 110)
+
+	promptView, err := gtk.TextViewNew()
+	if err != nil {
+		return nil, fmt.Errorf("create prompt view: %w", err)
+	}
+	promptView.SetWrapMode(gtk.WRAP_WORD)
+	promptBuffer, err := promptView.GetBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("access prompt buffer: %w", err)
+	}
+	if snapshot.SystemPrompt != "" {
+		promptBuffer.SetText(snapshot.SystemPrompt)
+	} else {
+		promptBuffer.SetText(prompts.System())
+	}
+	promptScroll.Add(promptView)
+	if err := form.addTall("System prompt", promptScroll); err != nil {
+		return nil, err
+	}
+
+	resetPrompt, err := gtk.ButtonNewWithLabel("Reset to default")
+	if err != nil {
+		return nil, fmt.Errorf("create reset prompt button: %w", err)
+	}
+	resetPrompt.SetHAlign(gtk.ALIGN_END)
+	resetPrompt.Connect("clicked", func() {
+		promptBuffer.SetText(prompts.System())
+	})
+	form.indent(resetPrompt)
+
+	promptNames := prompts.Names()
+	promptCombos := make([]*gtk.ComboBoxText, len(llm.Tasks))
+	for i, task := range llm.Tasks {
+		combo, err := gtk.ComboBoxTextNew()
+		if err != nil {
+			return nil, fmt.Errorf("create %s prompt combo: %w", task, err)
+		}
+		for _, name := range promptNames {
+			combo.Append(name, name)
+		}
+		if !combo.SetActiveID(prefs.Prompts[task.String()]) {
+			combo.SetActiveID(task.String())
+		}
+		combo.SetTooltipText("Prompt templates are the .tmpl files in the prompts folder of Chimera's configuration directory; edit them or add your own, then reopen settings to pick them here")
+		promptCombos[i] = combo
+		if err := form.add(taskLabel(task)+" prompt", combo); err != nil {
+			return nil, err
+		}
+	}
+
+	enrichCheck, err := form.check(fmt.Sprintf("Enrich compositions with the top %d linked pages", enrichLinks),
+		"Also scrapes same-site links and adds short extracts to the prompt; useful for landing and index pages", prefs.EnrichCompose)
+	if err != nil {
+		return nil, err
+	}
+	fetchCheck, err := form.check(fmt.Sprintf("Let the model read up to %d linked pages of the same site while composing", llm.DefaultFetchHops),
+		"Offers models that support tool calls a fetch_url tool, so they can pull in a linked FAQ or the next page when the page refers to it. Only links on the page that stay on its site can be read.", prefs.FetchLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddingCombo, err := gtk.ComboBoxTextNewWithEntry()
+	if err != nil {
+		return nil, fmt.Errorf("create embedding model combo: %w", err)
+	}
+	embeddingEntry, err := embeddingCombo.GetEntry()
+	if err != nil {
+		return nil, fmt.Errorf("access embedding model entry: %w", err)
+	}
+	embeddingEntry.SetPlaceholderText("text-embedding-3-small, nomic-embed-text...")
+	embeddingEntry.SetText(snapshot.EmbeddingModel)
+	embeddingCombo.SetTooltipText("Lets Search history find pages about a topic, not just pages containing its words. Leave empty to turn it off.")
+	if err := form.add("Embedding model", embeddingCombo); err != nil {
+		return nil, err
+	}
+
+	priceSpin, err := gtk.SpinButtonNewWithRange(0, 1, 0.0001)
+	if err != nil {
+		return nil, fmt.Errorf("create price spin: %w", err)
+	}
+	priceSpin.SetDigits(4)
+	priceSpin.SetValue(snapshot.InputPrice)
+	priceSpin.SetTooltipText("What a paid endpoint charges, in dollars, per 1000 prompt tokens. When set, pages in reader mode show an estimate next to the Compose button. Leave at 0 for local models.")
+	if err := form.add("Price per 1K input tokens", priceSpin); err != nil {
+		return nil, err
+	}
+
+	visionEntry, err := form.entry("Vision models", "gpt-4o, llava, qwen2.5vl",
+		"Models that accept images, separated by commas. While one of them is in use, pages that rely on their layout, such as landing pages, are composed with a screenshot of the original page. A name without a :tag covers all its tags.",
+		strings.Join(snapshot.VisionModels, ", "))
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackView, err := gtk.TextViewNew()
+	if err != nil {
+		return nil, fmt.Errorf("create fallback view: %w", err)
+	}
+	fallbackView.SetMonospace(true)
+	fallbackView.SetSizeRequest(360, 60)
+	fallbackView.SetTooltipText("One Name | base URL | model | API key per line, tried in order when the endpoint above fails or times out. The name and key may be left empty; (saved) keeps the key stored before.")
+	fallbackBuffer, err := fallbackView.GetBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("access fallback buffer: %w", err)
+	}
+	fallbackBuffer.SetText(formatFallbacks(snapshot.Fallbacks))
+	if err := form.addTall("Fallback providers", fallbackView); err != nil {
+		return nil, err
+	}
+
+	routingOrder, err := gtk.EntryNew()
+	if err != nil {
+		return nil, fmt.Errorf("create routing order entry: %w", err)
+	}
+	routingOrder.SetPlaceholderText("Anthropic, Together")
+	routingOrder.SetText(strings.Join(snapshot.Routing.Order, ", "))
+	routingOrder.SetTooltipText("Upstream providers OpenRouter should try first, in order, separated by commas")
+	routingSort, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create routing sort combo: %w", err)
+	}
+	for _, sort := range llm.RoutingSorts {
+		routingSort.Append(sort, routingSortLabel(sort))
+	}
+	routingSort.SetActiveID(snapshot.Routing.Sort)
+	routingOnly, err := gtk.CheckButtonNewWithLabel("Only these providers")
+	if err != nil {
+		return nil, fmt.Errorf("create routing only checkbox: %w", err)
+	}
+	routingOnly.SetActive(snapshot.Routing.Only)
+	routingDeny, err := gtk.CheckButtonNewWithLabel("Skip providers that store prompts")
+	if err != nil {
+		return nil, fmt.Errorf("create routing data checkbox: %w", err)
+	}
+	routingDeny.SetActive(snapshot.Routing.DenyDataCollection)
+
+	routingRow, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, fmt.Errorf("create routing row: %w", err)
+	}
+	routingRow.PackStart(routingOrder, true, true, 0)
+	routingRow.PackStart(routingSort, false, false, 0)
+	routingChecks, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 12)
+	if err != nil {
+		return nil, fmt.Errorf("create routing checks: %w", err)
+	}
+	routingChecks.PackStart(routingOnly, false, false, 0)
+	routingChecks.PackStart(routingDeny, false, false, 0)
+	routingBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
+	if err != nil {
+		return nil, fmt.Errorf("create routing box: %w", err)
+	}
+	routingBox.PackStart(routingRow, false, false, 0)
+	routingBox.PackStart(routingChecks, false, false, 0)
+	if err := form.add("OpenRouter routing", routingBox); err != nil {
+		return nil, err
+	}
+
+	formatCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create prompt format combo: %w", err)
+	}
+	for _, name := range llm.PromptFormats {
+		formatCombo.Append(name, promptFormatLabel(name))
+	}
+	if !formatCombo.SetActiveID(snapshot.PromptFormat) {
+		formatCombo.SetActiveID(llm.DefaultPromptFormat)
+	}
+	formatCombo.SetTooltipText("The chat template of the model, used with the llama.cpp /completion endpoint type, which takes one plain prompt instead of chat messages")
+	if err := form.add("llama.cpp prompt format", formatCombo); err != nil {
+		return nil, err
+	}
+
+	inFlightSpin, err := gtk.SpinButtonNewWithRange(0, 16, 1)
+	if err != nil {
+		return nil, fmt.Errorf("create in-flight spin: %w", err)
+	}
+	inFlightSpin.SetValue(float64(snapshot.MaxInFlight))
+	inFlightSpin.SetTooltipText("How many LLM requests each provider is sent at once; the others wait in line, with their place shown in the status bar. 0 uses 2.")
+	if err := form.add("Requests at once", inFlightSpin); err != nil {
+		return nil, err
+	}
+
+	// entryClient returns a client for the endpoint as entered, or nil
+	// while no base URL is.
+	entryClient := func() *llm.Client {
+		base, _ := baseEntry.GetText()
+		key, _ := keyEntry.GetText()
+		if strings.TrimSpace(base) == "" {
+			return nil
+		}
+		proxyURL, _ := proxyEntry.GetText()
+		return llm.NewClient(llm.Config{
+			BaseURL:  strings.TrimSpace(base),
+			Endpoint: llm.ParseEndpoint(endpointCombo.GetActiveID()),
+			APIKey:   strings.TrimSpace(key),
+			Timeout:  10 * time.Second,
+			Proxy:    strings.TrimSpace(proxyURL),
+		})
+	}
+
+	loadModels := func() {
+		client := entryClient()
+		if client == nil {
+			return
+		}
+		refreshModels.SetSensitive(false)
+		go func() {
+			models, err := client.ListModels(ctx)
+			glib.IdleAdd(func() bool {
+				if ctx.Err() != nil {
+					return false
+				}
+				refreshModels.SetSensitive(true)
+				if err != nil {
+					refreshModels.SetTooltipText(fmt.Sprintf("Could not list models: %v", err))
+					return false
+				}
+				refreshModels.SetTooltipText(fmt.Sprintf("%d models available; click to refresh", len(models)))
+				current, _ := modelEntry.GetText()
+				currentEmbedding, _ := embeddingEntry.GetText()
+				modelCombo.RemoveAll()
+				embeddingCombo.RemoveAll()
+				for _, name := range models {
+					modelCombo.Append(name, name)
+					embeddingCombo.Append(name, name)
+				}
+				modelEntry.SetText(current)
+				embeddingEntry.SetText(currentEmbedding)
+				return false
+			})
+		}()
+	}
+	refreshModels.Connect("clicked", loadModels)
+	loadModels()
+
+	testButton.Connect("clicked", func() {
+		client := entryClient()
+		if client == nil {
+			testResult.SetText("Enter a base URL first.")
+			return
+		}
+		testButton.SetSensitive(false)
+		testResult.SetText("Testing...")
+		go func() {
+			health := client.Ping(ctx)
+			glib.IdleAdd(func() bool {
+				if ctx.Err() != nil {
+					return false
+				}
+				testButton.SetSensitive(true)
+				testResult.SetText(health.String())
+				return false
+			})
+		}()
+	})
+
+	apply := func(draft *settingsDraft) error {
+		base, err := entryText(baseEntry, "base URL")
+		if err != nil {
+			return err
+		}
+		model, err := entryText(modelEntry, "model")
+		if err != nil {
+			return err
+		}
+		key, err := entryText(keyEntry, "API key")
+		if err != nil {
+			return err
+		}
+		start, end := promptBuffer.GetBounds()
+		promptText, err := promptBuffer.GetText(start, end, false)
+		if err != nil {
+			return fmt.Errorf("read system prompt: %w", err)
+		}
+		promptText = strings.TrimSpace(promptText)
+		if promptText == prompts.System() {
+			promptText = ""
+		}
+		embeddingModel, err := entryText(embeddingEntry, "embedding model")
+		if err != nil {
+			return err
+		}
+		visionText, err := entryText(visionEntry, "vision models")
+		if err != nil {
+			return err
+		}
+		fallbackStart, fallbackEnd := fallbackBuffer.GetBounds()
+		fallbackText, err := fallbackBuffer.GetText(fallbackStart, fallbackEnd, false)
+		if err != nil {
+			return fmt.Errorf("read fallback providers: %w", err)
+		}
+		fallbacks, err := parseFallbacks(fallbackText, snapshot.Fallbacks)
+		if err != nil {
+			return err
+		}
+		orderText, err := entryText(routingOrder, "routing order")
+		if err != nil {
+			return err
+		}
+		summaryLang, err := entryText(summaryLangEntry, "summary language")
+		if err != nil {
+			return err
+		}
+		translateLang, err := entryText(translateLangEntry, "translation language")
+		if err != nil {
+			return err
+		}
+
+		draft.llm = appLLMSettings{
+			BaseURL:  base,
+			Endpoint: llm.ParseEndpoint(endpointCombo.GetActiveID()),
+			Model:    model,
+			APIKey:   key,
+
+			ContextTokens:  contextSpin.GetValueAsInt(),
+			SystemPrompt:   promptText,
+			EmbeddingModel: embeddingModel,
+			InputPrice:     priceSpin.GetValue(),
+			VisionModels:   commaList(visionText),
+			Fallbacks:      fallbacks,
+			Routing: llm.Routing{
+				Order:              commaList(orderText),
+				Sort:               routingSort.GetActiveID(),
+				Only:               routingOnly.GetActive(),
+				DenyDataCollection: routingDeny.GetActive(),
+			},
+			PromptFormat: formatCombo.GetActiveID(),
+			MaxInFlight:  inFlightSpin.GetValueAsInt(),
+		}
+		draft.prefer = preferCheck.GetActive()
+		draft.prefs.PlaintextAPIKey = plaintextCheck.GetActive()
+		draft.prefs.WarmUp = warmCheck.GetActive()
+		draft.prefs.EnrichCompose = enrichCheck.GetActive()
+		draft.prefs.FetchLinks = fetchCheck.GetActive()
+		draft.prefs.SummaryLanguage = summaryLang
+		draft.prefs.TranslationLanguage = translateLang
+		draft.prefs.Prompts = nil
+		for i, task := range llm.Tasks {
+			if name := promptCombos[i].GetActiveID(); name != "" && name != task.String() {
+				if draft.prefs.Prompts == nil {
+					draft.prefs.Prompts = make(map[string]string)
+				}
+				draft.prefs.Prompts[task.String()] = name
+			}
+		}
+		return nil
+	}
+	return &settingsSection{title: "LLM", form: form, apply: apply}, nil
+}
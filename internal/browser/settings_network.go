@@ -0,0 +1,144 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"chimera/internal/proxy"
+	"chimera/internal/scraper"
+	persist "chimera/internal/settings"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// networkSection builds the settings for how pages are fetched. It also
+// returns the proxy entry, which the LLM section tests the endpoint with.
+func networkSection(prefs appPreferences) (*settingsSection, *gtk.Entry, error) {
+	form, err := newSettingsForm()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proxyEntry, err := form.entry("Proxy", "System default, e.g. socks5://127.0.0.1:9050",
+		"http://, https:// or socks5:// proxy for page fetches, LLM requests and the web views. Empty uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY.", prefs.Proxy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	agentCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create user agent presets: %w", err)
+	}
+	agentCombo.Append("", "Chimera (default)")
+	for _, preset := range scraper.UserAgentPresets {
+		agentCombo.Append(preset.Value, preset.Name)
+	}
+	agentCombo.Append(customAgentID, "Custom")
+	if err := form.add("User-Agent", agentCombo); err != nil {
+		return nil, nil, err
+	}
+
+	agentEntry, err := gtk.EntryNew()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create user agent entry: %w", err)
+	}
+	agentEntry.SetPlaceholderText("ChimeraScraper/0.1")
+	agentEntry.SetText(prefs.UserAgent)
+	form.indent(agentEntry)
+
+	if !agentCombo.SetActiveID(prefs.UserAgent) {
+		agentCombo.SetActiveID(customAgentID)
+	}
+	agentCombo.Connect("changed", func() {
+		if id := agentCombo.GetActiveID(); id != customAgentID {
+			agentEntry.SetText(id)
+		}
+	})
+	agentEntry.Connect("changed", func() {
+		text, _ := agentEntry.GetText()
+		if agentCombo.GetActiveID() != strings.TrimSpace(text) {
+			agentCombo.SetActiveID(customAgentID)
+		}
+	})
+
+	languageEntry, err := form.entry("Accept-Language", "From your locale, e.g. en-GB,en;q=0.8", "", prefs.AcceptLanguage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scriptsCheck, err := form.check("Run scripts for pages that need JavaScript",
+		"When a page is an empty script shell, loads it in a hidden WebKit view and reads the rendered content", !prefs.NoScriptRendering)
+	if err != nil {
+		return nil, nil, err
+	}
+	consentCheck, err := form.check("Get past cookie consent walls",
+		"When a known consent manager hides the page, fetches it again with cookies that record consent", prefs.SkipConsentWalls)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redirectsSpin, err := gtk.SpinButtonNewWithRange(0, 30, 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create redirects spin: %w", err)
+	}
+	switch {
+	case prefs.MaxRedirects < 0:
+		redirectsSpin.SetValue(0)
+	case prefs.MaxRedirects == 0:
+		redirectsSpin.SetValue(defaultMaxRedirects)
+	default:
+		redirectsSpin.SetValue(float64(prefs.MaxRedirects))
+	}
+	redirectsSpin.SetTooltipText("0 refuses all redirects; meta refresh and script redirects count too")
+	if err := form.add("Redirects to follow", redirectsSpin); err != nil {
+		return nil, nil, err
+	}
+
+	crossSiteCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create cross-site redirects combo: %w", err)
+	}
+	crossSiteCombo.Append("", "Follow")
+	crossSiteCombo.Append(persist.RedirectsAsk, "Ask, showing the redirect chain")
+	crossSiteCombo.Append(persist.RedirectsBlock, "Block")
+	crossSiteCombo.SetActiveID(prefs.CrossSiteRedirects)
+	crossSiteCombo.SetTooltipText("What to do when a link, such as a URL shortener, redirects to a different site")
+	if err := form.add("Redirects to other sites", crossSiteCombo); err != nil {
+		return nil, nil, err
+	}
+
+	apply := func(draft *settingsDraft) error {
+		proxyURL, err := entryText(proxyEntry, "proxy")
+		if err != nil {
+			return err
+		}
+		if _, err := proxy.Parse(proxyURL); err != nil {
+			return err
+		}
+		agent, err := entryText(agentEntry, "user agent")
+		if err != nil {
+			return err
+		}
+		acceptLanguage, err := entryText(languageEntry, "accept language")
+		if err != nil {
+			return err
+		}
+
+		draft.prefs.Proxy = proxyURL
+		draft.prefs.UserAgent = agent
+		draft.prefs.AcceptLanguage = acceptLanguage
+		draft.prefs.NoScriptRendering = !scriptsCheck.GetActive()
+		draft.prefs.SkipConsentWalls = consentCheck.GetActive()
+		switch redirects := redirectsSpin.GetValueAsInt(); redirects {
+		case 0:
+			draft.prefs.MaxRedirects = -1
+		case defaultMaxRedirects:
+			draft.prefs.MaxRedirects = 0
+		default:
+			draft.prefs.MaxRedirects = redirects
+		}
+		draft.prefs.CrossSiteRedirects = crossSiteCombo.GetActiveID()
+		return nil
+	}
+	return &settingsSection{title: "Network", form: form, apply: apply}, proxyEntry, nil
+}
@@ -0,0 +1,34 @@
+package browser
+
+// privacySection builds the settings for what pages and the LLM learn
+// about the reader.
+func privacySection(prefs appPreferences) (*settingsSection, error) {
+	form, err := newSettingsForm()
+	if err != nil {
+		return nil, err
+	}
+
+	cleanCheck, err := form.check("Strip tracking parameters from clicked links",
+		"Removes utm_*, fbclid, gclid and similar parameters and unwraps Facebook, Google and other redirect links before scraping", prefs.CleanLinks)
+	if err != nil {
+		return nil, err
+	}
+	blockExternalCheck, err := form.check("Block remote images, fonts and stylesheets",
+		"Stops pages in reader mode and compositions loading anything from the web, so a page cannot report back that it was read. Scripts written by the model never run.", prefs.BlockExternal)
+	if err != nil {
+		return nil, err
+	}
+	digestCheck, err := form.check("Personalised start page digest from recent history",
+		"Sends only the titles and domains of recently visited pages, after you review them on the start page", prefs.PersonalDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	apply := func(draft *settingsDraft) error {
+		draft.prefs.CleanLinks = cleanCheck.GetActive()
+		draft.prefs.BlockExternal = blockExternalCheck.GetActive()
+		draft.prefs.PersonalDigest = digestCheck.GetActive()
+		return nil
+	}
+	return &settingsSection{title: "Privacy", form: form, apply: apply}, nil
+}
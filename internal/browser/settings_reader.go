@@ -0,0 +1,297 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"chimera/internal/render"
+	"chimera/internal/scraper"
+	persist "chimera/internal/settings"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// readerSection builds the settings for what is extracted from pages and
+// how reader mode shows them, and which page opens at startup.
+func (a *App) readerSection(prefs appPreferences) (*settingsSection, error) {
+	form, err := newSettingsForm()
+	if err != nil {
+		return nil, err
+	}
+
+	renderingCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create rendering combo: %w", err)
+	}
+	renderingCombo.Append(render.RenderingAuto, "Automatic (lite on low-memory systems)")
+	renderingCombo.Append(render.RenderingFull, "Full styling")
+	renderingCombo.Append(render.RenderingLite, "Lite")
+	renderingCombo.SetActiveID(prefs.Rendering)
+	if err := form.add("Rendering", renderingCombo); err != nil {
+		return nil, err
+	}
+
+	limits := a.cfg.Scraper.Limits()
+	itemsBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, fmt.Errorf("create items box: %w", err)
+	}
+	limitSpin := func(name string, value int) (*gtk.SpinButton, error) {
+		label, err := gtk.LabelNew(name)
+		if err != nil {
+			return nil, fmt.Errorf("create %s label: %w", strings.ToLower(name), err)
+		}
+		spin, err := gtk.SpinButtonNewWithRange(1, 200, 1)
+		if err != nil {
+			return nil, fmt.Errorf("create %s spin: %w", strings.ToLower(name), err)
+		}
+		spin.SetValue(float64(value))
+		itemsBox.PackStart(label, false, false, 0)
+		itemsBox.PackStart(spin, false, false, 0)
+		return spin, nil
+	}
+	headingsSpin, err := limitSpin("Headings", limits.Headings)
+	if err != nil {
+		return nil, err
+	}
+	paragraphsSpin, err := limitSpin("Paragraphs", limits.Paragraphs)
+	if err != nil {
+		return nil, err
+	}
+	codeSpin, err := limitSpin("Code", limits.CodeBlocks)
+	if err != nil {
+		return nil, err
+	}
+	linksSpin, err := limitSpin("Links", limits.Links)
+	if err != nil {
+		return nil, err
+	}
+	itemsBox.SetTooltipText("How many headings, paragraphs, code blocks and links are extracted from each page")
+	if err := form.add("Items per page", itemsBox); err != nil {
+		return nil, err
+	}
+
+	depthCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create depth combo: %w", err)
+	}
+	for level := 1; level <= 6; level++ {
+		name := "H1 only"
+		if level > 1 {
+			name = fmt.Sprintf("H1–H%d", level)
+		}
+		depthCombo.Append(strconv.Itoa(level), name)
+	}
+	depthCombo.SetActiveID(strconv.Itoa(limits.HeadingDepth))
+	if err := form.add("Heading depth", depthCombo); err != nil {
+		return nil, err
+	}
+
+	shortSpin, err := gtk.SpinButtonNewWithRange(1, 1000, 10)
+	if err != nil {
+		return nil, fmt.Errorf("create paragraph length spin: %w", err)
+	}
+	shortSpin.SetValue(float64(limits.MinParagraph))
+	shortSpin.SetTooltipText("Paragraphs with fewer characters are skipped as fragments")
+	if err := form.add("Shortest paragraph", shortSpin); err != nil {
+		return nil, err
+	}
+
+	boilerplateCheck, err := form.check("Remove menus, footers, cookie banners and ads before extraction",
+		"Keeps navigation, sidebar and consent banner text out of the reader view and LLM prompts", !prefs.KeepBoilerplate)
+	if err != nil {
+		return nil, err
+	}
+	joinCheck, err := form.check("Join multi-page articles",
+		"Follows rel=\"next\" and \"Page 1 of N\" pagers and reads up to nine more pages into one view", prefs.JoinPages)
+	if err != nil {
+		return nil, err
+	}
+
+	readerFontEntry, err := form.entry("Reader font", "Inter, Segoe UI, sans-serif",
+		"Font families for reader mode, most preferred first. Also suggested to the LLM.", prefs.Typography.FontFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	readerTextRow, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, fmt.Errorf("create reader text row: %w", err)
+	}
+	readerSizeSpin, err := gtk.SpinButtonNewWithRange(10, 40, 1)
+	if err != nil {
+		return nil, fmt.Errorf("create reader font size spin: %w", err)
+	}
+	readerSize := prefs.Typography.FontSize
+	if readerSize <= 0 {
+		readerSize = defaultReaderFontSize
+	}
+	readerSizeSpin.SetValue(float64(readerSize))
+	readerSizeSpin.SetTooltipText("Base font size in pixels; headings grow with it")
+	readerLineSpin, err := gtk.SpinButtonNewWithRange(1, 2.5, 0.05)
+	if err != nil {
+		return nil, fmt.Errorf("create reader line height spin: %w", err)
+	}
+	readerLineSpin.SetDigits(2)
+	readerLine := prefs.Typography.LineHeight
+	if readerLine <= 0 {
+		readerLine = defaultReaderLineHeight
+	}
+	readerLineSpin.SetValue(readerLine)
+	readerLineSpin.SetTooltipText("Line height as a multiple of the font size")
+	readerWidthSpin, err := gtk.SpinButtonNewWithRange(400, 2000, 20)
+	if err != nil {
+		return nil, fmt.Errorf("create reader width spin: %w", err)
+	}
+	readerWidth := prefs.Typography.Width
+	if readerWidth <= 0 {
+		readerWidth = defaultReaderWidth
+	}
+	readerWidthSpin.SetValue(float64(readerWidth))
+	readerWidthSpin.SetTooltipText("Widest the text column grows, in pixels")
+	readerJustifyCheck, err := gtk.CheckButtonNewWithLabel("Justify")
+	if err != nil {
+		return nil, fmt.Errorf("create justify checkbox: %w", err)
+	}
+	readerJustifyCheck.SetActive(prefs.Typography.Justify)
+	readerJustifyCheck.SetTooltipText("Aligns paragraphs to both edges and hyphenates words")
+	readerTextRow.PackStart(readerSizeSpin, false, false, 0)
+	readerTextRow.PackStart(readerLineSpin, false, false, 0)
+	readerTextRow.PackStart(readerWidthSpin, false, false, 0)
+	readerTextRow.PackStart(readerJustifyCheck, false, false, 0)
+	if err := form.add("Reader text", readerTextRow); err != nil {
+		return nil, err
+	}
+
+	sourceBaseCheck, err := form.check("Resolve relative links and images against the page's address",
+		"Renders pages with the scraped URL as their base, so images and links written as relative paths work", prefs.SourceBase)
+	if err != nil {
+		return nil, err
+	}
+
+	lowVisionCheck, err := form.check("Low-vision mode",
+		"Enforces a minimum font size and text contrast on every page, including LLM compositions, and underlines links", prefs.LowVision)
+	if err != nil {
+		return nil, err
+	}
+
+	fontSpin, err := gtk.SpinButtonNewWithRange(10, 48, 1)
+	if err != nil {
+		return nil, fmt.Errorf("create font size spin: %w", err)
+	}
+	minFont := prefs.MinFontSize
+	if minFont <= 0 {
+		minFont = defaultMinFontSize
+	}
+	fontSpin.SetValue(float64(minFont))
+	fontSpin.SetTooltipText("Text is never rendered smaller than this many pixels")
+	if err := form.add("Minimum font size", fontSpin); err != nil {
+		return nil, err
+	}
+
+	contrastCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create contrast combo: %w", err)
+	}
+	contrastCombo.Append("4.5", "4.5:1 (WCAG AA)")
+	contrastCombo.Append("7", "7:1 (WCAG AAA)")
+	contrastCombo.Append("10", "10:1")
+	minContrast := prefs.MinContrast
+	if minContrast <= 0 {
+		minContrast = defaultMinContrast
+	}
+	if !contrastCombo.SetActiveID(strconv.FormatFloat(minContrast, 'f', -1, 64)) {
+		contrastCombo.SetActiveID("7")
+	}
+	contrastCombo.SetTooltipText("Text below this contrast ratio against its background is redrawn in black or white")
+	if err := form.add("Minimum contrast", contrastCombo); err != nil {
+		return nil, err
+	}
+
+	updateLowVision := func() {
+		on := lowVisionCheck.GetActive()
+		fontSpin.SetSensitive(on)
+		contrastCombo.SetSensitive(on)
+	}
+	updateLowVision()
+	lowVisionCheck.Connect("toggled", updateLowVision)
+
+	startupCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create startup combo: %w", err)
+	}
+	startupCombo.Append("", "Open the start page")
+	startupCombo.Append(persist.StartupBlank, "Open a blank page")
+	startupCombo.Append(persist.StartupLastSession, "Reopen the last session")
+	startupCombo.Append(persist.StartupPage, "Open a specific page")
+	startupCombo.SetActiveID(prefs.Startup)
+	if err := form.add("At startup", startupCombo); err != nil {
+		return nil, err
+	}
+
+	startupEntry, err := gtk.EntryNew()
+	if err != nil {
+		return nil, fmt.Errorf("create startup URL entry: %w", err)
+	}
+	startupEntry.SetPlaceholderText("https://example.com/")
+	startupEntry.SetText(prefs.StartupURL)
+	form.indent(startupEntry)
+
+	startupCompose, err := gtk.CheckButtonNewWithLabel("Compose the startup page with the LLM")
+	if err != nil {
+		return nil, fmt.Errorf("create startup compose checkbox: %w", err)
+	}
+	startupCompose.SetActive(prefs.StartupCompose)
+	form.indent(startupCompose)
+
+	updateStartup := func() {
+		mode := startupCombo.GetActiveID()
+		startupEntry.SetSensitive(mode == persist.StartupPage)
+		startupCompose.SetSensitive(mode == persist.StartupPage || mode == persist.StartupLastSession)
+	}
+	updateStartup()
+	startupCombo.Connect("changed", updateStartup)
+
+	apply := func(draft *settingsDraft) error {
+		startupURL, err := entryText(startupEntry, "startup URL")
+		if err != nil {
+			return err
+		}
+		startup := startupCombo.GetActiveID()
+		if startup == persist.StartupPage {
+			parsed, err := url.Parse(startupURL)
+			if err != nil || !(isInternalURL(startupURL) || parsed.Scheme == "http" || parsed.Scheme == "https") {
+				return fmt.Errorf("startup page %q is not an http, https or chimera:// URL", startupURL)
+			}
+		}
+		readerFont, err := readerFontEntry.GetText()
+		if err != nil {
+			return fmt.Errorf("read reader font: %w", err)
+		}
+
+		draft.prefs.Rendering = renderingCombo.GetActiveID()
+		depth, _ := strconv.Atoi(depthCombo.GetActiveID())
+		draft.prefs.Limits = scraper.Limits{
+			Headings:     headingsSpin.GetValueAsInt(),
+			Paragraphs:   paragraphsSpin.GetValueAsInt(),
+			CodeBlocks:   codeSpin.GetValueAsInt(),
+			Links:        linksSpin.GetValueAsInt(),
+			HeadingDepth: depth,
+			MinParagraph: shortSpin.GetValueAsInt(),
+		}
+		draft.prefs.KeepBoilerplate = !boilerplateCheck.GetActive()
+		draft.prefs.JoinPages = joinCheck.GetActive()
+		draft.prefs.Typography = readerTypography(readerFont, readerSizeSpin.GetValueAsInt(), readerLineSpin.GetValue(), readerWidthSpin.GetValueAsInt(), readerJustifyCheck.GetActive())
+		draft.prefs.SourceBase = sourceBaseCheck.GetActive()
+		draft.prefs.LowVision = lowVisionCheck.GetActive()
+		draft.prefs.MinFontSize = fontSpin.GetValueAsInt()
+		draft.prefs.MinContrast, _ = strconv.ParseFloat(contrastCombo.GetActiveID(), 64)
+		draft.prefs.Startup = startup
+		draft.prefs.StartupURL = startupURL
+		draft.prefs.StartupCompose = startupCompose.GetActive()
+		return nil
+	}
+	return &settingsSection{title: "Reader", form: form, apply: apply}, nil
+}
@@ -0,0 +1,79 @@
+package browser
+
+import (
+	"fmt"
+
+	"chimera/internal/export"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// storageSection builds the settings for where visited and clipped pages
+// and their citations are written.
+func storageSection(prefs appPreferences) (*settingsSection, error) {
+	form, err := newSettingsForm()
+	if err != nil {
+		return nil, err
+	}
+
+	hookEntry, err := form.entry("After each visit", "e.g. jq -c . >> ~/notes/reading.jsonl",
+		"Shell command that receives each visited page as JSON on standard input, with CHIMERA_URL and CHIMERA_TITLE set", prefs.VisitCommand)
+	if err != nil {
+		return nil, err
+	}
+	vaultEntry, err := form.entry("Vault folder", "e.g. ~/Obsidian/Clippings",
+		"Clip to vault writes the page here as a Markdown note with YAML front matter", prefs.VaultDir)
+	if err != nil {
+		return nil, err
+	}
+	tagsEntry, err := form.entry("Clip tags", "e.g. clippings, to-read", "", prefs.VaultTags)
+	if err != nil {
+		return nil, err
+	}
+
+	citationCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create citation format combo: %w", err)
+	}
+	citationCombo.Append(export.CitationBibTeX, "BibTeX (biblatex)")
+	citationCombo.Append(export.CitationCSL, "CSL-JSON (Zotero, Pandoc)")
+	if !citationCombo.SetActiveID(prefs.CitationFormat) {
+		citationCombo.SetActiveID(export.CitationBibTeX)
+	}
+	if err := form.add("Citation format", citationCombo); err != nil {
+		return nil, err
+	}
+
+	bibEntry, err := form.entry("Bibliography file", "e.g. ~/papers/web.bib",
+		"Copy citation also appends each citation here, once per page", prefs.CitationFile)
+	if err != nil {
+		return nil, err
+	}
+
+	apply := func(draft *settingsDraft) error {
+		visitCommand, err := entryText(hookEntry, "post-visit command")
+		if err != nil {
+			return err
+		}
+		vaultDir, err := entryText(vaultEntry, "vault folder")
+		if err != nil {
+			return err
+		}
+		vaultTags, err := entryText(tagsEntry, "clip tags")
+		if err != nil {
+			return err
+		}
+		citationFile, err := entryText(bibEntry, "bibliography file")
+		if err != nil {
+			return err
+		}
+
+		draft.prefs.VisitCommand = visitCommand
+		draft.prefs.VaultDir = vaultDir
+		draft.prefs.VaultTags = vaultTags
+		draft.prefs.CitationFormat = citationCombo.GetActiveID()
+		draft.prefs.CitationFile = citationFile
+		return nil
+	}
+	return &settingsSection{title: "Storage", form: form, apply: apply}, nil
+}
@@ -0,0 +1,25 @@
+package browser
+
+import (
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// shortcut binds an application action to its keyboard accelerators.
+type shortcut struct {
+	name   string
+	accels []string
+	run    func()
+}
+
+func installShortcuts(app *gtk.Application, shortcuts []shortcut) {
+	for _, sc := range shortcuts {
+		run := sc.run
+		action := glib.SimpleActionNew(sc.name, nil)
+		action.Connect("activate", func() {
+			run()
+		})
+		app.AddAction(action)
+		app.SetAccelsForAction("app."+sc.name, sc.accels)
+	}
+}
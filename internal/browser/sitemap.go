@@ -0,0 +1,100 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"sort"
+	"strings"
+
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// sitemapURI lists the pages in a site's sitemap; the site goes in ?site=.
+const sitemapURI = "chimera://sitemap"
+
+// openSitemap shows the sitemap of the current page's site.
+func (a *App) openSitemap(info *gtk.Label, onNavigate func(string) bool) {
+	page := a.currentPage()
+	parsed, err := url.Parse(page.SourceURL)
+	if page.SourceURL == "" || err != nil || parsed.Host == "" {
+		a.setStatus(info, "Open a page to see its site map")
+		return
+	}
+	onNavigate(sitemapURI + "?site=" + url.QueryEscape(parsed.Scheme+"://"+parsed.Host))
+}
+
+func (a *App) sitemapPage(site string) internalPage {
+	return func(ctx context.Context) (string, error) {
+		if strings.TrimSpace(site) == "" {
+			return "", fmt.Errorf("no site given; use %s?site=example.com", sitemapURI)
+		}
+		pages, err := a.cfg.Scraper.Sitemap(ctx, site)
+		if err != nil {
+			return "", err
+		}
+
+		// Newest first; pages without a date keep the sitemap's order at the end.
+		sort.SliceStable(pages, func(i, j int) bool {
+			return pages[i].LastMod.After(pages[j].LastMod)
+		})
+		dated := 0
+		for _, p := range pages {
+			if !p.LastMod.IsZero() {
+				dated++
+			}
+		}
+
+		var builder strings.Builder
+		err = sitemapTmpl.Execute(&builder, struct {
+			Site  string
+			Pages []scraper.SitemapURL
+			Dated int
+		}{site, pages, dated})
+		return builder.String(), err
+	}
+}
+
+var sitemapTmpl = template.Must(template.New("sitemap").Funcs(template.FuncMap{
+	"path": func(loc string) string {
+		if parsed, err := url.Parse(loc); err == nil && parsed.Path != "" {
+			return parsed.RequestURI()
+		}
+		return loc
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Site map of {{ .Site }} — Chimera</title>
+<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+h1 { margin: 0 0 .5rem 0; }
+table { width: 100%; border-collapse: collapse; background: #fff; border-radius: 12px; overflow: hidden; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+th, td { text-align: left; padding: .6rem 1rem; border-bottom: 1px solid #e5e8ef; }
+td.date { white-space: nowrap; color: #5b6576; }
+a { color: #2b5dcc; text-decoration: none; }
+small { color: #5b6576; }
+code { word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>Site map of {{ .Site }}</h1>
+<p><small>{{ len .Pages }} pages • {{ .Dated }} with a last-modified date • newest first</small></p>
+{{ if .Pages }}
+<table>
+<thead><tr><th>Page</th><th>Last modified</th></tr></thead>
+<tbody>
+{{ range .Pages }}<tr>
+<td><a href="{{ .Loc }}"><code>{{ path .Loc }}</code></a></td>
+<td class="date">{{ if not .LastMod.IsZero }}{{ .LastMod.Format "2006-01-02" }}{{ else }}—{{ end }}</td>
+</tr>
+{{ end }}
+</tbody>
+</table>
+{{ else }}<p>The sitemap lists no pages.</p>{{ end }}
+</body>
+</html>`))
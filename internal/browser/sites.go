@@ -0,0 +1,347 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"chimera/internal/keyring"
+	"chimera/internal/llm"
+	"chimera/internal/scraper"
+	persist "chimera/internal/settings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+const (
+	minZoom  = 0.3
+	maxZoom  = 3.0
+	zoomStep = 0.1
+
+	// customAgentID selects a hand-written User-Agent in the settings dialog.
+	customAgentID = "custom"
+)
+
+// site returns the remembered preferences for target's domain.
+func (a *App) site(target string) persist.Site {
+	key := persist.SiteKey(target)
+	if key == "" {
+		return persist.Site{}
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.prefs.Sites[key]
+}
+
+// siteMode reports the mode remembered for target's domain, if any.
+func (a *App) siteMode(target string) (useLLM, ok bool) {
+	switch a.site(target).Mode {
+	case persist.SiteModeReader:
+		return false, true
+	case persist.SiteModeLLM:
+		return a.llmAvailable(), true
+	default:
+		return false, false
+	}
+}
+
+// defaultMode is the mode for a URL typed into the entry.
+func (a *App) defaultMode(target string) bool {
+	if use, ok := a.siteMode(target); ok {
+		return use
+	}
+	if use, ok := a.workspaceMode(); ok {
+		return use
+	}
+	return a.prefersLLM()
+}
+
+func (a *App) siteTask(target string) llm.Task {
+	return llm.ParseTask(a.site(target).Template)
+}
+
+func (a *App) siteZoom(target string) float64 {
+	if zoom := a.site(target).Zoom; zoom > 0 {
+		return zoom
+	}
+	return 1
+}
+
+func (a *App) applyZoom(view *viewHost, target string) {
+	zoom := a.siteZoom(target)
+	glib.IdleAdd(func() bool {
+		view.current().SetZoom(zoom)
+		return false
+	})
+}
+
+// requestOptions maps the header preferences onto the scraper's request options.
+func requestOptions(prefs appPreferences) scraper.RequestOptions {
+	opts := scraper.RequestOptions{
+		UserAgent:        prefs.UserAgent,
+		AcceptLanguage:   prefs.AcceptLanguage,
+		SkipConsentWalls: prefs.SkipConsentWalls,
+	}
+	for key, site := range prefs.Sites {
+		if site.UserAgent == "" && len(site.Headers) == 0 {
+			continue
+		}
+		if opts.Sites == nil {
+			opts.Sites = make(map[string]scraper.SiteRequest)
+		}
+		opts.Sites[key] = scraper.SiteRequest{UserAgent: site.UserAgent, Headers: site.Headers}
+	}
+	return opts
+}
+
+// parseHeaders reads "Name: value" lines, ignoring blank lines.
+func parseHeaders(text string) (map[string]string, error) {
+	var headers map[string]string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header line %q (expected Name: value)", line)
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+func formatHeaders(headers map[string]string) string {
+	lines := make([]string, 0, len(headers))
+	for name, value := range headers {
+		lines = append(lines, name+": "+value)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// updateSite stores site for key, dropping the entry when it overrides nothing.
+func (a *App) updateSite(key string, site persist.Site) error {
+	settings, prefer := a.settingsSnapshot()
+	prefs := a.preferences()
+
+	sites := make(map[string]persist.Site, len(prefs.Sites)+1)
+	maps.Copy(sites, prefs.Sites)
+	if site.IsZero() {
+		delete(sites, key)
+	} else {
+		sites[key] = site
+	}
+	prefs.Sites = sites
+
+	// A missing keyring was already reported when the settings were saved.
+	if err := a.applySettings(settings, prefer, prefs); err != nil && !errors.Is(err, keyring.ErrUnavailable) {
+		return err
+	}
+	return nil
+}
+
+// zoomBy changes the zoom of the displayed page by delta, or resets it when
+// delta is zero, and remembers the result for the page's domain.
+func (a *App) zoomBy(view *viewHost, info *gtk.Label, delta float64) {
+	target := a.lastSourceURL()
+	key := persist.SiteKey(target)
+
+	zoom := 1.0
+	if delta != 0 {
+		zoom = view.current().Zoom() + delta
+		zoom = math.Round(math.Min(maxZoom, math.Max(minZoom, zoom))*10) / 10
+	}
+	view.current().SetZoom(zoom)
+	a.setStatus(info, fmt.Sprintf("Zoom %d%%", int(math.Round(zoom*100))))
+
+	if key == "" {
+		return
+	}
+	site := a.site(target)
+	site.Zoom = zoom
+	if zoom == 1 {
+		site.Zoom = 0
+	}
+	if err := a.updateSite(key, site); err != nil {
+		a.setStatus(info, fmt.Sprintf("Saving zoom failed: %v", err))
+	}
+}
+
+func (a *App) openSiteDialog(parent *gtk.ApplicationWindow, view *viewHost, info *gtk.Label) error {
+	target := a.lastSourceURL()
+	key := persist.SiteKey(target)
+	if key == "" {
+		a.setStatus(info, "Open a page first")
+		return nil
+	}
+	site := a.site(target)
+
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return fmt.Errorf("create dialog: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle(fmt.Sprintf("Preferences for %s", key))
+	dialog.SetModal(true)
+	dialog.SetTransientFor(parent)
+	dialog.AddButton("Forget site", gtk.RESPONSE_REJECT)
+	dialog.AddButton("Cancel", gtk.RESPONSE_CANCEL)
+	dialog.AddButton("Save", gtk.RESPONSE_OK)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		return fmt.Errorf("access content area: %w", err)
+	}
+
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return fmt.Errorf("create grid: %w", err)
+	}
+	grid.SetRowSpacing(10)
+	grid.SetColumnSpacing(14)
+	grid.SetMarginTop(14)
+	grid.SetMarginBottom(14)
+	grid.SetMarginStart(18)
+	grid.SetMarginEnd(18)
+
+	modeLabel, err := gtk.LabelNew("Open with")
+	if err != nil {
+		return fmt.Errorf("create mode label: %w", err)
+	}
+	modeLabel.SetXAlign(0)
+	grid.Attach(modeLabel, 0, 0, 1, 1)
+
+	modeCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return fmt.Errorf("create mode combo: %w", err)
+	}
+	modeCombo.Append("", "Default")
+	modeCombo.Append(persist.SiteModeReader, "Reader mode")
+	modeCombo.Append(persist.SiteModeLLM, "LLM compose")
+	modeCombo.SetActiveID(site.Mode)
+	grid.Attach(modeCombo, 1, 0, 1, 1)
+
+	templateLabel, err := gtk.LabelNew("Prompt")
+	if err != nil {
+		return fmt.Errorf("create template label: %w", err)
+	}
+	templateLabel.SetXAlign(0)
+	grid.Attach(templateLabel, 0, 1, 1, 1)
+
+	templateCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return fmt.Errorf("create template combo: %w", err)
+	}
+	for _, task := range llm.Tasks {
+		if task == llm.TaskCompose {
+			templateCombo.Append("", taskLabel(task)+" (default)")
+			continue
+		}
+		templateCombo.Append(task.String(), taskLabel(task))
+	}
+	templateCombo.SetActiveID(site.Template)
+	grid.Attach(templateCombo, 1, 1, 1, 1)
+
+	zoomLabel, err := gtk.LabelNew("Zoom (%)")
+	if err != nil {
+		return fmt.Errorf("create zoom label: %w", err)
+	}
+	zoomLabel.SetXAlign(0)
+	grid.Attach(zoomLabel, 0, 2, 1, 1)
+
+	zoomSpin, err := gtk.SpinButtonNewWithRange(minZoom*100, maxZoom*100, zoomStep*100)
+	if err != nil {
+		return fmt.Errorf("create zoom spin: %w", err)
+	}
+	zoomSpin.SetValue(a.siteZoom(target) * 100)
+	grid.Attach(zoomSpin, 1, 2, 1, 1)
+
+	agentLabel, err := gtk.LabelNew("User-Agent")
+	if err != nil {
+		return fmt.Errorf("create user agent label: %w", err)
+	}
+	agentLabel.SetXAlign(0)
+	grid.Attach(agentLabel, 0, 3, 1, 1)
+
+	agentEntry, err := gtk.EntryNew()
+	if err != nil {
+		return fmt.Errorf("create user agent entry: %w", err)
+	}
+	agentEntry.SetPlaceholderText("Global setting")
+	agentEntry.SetText(site.UserAgent)
+	grid.Attach(agentEntry, 1, 3, 1, 1)
+
+	headersLabel, err := gtk.LabelNew("Extra headers")
+	if err != nil {
+		return fmt.Errorf("create headers label: %w", err)
+	}
+	headersLabel.SetXAlign(0)
+	headersLabel.SetYAlign(0)
+	grid.Attach(headersLabel, 0, 4, 1, 1)
+
+	headersView, err := gtk.TextViewNew()
+	if err != nil {
+		return fmt.Errorf("create headers view: %w", err)
+	}
+	headersView.SetMonospace(true)
+	headersView.SetSizeRequest(360, 70)
+	headersView.SetTooltipText("One Name: value per line, sent with every request to this site")
+	headersBuffer, err := headersView.GetBuffer()
+	if err != nil {
+		return fmt.Errorf("access headers buffer: %w", err)
+	}
+	headersBuffer.SetText(formatHeaders(site.Headers))
+	grid.Attach(headersView, 1, 4, 1, 1)
+
+	content.Add(grid)
+	dialog.ShowAll()
+
+	switch dialog.Run() {
+	case gtk.RESPONSE_OK:
+		agent, err := agentEntry.GetText()
+		if err != nil {
+			return fmt.Errorf("read user agent: %w", err)
+		}
+		start, end := headersBuffer.GetBounds()
+		headerText, err := headersBuffer.GetText(start, end, false)
+		if err != nil {
+			return fmt.Errorf("read headers: %w", err)
+		}
+		headers, err := parseHeaders(headerText)
+		if err != nil {
+			return err
+		}
+		site = persist.Site{
+			Mode:      modeCombo.GetActiveID(),
+			Template:  templateCombo.GetActiveID(),
+			Zoom:      zoomSpin.GetValue() / 100,
+			UserAgent: strings.TrimSpace(agent),
+			Headers:   headers,
+		}
+		if site.Zoom == 1 {
+			site.Zoom = 0
+		}
+	case gtk.RESPONSE_REJECT:
+		site = persist.Site{}
+	default:
+		return nil
+	}
+
+	if err := a.updateSite(key, site); err != nil {
+		return fmt.Errorf("save site preferences: %w", err)
+	}
+	view.current().SetZoom(a.siteZoom(target))
+	a.setStatus(info, fmt.Sprintf("Saved preferences for %s", key))
+	return nil
+}
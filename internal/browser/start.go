@@ -0,0 +1,186 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"strings"
+
+	"chimera/internal/history"
+	"chimera/internal/llm"
+	"chimera/internal/scraper"
+	persist "chimera/internal/settings"
+)
+
+const (
+	startURL = internalScheme + "start"
+	// startRecent is how many visits the start page lists.
+	startRecent = 15
+	// digestRecent is how many visits feed the personalised digest.
+	digestRecent = 40
+)
+
+// startupTarget returns the page to open at launch, "" for a blank window,
+// and whether to compose it with the LLM.
+func (a *App) startupTarget() (string, bool) {
+	prefs := a.preferences()
+	compose := prefs.StartupCompose && a.llmAvailable()
+	switch prefs.Startup {
+	case persist.StartupBlank:
+		return "", false
+	case persist.StartupLastSession:
+		recent, err := a.cfg.History.Recent(1)
+		if err != nil {
+			slog.Warn("load last session", "err", err)
+		}
+		if len(recent) > 0 {
+			return recent[0].URL, compose
+		}
+	case persist.StartupPage:
+		if target := strings.TrimSpace(prefs.StartupURL); target != "" {
+			return target, compose && !isInternalURL(target)
+		}
+	}
+	return startURL, false
+}
+
+// recordVisit adds result to the history under its page key. When the
+// entered URL is a variant of a canonical page visited before, it returns
+// a note saying so.
+func (a *App) recordVisit(result *scraper.Result) string {
+	key := pageKey(result)
+	var note string
+	if key != result.SourceURL {
+		if earlier, ok, err := a.cfg.History.Find(key); err != nil {
+			slog.Warn("look up history", "url", key, "err", err)
+		} else if ok {
+			slog.Info("visited canonical page before", "url", result.SourceURL, "canonical", key)
+			note = fmt.Sprintf("same page as %s, visited %s", key, earlier.VisitedAt.Format("02 Jan 15:04"))
+		}
+	}
+	if err := a.cfg.History.Add(key, result.Title); err != nil {
+		slog.Warn("record history", "url", key, "err", err)
+	}
+	a.indexVisit(key, result)
+	return note
+}
+
+// pageKey identifies result in the history and composition cache: its
+// canonical URL when that is on the same site, so tracking-parameter
+// variants share one entry, and the requested URL otherwise.
+func pageKey(result *scraper.Result) string {
+	if result.Canonical != "" && persist.SiteKey(result.Canonical) == persist.SiteKey(result.SourceURL) {
+		return result.Canonical
+	}
+	return result.SourceURL
+}
+
+// digestItems reduces recent history to titles and domains.
+func (a *App) digestItems() ([]llm.DigestItem, error) {
+	recent, err := a.cfg.History.Recent(digestRecent)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]llm.DigestItem, 0, len(recent))
+	for _, e := range recent {
+		domain := persist.SiteKey(e.URL)
+		if domain == "" {
+			continue
+		}
+		title := e.Title
+		if title == "" {
+			title = domain
+		}
+		items = append(items, llm.DigestItem{Title: title, Domain: domain})
+	}
+	return items, nil
+}
+
+func (a *App) startPage(ctx context.Context) (string, error) {
+	recent, err := a.cfg.History.Recent(startRecent)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Recent       []history.Entry
+		DigestOn     bool
+		LLMAvailable bool
+		Preview      string
+	}{
+		Recent:       recent,
+		DigestOn:     a.preferences().PersonalDigest,
+		LLMAvailable: a.llmAvailable(),
+	}
+	if data.DigestOn {
+		items, err := a.digestItems()
+		if err != nil {
+			return "", err
+		}
+		if len(items) > 0 {
+			data.Preview = llm.DigestPrompt(items, llm.PageOptions{Lite: a.liteRendering()})
+		}
+	}
+
+	var builder strings.Builder
+	err = startTmpl.Execute(&builder, data)
+	return builder.String(), err
+}
+
+func (a *App) digestPage(ctx context.Context) (string, error) {
+	if !a.preferences().PersonalDigest {
+		return "", errors.New("the personalised digest is turned off in LLM settings")
+	}
+	items, err := a.digestItems()
+	if err != nil {
+		return "", err
+	}
+	return a.currentLLM().GenerateDigest(ctx, items, llm.PageOptions{Lite: a.liteRendering()})
+}
+
+var startTmpl = template.Must(template.New("start").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Start — Chimera</title>
+<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+h1 { margin: 0 0 1rem 0; }
+h2 { margin-top: 2rem; }
+ul { list-style: none; padding: 0; background: #fff; border-radius: 12px; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+li { padding: .6rem 1rem; border-bottom: 1px solid #e5e8ef; }
+li:last-child { border-bottom: none; }
+a { color: #2b5dcc; text-decoration: none; }
+small { color: #5b6576; }
+pre { white-space: pre-wrap; background: #fff; border-radius: 12px; padding: 1rem; box-shadow: 0 1px 3px rgba(0,0,0,.08); font-size: .85rem; }
+.button { display: inline-block; padding: .5rem 1rem; border-radius: 8px; background: #2b5dcc; color: #fff; }
+input[type=search] { width: 100%; box-sizing: border-box; padding: .6rem .8rem; border: 1px solid #cfd5e1; border-radius: 8px; font-size: 1rem; }
+</style>
+</head>
+<body>
+<h1>Chimera</h1>
+<form action="chimera://search" method="get"><input type="search" name="q" placeholder="Search the pages you have visited" /></form>
+<h2>Recently visited</h2>
+{{ if .Recent }}
+<ul>
+{{ range .Recent }}<li><a href="{{ .URL }}">{{ if .Title }}{{ .Title }}{{ else }}{{ .URL }}{{ end }}</a> <small>{{ .VisitedAt.Format "Jan 2 15:04" }}</small></li>
+{{ end }}
+</ul>
+{{ else }}<p><small>Pages you open will be listed here.</small></p>{{ end }}
+
+<h2>Personalised digest</h2>
+{{ if not .DigestOn }}
+<p><small>Off. Turn on "Personalised start page digest" in LLM Settings to let the LLM group your recent reading into themes. Only page titles and domains are sent, and you can review them here first.</small></p>
+{{ else if not .Preview }}
+<p><small>Visit a few pages first; the digest is built from your recent history.</small></p>
+{{ else }}
+<p><small>This is exactly what will be sent to the LLM: titles and domains from your local history, never page addresses or content.</small></p>
+<pre>{{ .Preview }}</pre>
+{{ if .LLMAvailable }}<p><a class="button" href="chimera://start/digest">Generate digest</a></p>
+{{ else }}<p><small>Configure an LLM endpoint to generate the digest.</small></p>{{ end }}
+{{ end }}
+</body>
+</html>`))
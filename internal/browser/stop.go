@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// errGenerationStopped is why a generation stopped with the Stop button or
+// Escape was cancelled.
+var errGenerationStopped = errors.New("generation stopped")
+
+// newStopButton creates the Stop button shown in the toolbar while the LLM
+// writes a page.
+func newStopButton() (*gtk.Button, error) {
+	button, err := gtk.ButtonNewWithLabel("Stop")
+	if err != nil {
+		return nil, fmt.Errorf("create stop button: %w", err)
+	}
+	button.SetName("chimera-btn-secondary")
+	if ctx, err := button.GetStyleContext(); err == nil {
+		ctx.AddClass("destructive-action")
+	}
+	button.SetTooltipText("Stop the LLM and show the page in reader mode (Escape)")
+	button.SetNoShowAll(true)
+	return button, nil
+}
+
+// beginGeneration returns a context for an LLM generation of the page being
+// shown, which stopGeneration cancels, and shows the Stop button until the
+// returned end is called.
+func (a *App) beginGeneration(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	a.mu.Lock()
+	if a.genCancel != nil {
+		a.genCancel(context.Canceled)
+	}
+	a.genCancel = cancel
+	a.genID++
+	id := a.genID
+	a.mu.Unlock()
+	a.showStopButton(true)
+
+	end := func() {
+		a.mu.Lock()
+		current := a.genID == id
+		if current {
+			a.genCancel = nil
+		}
+		a.mu.Unlock()
+		cancel(context.Canceled)
+		if current {
+			a.showStopButton(false)
+		}
+	}
+	return ctx, end
+}
+
+// stopGeneration cancels the running LLM generation, if there is one, so
+// the page it was writing is shown in reader mode instead.
+func (a *App) stopGeneration() bool {
+	a.mu.Lock()
+	cancel := a.genCancel
+	a.genCancel = nil
+	a.mu.Unlock()
+
+	if cancel == nil {
+		return false
+	}
+	cancel(errGenerationStopped)
+	return true
+}
+
+// generationStopped reports whether ctx, from beginGeneration, was
+// cancelled by stopGeneration.
+func generationStopped(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errGenerationStopped)
+}
+
+func (a *App) showStopButton(visible bool) {
+	a.mu.RLock()
+	button := a.stopBtn
+	a.mu.RUnlock()
+	if button == nil {
+		return
+	}
+	glib.IdleAdd(func() bool {
+		button.SetVisible(visible)
+		return false
+	})
+}
@@ -0,0 +1,26 @@
+package browser
+
+import "strings"
+
+// darkModeStyle turns a light page dark when the desktop theme is dark,
+// which WebKit reports through prefers-color-scheme. The page is inverted
+// with its hues kept, and media are inverted back so photos look right.
+const darkModeStyle = `<style id="chimera-dark-mode">@media (prefers-color-scheme: dark) {
+html { background: #fff; filter: invert(1) hue-rotate(180deg); }
+img, picture, video, canvas, iframe, embed, object, svg image, [style*="background-image"] { filter: invert(1) hue-rotate(180deg); }
+}</style>`
+
+// withDarkMode makes an LLM composition follow the desktop's dark theme
+// without asking the model to restyle it. Pages that declare their own
+// color scheme are left alone, since inverting them would turn their dark
+// styles light.
+func withDarkMode(html string) string {
+	lower := strings.ToLower(html)
+	if strings.Contains(lower, "color-scheme") {
+		return html
+	}
+	if i := strings.Index(lower, "</head>"); i >= 0 {
+		return html[:i] + darkModeStyle + html[i:]
+	}
+	return darkModeStyle + html
+}
@@ -0,0 +1,100 @@
+package browser
+
+import (
+	"context"
+	"log/slog"
+
+	"chimera/internal/llm"
+	"chimera/internal/scraper"
+)
+
+// translatePage translates the text blocks of result into language. Blocks
+// found in the translation memory are reused; only the rest are sent to the
+// LLM, and their translations are remembered. It returns the translated copy
+// and how many blocks came from memory.
+func (a *App) translatePage(ctx context.Context, client *llm.Client, result *scraper.Result, language string) (*scraper.Result, int, error) {
+	translated := copyText(result)
+	fields := textFields(translated)
+
+	sources := make([]string, len(fields))
+	for i, field := range fields {
+		sources[i] = *field
+	}
+	remembered, ok, err := a.cfg.Translations.Lookup(language, sources)
+	if err != nil {
+		slog.Warn("read translation memory", "err", err)
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	reused := 0
+	for i, source := range sources {
+		switch {
+		case ok[i]:
+			reused++
+		case !seen[source]:
+			seen[source] = true
+			missing = append(missing, source)
+		}
+	}
+
+	fresh := make(map[string]string, len(missing))
+	if len(missing) > 0 {
+		out, err := client.TranslateBlocks(ctx, missing, language)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i, source := range missing {
+			fresh[source] = out[i]
+		}
+		if err := a.cfg.Translations.Add(language, missing, out); err != nil {
+			slog.Warn("store translations", "err", err)
+		}
+	}
+
+	for i, field := range fields {
+		if ok[i] {
+			*field = remembered[i]
+		} else {
+			*field = fresh[sources[i]]
+		}
+	}
+	return translated, reused, nil
+}
+
+// copyText returns a copy of result whose text slices can be changed
+// without touching result.
+func copyText(result *scraper.Result) *scraper.Result {
+	out := *result
+	out.Headings = append([]scraper.Heading(nil), result.Headings...)
+	out.Paragraphs = append([]string(nil), result.Paragraphs...)
+	out.Footnotes = append([]scraper.Footnote(nil), result.Footnotes...)
+	out.Links = append([]scraper.Link(nil), result.Links...)
+	return &out
+}
+
+// textFields returns the non-empty prose of result that translation
+// replaces. Code, formulas and URLs are left as they are.
+func textFields(result *scraper.Result) []*string {
+	var fields []*string
+	add := func(s *string) {
+		if *s != "" {
+			fields = append(fields, s)
+		}
+	}
+	add(&result.Title)
+	add(&result.Description)
+	for i := range result.Headings {
+		add(&result.Headings[i].Text)
+	}
+	for i := range result.Paragraphs {
+		add(&result.Paragraphs[i])
+	}
+	for i := range result.Footnotes {
+		add(&result.Footnotes[i].Text)
+	}
+	for i := range result.Links {
+		add(&result.Links[i].Text)
+	}
+	return fields
+}
@@ -0,0 +1,40 @@
+package browser
+
+import (
+	"math"
+	"strings"
+
+	"chimera/internal/render"
+)
+
+// The reader template's own text style, shown in settings until changed.
+// Typography fields equal to these are stored as zero, so the template's
+// defaults keep applying.
+const (
+	defaultReaderFontSize   = 16
+	defaultReaderLineHeight = 1.2
+	defaultReaderWidth      = 960
+)
+
+// readerOptions returns how reader mode is styled.
+func (a *App) readerOptions() render.Options {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return render.Options{Lite: a.lite, Typography: a.prefs.Typography}
+}
+
+// readerTypography builds the typography preference from the settings
+// dialog, dropping values that match the defaults.
+func readerTypography(font string, size int, lineHeight float64, width int, justify bool) render.Typography {
+	t := render.Typography{FontFamily: strings.TrimSpace(font), Justify: justify}
+	if size != defaultReaderFontSize {
+		t.FontSize = size
+	}
+	if lineHeight = math.Round(lineHeight*100) / 100; lineHeight != defaultReaderLineHeight {
+		t.LineHeight = lineHeight
+	}
+	if width != defaultReaderWidth {
+		t.Width = width
+	}
+	return t
+}
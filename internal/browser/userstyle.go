@@ -0,0 +1,72 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"chimera/internal/browser/webkit"
+	persist "chimera/internal/settings"
+
+	"github.com/gotk3/gotk3/glib"
+)
+
+// globalStyleFile is the user stylesheet applied to every page; a site's
+// own is named after its settings.SiteKey, such as "example.com.css".
+const globalStyleFile = "user.css"
+
+// userStyle returns the user's CSS for target: the global stylesheet
+// followed by the one for its site, so site rules win. The files are read
+// for every page, so edits apply from the next page on.
+func (a *App) userStyle(target string) string {
+	dir := a.cfg.StylesDir
+	if dir == "" {
+		return ""
+	}
+	names := []string{globalStyleFile}
+	if site := persist.SiteKey(target); site != "" && site+".css" != globalStyleFile {
+		names = append(names, site+".css")
+	}
+
+	var style string
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			slog.Warn("read user stylesheet", "file", name, "err", err)
+			continue
+		}
+		style += string(data) + "\n"
+	}
+	return style
+}
+
+// applyUserContent sets the stylesheet and script injected into pages view
+// loads from now on: the low-vision ones when that mode is on, and the
+// user's stylesheets for target's site. Must be called on the GTK main loop.
+func (a *App) applyUserContent(view *webkit.WebView, target string) {
+	style, script := a.userStyle(target), ""
+	if prefs := a.preferences(); prefs.LowVision {
+		contrast := prefs.MinContrast
+		if contrast <= 0 {
+			contrast = defaultMinContrast
+		}
+		style = lowVisionStyle + style
+		script = fmt.Sprintf(lowVisionScript, contrast)
+	}
+	view.SetUserContent(style, script)
+}
+
+// applySiteStyle switches view to the user stylesheets for target before
+// its page is shown.
+func (a *App) applySiteStyle(view *viewHost, target string) {
+	glib.IdleAdd(func() bool {
+		a.applyUserContent(view.current(), target)
+		return false
+	})
+}
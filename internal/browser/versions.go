@@ -0,0 +1,132 @@
+package browser
+
+import (
+	"fmt"
+	"time"
+
+	"chimera/internal/cache"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// versionPicker lists stored compositions for the current URL and lets the user pin one.
+type versionPicker struct {
+	box      *gtk.Box
+	combo    *gtk.ComboBoxText
+	pin      *gtk.ToggleButton
+	refresh  *gtk.Button
+	url      string
+	entries  []cache.Composition
+	updating bool
+}
+
+func newVersionPicker() (*versionPicker, error) {
+	box, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, fmt.Errorf("create version box: %w", err)
+	}
+	box.SetName("chimera-version-picker")
+
+	combo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create version combo: %w", err)
+	}
+	combo.SetTooltipText("Previously composed versions of this page")
+
+	pin, err := gtk.ToggleButtonNewWithLabel("Pin")
+	if err != nil {
+		return nil, fmt.Errorf("create pin button: %w", err)
+	}
+	pin.SetName("chimera-btn-ghost")
+	pin.SetTooltipText("Show this version instead of composing again")
+
+	refresh, err := gtk.ButtonNewWithLabel("Revalidate")
+	if err != nil {
+		return nil, fmt.Errorf("create revalidate button: %w", err)
+	}
+	refresh.SetName("chimera-btn-ghost")
+	refresh.SetTooltipText("Ask the site whether the page changed since this version was composed, and compose it again only if it did")
+
+	box.PackStart(combo, false, false, 0)
+	box.PackStart(pin, false, false, 0)
+	box.PackStart(refresh, false, false, 0)
+
+	return &versionPicker{box: box, combo: combo, pin: pin, refresh: refresh}, nil
+}
+
+// show replaces the listed versions. Must be called on the GTK main loop.
+func (p *versionPicker) show(url string, entries []cache.Composition, activeID string) {
+	p.updating = true
+	defer func() { p.updating = false }()
+
+	p.url = url
+	p.entries = entries
+	p.combo.RemoveAll()
+	for _, c := range entries {
+		p.combo.Append(c.ID, versionLabel(c))
+	}
+
+	if len(entries) == 0 {
+		p.box.Hide()
+		return
+	}
+
+	if activeID == "" || !p.combo.SetActiveID(activeID) {
+		p.combo.SetActive(-1)
+	}
+	current, ok := p.selected()
+	p.pin.SetActive(ok && current.Pinned)
+	p.pin.SetSensitive(ok)
+	p.refresh.SetSensitive(ok)
+	p.box.ShowAll()
+}
+
+func (p *versionPicker) selected() (cache.Composition, bool) {
+	id := p.combo.GetActiveID()
+	for _, c := range p.entries {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return cache.Composition{}, false
+}
+
+func versionLabel(c cache.Composition) string {
+	label := c.CreatedAt.Local().Format("02 Jan 15:04")
+	if c.Model != "" {
+		label += " · " + c.Model
+	}
+	if c.Pinned {
+		label += " · pinned"
+	}
+	return label
+}
+
+// versionAge describes how old c is, and when the page was last confirmed
+// unchanged, such as "3 days old, checked 2 hours ago".
+func versionAge(c cache.Composition, now time.Time) string {
+	age := roughDuration(now.Sub(c.CreatedAt)) + " old"
+	if !c.CheckedAt.IsZero() {
+		age += ", checked " + roughDuration(now.Sub(c.CheckedAt)) + " ago"
+	}
+	return age
+}
+
+func roughDuration(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return "under a minute"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 48*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d/(24*time.Hour)), "day")
+	}
+}
@@ -0,0 +1,82 @@
+package browser
+
+import (
+	"context"
+	"strings"
+
+	"chimera/internal/browser/webkit"
+	"chimera/internal/scraper"
+
+	"github.com/gotk3/gotk3/glib"
+)
+
+const (
+	// complexLinkDensity is how many links per 100 words make a page count
+	// as visually complex, as landing pages and indexes do.
+	complexLinkDensity = 4
+	// complexHeadings is how many headings make a page count as visually
+	// complex, as dashboards and feature grids do.
+	complexHeadings = 15
+)
+
+// visuallyComplex reports whether result's page likely relies on its
+// layout more than its text: scripts had to build it, it is dense with
+// links, or it is split into many small sections.
+func visuallyComplex(result *scraper.Result) bool {
+	return result.Rendered ||
+		len(result.Links)*100 >= complexLinkDensity*max(result.Words, 1) ||
+		len(result.Headings) >= complexHeadings
+}
+
+// screenshotFor returns a function that captures result's original page
+// for a model that can see, or nil when there is no point: the model
+// cannot, or the page is mostly text.
+func (a *App) screenshotFor(result *scraper.Result) func(context.Context) ([]byte, error) {
+	if !a.currentLLM().Vision() || !visuallyComplex(result) {
+		return nil
+	}
+	return func(ctx context.Context) ([]byte, error) {
+		return a.captureScreenshot(ctx, result.FinalURL)
+	}
+}
+
+// captureScreenshot loads uri in an offscreen WebKit view and returns a PNG
+// of its first screenful once its scripts have settled.
+func (a *App) captureScreenshot(ctx context.Context, uri string) ([]byte, error) {
+	type captured struct {
+		png []byte
+		err error
+	}
+	done := make(chan captured, 1)
+
+	var cancel func()
+	glib.IdleAdd(func() bool {
+		cancel = webkit.Snapshot(uri, scriptSettle, scriptTimeout, func(png []byte, err error) {
+			done <- captured{png, err}
+		})
+		return false
+	})
+
+	select {
+	case <-ctx.Done():
+		glib.IdleAdd(func() bool {
+			cancel()
+			return false
+		})
+		return nil, ctx.Err()
+	case c := <-done:
+		return c.png, c.err
+	}
+}
+
+// commaList splits the comma-separated names of a settings field, such as
+// the vision models, dropping empty ones.
+func commaList(text string) []string {
+	var names []string
+	for _, name := range strings.Split(text, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
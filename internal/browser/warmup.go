@@ -0,0 +1,62 @@
+package browser
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// keepAliveInterval stays under Ollama's default five-minute unload timeout.
+const keepAliveInterval = 4 * time.Minute
+
+// warmUp asks the model for a one-token completion in the background so the
+// first composition does not wait for a local server to load it.
+func (a *App) warmUp(ctx context.Context) {
+	client := a.currentLLM()
+	if !a.preferences().WarmUp || !client.Available() || client.Paused() > 0 {
+		return
+	}
+
+	a.mu.Lock()
+	a.lastWarmUp = time.Now()
+	a.mu.Unlock()
+
+	go func() {
+		start := time.Now()
+		if err := client.WarmUp(ctx); err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("llm warm-up", "model", client.Model(), "err", err)
+			}
+			return
+		}
+		slog.Info("llm warmed up", "model", client.Model(), "took", time.Since(start).Round(time.Millisecond))
+	}()
+}
+
+// keepModelWarm repeats the warm-up while the window is focused, and right
+// away when focus returns after the model may have been unloaded.
+func (a *App) keepModelWarm(ctx context.Context, window *gtk.ApplicationWindow) {
+	stale := func() bool {
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+		return time.Since(a.lastWarmUp) >= keepAliveInterval
+	}
+
+	window.Connect("notify::is-active", func() {
+		if window.IsActive() && stale() {
+			a.warmUp(ctx)
+		}
+	})
+	glib.TimeoutAdd(uint(keepAliveInterval/time.Millisecond), func() bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if window.IsActive() && stale() {
+			a.warmUp(ctx)
+		}
+		return true
+	})
+}
@@ -0,0 +1,275 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"strings"
+	"time"
+
+	"chimera/internal/scraper"
+	"chimera/internal/watch"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// watchesURI lists the watched pages and what changed on them.
+const watchesURI = "chimera://watches"
+
+// watchInterval is how often each watched page is scraped again.
+const watchInterval = 6 * time.Hour
+
+// watchTick is how often the background loop looks for pages due a check.
+const watchTick = 15 * time.Minute
+
+// maxWatchChecks caps how many watched pages are scraped at once.
+const maxWatchChecks = 4
+
+// watchPage starts watching the current page, from its content as shown.
+func (a *App) watchPage(info *gtk.Label) {
+	page := a.currentPage()
+	if page.Result == nil {
+		a.setStatus(info, "Nothing to watch yet")
+		return
+	}
+	now := time.Now()
+	err := a.cfg.Watches.Add(watch.Watch{
+		URL:      page.SourceURL,
+		Title:    page.Title,
+		AddedAt:  now,
+		Snapshot: watch.NewSnapshot(page.Result, now),
+	})
+	if err != nil {
+		a.setStatus(info, fmt.Sprintf("Watch failed: %v", err))
+		return
+	}
+	a.setStatus(info, fmt.Sprintf("Watching; checked every %d hours", int(watchInterval.Hours())))
+}
+
+// keepWatching checks watched pages in the background and sends a desktop
+// notification when one of them changed. Clicking it opens the watch list.
+func (a *App) keepWatching(ctx context.Context, app *gtk.Application) {
+	check := func() {
+		if !a.watching.CompareAndSwap(false, true) {
+			return
+		}
+		go func() {
+			defer a.watching.Store(false)
+			changed, err := a.checkWatches(scraper.WithPriority(ctx, scraper.PriorityBackground), false)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("check watched pages", "err", err)
+				}
+				return
+			}
+			if len(changed) == 0 {
+				return
+			}
+			glib.IdleAdd(func() {
+				title := fmt.Sprintf("%d watched pages changed", len(changed))
+				body := make([]string, 0, len(changed))
+				for _, w := range changed {
+					body = append(body, w.Title)
+				}
+				if len(changed) == 1 {
+					title = "Watched page changed"
+					body = []string{changed[0].Title + ": " + changed[0].Change.Summary()}
+				}
+				notification := glib.NotificationNew(title)
+				notification.SetBody(strings.Join(body, "\n"))
+				notification.SetDefaultAction("app.watches")
+				app.SendNotification("watches", notification)
+			})
+		}()
+	}
+
+	check()
+	glib.TimeoutAdd(uint(watchTick/time.Millisecond), func() bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		check()
+		return true
+	})
+}
+
+// checkWatches scrapes the watched pages last checked more than
+// watchInterval ago, or all of them when force is set, records the
+// outcome and returns the pages that changed.
+func (a *App) checkWatches(ctx context.Context, force bool) ([]watch.Watch, error) {
+	saved, err := a.cfg.Watches.List()
+	if err != nil {
+		return nil, err
+	}
+	var due []string
+	for _, w := range saved {
+		if force || time.Since(w.CheckedAt) >= watchInterval {
+			due = append(due, w.URL)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+
+	results, errs := a.cfg.Scraper.ScrapeAll(ctx, due, maxWatchChecks)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var changed []watch.Watch
+	for i, target := range due {
+		var snapshot *watch.Snapshot
+		if errs[i] == nil {
+			taken := watch.NewSnapshot(results[i], now)
+			snapshot = &taken
+		}
+		isChanged, err := a.cfg.Watches.Record(target, now, snapshot, errs[i])
+		if err != nil {
+			return changed, err
+		}
+		if isChanged {
+			if w, ok, _ := a.cfg.Watches.Get(target); ok {
+				changed = append(changed, w)
+			}
+		}
+	}
+	slog.Info("checked watched pages", "checked", len(due), "changed", len(changed))
+	return changed, nil
+}
+
+func (a *App) watchesPage(ctx context.Context) (string, error) {
+	saved, err := a.cfg.Watches.List()
+	if err != nil {
+		return "", err
+	}
+	unseen := 0
+	for _, w := range saved {
+		if w.Unseen {
+			unseen++
+		}
+	}
+
+	var builder strings.Builder
+	err = watchesTmpl.Execute(&builder, struct {
+		Watches       []watch.Watch
+		Unseen        int
+		IntervalHours int
+	}{saved, unseen, int(watchInterval.Hours())})
+	return builder.String(), err
+}
+
+// checkWatchesPage checks every watched page now and lists them.
+func (a *App) checkWatchesPage(ctx context.Context) (string, error) {
+	if !a.watching.CompareAndSwap(false, true) {
+		return "", fmt.Errorf("watched pages are being checked already")
+	}
+	defer a.watching.Store(false)
+	if _, err := a.checkWatches(ctx, true); err != nil {
+		return "", err
+	}
+	return a.watchesPage(ctx)
+}
+
+// removeWatchPage stops watching target and lists the rest.
+func (a *App) removeWatchPage(target string) internalPage {
+	return func(ctx context.Context) (string, error) {
+		if err := a.cfg.Watches.Remove(target); err != nil {
+			return "", err
+		}
+		return a.watchesPage(ctx)
+	}
+}
+
+// watchDiffPage shows the last change found on target and marks it seen.
+func (a *App) watchDiffPage(target string) internalPage {
+	return func(ctx context.Context) (string, error) {
+		w, ok, err := a.cfg.Watches.Get(target)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("%s is not watched", target)
+		}
+		if err := a.cfg.Watches.MarkSeen(target); err != nil {
+			return "", err
+		}
+
+		var builder strings.Builder
+		err = watchDiffTmpl.Execute(&builder, w)
+		return builder.String(), err
+	}
+}
+
+// watchStyle is shared by the watch list and the diff page.
+const watchStyle = `<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+h1 { margin: 0 0 .5rem 0; }
+table { width: 100%; border-collapse: collapse; background: #fff; border-radius: 12px; overflow: hidden; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+th, td { text-align: left; padding: .6rem 1rem; border-bottom: 1px solid #e5e8ef; }
+tr.unseen td { background: #f1f5fd; }
+.changed { color: #2b5dcc; font-weight: 600; }
+.problem { color: #b3261e; }
+a { color: #2b5dcc; text-decoration: none; }
+small { color: #5b6576; }
+code { word-break: break-all; }
+section { background: #fff; border-radius: 12px; padding: .5rem 1.5rem; margin: 1rem 0; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+ins, del { display: block; padding: .4rem .8rem; margin: .4rem 0; border-radius: 6px; text-decoration: none; }
+ins { background: #e8f5e9; border-left: 3px solid #2e7d32; }
+del { background: #fdf1f1; border-left: 3px solid #b3261e; color: #5b6576; }
+</style>`
+
+var watchesTmpl = template.Must(template.New("watches").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Watched pages — Chimera</title>
+` + watchStyle + `
+</head>
+<body>
+<h1>Watched pages</h1>
+<p><small>{{ len .Watches }} pages • {{ .Unseen }} with unseen changes • checked every {{ .IntervalHours }} hours while Chimera runs •
+<a href="chimera://watches/check">Check now</a></small></p>
+{{ if .Watches }}
+<table>
+<thead><tr><th>Page</th><th>Last change</th><th></th></tr></thead>
+<tbody>
+{{ range $w := .Watches }}<tr{{ if .Unseen }} class="unseen"{{ end }}>
+<td><a href="{{ .URL }}">{{ if .Title }}{{ .Title }}{{ else }}{{ .URL }}{{ end }}</a><br /><small><code>{{ .URL }}</code></small></td>
+<td>{{ with .Change }}<a class="changed" href="chimera://watches/diff?url={{ $w.URL }}">{{ .Summary }}</a><br /><small>found {{ .Found.Format "2006-01-02 15:04" }}</small>{{ else }}<small>No changes yet</small>{{ end }}
+{{ if .Problem }}<br /><span class="problem">Last check failed: {{ .Problem }}</span>{{ end }}
+<br /><small>checked {{ .CheckedAt.Format "2006-01-02 15:04" }}</small></td>
+<td><a href="chimera://watches/remove?url={{ .URL }}">Stop watching</a></td>
+</tr>
+{{ end }}
+</tbody>
+</table>
+{{ else }}<p>No watched pages yet. Use "Watch this page" in the menu to be told when a page changes.</p>{{ end }}
+</body>
+</html>`))
+
+var watchDiffTmpl = template.Must(template.New("watch-diff").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Changes to {{ if .Title }}{{ .Title }}{{ else }}{{ .URL }}{{ end }} — Chimera</title>
+` + watchStyle + `
+</head>
+<body>
+<h1>Changes to {{ if .Title }}{{ .Title }}{{ else }}{{ .URL }}{{ end }}</h1>
+<p><small><a href="{{ .URL }}">Open the page</a> • <a href="chimera://watches">All watched pages</a></small></p>
+{{ with .Change }}
+<p>{{ .Summary }} between {{ .Since.Format "2006-01-02 15:04" }} and {{ .Found.Format "2006-01-02 15:04" }}.</p>
+{{ if or .OldTitle .NewTitle }}<section><h2>Title</h2><del>{{ .OldTitle }}</del><ins>{{ .NewTitle }}</ins></section>{{ end }}
+{{ if or .AddedHeadings .RemovedHeadings }}<section><h2>Outline</h2>
+{{ range .RemovedHeadings }}<del>{{ . }}</del>{{ end }}
+{{ range .AddedHeadings }}<ins>{{ . }}</ins>{{ end }}
+</section>{{ end }}
+{{ if or .AddedParagraphs .RemovedParagraphs }}<section><h2>Text</h2>
+{{ range .RemovedParagraphs }}<del>{{ . }}</del>{{ end }}
+{{ range .AddedParagraphs }}<ins>{{ . }}</ins>{{ end }}
+</section>{{ end }}
+{{ else }}<p>No changes found since the page was first watched.</p>{{ end }}
+</body>
+</html>`))
@@ -0,0 +1,102 @@
+package browser
+
+import (
+	"fmt"
+	"log/slog"
+
+	"chimera/internal/browser/webkit"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+const (
+	watchdogIntervalMs = 2000
+	// watchdogStrikes is how many consecutive unresponsive checks trigger a restart.
+	watchdogStrikes = 4
+	reloadURI       = "chimera://reload"
+)
+
+// viewHost owns the active WebView and replaces it when its web process dies.
+// All methods must be called on the GTK main loop.
+type viewHost struct {
+	overlay *gtk.Overlay
+	view    *webkit.WebView
+	setup   func(*webkit.WebView)
+	strikes int
+}
+
+func newViewHost(overlay *gtk.Overlay, setup func(*webkit.WebView)) (*viewHost, error) {
+	host := &viewHost{overlay: overlay, setup: setup}
+	view, err := host.build()
+	if err != nil {
+		return nil, err
+	}
+	host.view = view
+	overlay.Add(view.Widget())
+	return host, nil
+}
+
+func (h *viewHost) current() *webkit.WebView {
+	return h.view
+}
+
+func (h *viewHost) build() (*webkit.WebView, error) {
+	view, err := webkit.NewWebView()
+	if err != nil {
+		return nil, fmt.Errorf("create webview: %w", err)
+	}
+	view.Widget().SetName("chimera-webview")
+	h.setup(view)
+	return view, nil
+}
+
+// recreate swaps in a fresh WebView, discarding the old one.
+func (h *viewHost) recreate() error {
+	view, err := h.build()
+	if err != nil {
+		return err
+	}
+
+	old := h.view
+	h.overlay.Remove(old.Widget())
+	old.Destroy()
+
+	h.view = view
+	h.strikes = 0
+	h.overlay.Add(view.Widget())
+	view.Widget().Show()
+	return nil
+}
+
+// watch polls the web process and terminates it once it has been unresponsive
+// for several consecutive checks; the resulting termination signal triggers recovery.
+func (h *viewHost) watch() {
+	glib.TimeoutAdd(watchdogIntervalMs, func() bool {
+		if h.view.IsResponsive() {
+			h.strikes = 0
+			return true
+		}
+
+		h.strikes++
+		if h.strikes == watchdogStrikes {
+			slog.Warn("web process unresponsive; terminating", "seconds", watchdogStrikes*watchdogIntervalMs/1000)
+			h.view.TerminateWebProcess()
+		}
+		return true
+	})
+}
+
+func (a *App) recoverWebView(host *viewHost, info *gtk.Label, reason string) {
+	slog.Warn("web process ended; recreating view", "reason", reason)
+	if err := host.recreate(); err != nil {
+		slog.Error("recreate webview", "err", err)
+		info.SetText(fmt.Sprintf("Page %s and the view could not be restored", reason))
+		return
+	}
+
+	host.current().InjectActionBubble("Page crashed — reload?",
+		fmt.Sprintf("The rendering process %s. Your session is intact; reload to render the page again.", reason),
+		"Reload", reloadURI)
+	info.SetText(fmt.Sprintf("Page %s", reason))
+}
@@ -0,0 +1,307 @@
+package webkit
+
+/*
+#cgo pkg-config: webkit2gtk-4.1
+#include <stdlib.h>
+#include <gtk/gtk.h>
+#include <webkit2/webkit2.h>
+
+extern void goChimeraOffscreenLoadChanged(WebKitWebView*, WebKitLoadEvent, gpointer);
+extern gboolean goChimeraOffscreenLoadFailed(WebKitWebView*, WebKitLoadEvent, gchar*, GError*, gpointer);
+extern void goChimeraJavascriptDone(guintptr, gchar*, gchar*);
+extern void goChimeraSnapshotDone(guintptr, guchar*, guint, gchar*);
+
+static GtkWidget* chimera_offscreen_window_new(int width, int height) {
+    GtkWidget* window = gtk_offscreen_window_new();
+    gtk_window_set_default_size(GTK_WINDOW(window), width, height);
+    return window;
+}
+
+static WebKitWebView* chimera_offscreen_view_new(GtkWidget* window) {
+    GtkWidget* view = webkit_web_view_new();
+    webkit_web_view_set_is_muted(WEBKIT_WEB_VIEW(view), TRUE);
+    gtk_container_add(GTK_CONTAINER(window), view);
+    gtk_widget_show_all(window);
+    g_signal_connect(view, "load-changed", G_CALLBACK(goChimeraOffscreenLoadChanged), NULL);
+    g_signal_connect(view, "load-failed", G_CALLBACK(goChimeraOffscreenLoadFailed), NULL);
+    return WEBKIT_WEB_VIEW(view);
+}
+
+static gboolean chimera_error_is_cancelled(GError* error) {
+    return g_error_matches(error, WEBKIT_NETWORK_ERROR, WEBKIT_NETWORK_ERROR_CANCELLED);
+}
+
+static void chimera_javascript_finished(GObject* object, GAsyncResult* res, gpointer user_data) {
+    GError* error = NULL;
+    WebKitJavascriptResult* result = webkit_web_view_run_javascript_finish(WEBKIT_WEB_VIEW(object), res, &error);
+    if (result == NULL) {
+        goChimeraJavascriptDone((guintptr)user_data, NULL, error != NULL ? error->message : (gchar*)"script failed");
+        if (error != NULL) {
+            g_error_free(error);
+        }
+        return;
+    }
+    gchar* value = jsc_value_to_string(webkit_javascript_result_get_js_value(result));
+    goChimeraJavascriptDone((guintptr)user_data, value, NULL);
+    g_free(value);
+    webkit_javascript_result_unref(result);
+}
+
+static void chimera_run_javascript(WebKitWebView* view, const gchar* script, guintptr id) {
+    webkit_web_view_run_javascript(view, script, NULL, chimera_javascript_finished, (gpointer)id);
+}
+
+static cairo_status_t chimera_png_append(void* closure, const unsigned char* data, unsigned int length) {
+    g_byte_array_append((GByteArray*)closure, data, length);
+    return CAIRO_STATUS_SUCCESS;
+}
+
+static void chimera_snapshot_finished(GObject* object, GAsyncResult* res, gpointer user_data) {
+    GError* error = NULL;
+    cairo_surface_t* surface = webkit_web_view_get_snapshot_finish(WEBKIT_WEB_VIEW(object), res, &error);
+    if (surface == NULL) {
+        goChimeraSnapshotDone((guintptr)user_data, NULL, 0, error != NULL ? error->message : (gchar*)"snapshot failed");
+        if (error != NULL) {
+            g_error_free(error);
+        }
+        return;
+    }
+    GByteArray* png = g_byte_array_new();
+    cairo_status_t status = cairo_surface_write_to_png_stream(surface, chimera_png_append, png);
+    cairo_surface_destroy(surface);
+    if (status != CAIRO_STATUS_SUCCESS) {
+        goChimeraSnapshotDone((guintptr)user_data, NULL, 0, (gchar*)cairo_status_to_string(status));
+    } else {
+        goChimeraSnapshotDone((guintptr)user_data, png->data, png->len, NULL);
+    }
+    g_byte_array_unref(png);
+}
+
+static void chimera_snapshot(WebKitWebView* view, guintptr id) {
+    webkit_web_view_get_snapshot(view, WEBKIT_SNAPSHOT_REGION_VISIBLE, WEBKIT_SNAPSHOT_OPTIONS_NONE, NULL, chimera_snapshot_finished, (gpointer)id);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/gotk3/gotk3/glib"
+)
+
+const (
+	offscreenWidth  = 1280
+	offscreenHeight = 900
+	// domPollInterval is how often the DOM size is sampled while waiting for it to settle.
+	domPollInterval = 500 * time.Millisecond
+)
+
+// offscreenRender tracks one RenderDOM or Snapshot call. Its fields are
+// only touched on the GTK main loop.
+type offscreenRender struct {
+	window *C.GtkWidget
+	view   *C.WebKitWebView
+	// collect reads what the call is for once the page has settled and
+	// passes it to finish.
+	collect func(r *offscreenRender)
+	done    func([]byte, error)
+
+	settlePolls int
+	stablePolls int
+	lastSize    string
+	extracting  bool
+	closed      bool
+}
+
+var (
+	offscreenRenders  sync.Map
+	jsCallbacks       sync.Map
+	jsCallbackID      atomic.Uintptr
+	snapshotCallbacks sync.Map
+)
+
+// RenderDOM loads uri in a hidden view, lets its scripts run, and passes the
+// serialised DOM to done once the markup has stopped changing for settle, or
+// when timeout expires. It must be called on the GTK main loop, where done
+// also runs. The returned function abandons the render without calling done.
+func RenderDOM(uri string, settle, timeout time.Duration, done func(html string, err error)) func() {
+	collect := func(r *offscreenRender) {
+		r.run("document.documentElement ? document.documentElement.outerHTML : ''", func(html string, err error) {
+			r.finish([]byte(html), err)
+		})
+	}
+	return renderOffscreen(uri, settle, timeout, collect, func(html []byte, err error) {
+		done(string(html), err)
+	})
+}
+
+// Snapshot loads uri in a hidden view like RenderDOM, and passes a PNG of
+// the part of the page in its window, offscreenWidth by offscreenHeight
+// pixels, to done once the page has settled.
+func Snapshot(uri string, settle, timeout time.Duration, done func(png []byte, err error)) func() {
+	collect := func(r *offscreenRender) {
+		id := jsCallbackID.Add(1)
+		snapshotCallbacks.Store(id, r.finish)
+		C.chimera_snapshot(r.view, C.guintptr(id))
+	}
+	return renderOffscreen(uri, settle, timeout, collect, done)
+}
+
+func renderOffscreen(uri string, settle, timeout time.Duration, collect func(*offscreenRender), done func([]byte, error)) func() {
+	window := C.chimera_offscreen_window_new(C.int(offscreenWidth), C.int(offscreenHeight))
+	view := C.chimera_offscreen_view_new(window)
+
+	r := &offscreenRender{
+		window:      window,
+		view:        view,
+		collect:     collect,
+		done:        done,
+		settlePolls: max(1, int(settle/domPollInterval)),
+	}
+	offscreenRenders.Store(uintptr(unsafe.Pointer(view)), r)
+
+	cURI := C.CString(uri)
+	defer C.free(unsafe.Pointer(cURI))
+	C.webkit_web_view_load_uri(view, (*C.gchar)(cURI))
+
+	// Slow pages still yield whatever has rendered when time runs out.
+	glib.TimeoutAdd(uint(timeout/time.Millisecond), func() bool {
+		r.extract()
+		return false
+	})
+	return r.close
+}
+
+func lookupRender(view *C.WebKitWebView) (*offscreenRender, bool) {
+	value, ok := offscreenRenders.Load(uintptr(unsafe.Pointer(view)))
+	if !ok {
+		return nil, false
+	}
+	return value.(*offscreenRender), true
+}
+
+//export goChimeraOffscreenLoadChanged
+func goChimeraOffscreenLoadChanged(view *C.WebKitWebView, event C.WebKitLoadEvent, _ C.gpointer) {
+	if event != C.WEBKIT_LOAD_FINISHED {
+		return
+	}
+	if r, ok := lookupRender(view); ok {
+		r.poll()
+	}
+}
+
+//export goChimeraOffscreenLoadFailed
+func goChimeraOffscreenLoadFailed(view *C.WebKitWebView, _ C.WebKitLoadEvent, uri *C.gchar, gerr *C.GError, _ C.gpointer) C.gboolean {
+	r, ok := lookupRender(view)
+	if !ok || C.chimera_error_is_cancelled(gerr) != C.FALSE {
+		// A script navigating elsewhere cancels the first load; keep waiting.
+		return C.FALSE
+	}
+	r.finish(nil, fmt.Errorf("load %s: %s", C.GoString((*C.char)(uri)), C.GoString((*C.char)(gerr.message))))
+	return C.FALSE
+}
+
+// poll samples the DOM size and extracts it once it has been stable for settle.
+func (r *offscreenRender) poll() {
+	if r.closed || r.extracting {
+		return
+	}
+	r.run("String(document.documentElement ? document.documentElement.outerHTML.length : 0)", func(size string, err error) {
+		if r.closed || r.extracting {
+			return
+		}
+		if err != nil {
+			r.extract()
+			return
+		}
+		if size == r.lastSize {
+			r.stablePolls++
+		} else {
+			r.lastSize, r.stablePolls = size, 0
+		}
+		if r.stablePolls >= r.settlePolls {
+			r.extract()
+			return
+		}
+		glib.TimeoutAdd(uint(domPollInterval/time.Millisecond), func() bool {
+			r.poll()
+			return false
+		})
+	})
+}
+
+func (r *offscreenRender) extract() {
+	if r.closed || r.extracting {
+		return
+	}
+	r.extracting = true
+	r.collect(r)
+}
+
+func (r *offscreenRender) finish(value []byte, err error) {
+	if r.closed {
+		return
+	}
+	r.close()
+	if err == nil && len(value) == 0 {
+		err = errors.New("rendered page is empty")
+	}
+	r.done(value, err)
+}
+
+func (r *offscreenRender) close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	offscreenRenders.Delete(uintptr(unsafe.Pointer(r.view)))
+	C.gtk_widget_destroy(r.window)
+}
+
+func (r *offscreenRender) run(script string, fn func(string, error)) {
+	runJavaScript(r.view, script, fn)
+}
+
+// runJavaScript evaluates script in view and passes its value, converted to
+// a string, to fn on the GTK main loop.
+func runJavaScript(view *C.WebKitWebView, script string, fn func(string, error)) {
+	id := jsCallbackID.Add(1)
+	jsCallbacks.Store(id, fn)
+
+	cScript := C.CString(script)
+	defer C.free(unsafe.Pointer(cScript))
+	C.chimera_run_javascript(view, (*C.gchar)(cScript), C.guintptr(id))
+}
+
+//export goChimeraJavascriptDone
+func goChimeraJavascriptDone(id C.guintptr, value *C.gchar, errMsg *C.gchar) {
+	stored, ok := jsCallbacks.LoadAndDelete(uintptr(id))
+	if !ok {
+		return
+	}
+	fn := stored.(func(string, error))
+	if errMsg != nil {
+		fn("", errors.New(C.GoString((*C.char)(errMsg))))
+		return
+	}
+	fn(C.GoString((*C.char)(value)), nil)
+}
+
+//export goChimeraSnapshotDone
+func goChimeraSnapshotDone(id C.guintptr, data *C.guchar, length C.guint, errMsg *C.gchar) {
+	stored, ok := snapshotCallbacks.LoadAndDelete(uintptr(id))
+	if !ok {
+		return
+	}
+	fn := stored.(func([]byte, error))
+	if errMsg != nil {
+		fn(nil, errors.New(C.GoString((*C.char)(errMsg))))
+		return
+	}
+	fn(C.GoBytes(unsafe.Pointer(data), C.int(length)), nil)
+}
@@ -14,12 +14,62 @@ static void chimera_webview_load_html(WebKitWebView* view, const gchar* content,
     webkit_web_view_load_html(view, content, base_uri);
 }
 
+static void chimera_set_network_proxy(const gchar* uri) {
+    WebKitWebsiteDataManager* manager = webkit_web_context_get_website_data_manager(webkit_web_context_get_default());
+    if (uri == NULL) {
+        webkit_website_data_manager_set_network_proxy_settings(manager, WEBKIT_NETWORK_PROXY_MODE_DEFAULT, NULL);
+        return;
+    }
+    WebKitNetworkProxySettings* settings = webkit_network_proxy_settings_new(uri, NULL);
+    webkit_website_data_manager_set_network_proxy_settings(manager, WEBKIT_NETWORK_PROXY_MODE_CUSTOM, settings);
+    webkit_network_proxy_settings_free(settings);
+}
+
+static void chimera_webview_set_minimum_font_size(WebKitWebView* view, guint32 size) {
+    webkit_settings_set_minimum_font_size(webkit_web_view_get_settings(view), size);
+}
+
+static void chimera_webview_set_user_content(WebKitWebView* view, const gchar* style, const gchar* script) {
+    WebKitUserContentManager* manager = webkit_web_view_get_user_content_manager(view);
+    webkit_user_content_manager_remove_all_style_sheets(manager);
+    webkit_user_content_manager_remove_all_scripts(manager);
+    if (style != NULL) {
+        WebKitUserStyleSheet* sheet = webkit_user_style_sheet_new(style, WEBKIT_USER_CONTENT_INJECT_ALL_FRAMES, WEBKIT_USER_STYLE_LEVEL_USER, NULL, NULL);
+        webkit_user_content_manager_add_style_sheet(manager, sheet);
+        webkit_user_style_sheet_unref(sheet);
+    }
+    if (script != NULL) {
+        WebKitUserScript* user_script = webkit_user_script_new(script, WEBKIT_USER_CONTENT_INJECT_ALL_FRAMES, WEBKIT_USER_SCRIPT_INJECT_AT_DOCUMENT_END, NULL, NULL);
+        webkit_user_content_manager_add_script(manager, user_script);
+        webkit_user_script_unref(user_script);
+    }
+}
+
 extern gboolean goChimeraDecidePolicy(WebKitWebView*, WebKitPolicyDecision*, WebKitPolicyDecisionType, gpointer);
 
 static void chimera_webview_connect_decide_policy(WebKitWebView* view) {
     g_signal_connect(view, "decide-policy", G_CALLBACK(goChimeraDecidePolicy), NULL);
 }
 
+extern void goChimeraWebProcessTerminated(WebKitWebView*, WebKitWebProcessTerminationReason, gpointer);
+
+static void chimera_webview_connect_terminated(WebKitWebView* view) {
+    g_signal_connect(view, "web-process-terminated", G_CALLBACK(goChimeraWebProcessTerminated), NULL);
+}
+
+extern void goChimeraAudioChanged(WebKitWebView*, GParamSpec*, gpointer);
+
+static void chimera_webview_connect_audio(WebKitWebView* view) {
+    g_signal_connect(view, "notify::is-playing-audio", G_CALLBACK(goChimeraAudioChanged), NULL);
+    g_signal_connect(view, "notify::is-muted", G_CALLBACK(goChimeraAudioChanged), NULL);
+}
+
+extern void goChimeraURIChanged(WebKitWebView*, GParamSpec*, gpointer);
+
+static void chimera_webview_connect_uri(WebKitWebView* view) {
+    g_signal_connect(view, "notify::uri", G_CALLBACK(goChimeraURIChanged), NULL);
+}
+
 static const gchar* chimera_navigation_policy_uri(WebKitPolicyDecision* decision) {
     if (!WEBKIT_IS_NAVIGATION_POLICY_DECISION(decision)) {
         return NULL;
@@ -44,6 +94,7 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"unsafe"
 
@@ -54,9 +105,12 @@ import (
 
 // WebView wraps a WebKitWebView for GTK integration.
 type WebView struct {
-	widget  *gtk.Widget
-	view    *C.WebKitWebView
-	navOnce sync.Once
+	widget    *gtk.Widget
+	view      *C.WebKitWebView
+	navOnce   sync.Once
+	termOnce  sync.Once
+	audioOnce sync.Once
+	uriOnce   sync.Once
 }
 
 // NewWebView constructs a new WebKit web view widget.
@@ -73,25 +127,51 @@ func NewWebView() (*WebView, error) {
 	}, nil
 }
 
+// SetProxy routes the requests of every web view, visible or offscreen,
+// through uri, an http, https or socks5 proxy URL; empty uses the system
+// proxy settings. It must be called on the GTK main thread.
+func SetProxy(uri string) {
+	if uri == "" {
+		C.chimera_set_network_proxy(nil)
+		return
+	}
+	cURI := C.CString(uri)
+	defer C.free(unsafe.Pointer(cURI))
+	C.chimera_set_network_proxy((*C.gchar)(cURI))
+}
+
 // Widget exposes the underlying GTK widget for packing into containers.
 func (w *WebView) Widget() *gtk.Widget {
 	return w.widget
 }
 
-// LoadHTML renders the provided HTML content.
+// LoadHTML renders the provided HTML content. Relative URLs in it resolve
+// against baseURI, which the view then reports as its URI; loading it does
+// not pass through OnNavigate.
 func (w *WebView) LoadHTML(html string, baseURI string) {
 	cHTML := C.CString(html)
 	defer C.free(unsafe.Pointer(cHTML))
 
+	key := uintptr(unsafe.Pointer(w.view))
 	var cBase *C.char
 	if baseURI != "" {
 		cBase = C.CString(baseURI)
 		defer C.free(unsafe.Pointer(cBase))
+		pendingBases.Store(key, baseURI)
+	} else {
+		pendingBases.Delete(key)
 	}
 
 	C.chimera_webview_load_html(w.view, (*C.gchar)(cHTML), (*C.gchar)(cBase))
 }
 
+// LoadURI navigates the view to uri. The navigation passes through OnNavigate.
+func (w *WebView) LoadURI(uri string) {
+	cURI := C.CString(uri)
+	defer C.free(unsafe.Pointer(cURI))
+	C.webkit_web_view_load_uri(w.view, (*C.gchar)(cURI))
+}
+
 // OnNavigate registers a callback that fires when the user requests a new navigation.
 // Returning true from the handler signals that the navigation was handled and should not proceed.
 func (w *WebView) OnNavigate(handler func(uri string) bool) {
@@ -102,7 +182,176 @@ func (w *WebView) OnNavigate(handler func(uri string) bool) {
 	})
 }
 
-var navigationHandlers sync.Map
+// OnProcessTerminated registers a callback that fires when the web process
+// backing the view crashes, runs out of memory, or is terminated.
+func (w *WebView) OnProcessTerminated(handler func(reason string)) {
+	key := uintptr(unsafe.Pointer(w.view))
+	terminationHandlers.Store(key, handler)
+	w.termOnce.Do(func() {
+		C.chimera_webview_connect_terminated(w.view)
+	})
+}
+
+// URI returns the address of the page the view shows; empty for HTML loaded
+// with LoadHTML without a base URI.
+func (w *WebView) URI() string {
+	uri := C.webkit_web_view_get_uri(w.view)
+	if uri == nil {
+		return ""
+	}
+	return C.GoString((*C.char)(unsafe.Pointer(uri)))
+}
+
+// Reload loads the displayed page again.
+func (w *WebView) Reload() {
+	C.webkit_web_view_reload(w.view)
+}
+
+// OnURIChanged registers a callback that fires when the view's address
+// changes, such as when a link loaded by WebKit itself is followed.
+func (w *WebView) OnURIChanged(handler func(uri string)) {
+	key := uintptr(unsafe.Pointer(w.view))
+	uriHandlers.Store(key, handler)
+	w.uriOnce.Do(func() {
+		C.chimera_webview_connect_uri(w.view)
+	})
+}
+
+// OnAudioChanged registers a callback that fires when the page starts or
+// stops playing audio, or is muted or unmuted.
+func (w *WebView) OnAudioChanged(handler func()) {
+	key := uintptr(unsafe.Pointer(w.view))
+	audioHandlers.Store(key, handler)
+	w.audioOnce.Do(func() {
+		C.chimera_webview_connect_audio(w.view)
+	})
+}
+
+// IsPlayingAudio reports whether the page is playing audio, muted or not.
+func (w *WebView) IsPlayingAudio() bool {
+	return C.webkit_web_view_is_playing_audio(w.view) != C.FALSE
+}
+
+// SetMuted silences or restores the page's audio.
+func (w *WebView) SetMuted(muted bool) {
+	value := C.gboolean(C.FALSE)
+	if muted {
+		value = C.TRUE
+	}
+	C.webkit_web_view_set_is_muted(w.view, value)
+}
+
+// IsMuted reports whether the page's audio is silenced.
+func (w *WebView) IsMuted() bool {
+	return C.webkit_web_view_get_is_muted(w.view) != C.FALSE
+}
+
+// IsResponsive reports whether the web process is currently answering.
+func (w *WebView) IsResponsive() bool {
+	return C.webkit_web_view_get_is_web_process_responsive(w.view) != C.FALSE
+}
+
+// SetZoom sets the page zoom factor, where 1 is 100%.
+func (w *WebView) SetZoom(level float64) {
+	C.webkit_web_view_set_zoom_level(w.view, C.gdouble(level))
+}
+
+// Zoom returns the current page zoom factor.
+func (w *WebView) Zoom() float64 {
+	return float64(C.webkit_web_view_get_zoom_level(w.view))
+}
+
+// SetMinimumFontSize makes WebKit render no text smaller than px pixels; 0
+// lifts the limit.
+func (w *WebView) SetMinimumFontSize(px int) {
+	C.chimera_webview_set_minimum_font_size(w.view, C.guint32(px))
+}
+
+// SetUserContent replaces the user style sheet and script applied to every
+// page the view loads from now on, HTML loaded with LoadHTML included. Style
+// rules override the page only when marked !important; the script runs once
+// the document is parsed. Empty strings remove them.
+func (w *WebView) SetUserContent(style, script string) {
+	var cStyle, cScript *C.char
+	if style != "" {
+		cStyle = C.CString(style)
+		defer C.free(unsafe.Pointer(cStyle))
+	}
+	if script != "" {
+		cScript = C.CString(script)
+		defer C.free(unsafe.Pointer(cScript))
+	}
+	C.chimera_webview_set_user_content(w.view, (*C.gchar)(cStyle), (*C.gchar)(cScript))
+}
+
+// TerminateWebProcess kills the web process backing the view.
+func (w *WebView) TerminateWebProcess() {
+	C.webkit_web_view_terminate_web_process(w.view)
+}
+
+// Destroy releases the widget and any registered callbacks.
+func (w *WebView) Destroy() {
+	key := uintptr(unsafe.Pointer(w.view))
+	navigationHandlers.Delete(key)
+	terminationHandlers.Delete(key)
+	audioHandlers.Delete(key)
+	uriHandlers.Delete(key)
+	pendingBases.Delete(key)
+	w.widget.Destroy()
+}
+
+var (
+	navigationHandlers  sync.Map
+	terminationHandlers sync.Map
+	audioHandlers       sync.Map
+	uriHandlers         sync.Map
+	// pendingBases holds the base URI of HTML being loaded by LoadHTML,
+	// whose navigation is let through once.
+	pendingBases sync.Map
+)
+
+//export goChimeraURIChanged
+func goChimeraURIChanged(view *C.WebKitWebView, _ *C.GParamSpec, _ C.gpointer) {
+	cb, ok := uriHandlers.Load(uintptr(unsafe.Pointer(view)))
+	if !ok {
+		return
+	}
+	if handler, ok := cb.(func(string)); ok {
+		handler(C.GoString((*C.char)(unsafe.Pointer(C.webkit_web_view_get_uri(view)))))
+	}
+}
+
+//export goChimeraAudioChanged
+func goChimeraAudioChanged(view *C.WebKitWebView, _ *C.GParamSpec, _ C.gpointer) {
+	cb, ok := audioHandlers.Load(uintptr(unsafe.Pointer(view)))
+	if !ok {
+		return
+	}
+	if handler, ok := cb.(func()); ok {
+		handler()
+	}
+}
+
+//export goChimeraWebProcessTerminated
+func goChimeraWebProcessTerminated(view *C.WebKitWebView, reason C.WebKitWebProcessTerminationReason, _ C.gpointer) {
+	cb, ok := terminationHandlers.Load(uintptr(unsafe.Pointer(view)))
+	if !ok {
+		return
+	}
+	handler, ok := cb.(func(string))
+	if !ok {
+		return
+	}
+
+	switch reason {
+	case C.WEBKIT_WEB_PROCESS_CRASHED:
+		handler("crashed")
+	case C.WEBKIT_WEB_PROCESS_EXCEEDED_MEMORY_LIMIT:
+		handler("exceeded memory limit")
+	default:
+		handler("terminated")
+	}
+}
 
 func lookupNavigationHandler(view *C.WebKitWebView) (func(string) bool, bool) {
 	key := uintptr(unsafe.Pointer(view))
@@ -134,6 +383,11 @@ func goChimeraDecidePolicy(view *C.WebKitWebView, decision *C.WebKitPolicyDecisi
 	if uri == "" {
 		return C.FALSE
 	}
+	key := uintptr(unsafe.Pointer(view))
+	if base, ok := pendingBases.Load(key); ok && strings.TrimSuffix(base.(string), "/") == strings.TrimSuffix(uri, "/") {
+		pendingBases.Delete(key)
+		return C.FALSE
+	}
 
 	if handler(uri) {
 		C.webkit_policy_decision_ignore(decision)
@@ -143,9 +397,42 @@ func goChimeraDecidePolicy(view *C.WebKitWebView, decision *C.WebKitPolicyDecisi
 	return C.FALSE
 }
 
+// RunJavaScript evaluates script in the displayed page and passes its value,
+// converted to a string, to done. It must be called on the GTK main loop,
+// where done also runs.
+func (w *WebView) RunJavaScript(script string, done func(value string, err error)) {
+	runJavaScript(w.view, script, done)
+}
+
 // InjectStatusBubble displays an informational panel above the page content.
 func (w *WebView) InjectStatusBubble(title, message string) {
-	html := fmt.Sprintf(`<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><style>body{margin:0;padding:24px;font-family:"Inter","Segoe UI",sans-serif;background:rgba(15,23,42,0.05);} .card{max-width:640px;margin:32px auto;padding:24px;border-radius:18px;background:#fff;box-shadow:0 16px 42px rgba(15,35,95,0.18);} .card h1{margin:0 0 12px 0;font-size:24px;color:#1f2937;} .card p{margin:0;font-size:15px;color:#475569;line-height:1.48;}
-</style></head><body><div class="card"><h1>%s</h1><p>%s</p></div></body></html>`, template.HTMLEscapeString(title), template.HTMLEscapeString(message))
-	w.LoadHTML(html, "")
+	w.LoadHTML(fmt.Sprintf(bubbleHTML, template.HTMLEscapeString(title), template.HTMLEscapeString(message), ""), "")
+}
+
+// InjectActionBubble displays an informational panel with a single action link.
+func (w *WebView) InjectActionBubble(title, message, label, href string) {
+	w.InjectActionsBubble(title, message, []Action{{Label: label, Href: href}})
+}
+
+// Action is a link offered on an informational panel.
+type Action struct {
+	Label string
+	Href  string
+}
+
+// InjectActionsBubble displays an informational panel with action links; the
+// first is styled as the primary action.
+func (w *WebView) InjectActionsBubble(title, message string, actions []Action) {
+	var links strings.Builder
+	for i, action := range actions {
+		class := "action"
+		if i > 0 {
+			class = "action secondary"
+		}
+		fmt.Fprintf(&links, `<a class="%s" href="%s">%s</a> `, class, template.HTMLEscapeString(action.Href), template.HTMLEscapeString(action.Label))
+	}
+	w.LoadHTML(fmt.Sprintf(bubbleHTML, template.HTMLEscapeString(title), template.HTMLEscapeString(message), links.String()), "")
 }
+
+const bubbleHTML = `<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><style>body{margin:0;padding:24px;font-family:"Inter","Segoe UI",sans-serif;background:rgba(15,23,42,0.05);} .card{max-width:640px;margin:32px auto;padding:24px;border-radius:18px;background:#fff;box-shadow:0 16px 42px rgba(15,35,95,0.18);} .card h1{margin:0 0 12px 0;font-size:24px;color:#1f2937;} .card p{margin:0;font-size:15px;color:#475569;line-height:1.48;} .card .action{display:inline-block;margin-top:18px;padding:8px 18px;border-radius:999px;background:#4f6ef7;color:#fff;font-weight:600;text-decoration:none;} .card .action.secondary{background:#e8ecfb;color:#2b3f9e;}
+</style></head><body><div class="card"><h1>%s</h1><p>%s</p>%s</div></body></html>`
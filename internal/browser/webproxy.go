@@ -0,0 +1,38 @@
+package browser
+
+import (
+	"log/slog"
+
+	"chimera/internal/browser/webkit"
+	"chimera/internal/proxy"
+
+	"github.com/gotk3/gotk3/glib"
+)
+
+// unreachableProxy is where web views are sent while the proxy setting is
+// invalid, so their page scripts, images and raw pages fail like the
+// scraper's fetches instead of connecting directly.
+const unreachableProxy = "http://127.0.0.1:0"
+
+// applyWebProxy routes the web views' own requests, for raw pages, script
+// rendering, snapshots and remote resources, through the proxy page
+// fetches use. It can be called from any goroutine.
+func applyWebProxy(raw string) {
+	uri := ""
+	parsed, err := proxy.Parse(raw)
+	switch {
+	case err != nil:
+		slog.Warn("invalid proxy; web views will not connect", "err", err)
+		uri = unreachableProxy
+	case parsed != nil:
+		// WebKit's socks5 proxies already resolve host names remotely.
+		if parsed.Scheme == "socks5h" {
+			parsed.Scheme = "socks5"
+		}
+		uri = parsed.String()
+	}
+	glib.IdleAdd(func() bool {
+		webkit.SetProxy(uri)
+		return false
+	})
+}
@@ -0,0 +1,282 @@
+package browser
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"chimera/internal/llm"
+	"chimera/internal/session"
+	persist "chimera/internal/settings"
+	"chimera/internal/workspace"
+
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// workspacePicker is the header bar switcher between workspaces. Its
+// methods must be called on the GTK main loop.
+type workspacePicker struct {
+	combo    *gtk.ComboBoxText
+	updating bool
+}
+
+func newWorkspacePicker() (*workspacePicker, error) {
+	combo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, fmt.Errorf("create workspace combo: %w", err)
+	}
+	combo.SetName("chimera-workspaces")
+	combo.SetTooltipText("Workspace: each keeps its own pages, mode and model")
+	combo.SetVAlign(gtk.ALIGN_CENTER)
+	return &workspacePicker{combo: combo}, nil
+}
+
+// show lists the workspaces with current selected.
+func (p *workspacePicker) show(list []workspace.Workspace, current string) {
+	p.updating = true
+	defer func() { p.updating = false }()
+
+	p.combo.RemoveAll()
+	for _, w := range list {
+		p.combo.Append(w.Name, w.Name)
+	}
+	p.combo.SetActiveID(current)
+}
+
+// selected returns the chosen workspace; ok is false while the list is
+// being replaced.
+func (p *workspacePicker) selected() (name string, ok bool) {
+	if p.updating {
+		return "", false
+	}
+	return p.combo.GetActiveID(), true
+}
+
+// refreshWorkspaces lists the saved workspaces in picker.
+func (a *App) refreshWorkspaces(picker *workspacePicker) {
+	list, current, err := a.cfg.Workspaces.List()
+	if err != nil {
+		slog.Warn("load workspaces", "err", err)
+		return
+	}
+	picker.show(list, current.Name)
+}
+
+func (a *App) currentWorkspace() workspace.Workspace {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.workspace
+}
+
+// loadWorkspace makes the workspace that was current when Chimera last
+// closed current again.
+func (a *App) loadWorkspace() {
+	_, current, err := a.cfg.Workspaces.List()
+	if err != nil {
+		slog.Warn("load workspaces", "err", err)
+		return
+	}
+	a.useWorkspace(current)
+}
+
+// useWorkspace applies w's model and default mode. The mode last used is
+// forgotten, so links follow w's default until another is picked.
+func (a *App) useWorkspace(w workspace.Workspace) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	model := a.workspace.Model
+	a.workspace = w
+	a.llmLastSet = false
+	if w.Model != model {
+		a.llmClient = llm.NewClient(workspaceLLMConfig(a.cfg.LLMConfig, w))
+		a.cfg.LLM = a.llmClient
+	}
+}
+
+// workspaceLLMConfig returns cfg with the model w composes with.
+func workspaceLLMConfig(cfg llm.Config, w workspace.Workspace) llm.Config {
+	if w.Model != "" {
+		cfg.Model = w.Model
+	}
+	return cfg
+}
+
+// workspaceMode returns the mode the current workspace opens entered URLs
+// in; ok is false when it follows the LLM settings.
+func (a *App) workspaceMode() (useLLM, ok bool) {
+	switch a.currentWorkspace().Mode {
+	case persist.SiteModeReader:
+		return false, true
+	case persist.SiteModeLLM:
+		return a.llmAvailable(), true
+	default:
+		return false, false
+	}
+}
+
+// leaveWorkspace records page, scrolled down by scrollY, as the page open
+// in the current workspace.
+func (a *App) leaveWorkspace(page renderedPage, scrollY int) {
+	var tabs []session.Tab
+	if page.SourceURL != "" {
+		tabs = []session.Tab{{URL: page.SourceURL, Title: page.Title, Mode: sessionMode(page), ScrollY: scrollY}}
+	}
+	if err := a.cfg.Workspaces.Leave(a.currentWorkspace().Name, tabs, 0); err != nil {
+		slog.Warn("save workspace pages", "err", err)
+	}
+}
+
+// switchWorkspace keeps the page in view in the current workspace, makes
+// the workspace called name current and passes it to open on the GTK main
+// loop, so its pages can be shown.
+func (a *App) switchWorkspace(view *viewHost, info *gtk.Label, name string, open func(workspace.Workspace)) {
+	if name == "" || name == a.currentWorkspace().Name {
+		return
+	}
+	page := a.currentPage()
+	scrollPosition(view, func(y int) {
+		a.leaveWorkspace(page, y)
+		next, err := a.cfg.Workspaces.Switch(name)
+		if err != nil {
+			a.setStatus(info, fmt.Sprintf("Workspace error: %v", err))
+			return
+		}
+		a.useWorkspace(next)
+		a.setStatus(info, fmt.Sprintf("Workspace %s", next.Name))
+		open(next)
+	})
+}
+
+// removeWorkspace deletes the current workspace, without keeping its
+// pages, and returns the one that becomes current.
+func (a *App) removeWorkspace() (workspace.Workspace, error) {
+	if err := a.cfg.Workspaces.Remove(a.currentWorkspace().Name); err != nil {
+		return workspace.Workspace{}, err
+	}
+	_, current, err := a.cfg.Workspaces.List()
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+	a.useWorkspace(current)
+	return current, nil
+}
+
+// openWorkspaceDialog asks for the name, default mode and model of a
+// workspace, a new one unless edit is set, and saves it. It returns the
+// workspace saved; ok is false when the user cancelled.
+func (a *App) openWorkspaceDialog(parent *gtk.ApplicationWindow, edit bool) (w workspace.Workspace, ok bool, err error) {
+	title := "New workspace"
+	previous := ""
+	if edit {
+		w = a.currentWorkspace()
+		previous = w.Name
+		title = fmt.Sprintf("Workspace %s", w.Name)
+	}
+
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return w, false, fmt.Errorf("create dialog: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle(title)
+	dialog.SetModal(true)
+	dialog.SetTransientFor(parent)
+	dialog.AddButton("Cancel", gtk.RESPONSE_CANCEL)
+	dialog.AddButton("Save", gtk.RESPONSE_OK)
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		return w, false, fmt.Errorf("access content area: %w", err)
+	}
+
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return w, false, fmt.Errorf("create grid: %w", err)
+	}
+	grid.SetRowSpacing(10)
+	grid.SetColumnSpacing(14)
+	grid.SetMarginTop(14)
+	grid.SetMarginBottom(14)
+	grid.SetMarginStart(18)
+	grid.SetMarginEnd(18)
+
+	nameLabel, err := gtk.LabelNew("Name")
+	if err != nil {
+		return w, false, fmt.Errorf("create name label: %w", err)
+	}
+	nameLabel.SetXAlign(0)
+	grid.Attach(nameLabel, 0, 0, 1, 1)
+
+	nameEntry, err := gtk.EntryNew()
+	if err != nil {
+		return w, false, fmt.Errorf("create name entry: %w", err)
+	}
+	nameEntry.SetPlaceholderText("e.g. Research, News, Docs")
+	nameEntry.SetText(w.Name)
+	nameEntry.SetActivatesDefault(true)
+	nameEntry.SetHExpand(true)
+	grid.Attach(nameEntry, 1, 0, 1, 1)
+
+	modeLabel, err := gtk.LabelNew("Open with")
+	if err != nil {
+		return w, false, fmt.Errorf("create mode label: %w", err)
+	}
+	modeLabel.SetXAlign(0)
+	grid.Attach(modeLabel, 0, 1, 1, 1)
+
+	modeCombo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return w, false, fmt.Errorf("create mode combo: %w", err)
+	}
+	modeCombo.Append("", "Default")
+	modeCombo.Append(persist.SiteModeReader, "Reader mode")
+	modeCombo.Append(persist.SiteModeLLM, "LLM compose")
+	modeCombo.SetActiveID(w.Mode)
+	grid.Attach(modeCombo, 1, 1, 1, 1)
+
+	modelLabel, err := gtk.LabelNew("Model")
+	if err != nil {
+		return w, false, fmt.Errorf("create model label: %w", err)
+	}
+	modelLabel.SetXAlign(0)
+	grid.Attach(modelLabel, 0, 2, 1, 1)
+
+	modelEntry, err := gtk.EntryNew()
+	if err != nil {
+		return w, false, fmt.Errorf("create model entry: %w", err)
+	}
+	modelEntry.SetPlaceholderText("Model from LLM settings")
+	modelEntry.SetText(w.Model)
+	modelEntry.SetActivatesDefault(true)
+	grid.Attach(modelEntry, 1, 2, 1, 1)
+
+	content.Add(grid)
+	dialog.ShowAll()
+
+	if dialog.Run() != gtk.RESPONSE_OK {
+		return w, false, nil
+	}
+	name, err := nameEntry.GetText()
+	if err != nil {
+		return w, false, fmt.Errorf("read name: %w", err)
+	}
+	model, err := modelEntry.GetText()
+	if err != nil {
+		return w, false, fmt.Errorf("read model: %w", err)
+	}
+	// Pages are left out, so the store keeps those it has.
+	w = workspace.Workspace{
+		Name:  strings.TrimSpace(name),
+		Mode:  modeCombo.GetActiveID(),
+		Model: strings.TrimSpace(model),
+	}
+	if err := a.cfg.Workspaces.Put(previous, w); err != nil {
+		return w, false, err
+	}
+	if edit {
+		a.useWorkspace(w)
+	}
+	return w, true, nil
+}
@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"chimera/internal/storage"
+)
+
+// Composition is a single LLM rendering of a page.
+type Composition struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	HTML      string    `json:"html"`
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+	Pinned    bool      `json:"pinned"`
+	// ETag and LastModified are the validators of the page the composition
+	// was made from, used to ask the server whether it changed since.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// CheckedAt is when the page was last confirmed unchanged; zero if never.
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// Store keeps the most recent compositions per URL in a storage.Backend, by
+// default below the user's cache directory.
+type Store struct {
+	backend storage.Backend
+	limit   int
+	mu      sync.Mutex
+}
+
+// NewStore builds a Store that retains up to limit compositions per URL.
+func NewStore(appID string, limit int) (*Store, error) {
+	backend, err := storage.CacheFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend, limit), nil
+}
+
+// NewStoreWith builds a Store kept in backend that retains up to limit
+// compositions per URL; a nil backend gives a nil Store, which stores
+// nothing.
+func NewStoreWith(backend storage.Backend, limit int) *Store {
+	if backend == nil {
+		return nil
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+	return &Store{backend: backend, limit: limit}
+}
+
+// List returns the stored compositions for url, newest first.
+func (s *Store) List(url string) ([]Composition, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read(url)
+}
+
+// Add records a new composition for its URL and evicts the oldest unpinned entries beyond the limit.
+func (s *Store) Add(c Composition) (Composition, error) {
+	if s == nil {
+		return c, nil
+	}
+	if c.URL == "" {
+		return c, errors.New("composition URL is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read(c.URL)
+	if err != nil {
+		return c, err
+	}
+
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	if c.ID == "" {
+		c.ID = strconv.FormatInt(c.CreatedAt.UnixNano(), 36)
+	}
+	c.Pinned = false
+
+	entries = append([]Composition{c}, entries...)
+	for len(entries) > s.limit {
+		idx := oldestUnpinned(entries)
+		if idx < 0 {
+			break
+		}
+		entries = append(entries[:idx], entries[idx+1:]...)
+	}
+
+	if err := s.write(c.URL, entries); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// Pin marks the composition with the given id as the preferred version for url.
+// Passing an empty id clears the pin.
+func (s *Store) Pin(url, id string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read(url)
+	if err != nil {
+		return err
+	}
+
+	found := id == ""
+	for i := range entries {
+		entries[i].Pinned = entries[i].ID == id && id != ""
+		if entries[i].Pinned {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("composition %q not found", id)
+	}
+
+	return s.write(url, entries)
+}
+
+// MarkCurrent records that the page behind composition id of url was
+// unchanged at checkedAt, keeping the validators the server sent then.
+func (s *Store) MarkCurrent(url, id string, checkedAt time.Time, etag, lastModified string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read(url)
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		if entries[i].ID != id {
+			continue
+		}
+		entries[i].CheckedAt = checkedAt
+		entries[i].ETag, entries[i].LastModified = etag, lastModified
+		return s.write(url, entries)
+	}
+	return fmt.Errorf("composition %q not found", id)
+}
+
+// Pinned returns the pinned composition for url, if any.
+func (s *Store) Pinned(url string) (Composition, bool, error) {
+	entries, err := s.List(url)
+	if err != nil {
+		return Composition{}, false, err
+	}
+	for _, c := range entries {
+		if c.Pinned {
+			return c, true, nil
+		}
+	}
+	return Composition{}, false, nil
+}
+
+func (s *Store) read(url string) ([]Composition, error) {
+	bytes, err := s.backend.Get(keyFor(url))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read compositions: %w", err)
+	}
+
+	var entries []Composition
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return nil, fmt.Errorf("decode compositions: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+func (s *Store) write(url string, entries []Composition) error {
+	encoded, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("encode compositions: %w", err)
+	}
+
+	if err := s.backend.Put(keyFor(url), encoded); err != nil {
+		return fmt.Errorf("write compositions: %w", err)
+	}
+
+	return nil
+}
+
+func keyFor(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "compositions/" + hex.EncodeToString(sum[:]) + ".json"
+}
+
+func oldestUnpinned(entries []Composition) int {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].Pinned {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"chimera/internal/storage"
+)
+
+// maxTranslations caps the translation memory; the least recently used
+// translations are dropped first.
+const maxTranslations = 20000
+
+type translation struct {
+	Text   string    `json:"text"`
+	UsedAt time.Time `json:"used_at"`
+}
+
+// Translations is a translation memory: translated text blocks keyed by a
+// hash of their source text and the target language, kept below the user's
+// cache directory so revisited pages and shared boilerplate are not sent again.
+type Translations struct {
+	backend storage.Backend
+	mu      sync.Mutex
+	entries map[string]translation
+}
+
+// NewTranslations builds a translation memory for appID.
+func NewTranslations(appID string) (*Translations, error) {
+	backend, err := storage.CacheFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewTranslationsWith(backend), nil
+}
+
+// NewTranslationsWith builds a translation memory kept in backend; a nil
+// backend gives a nil memory, which remembers nothing.
+func NewTranslationsWith(backend storage.Backend) *Translations {
+	if backend == nil {
+		return nil
+	}
+	return &Translations{backend: backend}
+}
+
+// Lookup returns the remembered translation of each text into language, with
+// "" and false in ok for texts that have none.
+func (t *Translations) Lookup(language string, texts []string) (translated []string, ok []bool, err error) {
+	translated = make([]string, len(texts))
+	ok = make([]bool, len(texts))
+	if t == nil {
+		return translated, ok, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.load(); err != nil {
+		return translated, ok, err
+	}
+	now := time.Now()
+	for i, text := range texts {
+		key := translationKey(language, text)
+		if entry, found := t.entries[key]; found {
+			translated[i], ok[i] = entry.Text, true
+			entry.UsedAt = now
+			t.entries[key] = entry
+		}
+	}
+	return translated, ok, nil
+}
+
+// Add remembers that each of sources translates into the matching entry of
+// translations in language.
+func (t *Translations) Add(language string, sources, translations []string) error {
+	if t == nil {
+		return nil
+	}
+	if len(sources) != len(translations) {
+		return errors.New("translation count does not match sources")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.load(); err != nil {
+		return err
+	}
+	now := time.Now()
+	for i, source := range sources {
+		t.entries[translationKey(language, source)] = translation{Text: translations[i], UsedAt: now}
+	}
+	t.evict()
+	return t.write()
+}
+
+func translationKey(language, text string) string {
+	sum := sha256.Sum256([]byte(language + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *Translations) evict() {
+	if len(t.entries) <= maxTranslations {
+		return
+	}
+	keys := make([]string, 0, len(t.entries))
+	for key := range t.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return t.entries[keys[i]].UsedAt.Before(t.entries[keys[j]].UsedAt)
+	})
+	for _, key := range keys[:len(keys)-maxTranslations] {
+		delete(t.entries, key)
+	}
+}
+
+func (t *Translations) load() error {
+	if t.entries != nil {
+		return nil
+	}
+	bytes, err := t.backend.Get("translations.json")
+	if errors.Is(err, storage.ErrNotFound) {
+		t.entries = make(map[string]translation)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read translations: %w", err)
+	}
+
+	entries := make(map[string]translation)
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return fmt.Errorf("decode translations: %w", err)
+	}
+	t.entries = entries
+	return nil
+}
+
+func (t *Translations) write() error {
+	encoded, err := json.Marshal(t.entries)
+	if err != nil {
+		return fmt.Errorf("encode translations: %w", err)
+	}
+
+	if err := t.backend.Put("translations.json", encoded); err != nil {
+		return fmt.Errorf("write translations: %w", err)
+	}
+	return nil
+}
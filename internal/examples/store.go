@@ -0,0 +1,156 @@
+package examples
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chimera/internal/storage"
+)
+
+// Example is an input/output pair replayed to the LLM as a few-shot demonstration.
+type Example struct {
+	ID string `json:"id"`
+	// Template names the prompt the example belongs to, e.g. "compose" or "summarize".
+	Template  string    `json:"template"`
+	SourceURL string    `json:"source_url,omitempty"`
+	Input     string    `json:"input"`
+	Output    string    `json:"output"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// Store persists examples as JSON in a storage.Backend, by default below
+// the user's configuration directory.
+type Store struct {
+	backend storage.Backend
+	mu      sync.RWMutex
+}
+
+// NewStore builds a Store below the user's configuration directory.
+func NewStore(appID string) (*Store, error) {
+	backend, err := storage.ConfigFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend), nil
+}
+
+// NewStoreWith builds a Store keeping its data in backend; a nil backend gives a nil Store,
+// which stores nothing.
+func NewStoreWith(backend storage.Backend) *Store {
+	if backend == nil {
+		return nil
+	}
+	return &Store{backend: backend}
+}
+
+// List returns the examples for template in the order they were added.
+// An empty template returns every example.
+func (s *Store) List(template string) ([]Example, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.read()
+	if err != nil || template == "" {
+		return entries, err
+	}
+
+	matched := entries[:0]
+	for _, e := range entries {
+		if e.Template == template {
+			matched = append(matched, e)
+		}
+	}
+	return matched, nil
+}
+
+// Add saves e, assigning an ID and timestamp when missing.
+func (s *Store) Add(e Example) (Example, error) {
+	if s == nil {
+		return e, nil
+	}
+	e.Template = strings.TrimSpace(e.Template)
+	if e.Template == "" {
+		return e, errors.New("example template is empty")
+	}
+	if strings.TrimSpace(e.Input) == "" || strings.TrimSpace(e.Output) == "" {
+		return e, errors.New("example input and output are required")
+	}
+	if e.AddedAt.IsZero() {
+		e.AddedAt = time.Now()
+	}
+	if e.ID == "" {
+		e.ID = strconv.FormatInt(e.AddedAt.UnixNano(), 36)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return e, err
+	}
+
+	return e, s.write(append(entries, e))
+}
+
+// Remove deletes the example with id, if present.
+func (s *Store) Remove(id string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.ID != id {
+			kept = append(kept, e)
+		}
+	}
+
+	return s.write(kept)
+}
+
+func (s *Store) read() ([]Example, error) {
+	bytes, err := s.backend.Get("examples.json")
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read examples: %w", err)
+	}
+
+	var entries []Example
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return nil, fmt.Errorf("decode examples: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) write(entries []Example) error {
+	encoded, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode examples: %w", err)
+	}
+
+	if err := s.backend.Put("examples.json", encoded); err != nil {
+		return fmt.Errorf("write examples: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,324 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"chimera/internal/scraper"
+)
+
+// Citation formats.
+const (
+	// CitationBibTeX is a biblatex @online entry, which Zotero, JabRef and
+	// most LaTeX setups import.
+	CitationBibTeX = "bibtex"
+	// CitationCSL is a CSL-JSON item, the format of Zotero and Pandoc.
+	CitationCSL = "csl-json"
+)
+
+// authorSeparator splits a byline naming several people.
+var authorSeparator = regexp.MustCompile(`\s*(?:,|;|&|\band\b)\s*`)
+
+// Citation is a page to be cited.
+type Citation struct {
+	// URL is the address cited; pass the canonical URL.
+	URL string
+	// Accessed is when the page was read.
+	Accessed time.Time
+	Result   *scraper.Result
+}
+
+// FormatCitation renders citation in format, one of CitationBibTeX and
+// CitationCSL.
+func FormatCitation(format string, citation Citation) (string, error) {
+	if citation.Result == nil {
+		return "", errors.New("nothing to cite")
+	}
+	switch format {
+	case CitationBibTeX, "":
+		return bibTeX(citation, citationKey(citation)), nil
+	case CitationCSL:
+		data, err := encodeCSL(newCSLItem(citation, citationKey(citation)))
+		if err != nil {
+			return "", fmt.Errorf("encode citation: %w", err)
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unknown citation format %q", format)
+	}
+}
+
+// AppendCitation adds citation to the bibliography file at path: a BibTeX
+// entry at the end of a .bib file, or an item in the JSON array of a
+// CSL-JSON file. A page whose URL is already in the file is not added
+// again, and existing is set; a key taken by another page gets a letter.
+func AppendCitation(path, format string, citation Citation) (existing bool, err error) {
+	if citation.Result == nil {
+		return false, errors.New("nothing to cite")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("create bibliography dir: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("read bibliography: %w", err)
+	}
+
+	if format == CitationCSL {
+		var items []map[string]any
+		if len(strings.TrimSpace(string(data))) > 0 {
+			if err := json.Unmarshal(data, &items); err != nil {
+				return false, fmt.Errorf("read bibliography: %w", err)
+			}
+		}
+		keys := make(map[string]bool)
+		for _, item := range items {
+			if item["URL"] == citation.URL {
+				return true, nil
+			}
+			if id, ok := item["id"].(string); ok {
+				keys[id] = true
+			}
+		}
+		key := uniqueKey(citationKey(citation), func(key string) bool { return keys[key] })
+		encoded, err := json.Marshal(newCSLItem(citation, key))
+		if err != nil {
+			return false, fmt.Errorf("encode citation: %w", err)
+		}
+		var item map[string]any
+		if err := json.Unmarshal(encoded, &item); err != nil {
+			return false, fmt.Errorf("encode citation: %w", err)
+		}
+		out, err := encodeCSL(append(items, item))
+		if err != nil {
+			return false, fmt.Errorf("encode bibliography: %w", err)
+		}
+		if err := os.WriteFile(path, out, 0o644); err != nil {
+			return false, fmt.Errorf("write bibliography: %w", err)
+		}
+		return false, nil
+	}
+
+	if strings.Contains(string(data), "url = {"+citation.URL+"}") {
+		return true, nil
+	}
+	key := uniqueKey(citationKey(citation), func(key string) bool {
+		return strings.Contains(string(data), "{"+key+",")
+	})
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return false, fmt.Errorf("open bibliography: %w", err)
+	}
+	entry := bibTeX(citation, key)
+	if len(data) > 0 {
+		entry = "\n" + entry
+	}
+	if _, err := file.WriteString(entry); err != nil {
+		file.Close()
+		return false, fmt.Errorf("write bibliography: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return false, fmt.Errorf("write bibliography: %w", err)
+	}
+	return false, nil
+}
+
+// uniqueKey appends a letter to key while taken reports it in use, as
+// "doe2024rust" becomes "doe2024rustb".
+func uniqueKey(key string, taken func(string) bool) string {
+	candidate := key
+	for suffix := 'b'; taken(candidate) && suffix <= 'z'; suffix++ {
+		candidate = key + string(suffix)
+	}
+	return candidate
+}
+
+// bibTeX renders citation as a biblatex @online entry.
+func bibTeX(citation Citation, key string) string {
+	result := citation.Result
+	var b strings.Builder
+	fmt.Fprintf(&b, "@online{%s,\n", key)
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "  %s = {%s},\n", name, value)
+		}
+	}
+	field("title", "{"+bibEscape(citationTitle(citation))+"}")
+	if authors := citationAuthors(result.Author); len(authors) > 0 {
+		for i, author := range authors {
+			authors[i] = bibEscape(author)
+		}
+		field("author", strings.Join(authors, " and "))
+	}
+	field("organization", bibEscape(citationSite(citation)))
+	if !result.PublishedAt.IsZero() {
+		field("date", result.PublishedAt.Format("2006-01-02"))
+	}
+	field("url", citation.URL)
+	field("urldate", citation.Accessed.Format("2006-01-02"))
+	field("langid", result.Language)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// cslItem is a CSL-JSON webpage item.
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	Author         []cslName `json:"author,omitempty"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	Accessed       *cslDate  `json:"accessed"`
+	URL            string    `json:"URL"`
+	Abstract       string    `json:"abstract,omitempty"`
+	Language       string    `json:"language,omitempty"`
+}
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+func newCSLItem(citation Citation, key string) cslItem {
+	result := citation.Result
+	item := cslItem{
+		ID:             key,
+		Type:           "webpage",
+		Title:          citationTitle(citation),
+		ContainerTitle: citationSite(citation),
+		Accessed:       newCSLDate(citation.Accessed),
+		URL:            citation.URL,
+		Abstract:       result.Description,
+		Language:       result.Language,
+	}
+	for _, author := range citationAuthors(result.Author) {
+		item.Author = append(item.Author, splitName(author))
+	}
+	if !result.PublishedAt.IsZero() {
+		item.Issued = newCSLDate(result.PublishedAt)
+	}
+	return item
+}
+
+// encodeCSL indents v without escaping &, < and >, which titles often contain.
+func encodeCSL(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newCSLDate(t time.Time) *cslDate {
+	return &cslDate{DateParts: [][]int{{t.Year(), int(t.Month()), t.Day()}}}
+}
+
+// citationKey builds a key such as "doe2024rust" from the first author's
+// family name, or the site, the year and the first long word of the title.
+func citationKey(citation Citation) string {
+	result := citation.Result
+	name := citationSite(citation)
+	if authors := citationAuthors(result.Author); len(authors) > 0 {
+		name = splitName(authors[0]).Family
+		if name == "" {
+			name = authors[0]
+		}
+	}
+	year := ""
+	if !result.PublishedAt.IsZero() {
+		year = result.PublishedAt.Format("2006")
+	}
+	word := ""
+	for _, w := range strings.Fields(citationTitle(citation)) {
+		if w = keyPart(w); len(w) > 3 {
+			word = w
+			break
+		}
+	}
+	key := keyPart(strings.ReplaceAll(name, " ", "")) + year + word
+	if key == "" {
+		key = "page"
+	}
+	return key
+}
+
+// keyPart lowercases text and keeps only ASCII letters and digits, which
+// every BibTeX implementation accepts in a key.
+func keyPart(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func citationTitle(citation Citation) string {
+	if title := markdownLine(citation.Result.Title); title != "" {
+		return title
+	}
+	return citation.URL
+}
+
+// citationSite returns the site's own name, or its host without "www.".
+func citationSite(citation Citation) string {
+	if citation.Result.SiteName != "" {
+		return citation.Result.SiteName
+	}
+	if parsed, err := url.Parse(citation.URL); err == nil {
+		return strings.TrimPrefix(parsed.Hostname(), "www.")
+	}
+	return ""
+}
+
+// citationAuthors splits a byline such as "Jane Doe and John Roe". A single
+// "Doe, Jane" is read as one name in family-first order.
+func citationAuthors(byline string) []string {
+	if family, given, ok := strings.Cut(byline, ","); ok && !strings.ContainsAny(given, ",;&") &&
+		len(strings.Fields(family)) == 1 && len(strings.Fields(given)) == 1 {
+		return []string{strings.TrimSpace(given) + " " + strings.TrimSpace(family)}
+	}
+	var authors []string
+	for _, name := range authorSeparator.Split(byline, -1) {
+		if name = strings.TrimSpace(name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	return authors
+}
+
+// splitName splits a person's name at its last word; a single word, such
+// as an organisation's name, is kept whole.
+func splitName(name string) cslName {
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return cslName{Literal: name}
+	}
+	return cslName{Family: words[len(words)-1], Given: strings.Join(words[:len(words)-1], " ")}
+}
+
+// bibEscape escapes the characters that are special in BibTeX values.
+func bibEscape(text string) string {
+	return strings.NewReplacer(
+		`\`, `\textbackslash{}`, "{", `\{`, "}", `\}`,
+		"&", `\&`, "%", `\%`, "$", `\$`, "#", `\#`, "_", `\_`,
+	).Replace(text)
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"chimera/internal/scraper"
+)
+
+// Markdown renders the extracted content of result as Markdown: the
+// description, an outline of the headings, the paragraphs with their
+// footnotes and formulas, the code blocks and the links from the text.
+func Markdown(result *scraper.Result) string {
+	var b strings.Builder
+
+	title := result.Title
+	if title == "" {
+		title = result.SourceURL
+	}
+	fmt.Fprintf(&b, "# %s\n\n", markdownLine(title))
+	if result.Description != "" {
+		fmt.Fprintf(&b, "> %s\n\n", markdownLine(result.Description))
+	}
+
+	if len(result.Headings) > 0 {
+		b.WriteString("## Contents\n\n")
+		for _, h := range result.Headings {
+			fmt.Fprintf(&b, "%s- %s\n", strings.Repeat("  ", max(h.Level-1, 0)), markdownLine(h.Text))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Paragraphs) > 0 {
+		b.WriteString("## Highlights\n\n")
+		for _, p := range result.Paragraphs {
+			b.WriteString(markdownFormulas(result.Formulas, markdownLine(p)))
+			b.WriteString("\n\n")
+		}
+	}
+
+	if len(result.CodeBlocks) > 0 {
+		b.WriteString("## Code\n\n")
+		for _, block := range result.CodeBlocks {
+			fence := "```"
+			for strings.Contains(block.Code, fence) {
+				fence += "`"
+			}
+			fmt.Fprintf(&b, "%s%s\n%s\n%s\n\n", fence, block.Language, strings.TrimRight(block.Code, "\n"), fence)
+		}
+	}
+
+	var links []scraper.Link
+	for _, link := range result.Links {
+		if link.Class == scraper.LinkContent || link.Class == "" {
+			links = append(links, link)
+		}
+	}
+	if len(links) > 0 {
+		b.WriteString("## Links\n\n")
+		for _, link := range links {
+			text := markdownLine(link.Text)
+			if text == "" {
+				text = link.Href
+			}
+			fmt.Fprintf(&b, "- [%s](<%s>)\n", strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text), link.Href)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, note := range result.Footnotes {
+		fmt.Fprintf(&b, "[^%s]: %s\n", note.Label, markdownLine(note.Text))
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// markdownLine collapses whitespace so text cannot start a new block.
+func markdownLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// markdownFormulas replaces [math:id] markers with the formula's TeX in
+// dollar delimiters, which Obsidian and most Markdown renderers display.
+func markdownFormulas(formulas []scraper.Formula, paragraph string) string {
+	return scraper.MathRefPattern.ReplaceAllStringFunc(paragraph, func(marker string) string {
+		id := scraper.MathRefPattern.FindStringSubmatch(marker)[1]
+		for _, f := range formulas {
+			if f.ID != id || f.TeX == "" {
+				continue
+			}
+			if f.Display {
+				return "$$" + f.TeX + "$$"
+			}
+			return "$" + f.TeX + "$"
+		}
+		return marker
+	})
+}
@@ -0,0 +1,136 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"chimera/internal/render"
+	"chimera/internal/scraper"
+)
+
+// SitePage is a single clipping in a static site export.
+type SitePage struct {
+	URL     string
+	Title   string
+	AddedAt time.Time
+	Result  *scraper.Result
+}
+
+type siteEntry struct {
+	SitePage
+	Path string
+}
+
+// WriteSite renders pages with the reader template into dir, alongside an
+// index.html linking to each of them.
+func WriteSite(dir, title string, pages []SitePage) error {
+	pagesDir := filepath.Join(dir, "pages")
+	if err := os.MkdirAll(pagesDir, 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	used := make(map[string]bool)
+	entries := make([]siteEntry, 0, len(pages))
+	for _, page := range pages {
+		if page.Result == nil {
+			continue
+		}
+		if page.Title == "" {
+			page.Title = page.Result.Title
+		}
+		if page.Title == "" {
+			page.Title = page.URL
+		}
+
+		// Pages whose URLs slug alike get -2, -3 and so on, skipping names
+		// that other pages' URLs slug to.
+		slug := Slug(page.URL)
+		name := slug
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", slug, n)
+		}
+		used[name] = true
+		rel := "pages/" + name + ".html"
+
+		html, err := render.Simple(page.Result, render.Options{})
+		if err != nil {
+			return fmt.Errorf("render %s: %w", page.URL, err)
+		}
+		html = strings.Replace(html, "<body>", `<body>`+"\n"+`<nav><a href="../index.html">← `+template.HTMLEscapeString(title)+`</a></nav>`, 1)
+
+		if err := os.WriteFile(filepath.Join(dir, rel), []byte(html), 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", rel, err)
+		}
+		entries = append(entries, siteEntry{SitePage: page, Path: rel})
+	}
+
+	var index strings.Builder
+	if err := siteIndexTmpl.Execute(&index, struct {
+		Title     string
+		Generated time.Time
+		Entries   []siteEntry
+	}{title, time.Now(), entries}); err != nil {
+		return fmt.Errorf("render index: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(index.String()), 0o644); err != nil {
+		return fmt.Errorf("write index: %w", err)
+	}
+	return nil
+}
+
+// Slug turns a URL into a file-name friendly string.
+func Slug(source string) string {
+	parsed, err := url.Parse(source)
+	if err != nil || parsed.Host == "" {
+		return "chimera-page"
+	}
+
+	name := parsed.Host + strings.TrimSuffix(parsed.Path, "/")
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+	name = strings.Trim(name, "-.")
+	if name == "" {
+		return "chimera-page"
+	}
+	return name
+}
+
+var siteIndexTmpl = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>{{ .Title }}</title>
+<style>
+body { font-family: "Inter", "Segoe UI", sans-serif; margin: 0 auto; max-width: 960px; padding: 2rem; background: #f5f7fb; color: #1d2433; }
+header { border-bottom: 1px solid #d4d9e2; margin-bottom: 1.5rem; padding-bottom: 1rem; }
+h1 { margin: 0 0 .5rem 0; font-size: 2.4rem; }
+ol { list-style: none; padding: 0; }
+li { margin-bottom: 1rem; background: #fff; border-radius: 12px; padding: 1rem 1.5rem; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+a { color: #2b5dcc; text-decoration: none; }
+a:hover { text-decoration: underline; }
+small { color: #5b6576; }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{ .Title }}</h1>
+  <small>{{ len .Entries }} pages • generated {{ .Generated.Format "02 Jan 2006" }}</small>
+</header>
+<ol>
+  {{ range .Entries }}<li><a href="{{ .Path }}"><strong>{{ .Title }}</strong></a><br><small><a href="{{ .URL }}">{{ .URL }}</a>{{ if not .AddedAt.IsZero }} • saved {{ .AddedAt.Format "02 Jan 2006" }}{{ end }}</small>{{ if .Result.Description }}<p>{{ .Result.Description }}</p>{{ end }}</li>
+  {{ end }}
+</ol>
+</body>
+</html>`))
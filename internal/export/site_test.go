@@ -0,0 +1,48 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chimera/internal/scraper"
+)
+
+func TestSite_WriteNamesPagesApart(t *testing.T) {
+	urls := []string{
+		"https://example.com/x?page=1",
+		"https://example.com/x?page=2",
+		"https://example.com/x-2",
+		"https://example.com/x/",
+	}
+	var pages []SitePage
+	for _, u := range urls {
+		pages = append(pages, SitePage{URL: u, Result: &scraper.Result{SourceURL: u, Title: "Page " + u}})
+	}
+
+	dir := t.TempDir()
+	if err := WriteSite(dir, "Clippings", pages); err != nil {
+		t.Fatal(err)
+	}
+	files, err := os.ReadDir(filepath.Join(dir, "pages"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(urls) {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Fatalf("wrote %d pages (%s), want %d", len(files), strings.Join(names, ", "), len(urls))
+	}
+	for _, f := range files {
+		html, err := os.ReadFile(filepath.Join(dir, "pages", f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(html), "Page https://example.com/") {
+			t.Errorf("%s does not hold a page title", f.Name())
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package export
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Options controls how a page is turned into a standalone document.
+type Options struct {
+	// Fetch downloads an asset of at most limit bytes and returns its
+	// content type, such as (*scraper.Scraper).Asset does, so assets share
+	// the scraper's proxy, headers and rate limits. Nil fetches them with
+	// HTTPClient, or a plain client when that is nil too.
+	Fetch         func(ctx context.Context, target string, limit int64) ([]byte, string, error)
+	HTTPClient    *http.Client
+	SourceURL     string
+	Model         string
+	MaxAssetBytes int64
+	ExportedAt    time.Time
+}
+
+// Standalone inlines stylesheets and images referenced by page and appends a
+// source attribution footer, producing a single self-contained HTML document.
+// Assets that fail to download are left untouched.
+func Standalone(ctx context.Context, page string, opts Options) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return "", fmt.Errorf("parse page: %w", err)
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	limit := opts.MaxAssetBytes
+	if limit <= 0 {
+		limit = 4 * 1024 * 1024
+	}
+	if opts.ExportedAt.IsZero() {
+		opts.ExportedAt = time.Now()
+	}
+
+	base, _ := url.Parse(opts.SourceURL)
+	fetcher := &assetFetcher{fetchURL: opts.Fetch, client: client, base: base, limit: limit}
+
+	doc.Find("link[rel='stylesheet'][href]").Each(func(_ int, sel *goquery.Selection) {
+		href, _ := sel.Attr("href")
+		body, _, err := fetcher.fetch(ctx, href)
+		if err != nil {
+			slog.Warn("export skips stylesheet", "href", href, "err", err)
+			return
+		}
+		css := absolutizeCSS(string(body), fetcher.resolve(href))
+		sel.ReplaceWithHtml("<style>" + strings.ReplaceAll(css, "</style", `<\/style`) + "</style>")
+	})
+
+	doc.Find("img[src]").Each(func(_ int, sel *goquery.Selection) {
+		src, _ := sel.Attr("src")
+		if strings.HasPrefix(src, "data:") {
+			return
+		}
+		body, contentType, err := fetcher.fetch(ctx, src)
+		if err != nil {
+			slog.Warn("export skips image", "src", src, "err", err)
+			return
+		}
+		sel.SetAttr("src", "data:"+contentType+";base64,"+base64.StdEncoding.EncodeToString(body))
+		sel.RemoveAttr("srcset")
+	})
+
+	body := doc.Find("body")
+	if body.Length() == 0 {
+		body = doc.Selection
+	}
+	body.AppendHtml(attributionFooter(opts))
+
+	rendered, err := goquery.OuterHtml(doc.Selection)
+	if err != nil {
+		return "", fmt.Errorf("render page: %w", err)
+	}
+	return "<!DOCTYPE html>\n" + rendered, nil
+}
+
+func attributionFooter(opts Options) string {
+	var b strings.Builder
+	b.WriteString(`<footer class="chimera-attribution" style="margin:3rem auto 1rem;max-width:960px;padding-top:1rem;border-top:1px solid #d4d9e2;font:13px/1.5 sans-serif;color:#5b6576;">`)
+	b.WriteString("Exported from Chimera on ")
+	b.WriteString(template.HTMLEscapeString(opts.ExportedAt.Format("02 Jan 2006 15:04 MST")))
+	if opts.SourceURL != "" {
+		b.WriteString(`. Original source: <a href="`)
+		b.WriteString(template.HTMLEscapeString(opts.SourceURL))
+		b.WriteString(`">`)
+		b.WriteString(template.HTMLEscapeString(opts.SourceURL))
+		b.WriteString("</a>")
+	}
+	if opts.Model != "" {
+		b.WriteString(". Composed with ")
+		b.WriteString(template.HTMLEscapeString(opts.Model))
+	}
+	b.WriteString(".</footer>")
+	return b.String()
+}
+
+var cssURLPattern = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)`)
+
+// absolutizeCSS rewrites relative url() references so they keep working once
+// the stylesheet is inlined into the document.
+func absolutizeCSS(css string, sheet *url.URL) string {
+	if sheet == nil {
+		return css
+	}
+	return cssURLPattern.ReplaceAllStringFunc(css, func(match string) string {
+		parts := cssURLPattern.FindStringSubmatch(match)
+		ref := parts[2]
+		if strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+			return match
+		}
+		parsed, err := url.Parse(ref)
+		if err != nil {
+			return match
+		}
+		return "url(" + parts[1] + sheet.ResolveReference(parsed).String() + parts[3] + ")"
+	})
+}
+
+type assetFetcher struct {
+	fetchURL func(ctx context.Context, target string, limit int64) ([]byte, string, error)
+	client   *http.Client
+	base     *url.URL
+	limit    int64
+}
+
+func (f *assetFetcher) resolve(ref string) *url.URL {
+	target, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return nil
+	}
+	if f.base != nil {
+		target = f.base.ResolveReference(target)
+	}
+	return target
+}
+
+func (f *assetFetcher) fetch(ctx context.Context, ref string) ([]byte, string, error) {
+	target := f.resolve(ref)
+	if target == nil {
+		return nil, "", fmt.Errorf("invalid asset URL %q", ref)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, "", fmt.Errorf("unsupported scheme %q", target.Scheme)
+	}
+
+	fetch := f.fetchURL
+	if fetch == nil {
+		fetch = f.get
+	}
+	body, contentType, err := fetch(ctx, target.String(), f.limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(target.Path))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+
+	return body, contentType, nil
+}
+
+// get downloads target with f's client, refusing bodies over limit bytes.
+func (f *assetFetcher) get(ctx context.Context, target string, limit int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(body)) > limit {
+		return nil, "", fmt.Errorf("asset exceeds %d bytes", limit)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
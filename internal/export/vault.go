@@ -0,0 +1,148 @@
+package export
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"chimera/internal/scraper"
+)
+
+// maxNoteNameRunes bounds the file name of a clipped note.
+const maxNoteNameRunes = 80
+
+// Clip is a page to be written into a Markdown vault.
+type Clip struct {
+	// URL identifies the page in the vault; pass its canonical URL so
+	// tracking-parameter variants are recognised as the same page.
+	URL    string
+	Tags   []string
+	Result *scraper.Result
+}
+
+// ClipToVault writes clip as a Markdown note with YAML front matter into
+// dir, named after the page title. When a note in dir already has the same
+// url in its front matter it is left alone, so edits made in the vault are
+// kept, and its path is returned with existing set.
+func ClipToVault(dir string, clip Clip, now time.Time) (path string, existing bool, err error) {
+	if clip.Result == nil {
+		return "", false, errors.New("nothing to clip")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", false, fmt.Errorf("create vault dir: %w", err)
+	}
+
+	notes, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return "", false, fmt.Errorf("list vault notes: %w", err)
+	}
+	for _, note := range notes {
+		if noteURL(note) == clip.URL {
+			return note, true, nil
+		}
+	}
+
+	base := noteName(clip.Result.Title)
+	if base == "" {
+		base = Slug(clip.URL)
+	}
+	path = filepath.Join(dir, base+".md")
+	for n := 2; fileExists(path); n++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s %d.md", base, n))
+	}
+
+	note := frontMatter(clip, now) + "\n" + Markdown(clip.Result)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", false, fmt.Errorf("create note: %w", err)
+	}
+	if _, err := file.WriteString(note); err != nil {
+		file.Close()
+		return "", false, fmt.Errorf("write note: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", false, fmt.Errorf("write note: %w", err)
+	}
+	return path, false, nil
+}
+
+func frontMatter(clip Clip, now time.Time) string {
+	result := clip.Result
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "url: %s\n", strconv.Quote(clip.URL))
+	if result.Title != "" {
+		fmt.Fprintf(&b, "title: %s\n", strconv.Quote(result.Title))
+	}
+	if result.Author != "" {
+		fmt.Fprintf(&b, "author: %s\n", strconv.Quote(result.Author))
+	}
+	if !result.PublishedAt.IsZero() {
+		fmt.Fprintf(&b, "published: %s\n", result.PublishedAt.Format("2006-01-02"))
+	}
+	fmt.Fprintf(&b, "date: %s\n", now.Format("2006-01-02"))
+	if len(clip.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, tag := range clip.Tags {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(tag))
+		}
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+// noteURL returns the url field of the note's front matter, or "".
+func noteURL(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return ""
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "---" {
+			break
+		}
+		value, ok := strings.CutPrefix(line, "url:")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return strings.Trim(value, `'"`)
+	}
+	return ""
+}
+
+// noteName turns a title into a file name that Obsidian accepts: without
+// path separators or the characters it reserves for links.
+func noteName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|#^[]`, r) || r < ' ' {
+			return ' '
+		}
+		return r
+	}, title)
+	name = strings.Join(strings.Fields(name), " ")
+	if runes := []rune(name); len(runes) > maxNoteNameRunes {
+		name = strings.TrimSpace(string(runes[:maxNoteNameRunes]))
+	}
+	return strings.TrimLeft(name, ".")
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,235 @@
+// Package gate limits how many requests run at once and lines the others
+// up, as the scraper does for page fetches and the LLM client for each
+// provider.
+package gate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Class orders requests competing for a Gate's slots.
+type Class int
+
+const (
+	// Interactive is for requests someone is waiting for.
+	Interactive Class = iota
+	// Background is for work nobody is watching. It gets a slot only while
+	// no interactive request is waiting, and never the last free one unless
+	// the limit is one, where it would otherwise never run. An interactive
+	// request may then wait for one background request to finish.
+	Background
+)
+
+// Load describes the requests competing for a Gate's slots.
+type Load struct {
+	// Limit is the number of requests that may run at once.
+	Limit       int
+	Interactive Stats
+	Background  Stats
+}
+
+// Stats counts the requests of one class.
+type Stats struct {
+	InFlight int
+	Waiting  int
+	// Sent is the number of requests that got a slot since the Gate was
+	// created, and AverageWait and LongestWait how long they queued for it.
+	Sent        int
+	AverageWait time.Duration
+	LongestWait time.Duration
+}
+
+// Gate lets a limited number of requests run at once. Waiting requests get
+// free slots in the order they came, interactive ones first. A nil Gate
+// lets every request run.
+type Gate struct {
+	mu      sync.Mutex
+	limit   int
+	active  [2]int
+	queue   [2][]*waiter
+	sent    [2]int
+	waited  [2]time.Duration
+	longest [2]time.Duration
+}
+
+// waiter is a request waiting for a slot. ready is closed once it has one;
+// moved is signalled when the requests ahead of it change.
+type waiter struct {
+	ready   chan struct{}
+	moved   chan struct{}
+	since   time.Time
+	granted bool
+}
+
+// New returns a Gate letting limit requests run at once; zero or less
+// allows one.
+func New(limit int) *Gate {
+	return &Gate{limit: max(limit, 1)}
+}
+
+// SetLimit changes how many requests may run at once, starting waiting
+// requests if it grew. Requests already running are not stopped.
+func (g *Gate) SetLimit(limit int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.limit = max(limit, 1)
+	g.dispatch()
+}
+
+// Acquire waits until a request of class c may run and returns the
+// function that frees its slot, which must be called exactly once. While
+// it waits, moved is called with its place in line, 1 when it runs next,
+// each time that changes. It gives up when ctx is done.
+func (g *Gate) Acquire(ctx context.Context, c Class, moved func(position int)) (func(), error) {
+	if g == nil {
+		return func() {}, nil
+	}
+	g.mu.Lock()
+	if len(g.queue[c]) == 0 && g.canStart(c) {
+		g.start(c, 0)
+		g.mu.Unlock()
+		return func() { g.release(c) }, nil
+	}
+	w := &waiter{ready: make(chan struct{}), moved: make(chan struct{}, 1), since: time.Now()}
+	g.queue[c] = append(g.queue[c], w)
+	if c == Interactive {
+		// Waiting background requests fall back behind it.
+		g.moved()
+	}
+	position := g.position(c, w)
+	g.mu.Unlock()
+
+	reported := 0
+	for {
+		if moved != nil && position > 0 && position != reported {
+			moved(position)
+			reported = position
+		}
+		select {
+		case <-w.ready:
+			return func() { g.release(c) }, nil
+		case <-w.moved:
+			g.mu.Lock()
+			position = g.position(c, w)
+			g.mu.Unlock()
+		case <-ctx.Done():
+			g.leave(c, w)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Load reports the running and waiting requests by class.
+func (g *Gate) Load() Load {
+	if g == nil {
+		return Load{}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	of := func(c Class) Stats {
+		s := Stats{InFlight: g.active[c], Waiting: len(g.queue[c]), Sent: g.sent[c], LongestWait: g.longest[c]}
+		if g.sent[c] > 0 {
+			s.AverageWait = g.waited[c] / time.Duration(g.sent[c])
+		}
+		return s
+	}
+	return Load{Limit: g.limit, Interactive: of(Interactive), Background: of(Background)}
+}
+
+func (g *Gate) release(c Class) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.active[c]--
+	g.dispatch()
+}
+
+// leave takes w out of line after its request gave up.
+func (g *Gate) leave(c Class, w *waiter) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if w.granted {
+		// The slot arrived as ctx ended; pass it on.
+		g.active[c]--
+		g.dispatch()
+		return
+	}
+	for i, queued := range g.queue[c] {
+		if queued == w {
+			g.queue[c] = append(g.queue[c][:i], g.queue[c][i+1:]...)
+			break
+		}
+	}
+	// A background request may have been held back by this one.
+	g.dispatch()
+	g.moved()
+}
+
+// canStart reports whether a request of class c may take a slot now.
+// Background requests leave one slot free for interactive ones and wait
+// while any interactive request does. The caller holds g.mu.
+func (g *Gate) canStart(c Class) bool {
+	total := g.active[Interactive] + g.active[Background]
+	if c == Interactive {
+		return total < g.limit
+	}
+	return len(g.queue[Interactive]) == 0 && total < max(g.limit-1, 1)
+}
+
+// dispatch hands free slots to waiting requests and tells the rest when
+// they moved up. The caller holds g.mu.
+func (g *Gate) dispatch() {
+	started := 0
+	for _, c := range []Class{Interactive, Background} {
+		for len(g.queue[c]) > 0 && g.canStart(c) {
+			w := g.queue[c][0]
+			g.queue[c] = g.queue[c][1:]
+			w.granted = true
+			g.start(c, time.Since(w.since))
+			started++
+			close(w.ready)
+		}
+	}
+	if started > 0 {
+		g.moved()
+	}
+}
+
+// start counts a request of class c taking a slot after waiting for wait.
+// The caller holds g.mu.
+func (g *Gate) start(c Class, wait time.Duration) {
+	g.active[c]++
+	g.sent[c]++
+	g.waited[c] += wait
+	g.longest[c] = max(g.longest[c], wait)
+}
+
+// moved tells every waiting request that its place in line may have
+// changed. The caller holds g.mu.
+func (g *Gate) moved() {
+	for _, queue := range g.queue {
+		for _, w := range queue {
+			select {
+			case w.moved <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// position returns w's place in line, counting the interactive requests
+// ahead of a background one, or zero once it left the line. The caller
+// holds g.mu.
+func (g *Gate) position(c Class, w *waiter) int {
+	ahead := 0
+	if c == Background {
+		ahead = len(g.queue[Interactive])
+	}
+	for i, queued := range g.queue[c] {
+		if queued == w {
+			return ahead + i + 1
+		}
+	}
+	return 0
+}
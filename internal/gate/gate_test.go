@@ -0,0 +1,176 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquired starts an Acquire of class c in the background and returns a
+// channel that receives its release function once it has a slot.
+func acquired(t *testing.T, ctx context.Context, g *Gate, c Class, moved func(int)) (<-chan func(), <-chan error) {
+	t.Helper()
+	slots, errs := make(chan func(), 1), make(chan error, 1)
+	go func() {
+		release, err := g.Acquire(ctx, c, moved)
+		if err != nil {
+			errs <- err
+			return
+		}
+		slots <- release
+	}()
+	return slots, errs
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func mustAcquire(t *testing.T, g *Gate, c Class) func() {
+	t.Helper()
+	release, err := g.Acquire(context.Background(), c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return release
+}
+
+func TestGate_Order(t *testing.T) {
+	g := New(2)
+	first := mustAcquire(t, g, Interactive)
+	mustAcquire(t, g, Interactive)
+
+	background, _ := acquired(t, context.Background(), g, Background, nil)
+	waitFor(t, func() bool { return g.Load().Background.Waiting == 1 })
+	interactive, _ := acquired(t, context.Background(), g, Interactive, nil)
+	waitFor(t, func() bool { return g.Load().Interactive.Waiting == 1 })
+
+	first()
+	select {
+	case release := <-interactive:
+		release()
+	case <-background:
+		t.Fatal("background request started before the waiting interactive one")
+	case <-time.After(2 * time.Second):
+		t.Fatal("interactive request did not start")
+	}
+
+	// One slot is free, and background requests leave the last one alone.
+	select {
+	case <-background:
+		t.Fatal("background request took the last free slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	load := g.Load()
+	if load.Limit != 2 || load.Interactive.InFlight != 1 || load.Interactive.Sent != 3 || load.Background.Waiting != 1 {
+		t.Errorf("load = %+v", load)
+	}
+}
+
+func TestGate_BackgroundWithOneSlot(t *testing.T) {
+	g := New(1)
+	release := mustAcquire(t, g, Background)
+	release()
+	mustAcquire(t, g, Background)
+}
+
+func TestGate_Positions(t *testing.T) {
+	g := New(1)
+	first := mustAcquire(t, g, Interactive)
+
+	_, _ = acquired(t, context.Background(), g, Interactive, nil)
+	waitFor(t, func() bool { return g.Load().Interactive.Waiting == 1 })
+
+	positions := make(chan int, 10)
+	second, _ := acquired(t, context.Background(), g, Interactive, func(position int) { positions <- position })
+	for _, want := range []int{2, 1} {
+		select {
+		case got := <-positions:
+			if got != want {
+				t.Fatalf("position %d, want %d", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("position %d not reported", want)
+		}
+		if want == 2 {
+			first()
+		}
+	}
+	select {
+	case <-second:
+		t.Fatal("third request started while the second runs")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestGate_CancelledWaiter(t *testing.T) {
+	g := New(1)
+	release := mustAcquire(t, g, Interactive)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, errs := acquired(t, ctx, g, Interactive, nil)
+	waitFor(t, func() bool { return g.Load().Interactive.Waiting == 1 })
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if load := g.Load(); load.Interactive.Waiting != 0 || load.Interactive.InFlight != 1 {
+		t.Errorf("load after cancel = %+v", load)
+	}
+
+	release()
+	mustAcquire(t, g, Interactive)
+}
+
+func TestGate_GrantedAsCancelled(t *testing.T) {
+	g := New(1)
+	release := mustAcquire(t, g, Interactive)
+
+	// The slot is handed over under g.mu, so leave sees it granted.
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &waiter{ready: make(chan struct{}), moved: make(chan struct{}, 1), since: time.Now()}
+	g.mu.Lock()
+	g.queue[Interactive] = append(g.queue[Interactive], w)
+	g.mu.Unlock()
+	release()
+	cancel()
+	<-ctx.Done()
+	g.leave(Interactive, w)
+
+	if load := g.Load(); load.Interactive.InFlight != 0 {
+		t.Errorf("slot not passed on: %+v", load)
+	}
+	mustAcquire(t, g, Interactive)
+}
+
+func TestGate_SetLimit(t *testing.T) {
+	g := New(1)
+	mustAcquire(t, g, Interactive)
+	waiting, _ := acquired(t, context.Background(), g, Interactive, nil)
+	waitFor(t, func() bool { return g.Load().Interactive.Waiting == 1 })
+
+	g.SetLimit(2)
+	select {
+	case <-waiting:
+	case <-time.After(2 * time.Second):
+		t.Fatal("raising the limit did not start the waiting request")
+	}
+}
+
+func TestGate_Nil(t *testing.T) {
+	var g *Gate
+	release, err := g.Acquire(context.Background(), Interactive, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
@@ -0,0 +1,150 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"chimera/internal/storage"
+)
+
+// maxEntries caps the stored history; the oldest visits are dropped first.
+const maxEntries = 500
+
+// Entry is one visited page.
+type Entry struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	VisitedAt time.Time `json:"visited_at"`
+}
+
+// Store persists browsing history as JSON in a storage.Backend, by default
+// below the user's configuration directory.
+type Store struct {
+	backend storage.Backend
+	mu      sync.RWMutex
+}
+
+// NewStore builds a Store below the user's configuration directory.
+func NewStore(appID string) (*Store, error) {
+	backend, err := storage.ConfigFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend), nil
+}
+
+// NewStoreWith builds a Store keeping its data in backend; a nil backend gives a nil Store,
+// which stores nothing.
+func NewStoreWith(backend storage.Backend) *Store {
+	if backend == nil {
+		return nil
+	}
+	return &Store{backend: backend}
+}
+
+// Add records a visit to url, moving an earlier visit of the same URL to the front.
+func (s *Store) Add(url, title string) error {
+	if s == nil {
+		return nil
+	}
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return errors.New("history URL is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.URL != url {
+			kept = append(kept, e)
+		}
+	}
+	kept = append(kept, Entry{URL: url, Title: strings.TrimSpace(title), VisitedAt: time.Now()})
+	if len(kept) > maxEntries {
+		kept = kept[len(kept)-maxEntries:]
+	}
+
+	return s.write(kept)
+}
+
+// Find returns the most recent visit to url.
+func (s *Store) Find(url string) (Entry, bool, error) {
+	if s == nil {
+		return Entry{}, false, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].URL == url {
+			return entries[i], true, nil
+		}
+	}
+	return Entry{}, false, nil
+}
+
+// Recent returns up to limit entries, most recent first.
+func (s *Store) Recent(limit int) ([]Entry, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Entry, 0, min(limit, len(entries)))
+	for i := len(entries) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, entries[i])
+	}
+	return out, nil
+}
+
+func (s *Store) read() ([]Entry, error) {
+	bytes, err := s.backend.Get("history.json")
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return nil, fmt.Errorf("decode history: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) write(entries []Entry) error {
+	encoded, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode history: %w", err)
+	}
+
+	if err := s.backend.Put("history.json", encoded); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package keyring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnavailable indicates no Secret Service (or secret-tool) is reachable.
+	ErrUnavailable = errors.New("secret service unavailable")
+	// ErrNotFound indicates no secret is stored for the account.
+	ErrNotFound = errors.New("secret not found")
+)
+
+// callTimeout bounds each secret-tool call; a locked or missing keyring daemon can otherwise hang.
+const callTimeout = 5 * time.Second
+
+// Keyring stores secrets in the freedesktop Secret Service via libsecret's secret-tool.
+type Keyring struct {
+	service string
+}
+
+// New returns a Keyring whose entries are tagged with service.
+func New(service string) *Keyring {
+	return &Keyring{service: service}
+}
+
+// Get returns the secret stored for account.
+func (k *Keyring) Get(account string) (string, error) {
+	if k == nil {
+		return "", ErrUnavailable
+	}
+	out, err := k.run(nil, "lookup", "service", k.service, "account", account)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", ErrNotFound
+	}
+	return out, nil
+}
+
+// Set stores secret for account, replacing any previous value.
+func (k *Keyring) Set(account, label, secret string) error {
+	if k == nil {
+		return ErrUnavailable
+	}
+	_, err := k.run(strings.NewReader(secret), "store", "--label="+label, "service", k.service, "account", account)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: secret-tool store failed", ErrUnavailable)
+	}
+	return err
+}
+
+// Delete removes the secret stored for account. Missing entries are not an error.
+func (k *Keyring) Delete(account string) error {
+	if k == nil {
+		return ErrUnavailable
+	}
+	_, err := k.run(nil, "clear", "service", k.service, "account", account)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (k *Keyring) run(stdin *strings.Reader, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "secret-tool", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", fmt.Errorf("%w: secret-tool not installed", ErrUnavailable)
+	}
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("%w: secret-tool timed out", ErrUnavailable)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// secret-tool exits 1 without output when nothing matches.
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("%w: %s", ErrUnavailable, msg)
+	}
+	if err != nil {
+		return "", fmt.Errorf("run secret-tool: %w", err)
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
@@ -0,0 +1,313 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"chimera/internal/scraper"
+)
+
+// sectionsPlaceholder marks where composed sections are stitched into the page shell.
+const sectionsPlaceholder = "<!--CHIMERA-SECTIONS-->"
+
+// EstimateTokens approximates the token count of text at roughly four characters per token.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// promptBudget is the share of the context window available for input; the rest is left for the reply.
+func (c *Client) promptBudget() int {
+	return c.contextTokens / 2
+}
+
+// needsChunking reports whether the full prompt would not fit the configured context window.
+func (c *Client) needsChunking(prompt string) bool {
+	if c.contextTokens <= 0 {
+		return false
+	}
+	return EstimateTokens(c.systemPrompt)+EstimateTokens(prompt) > c.promptBudget()
+}
+
+// fitExamples drops the oldest examples until the rest fit alongside prompt.
+func (c *Client) fitExamples(prompt string, examples []Example) []Example {
+	if c.contextTokens <= 0 {
+		return examples
+	}
+	free := c.promptBudget() - EstimateTokens(c.systemPrompt) - EstimateTokens(prompt)
+	start := len(examples)
+	for start > 0 {
+		cost := EstimateTokens(examples[start-1].Input) + EstimateTokens(examples[start-1].Output)
+		if cost > free {
+			break
+		}
+		free -= cost
+		start--
+	}
+	return examples[start:]
+}
+
+// generateChunked composes each chunk of data as an HTML section, asks for a
+// page shell in a final merge pass, and stitches the sections into it.
+func (c *Client) generateChunked(ctx context.Context, data *scraper.Result, opts PageOptions) (string, error) {
+	chunks := chunkResult(data, c.chunkBudget(data, opts))
+	sections := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		fragment, err := c.complete(ctx, buildSectionPrompt(chunk, i+1, len(chunks), opts))
+		if err != nil {
+			return "", fmt.Errorf("compose part %d of %d: %w", i+1, len(chunks), err)
+		}
+		sections = append(sections, stripDocument(fragment))
+	}
+
+	shell, err := c.complete(ctx, buildShellPrompt(data, chunks, opts))
+	if err != nil {
+		return "", fmt.Errorf("compose page shell: %w", err)
+	}
+
+	return stitchSections(shell, sections), nil
+}
+
+// chunkBudget is how many tokens of page data each part of a chunked
+// composition of data may hold.
+func (c *Client) chunkBudget(data *scraper.Result, opts PageOptions) int {
+	budget := c.promptBudget() - EstimateTokens(c.systemPrompt) - EstimateTokens(buildSectionPrompt(pagePart{data: &scraper.Result{SourceURL: data.SourceURL, Title: data.Title}}, 1, 1, opts))
+	if budget < 256 {
+		budget = 256
+	}
+	return budget
+}
+
+// pagePart is one part of a chunked composition.
+type pagePart struct {
+	data *scraper.Result
+	// within are the headings, outermost first, of the sections the part's
+	// first blocks continue; an earlier part already holds them.
+	within []scraper.Heading
+}
+
+// chunkResult splits data into parts whose serialised prompt data fits within budget tokens.
+// Headings are placed among the paragraphs by their Paragraph position, and
+// a part that starts inside a section records the headings it falls under.
+// Footnotes and formulas travel with the first paragraph that references
+// them; code blocks and links, whose positions are unknown, follow the text.
+func chunkResult(data *scraper.Result, budget int) []pagePart {
+	newChunk := func() *scraper.Result {
+		return &scraper.Result{SourceURL: data.SourceURL, Title: data.Title, FetchedAt: data.FetchedAt}
+	}
+
+	var (
+		parts   []pagePart
+		current = pagePart{data: newChunk()}
+		used    int
+		// open holds the headings of the sections the next block falls under.
+		open []scraper.Heading
+	)
+	// add starts a new part when cost does not fit; level is the heading
+	// level of the block, or 0 for any other block.
+	add := func(cost, level int, apply func(*scraper.Result)) {
+		if used > 0 && used+cost > budget {
+			parts = append(parts, current)
+			current = pagePart{data: newChunk()}
+			used = 0
+			for _, h := range open {
+				if level == 0 || h.Level < level {
+					current.within = append(current.within, h)
+				}
+			}
+		}
+		apply(current.data)
+		used += cost
+	}
+
+	// pending are headings not yet added; each goes into the part of the
+	// paragraph it precedes.
+	var pending []scraper.Heading
+	next := 0
+	addWithHeadings := func(cost int, apply func(*scraper.Result)) {
+		level := 0
+		if len(pending) > 0 {
+			level = pending[0].Level
+		}
+		for _, h := range pending {
+			cost += EstimateTokens(h.Text) + 4
+		}
+		headings := pending
+		add(cost, level, func(r *scraper.Result) {
+			for _, h := range headings {
+				h.Paragraph = len(r.Paragraphs)
+				r.Headings = append(r.Headings, h)
+			}
+			apply(r)
+		})
+		for _, h := range headings {
+			for len(open) > 0 && open[len(open)-1].Level >= h.Level {
+				open = open[:len(open)-1]
+			}
+			open = append(open, h)
+		}
+		pending = nil
+	}
+
+	placed := make(map[string]bool)
+	for i, p := range data.Paragraphs {
+		for ; next < len(data.Headings) && data.Headings[next].Paragraph <= i; next++ {
+			pending = append(pending, data.Headings[next])
+		}
+		cost := EstimateTokens(p) + 2
+		var notes []scraper.Footnote
+		for _, note := range data.Footnotes {
+			marker := "[^" + note.Label + "]"
+			if !placed[marker] && strings.Contains(p, marker) {
+				placed[marker] = true
+				notes = append(notes, note)
+				cost += EstimateTokens(note.Text) + 4
+			}
+		}
+		var formulas []scraper.Formula
+		for _, f := range data.Formulas {
+			marker := "[math:" + f.ID + "]"
+			if !placed[marker] && strings.Contains(p, marker) {
+				placed[marker] = true
+				formulas = append(formulas, f)
+				cost += EstimateTokens(f.MathML) + EstimateTokens(f.TeX) + 4
+			}
+		}
+		addWithHeadings(cost, func(r *scraper.Result) {
+			r.Paragraphs = append(r.Paragraphs, p)
+			r.Footnotes = append(r.Footnotes, notes...)
+			r.Formulas = append(r.Formulas, formulas...)
+		})
+	}
+	if pending = append(pending, data.Headings[next:]...); len(pending) > 0 {
+		addWithHeadings(0, func(*scraper.Result) {})
+	}
+	for _, block := range data.CodeBlocks {
+		add(EstimateTokens(block.Code)+6, 0, func(r *scraper.Result) { r.CodeBlocks = append(r.CodeBlocks, block) })
+	}
+	for _, l := range data.Links {
+		add(EstimateTokens(l.Text)+EstimateTokens(l.Href)+5, 0, func(r *scraper.Result) { r.Links = append(r.Links, l) })
+	}
+
+	if used > 0 || len(parts) == 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+func buildSectionPrompt(part pagePart, index, total int, opts PageOptions) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("The source page is too long to compose at once, so it is split into %d parts. This is part %d of %d.\n", total, index, total))
+	builder.WriteString("Convert only this part into one or more semantic HTML5 <section> elements. Do not output <html>, <head>, <body>, or <style> elements; the sections will be inserted into a page that is composed separately.\n")
+	switch opts.Task {
+	case TaskSummarize:
+		builder.WriteString("Summarise the key points of this part concisely, keeping important figures, names, and the most relevant links.\n")
+	case TaskTranslate:
+		builder.WriteString("Translate all text in this part into the requested language, preserving every detail and outbound link.\n")
+	case TaskELI5:
+		builder.WriteString("Explain this part in short sentences and everyday words a curious ten-year-old could follow, keeping every fact accurate and the most relevant links.\n")
+	default:
+		builder.WriteString("Faithfully preserve all information, wording, and outbound links in this part. Do not summarise or omit details.\n")
+	}
+	builder.WriteString(anchorInstruction)
+	if len(part.within) > 0 {
+		var path []string
+		for _, h := range part.within {
+			path = append(path, fmt.Sprintf("H%d %s", h.Level, h.Text))
+		}
+		builder.WriteString("This part continues the section under " + strings.Join(path, " > ") + ", whose heading an earlier part already wrote; do not repeat it, and nest this part's own headings below it.\n")
+	}
+	if len(part.data.Footnotes) > 0 {
+		builder.WriteString(footnoteInstruction)
+	}
+	if len(part.data.CodeBlocks) > 0 {
+		builder.WriteString(codeInstruction)
+	}
+	if len(part.data.Formulas) > 0 {
+		builder.WriteString(mathInstruction)
+	}
+	builder.WriteString("Do not wrap the output in Markdown code fences.\n")
+	writeStyleHints(&builder, opts)
+	builder.WriteString("\n")
+
+	writeSourceData(&builder, part.data)
+
+	builder.WriteString("\nReturn only the raw HTML sections.")
+	return builder.String()
+}
+
+func buildShellPrompt(data *scraper.Result, chunks []pagePart, opts PageOptions) string {
+	var builder strings.Builder
+	builder.WriteString("You are composing the frame of an HTML page whose content sections have already been written.\n")
+	builder.WriteString("Infer the primary theme of the source page and produce a complete HTML document with a <head> containing a <style> block that styles <section> elements, a descriptive hero or title section, and a prominent reference to the original source.\n")
+	builder.WriteString("When an author or publication date is given, credit them beneath the title.\n")
+	builder.WriteString("Place the exact comment " + sectionsPlaceholder + " inside <main> where the content sections belong. Do not write the section content yourself.\n")
+	builder.WriteString("Do not wrap the output in Markdown code fences.\n")
+	writeStyleHints(&builder, opts)
+	builder.WriteString("\n")
+
+	builder.WriteString("Source URL: ")
+	builder.WriteString(data.SourceURL)
+	builder.WriteString("\n")
+	if data.Title != "" {
+		builder.WriteString("Title: ")
+		builder.WriteString(data.Title)
+		builder.WriteString("\n")
+	}
+	if data.Description != "" {
+		builder.WriteString("Description: ")
+		builder.WriteString(data.Description)
+		builder.WriteString("\n")
+	}
+	writeByline(&builder, data)
+
+	builder.WriteString(fmt.Sprintf("The page has %d content parts", len(chunks)))
+	var outline []string
+	for _, chunk := range chunks {
+		for _, h := range chunk.data.Headings {
+			outline = append(outline, fmt.Sprintf("- H%d %s", h.Level, h.Text))
+		}
+	}
+	if len(outline) > 0 {
+		builder.WriteString(" covering these headings:\n")
+		builder.WriteString(strings.Join(outline, "\n"))
+	}
+	builder.WriteString("\n\nReturn only raw HTML inside <html> tags.")
+	return builder.String()
+}
+
+// stitchSections inserts the composed sections into shell at the placeholder,
+// falling back to the end of <main> or <body> when the model dropped it.
+func stitchSections(shell string, sections []string) string {
+	joined := strings.Join(sections, "\n")
+	if strings.Contains(shell, sectionsPlaceholder) {
+		return strings.Replace(shell, sectionsPlaceholder, joined, 1)
+	}
+
+	lower := strings.ToLower(shell)
+	for _, marker := range []string{"</main>", "</body>"} {
+		if idx := strings.LastIndex(lower, marker); idx >= 0 {
+			return shell[:idx] + joined + "\n" + shell[idx:]
+		}
+	}
+	if shell == "" {
+		return "<!DOCTYPE html>\n<html><body><main>\n" + joined + "\n</main></body></html>"
+	}
+	return shell + "\n" + joined
+}
+
+// stripDocument unwraps a fragment the model returned as a full document anyway.
+func stripDocument(fragment string) string {
+	lower := strings.ToLower(fragment)
+	start := strings.Index(lower, "<body")
+	if start < 0 {
+		return fragment
+	}
+	open := strings.Index(lower[start:], ">")
+	end := strings.LastIndex(lower, "</body>")
+	if open < 0 || end < start {
+		return fragment
+	}
+	return strings.TrimSpace(fragment[start+open+1 : end])
+}
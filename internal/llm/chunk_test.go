@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"chimera/internal/scraper"
+)
+
+func TestChunk_Result(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	data := &scraper.Result{
+		SourceURL: "https://example.com/",
+		Title:     "Report",
+		Headings: []scraper.Heading{
+			{Level: 1, Text: "Report", ID: "report"},
+			{Level: 2, Text: "Results", ID: "results", Paragraph: 1},
+			{Level: 3, Text: "Revenue", ID: "revenue", Paragraph: 1},
+			{Level: 2, Text: "Outlook", ID: "outlook", Paragraph: 4},
+			{Level: 2, Text: "Appendix", ID: "appendix", Paragraph: 5},
+		},
+		Paragraphs: []string{
+			"intro " + long,
+			"revenue one [^1] " + long,
+			"revenue two " + long,
+			"revenue three [^1] " + long,
+			"outlook " + long,
+		},
+		Footnotes:  []scraper.Footnote{{Label: "1", Text: "A note."}},
+		CodeBlocks: []scraper.CodeBlock{{Language: "go", Code: "fmt.Println(1)"}},
+		Links:      []scraper.Link{{Text: "Home", Href: "https://example.com/"}},
+	}
+
+	parts := chunkResult(data, 70)
+
+	type want struct {
+		within     []string
+		headings   []string
+		paragraphs []string
+	}
+	describe := func(part pagePart) want {
+		var got want
+		for _, h := range part.within {
+			got.within = append(got.within, h.Text)
+		}
+		for _, h := range part.data.Headings {
+			got.headings = append(got.headings, fmt.Sprintf("%s@%d", h.Text, h.Paragraph))
+		}
+		for _, p := range part.data.Paragraphs {
+			got.paragraphs = append(got.paragraphs, strings.Fields(p)[0]+" "+strings.Fields(p)[1])
+		}
+		return got
+	}
+	wants := []want{
+		{nil, []string{"Report@0"}, []string{"intro word"}},
+		{[]string{"Report"}, []string{"Results@0", "Revenue@0"}, []string{"revenue one"}},
+		{[]string{"Report", "Results", "Revenue"}, nil, []string{"revenue two"}},
+		{[]string{"Report", "Results", "Revenue"}, nil, []string{"revenue three"}},
+		{[]string{"Report"}, []string{"Outlook@0", "Appendix@1"}, []string{"outlook word"}},
+		{[]string{"Report", "Appendix"}, nil, nil},
+	}
+	if len(parts) != len(wants) {
+		for i, part := range parts {
+			t.Logf("part %d: %+v", i, describe(part))
+		}
+		t.Fatalf("%d parts, want %d", len(parts), len(wants))
+	}
+	for i, part := range parts {
+		got := describe(part)
+		if !slices.Equal(got.within, wants[i].within) || !slices.Equal(got.headings, wants[i].headings) || !slices.Equal(got.paragraphs, wants[i].paragraphs) {
+			t.Errorf("part %d = %+v, want %+v", i, got, wants[i])
+		}
+	}
+
+	if len(parts[1].data.Footnotes) != 1 || len(parts[3].data.Footnotes) != 0 {
+		t.Errorf("footnote not with its first reference: %+v, %+v", parts[1].data.Footnotes, parts[3].data.Footnotes)
+	}
+	last := parts[len(parts)-1].data
+	if len(last.CodeBlocks) != 1 || len(last.Links) != 1 {
+		t.Errorf("code and links not after the text: %+v", last)
+	}
+
+	prompt := buildSectionPrompt(parts[2], 3, len(parts), PageOptions{})
+	if !strings.Contains(prompt, "continues the section under H1 Report > H2 Results > H3 Revenue") {
+		t.Errorf("section prompt lacks the enclosing headings:\n%s", prompt)
+	}
+	if prompt := buildSectionPrompt(parts[0], 1, len(parts), PageOptions{}); strings.Contains(prompt, "continues the section") {
+		t.Errorf("first part claims to continue a section:\n%s", prompt)
+	}
+}
+
+func TestChunk_ResultFitsInOnePart(t *testing.T) {
+	data := &scraper.Result{
+		Headings:   []scraper.Heading{{Level: 1, Text: "A"}, {Level: 2, Text: "B", Paragraph: 1}},
+		Paragraphs: []string{"one", "two"},
+	}
+	parts := chunkResult(data, 1000)
+	if len(parts) != 1 || len(parts[0].within) != 0 || len(parts[0].data.Headings) != 2 || len(parts[0].data.Paragraphs) != 2 {
+		t.Fatalf("parts = %+v, want everything in one", parts)
+	}
+
+	if parts := chunkResult(&scraper.Result{}, 100); len(parts) != 1 {
+		t.Errorf("empty result gave %d parts, want 1", len(parts))
+	}
+}
+
+func TestChunk_StitchSections(t *testing.T) {
+	sections := []string{"<section>a</section>", "<section>b</section>"}
+	joined := "<section>a</section>\n<section>b</section>"
+	tests := []struct {
+		name  string
+		shell string
+		want  string
+	}{
+		{"placeholder", "<html><body><main>" + sectionsPlaceholder + "</main></body></html>", "<html><body><main>" + joined + "</main></body></html>"},
+		{"end of main", "<html><body><main><h1>t</h1></MAIN></body></html>", "<html><body><main><h1>t</h1>" + joined + "\n</MAIN></body></html>"},
+		{"end of body", "<html><body><h1>t</h1></body></html>", "<html><body><h1>t</h1>" + joined + "\n</body></html>"},
+		{"no markers", "<h1>t</h1>", "<h1>t</h1>\n" + joined},
+		{"empty shell", "", "<!DOCTYPE html>\n<html><body><main>\n" + joined + "\n</main></body></html>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stitchSections(tt.shell, sections); got != tt.want {
+				t.Errorf("stitchSections = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunk_StripDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{"fragment", "<section>a</section>", "<section>a</section>"},
+		{"document", "<!DOCTYPE html><html><head><title>t</title></head><BODY class=\"x\">\n<section>a</section>\n</BODY></html>", "<section>a</section>"},
+		{"unclosed body", "<html><body><section>a</section>", "<html><body><section>a</section>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripDocument(tt.fragment); got != tt.want {
+				t.Errorf("stripDocument = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
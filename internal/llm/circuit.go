@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitFailures = 3
+	defaultCircuitCooldown = time.Minute
+)
+
+// CircuitOpenError is returned without contacting the endpoint after repeated
+// consecutive failures, until the cooldown ends.
+type CircuitOpenError struct {
+	RetryIn time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("llm paused after repeated failures; retry in %s", e.RetryIn.Round(time.Second))
+}
+
+// breaker stops requests to an endpoint that keeps failing. Once the cooldown
+// passes a single request is let through as a probe while the others wait
+// for it; another failure reopens the circuit.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	// probe is closed once the probe request sent after the cooldown is
+	// recorded; nil while none is in flight.
+	probe chan struct{}
+}
+
+func newBreaker(cfg Config) *breaker {
+	b := &breaker{threshold: cfg.CircuitFailures, cooldown: cfg.CircuitCooldown}
+	if b.threshold <= 0 {
+		b.threshold = defaultCircuitFailures
+	}
+	if b.cooldown <= 0 {
+		b.cooldown = defaultCircuitCooldown
+	}
+	return b
+}
+
+// allow reports whether a request may be sent. While the circuit is open
+// it returns a *CircuitOpenError. After the cooldown the first request is
+// the probe, and the ones after it wait until it is recorded, then go on
+// or fail with the reopened circuit. The caller passes probe on to record.
+func (b *breaker) allow(ctx context.Context) (probe bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+	for {
+		b.mu.Lock()
+		if wait := time.Until(b.openUntil); wait > 0 {
+			b.mu.Unlock()
+			return false, &CircuitOpenError{RetryIn: wait}
+		}
+		if b.failures < b.threshold {
+			b.mu.Unlock()
+			return false, nil
+		}
+		if b.probe == nil {
+			b.probe = make(chan struct{})
+			b.mu.Unlock()
+			return true, nil
+		}
+		waiting := b.probe
+		b.mu.Unlock()
+
+		select {
+		case <-waiting:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// remaining returns how long the circuit stays open, or zero when closed.
+func (b *breaker) remaining() time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if wait := time.Until(b.openUntil); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// record counts err against the endpoint, and ends the probe when probe,
+// from allow, is set. Cancellations and client errors such as a 400 for an
+// oversized prompt say nothing about its health.
+func (b *breaker) record(err error, probe bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		// The requests waiting for the probe see the state left below.
+		close(b.probe)
+		b.probe = nil
+	}
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && !httpErr.Transient() {
+		err = nil
+	}
+
+	if err == nil {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// Paused returns how long requests are skipped because the endpoint and
+// every fallback provider kept failing, or zero when the client may be used.
+func (c *Client) Paused() time.Duration {
+	if c == nil {
+		return 0
+	}
+	paused := c.breaker.remaining()
+	for _, next := range c.fallbacks {
+		paused = min(paused, next.breaker.remaining())
+	}
+	return paused
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	var healthy atomic.Bool
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		if !healthy.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer srv.Close()
+
+	const cooldown = 50 * time.Millisecond
+	c := NewClient(Config{BaseURL: srv.URL, Model: "m", CircuitFailures: 1, CircuitCooldown: cooldown, MaxInFlight: 8})
+	payload := chatCompletionRequest{Model: "m", Messages: []chatMessage{{Role: "user", Content: "hi"}}}
+	send := func(n int) []error {
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = c.sendChat(context.Background(), payload)
+			}()
+		}
+		wg.Wait()
+		return errs
+	}
+
+	if _, err := c.sendChat(context.Background(), payload); err == nil {
+		t.Fatal("request to a failing endpoint succeeded")
+	}
+	var open *CircuitOpenError
+	if _, err := c.sendChat(context.Background(), payload); !errors.As(err, &open) {
+		t.Fatalf("err = %v, want the circuit open", err)
+	}
+
+	// After the cooldown only the probe reaches the still failing endpoint.
+	time.Sleep(cooldown)
+	hits.Store(0)
+	errs := send(4)
+	if hits.Load() != 1 {
+		t.Errorf("endpoint got %d requests while half open, want 1", hits.Load())
+	}
+	reopened := 0
+	for _, err := range errs {
+		if errors.As(err, &open) {
+			reopened++
+		}
+	}
+	if reopened != 3 {
+		t.Errorf("%d requests failed with the reopened circuit, want 3: %v", reopened, errs)
+	}
+
+	// A successful probe closes the circuit for the requests behind it.
+	time.Sleep(cooldown)
+	healthy.Store(true)
+	hits.Store(0)
+	for _, err := range send(4) {
+		if err != nil {
+			t.Errorf("request after a successful probe: %v", err)
+		}
+	}
+	if hits.Load() != 4 || c.Paused() != 0 {
+		t.Errorf("endpoint got %d requests, paused %v; want 4 and none", hits.Load(), c.Paused())
+	}
+}
+
+func TestBreaker_CancelledProbe(t *testing.T) {
+	b := newBreaker(Config{CircuitFailures: 1, CircuitCooldown: time.Millisecond})
+	b.record(errors.New("down"), false)
+	time.Sleep(2 * time.Millisecond)
+
+	probe, err := b.allow(context.Background())
+	if !probe || err != nil {
+		t.Fatalf("allow = %v, %v; want the probe", probe, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.allow(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second allow = %v, want it to wait for the probe", err)
+	}
+
+	// A cancelled probe says nothing about the endpoint; the next request
+	// probes again.
+	b.record(context.Canceled, true)
+	if probe, err := b.allow(context.Background()); !probe || err != nil {
+		t.Errorf("allow after a cancelled probe = %v, %v; want a new probe", probe, err)
+	}
+}
@@ -2,15 +2,22 @@ package llm
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"chimera/internal/examples"
+	"chimera/internal/gate"
+	"chimera/internal/proxy"
+	"chimera/internal/retry"
 	"chimera/internal/scraper"
 )
 
@@ -24,14 +31,120 @@ type Config struct {
 	APIKey     string
 	HTTPClient *http.Client
 	Timeout    time.Duration
+
+	// Endpoint is the kind of API at BaseURL; EndpointAuto tells it from
+	// the host. For Azure OpenAI, Model names the deployment and BaseURL
+	// may carry an api-version query parameter.
+	Endpoint Endpoint
+
+	// AppTitle and AppURL name the app to OpenRouter, which lists its
+	// requests under them; AppTitle defaults to DefaultAppTitle and AppURL
+	// is left out when empty. Routing picks OpenRouter's upstream providers.
+	AppTitle string
+	AppURL   string
+	Routing  Routing
+
+	// PromptFormat is one of PromptFormats, the chat template of the model
+	// behind llama.cpp's /completion route, which takes a plain prompt;
+	// empty uses DefaultPromptFormat.
+	PromptFormat string
+
+	// Retries is the number of extra attempts after a 429 or 5xx response.
+	Retries int
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// subsequent retry up to RetryMaxBackoff. A longer Retry-After wins.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+
+	// ContextTokens is the model's context window. Pages whose prompt would
+	// not fit are composed in parts and stitched together; zero disables chunking.
+	ContextTokens int
+
+	// SystemPrompt replaces the system prompt of Prompts when set.
+	SystemPrompt string
+
+	// Prompts holds the prompt templates pages are composed with; nil uses
+	// the built-in ones.
+	Prompts *Prompts
+
+	// Examples holds the few-shot examples saved for each prompt template.
+	// The MaxExamples most recent of a prompt's template are replayed ahead
+	// of it.
+	Examples *examples.Store
+
+	// Proxy is an http, https or socks5 proxy URL; empty uses the proxy
+	// environment variables. It is ignored when HTTPClient is set.
+	Proxy string
+
+	// CircuitFailures is how many consecutive failed requests pause the
+	// client for CircuitCooldown. Defaults to 3 failures and one minute.
+	CircuitFailures int
+	CircuitCooldown time.Duration
+
+	// EmbeddingModel names the model Embed uses, such as
+	// text-embedding-3-small or nomic-embed-text; empty disables Embed.
+	EmbeddingModel string
+
+	// InputPrice is what the endpoint charges per 1000 prompt tokens, for
+	// cost estimates; zero for a free or local model.
+	InputPrice float64
+
+	// VisionModels names the models that accept images, such as gpt-4o or
+	// llava; a name without a tag also matches its tagged variants, so
+	// llava covers llava:13b.
+	VisionModels []string
+
+	// MaxInFlight is how many requests each provider, the endpoint and
+	// every fallback, is sent at once; the others wait in line, across
+	// every client of the provider. Zero uses 2.
+	MaxInFlight int
+
+	// Fallbacks are tried in order when the endpoint fails a request, for
+	// instance a cloud model behind a flaky local one. They share the other
+	// settings; the embedding model is only asked at the endpoint.
+	Fallbacks []Provider
 }
 
 // Client talks to a local LLM endpoint (e.g. Ollama or llama.cpp HTTP binding).
 type Client struct {
+	// name labels a fallback provider.
+	name    string
 	baseURL string
 	model   string
 	apiKey  string
 	client  *http.Client
+	retry   retryPolicy
+	breaker *breaker
+	queue   *gate.Gate
+
+	// endpoint is the kind of API at baseURL; apiVersion is sent with
+	// each Azure OpenAI request, and appTitle, appURL and routing with
+	// each OpenRouter request.
+	endpoint   Endpoint
+	apiVersion string
+	appTitle   string
+	appURL     string
+	routing    Routing
+	// promptFormat lays out the prompts of llama.cpp's /completion route.
+	promptFormat promptFormat
+
+	contextTokens int
+	systemPrompt  string
+	prompts       *Prompts
+	examples      *examples.Store
+	inputPrice    float64
+	vision        bool
+
+	embeddingModel string
+	// ollamaEmbeddings is set once the endpoint turned out to lack
+	// /v1/embeddings.
+	ollamaEmbeddings atomic.Bool
+	// noResponseFormat is set once the endpoint rejected a json_schema
+	// response format, and noTools once it rejected tools.
+	noResponseFormat atomic.Bool
+	noTools          atomic.Bool
+
+	fallbacks []*Client
 }
 
 // NewClient builds a new LLM client. If the endpoint is empty the client will be disabled.
@@ -40,6 +153,11 @@ func NewClient(cfg Config) *Client {
 		return &Client{}
 	}
 
+	prompt := strings.TrimSpace(cfg.SystemPrompt)
+	if prompt == "" {
+		prompt = cfg.Prompts.System()
+	}
+
 	timeout := cfg.Timeout
 	if timeout <= 0 {
 		timeout = 55 * time.Second
@@ -47,14 +165,42 @@ func NewClient(cfg Config) *Client {
 
 	httpClient := cfg.HTTPClient
 	if httpClient == nil {
-		httpClient = &http.Client{Timeout: timeout}
+		httpClient = &http.Client{Timeout: timeout, Transport: proxy.Transport(cfg.Proxy)}
+	}
+
+	endpoint := resolveEndpoint(cfg.Endpoint, cfg.BaseURL)
+	baseURL, apiVersion := strings.TrimRight(cfg.BaseURL, "/"), ""
+	if endpoint == EndpointAzure {
+		baseURL, apiVersion = azureBase(cfg.BaseURL)
 	}
 
 	return &Client{
-		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		baseURL: baseURL,
 		model:   cfg.Model,
 		apiKey:  cfg.APIKey,
 		client:  httpClient,
+		retry:   newRetryPolicy(cfg),
+		breaker: newBreaker(cfg),
+		queue:   queueFor(baseURL, cfg.MaxInFlight),
+
+		endpoint:   endpoint,
+		apiVersion: apiVersion,
+		appTitle:   cmp.Or(strings.TrimSpace(cfg.AppTitle), DefaultAppTitle),
+		appURL:     strings.TrimSpace(cfg.AppURL),
+		routing:    cfg.Routing,
+
+		promptFormat: lookupPromptFormat(cfg.PromptFormat),
+
+		contextTokens: cfg.ContextTokens,
+		systemPrompt:  prompt,
+		prompts:       cfg.Prompts,
+		examples:      cfg.Examples,
+		inputPrice:    cfg.InputPrice,
+		vision:        endpoint != EndpointLlamaCpp && seesImages(cfg.Model, cfg.VisionModels),
+
+		embeddingModel: strings.TrimSpace(cfg.EmbeddingModel),
+
+		fallbacks: newFallbacks(cfg),
 	}
 }
 
@@ -63,69 +209,451 @@ func (c *Client) Available() bool {
 	return c != nil && c.baseURL != ""
 }
 
+// Model returns the configured model name.
+func (c *Client) Model() string {
+	if c == nil {
+		return ""
+	}
+	return c.model
+}
+
+// PageOptions adjusts how a page is composed.
+type PageOptions struct {
+	// Task selects what to produce from the page; the zero value composes it.
+	Task Task
+	// Template names the prompt template to use; empty uses the one named
+	// after Task.
+	Template string
+	// Language is the output language for summaries and translations.
+	Language string
+	// Lite asks for lightweight styling suitable for low-spec machines.
+	Lite bool
+	// Note is the user's standing instruction for this page.
+	Note string
+	// Typography describes the reader's preferred text style, such as
+	// "Use a base font size of 20px."; empty leaves it to the model.
+	Typography string
+	// Related are pages linked from the source whose brief extracts are
+	// appended to the prompt. They are left out when the page is chunked.
+	Related []*scraper.Result
+	// Screenshot is a PNG of the original page, sent with the prompt so a
+	// model that can see reproduces its layout. It is left out for other
+	// models and when the page is chunked.
+	Screenshot []byte
+	// Fetch, when set, lets the model read same-site pages the page links
+	// to while composing it. It is not offered when the page is chunked.
+	Fetch *FetchTool
+}
+
+// MaxExamples caps how many saved examples accompany a single request.
+const MaxExamples = 3
+
+// Example is a source/HTML pair demonstrating the expected output.
+type Example struct {
+	Input  string
+	Output string
+}
+
+// Task identifies what the LLM should produce from a page.
+type Task int
+
+const (
+	// TaskCompose restyles the page while preserving all content.
+	TaskCompose Task = iota
+	// TaskSummarize produces a condensed digest of the page.
+	TaskSummarize
+	// TaskTranslate renders the full page in another language.
+	TaskTranslate
+	// TaskELI5 explains the page in plain words for a young reader.
+	TaskELI5
+)
+
+// Tasks lists every Task, in menu order.
+var Tasks = []Task{TaskCompose, TaskSummarize, TaskTranslate, TaskELI5}
+
+// String returns the prompt template name for t.
+func (t Task) String() string {
+	switch t {
+	case TaskSummarize:
+		return "summarize"
+	case TaskTranslate:
+		return "translate"
+	case TaskELI5:
+		return "eli5"
+	default:
+		return "compose"
+	}
+}
+
+// ParseTask maps a template name from Task.String back to its Task,
+// defaulting to TaskCompose.
+func ParseTask(name string) Task {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "summarize":
+		return TaskSummarize
+	case "translate":
+		return TaskTranslate
+	case "eli5":
+		return TaskELI5
+	default:
+		return TaskCompose
+	}
+}
+
 // GeneratePage asks the local LLM to turn the scrape result into standalone HTML.
-func (c *Client) GeneratePage(ctx context.Context, data *scraper.Result) (string, error) {
+func (c *Client) GeneratePage(ctx context.Context, data *scraper.Result, opts PageOptions) (string, error) {
 	if !c.Available() {
 		return "", ErrUnavailable
 	}
 
-	payload := chatCompletionRequest{
-		Model: c.model,
-		Messages: []chatMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: buildPrompt(data)},
-		},
+	prompt, err := c.buildPrompt(data, opts)
+	if err != nil {
+		return "", err
+	}
+	if c.needsChunking(prompt) {
+		return c.generateChunked(ctx, data, opts)
+	}
+
+	var image []byte
+	if c.vision {
+		image = opts.Screenshot
+	}
+	html, err := c.converse(ctx, prompt, image, c.fitExamples(prompt, c.fewShot(opts)), newFetchSession(opts.Fetch, data))
+	if err != nil {
+		return "", err
+	}
+	if html == "" {
+		return "", errors.New("llm response empty")
+	}
+
+	return html, nil
+}
+
+// complete sends prompt with the system prompt, preceded by any few-shot
+// examples, and returns the sanitised reply.
+func (c *Client) complete(ctx context.Context, prompt string, examples ...Example) (string, error) {
+	return c.converse(ctx, prompt, nil, examples, nil)
+}
+
+// converse is complete with image, a PNG, attached to prompt unless it is
+// nil, and with the fetch_url tool offered while fetch is open. Each tool
+// call is answered and the model asked again, until it replies without
+// one. Once fetch is closed the tool stays declared, as servers expect
+// for a conversation holding tool calls, but with tool_choice "none", so
+// the model must reply.
+func (c *Client) converse(ctx context.Context, prompt string, image []byte, examples []Example, fetch *fetchSession) (string, error) {
+	messages := make([]chatMessage, 0, 2+2*len(examples))
+	messages = append(messages, chatMessage{Role: "system", Content: c.systemPrompt})
+	for _, ex := range examples {
+		messages = append(messages,
+			chatMessage{Role: "user", Content: ex.Input},
+			chatMessage{Role: "assistant", Content: ex.Output},
+		)
+	}
+	user := chatMessage{Role: "user", Content: prompt}
+	if image != nil {
+		user.Images = [][]byte{image}
+	}
+	messages = append(messages, user)
+
+	request := chatCompletionRequest{
+		Model:       c.model,
+		Messages:    messages,
 		Temperature: 0.2,
 	}
+	called := false
+	for {
+		request.Tools, request.ToolChoice = nil, ""
+		switch {
+		case c.noTools.Load():
+		case fetch.open():
+			request.Tools = fetchTools
+		case called:
+			request.Tools, request.ToolChoice = fetchTools, "none"
+		}
+		parsed, err := c.postChat(ctx, request)
+		if request.Tools != nil && unsupported(err) {
+			c.noTools.Store(true)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		reply := parsed.firstReply()
+		if len(reply.ToolCalls) == 0 || request.Tools == nil || request.ToolChoice == "none" {
+			return sanitizeLLMOutput(reply.Content), nil
+		}
+		called = true
 
-	buf := new(bytes.Buffer)
-	if err := json.NewEncoder(buf).Encode(payload); err != nil {
-		return "", fmt.Errorf("encode request: %w", err)
+		request.Messages = append(request.Messages, chatMessage{Role: "assistant", Content: reply.Content, ToolCalls: reply.ToolCalls})
+		for _, call := range reply.ToolCalls {
+			request.Messages = append(request.Messages, chatMessage{Role: "tool", ToolCallID: call.ID, Content: fetch.run(ctx, call)})
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
 	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.completionsURL(), buf)
+// WarmUp sends a one-token completion so a local server loads the model into
+// memory. It is not retried and does not count towards the circuit breaker.
+func (c *Client) WarmUp(ctx context.Context) error {
+	if !c.Available() {
+		return ErrUnavailable
+	}
+	encoded, err := c.encodeChat(chatCompletionRequest{
+		Model:     c.model,
+		Messages:  []chatMessage{{Role: "user", Content: "Reply with OK."}},
+		MaxTokens: 1,
+	})
 	if err != nil {
-		return "", fmt.Errorf("build request: %w", err)
+		return fmt.Errorf("encode request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	_, err = c.postChatJSON(ctx, encoded)
+	return err
+}
+
+// postChat sends a chat completion request, retrying transient failures,
+// then asks each fallback provider in turn until one answers.
+func (c *Client) postChat(ctx context.Context, payload chatCompletionRequest) (chatCompletionResponse, error) {
+	parsed, err := c.sendChat(ctx, payload)
+	failed := c
+	for _, next := range c.fallbacks {
+		if !failOver(ctx, err) {
+			break
+		}
+		if notify := fallbackNotifier(ctx); notify != nil {
+			notify(failed.Provider(), next.Provider(), err)
+		}
+		parsed, err = next.sendChat(ctx, next.adapt(payload))
+		failed = next
+	}
+	return parsed, err
+}
+
+// sendChat sends a chat completion request to c's endpoint alone.
+func (c *Client) sendChat(ctx context.Context, payload chatCompletionRequest) (chatCompletionResponse, error) {
+	if c.endpoint == EndpointOpenRouter {
+		payload.Provider = c.routing.preferences()
+	}
+	encoded, err := c.encodeChat(payload)
+	if err != nil {
+		return chatCompletionResponse{}, fmt.Errorf("encode request: %w", err)
 	}
 
+	release, err := enqueue(ctx, c.queue, c.Provider())
+	if err != nil {
+		return chatCompletionResponse{}, err
+	}
+	probe, err := c.breaker.allow(ctx)
+	if err != nil {
+		release()
+		return chatCompletionResponse{}, err
+	}
+	var parsed chatCompletionResponse
+	err = c.retry.do(ctx, func() (err error) {
+		// Each attempt holds a slot only while it is sent, so others run
+		// during the backoff before a retry.
+		if release == nil {
+			if release, err = enqueue(ctx, c.queue, c.Provider()); err != nil {
+				return err
+			}
+		}
+		defer func() {
+			release()
+			release = nil
+		}()
+		parsed, err = c.postChatJSON(ctx, encoded)
+		return err
+	})
+	c.breaker.record(err, probe)
+	if err == nil {
+		c.reportServed(ctx, parsed)
+	}
+	return parsed, err
+}
+
+func (c *Client) postJSON(ctx context.Context, endpoint string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.authorize(req)
+
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("post llm request: %w", err)
+		return fmt.Errorf("post llm request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
-		return "", &HTTPError{Status: resp.StatusCode, Body: string(body)}
+		return &HTTPError{
+			Status:     resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: retry.ParseAfter(resp.Header.Get("Retry-After")),
+		}
 	}
 
-	var parsed chatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-		return "", fmt.Errorf("decode llm response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode llm response: %w", err)
 	}
+	return nil
+}
 
-	html := sanitizeLLMOutput(parsed.FirstMessage())
-	if html == "" {
-		return "", errors.New("llm response empty")
+// buildPrompt executes the prompt template opts selects for data.
+func (c *Client) buildPrompt(data *scraper.Result, opts PageOptions) (string, error) {
+	var rules strings.Builder
+	rules.WriteString(anchorInstruction)
+	if len(data.Footnotes) > 0 {
+		rules.WriteString(footnoteInstruction)
 	}
+	if len(data.CodeBlocks) > 0 {
+		rules.WriteString(codeInstruction)
+	}
+	if len(data.Formulas) > 0 {
+		rules.WriteString(mathInstruction)
+	}
+	rules.WriteString("Do not wrap the output in Markdown code fences.\n")
+
+	var hints, related strings.Builder
+	writeStyleHints(&hints, opts)
+	writeRelated(&related, opts.Related)
+
+	return c.prompts.render(opts.Template, opts.Task, PromptData{
+		Page:       data,
+		Task:       opts.Task.String(),
+		Language:   strings.TrimSpace(opts.Language),
+		Note:       strings.TrimSpace(opts.Note),
+		Typography: strings.TrimSpace(opts.Typography),
+		Lite:       opts.Lite,
+		Rules:      rules.String(),
+		Hints:      hints.String(),
+		Source:     SourceText(data),
+		Related:    related.String(),
+	})
+}
 
-	return html, nil
+// fewShot returns the few-shot turns for a prompt with opts: the most
+// recent examples saved for its template, oldest first.
+func (c *Client) fewShot(opts PageOptions) []Example {
+	name := opts.Task.String()
+	if c.prompts != nil {
+		name = c.prompts.resolve(opts.Template, opts.Task)
+	}
+	saved, err := c.examples.List(name)
+	if err != nil {
+		slog.Warn("load few-shot examples", "template", name, "err", err)
+		return nil
+	}
+	if len(saved) > MaxExamples {
+		saved = saved[len(saved)-MaxExamples:]
+	}
+	var out []Example
+	for _, e := range saved {
+		out = append(out, Example{Input: strings.TrimSpace(e.Input), Output: strings.TrimSpace(e.Output)})
+	}
+	return out
 }
 
-func buildPrompt(data *scraper.Result) string {
+func writeStyleHints(builder *strings.Builder, opts PageOptions) {
+	if language := strings.TrimSpace(opts.Language); language != "" && opts.Task != TaskCompose {
+		builder.WriteString("Write all output text in ")
+		builder.WriteString(language)
+		builder.WriteString(", including headings and the page title.\n")
+	}
+	if note := strings.TrimSpace(opts.Note); note != "" {
+		builder.WriteString("The reader left this note about the page; follow it: ")
+		builder.WriteString(note)
+		builder.WriteString("\n")
+	}
+	if typography := strings.TrimSpace(opts.Typography); typography != "" {
+		builder.WriteString("The reader set typography preferences; style the page's text accordingly. ")
+		builder.WriteString(typography)
+		builder.WriteString("\n")
+	}
+	if opts.Lite {
+		builder.WriteString("Keep the styling lightweight for a low-spec machine: plain system fonts, flat colours, no shadows, gradients, animations, or background images, and constrain any images to small sizes.\n")
+	}
+}
+
+// relatedExtractRunes caps the length of each paragraph quoted from a linked page.
+const relatedExtractRunes = 280
+
+func writeRelated(builder *strings.Builder, related []*scraper.Result) {
+	if len(related) == 0 {
+		return
+	}
+	builder.WriteString("\nLinked pages (brief extracts from pages this one links to; use them to give an overview of where the page leads and link to each):\n")
+	for _, page := range related {
+		builder.WriteString("- ")
+		if page.Title != "" {
+			builder.WriteString(page.Title)
+			builder.WriteString(" ")
+		}
+		builder.WriteString("<")
+		builder.WriteString(page.SourceURL)
+		builder.WriteString(">\n")
+		if page.Description != "" {
+			builder.WriteString("  ")
+			builder.WriteString(truncateRunes(page.Description, relatedExtractRunes))
+			builder.WriteString("\n")
+		}
+		for i, p := range page.Paragraphs {
+			if i == 2 {
+				break
+			}
+			builder.WriteString("  ")
+			builder.WriteString(truncateRunes(p, relatedExtractRunes))
+			builder.WriteString("\n")
+		}
+	}
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:limit])) + "…"
+}
+
+// SourceText renders the scraped data the way it appears in prompts, for use
+// as the input half of an Example.
+func SourceText(data *scraper.Result) string {
 	var builder strings.Builder
-	builder.WriteString("You are a helpful assistant that converts scraped website data into clean HTML.\n")
-	builder.WriteString("Study the information, infer the primary theme or purpose of the source page, and reflect it in the layout and copy.\n")
-	builder.WriteString("Reimagine the page with modern styling and structure while faithfully preserving all information, wording, lists, tables, media references, and outbound links.\n")
-	builder.WriteString("Do not summarise or omit details—represent the source content in full, simply with improved presentation.\n")
-	builder.WriteString("Use semantic HTML5, include a descriptive hero or title section, themed subsections, and contextual highlights that match the inferred theme.\n")
-	builder.WriteString("Ensure every original link is present and clickable, and reference the original source prominently.\n")
-	builder.WriteString("Do not wrap the output in Markdown code fences.\n\n")
+	writeSourceData(&builder, data)
+	return builder.String()
+}
+
+// anchorInstruction asks the model to keep the block ids so #fragment links resolve.
+const anchorInstruction = "Give each heading and paragraph you output the id shown in {#…} beside its source, as an id attribute, so links to #fragments keep working.\n"
+
+// footnoteInstruction asks the model to keep [^label] references linked.
+const footnoteInstruction = "Keep each [^label] footnote reference as a superscript link to its note in a notes list at the end, and give every note a link back to its reference.\n"
 
+// codeInstruction asks the model to keep code samples exactly as scraped.
+const codeInstruction = "Reproduce every code block verbatim inside <pre><code class=\"language-…\"> using its stated language; never reformat, translate or shorten code.\n"
+
+// mathInstruction asks the model to typeset [math:id] markers as MathML.
+const mathInstruction = "Replace each [math:id] marker with its formula as MathML: copy the given MathML verbatim, or convert the TeX into an equivalent <math> element. Give display formulas display=\"block\".\n"
+
+// writeByline adds the page's author and publication date, when known.
+func writeByline(builder *strings.Builder, data *scraper.Result) {
+	if data.Author != "" {
+		builder.WriteString("Author: ")
+		builder.WriteString(data.Author)
+		builder.WriteString("\n")
+	}
+	if !data.PublishedAt.IsZero() {
+		builder.WriteString("Published: ")
+		builder.WriteString(data.PublishedAt.Format("2006-01-02"))
+		builder.WriteString("\n")
+	}
+}
+
+func writeSourceData(builder *strings.Builder, data *scraper.Result) {
 	builder.WriteString("Source URL: ")
 	builder.WriteString(data.SourceURL)
 	builder.WriteString("\n")
@@ -142,42 +670,89 @@ func buildPrompt(data *scraper.Result) string {
 		builder.WriteString("\n")
 	}
 
+	writeByline(builder, data)
+
 	if len(data.Headings) > 0 {
-		builder.WriteString("Headings:\n")
+		builder.WriteString("Headings (levels corrected into a consistent outline; keep this hierarchy):\n")
 		for _, h := range data.Headings {
-			builder.WriteString(fmt.Sprintf("- H%d %s\n", h.Level, h.Text))
+			builder.WriteString(fmt.Sprintf("- H%d %s {#%s}\n", h.Level, h.Text, h.ID))
 		}
 	}
 
 	if len(data.Paragraphs) > 0 {
 		builder.WriteString("Paragraphs:\n")
 		for _, p := range data.Paragraphs {
-			builder.WriteString("- ")
+			builder.WriteString("- {#")
+			builder.WriteString(scraper.ParagraphID(p))
+			builder.WriteString("} ")
 			builder.WriteString(p)
 			builder.WriteString("\n")
 		}
 	}
 
+	if len(data.CodeBlocks) > 0 {
+		builder.WriteString("Code blocks:\n")
+		for _, block := range data.CodeBlocks {
+			builder.WriteString("```")
+			builder.WriteString(block.Language)
+			builder.WriteString("\n")
+			builder.WriteString(block.Code)
+			builder.WriteString("\n```\n")
+		}
+	}
+
+	if len(data.Formulas) > 0 {
+		builder.WriteString("Formulas (referenced above as [math:id]):\n")
+		for _, f := range data.Formulas {
+			builder.WriteString(fmt.Sprintf("[math:%s]", f.ID))
+			if f.Display {
+				builder.WriteString(" (display)")
+			}
+			if f.MathML != "" {
+				builder.WriteString(" MathML: ")
+				builder.WriteString(f.MathML)
+			} else {
+				builder.WriteString(" TeX: ")
+				builder.WriteString(f.TeX)
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	if len(data.Footnotes) > 0 {
+		builder.WriteString("Footnotes (referenced above as [^label]):\n")
+		for _, note := range data.Footnotes {
+			builder.WriteString(fmt.Sprintf("[^%s]: %s\n", note.Label, note.Text))
+		}
+	}
+
 	if len(data.Links) > 0 {
-		builder.WriteString("Links:\n")
+		builder.WriteString("Links (tagged by where they appear: content links are cited in the text and matter most; navigation, footer, external and anchor links are page chrome, so use them only when relevant):\n")
 		for _, link := range data.Links {
 			builder.WriteString("- ")
+			if link.Class != "" {
+				builder.WriteString("[")
+				builder.WriteString(string(link.Class))
+				builder.WriteString("] ")
+			}
 			builder.WriteString(link.Text)
 			builder.WriteString(" -> ")
 			builder.WriteString(link.Href)
 			builder.WriteString("\n")
 		}
 	}
-
-	builder.WriteString("\nReturn only raw HTML inside <html> tags.")
-
-	return builder.String()
 }
 
 func (c *Client) completionsURL() string {
 	if c.baseURL == "" {
 		return ""
 	}
+	switch c.endpoint {
+	case EndpointAzure:
+		return c.deploymentURL(c.model, "chat/completions")
+	case EndpointLlamaCpp:
+		return c.apiRoot() + "/completion"
+	}
 
 	if strings.HasSuffix(c.baseURL, "/v1/chat/completions") {
 		return c.baseURL
@@ -191,12 +766,14 @@ func (c *Client) completionsURL() string {
 	return trimmed + "/v1/chat/completions"
 }
 
-const systemPrompt = "You are a helpful assistant that turns structured website data into clean, self-contained HTML pages without using Markdown code fences. Infer the purpose or theme of the content, tailor the layout accordingly, and preserve every piece of information and link without summarising or omitting details."
+// DefaultSystemPrompt is the system message sent when no override is configured.
+const DefaultSystemPrompt = "You are a helpful assistant that turns structured website data into clean, self-contained HTML pages without using Markdown code fences. Infer the purpose or theme of the content, tailor the layout accordingly, and preserve every piece of information and link without summarising or omitting details."
 
 // HTTPError represents a non-successful HTTP status returned by the LLM endpoint.
 type HTTPError struct {
-	Status int
-	Body   string
+	Status     int
+	Body       string
+	RetryAfter time.Duration
 }
 
 func (e *HTTPError) Error() string {
@@ -212,46 +789,50 @@ func IsRateLimited(err error) bool {
 	return false
 }
 
-func sanitizeLLMOutput(content string) string {
-	trimmed := strings.TrimSpace(content)
-	if !strings.HasPrefix(trimmed, "```") {
-		return trimmed
-	}
-
-	trimmed = strings.TrimPrefix(trimmed, "```")
-	trimmed = strings.TrimSpace(trimmed)
-
-	if strings.HasPrefix(trimmed, "html") {
-		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "html"))
-	}
-
-	if idx := strings.Index(trimmed, "```"); idx >= 0 {
-		trimmed = trimmed[:idx]
-	}
-
-	return strings.TrimSpace(trimmed)
-}
-
 type chatMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// Images are PNGs sent along with Content; see MarshalJSON.
+	Images [][]byte `json:"-"`
+	// ToolCalls are the tools an assistant message asks to run, and
+	// ToolCallID the call a tool message answers.
+	ToolCalls  []toolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
 }
 
 type chatCompletionRequest struct {
 	Model       string        `json:"model"`
 	Messages    []chatMessage `json:"messages"`
 	Temperature float64       `json:"temperature,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+	Tools          []tool          `json:"tools,omitempty"`
+	// ToolChoice is "none" when Tools are declared but may not be called.
+	ToolChoice string `json:"tool_choice,omitempty"`
+	// Provider holds OpenRouter's routing preferences.
+	Provider *providerPreferences `json:"provider,omitempty"`
 }
 
 type chatCompletionResponse struct {
-	Choices []struct {
-		Message chatMessage `json:"message"`
-	} `json:"choices"`
+	Choices []chatChoice `json:"choices"`
+	// Model is the model that answered; Provider is the upstream provider
+	// OpenRouter routed the request to.
+	Model    string `json:"model"`
+	Provider string `json:"provider"`
+}
+
+type chatChoice struct {
+	Message chatMessage `json:"message"`
 }
 
 func (r chatCompletionResponse) FirstMessage() string {
+	return r.firstReply().Content
+}
+
+func (r chatCompletionResponse) firstReply() chatMessage {
 	if len(r.Choices) == 0 {
-		return ""
+		return chatMessage{}
 	}
-	return r.Choices[0].Message.Content
+	return r.Choices[0].Message
 }
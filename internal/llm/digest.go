@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// DigestItem is the only information about a visited page that a digest sends:
+// its title and domain, never its address or content.
+type DigestItem struct {
+	Title  string
+	Domain string
+}
+
+// DigestPrompt returns the exact prompt GenerateDigest sends for items, so it
+// can be shown to the user beforehand.
+func DigestPrompt(items []DigestItem, opts PageOptions) string {
+	var builder strings.Builder
+	builder.WriteString("Create a personal start page for a web browser as a single self-contained HTML document.\n")
+	builder.WriteString("Below are the titles and domains of pages the reader visited recently, newest first. Group them into a few themes that reflect the reader's current interests, give each theme a short heading and a one-sentence description, and list the matching pages by title with their domain.\n")
+	builder.WriteString("Do not invent pages, links or facts about their content; you only know the titles. Do not wrap the output in Markdown code fences.\n")
+	writeStyleHints(&builder, opts)
+	builder.WriteString("\nRecent pages:\n")
+	for _, item := range items {
+		builder.WriteString("- ")
+		builder.WriteString(item.Title)
+		builder.WriteString(" (")
+		builder.WriteString(item.Domain)
+		builder.WriteString(")\n")
+	}
+	builder.WriteString("\nReturn only raw HTML inside <html> tags.")
+	return builder.String()
+}
+
+// GenerateDigest asks the LLM for a start page built from recently visited titles and domains.
+func (c *Client) GenerateDigest(ctx context.Context, items []DigestItem, opts PageOptions) (string, error) {
+	if !c.Available() {
+		return "", ErrUnavailable
+	}
+	if len(items) == 0 {
+		return "", errors.New("no history to summarise")
+	}
+
+	html, err := c.complete(ctx, DigestPrompt(items, opts))
+	if err != nil {
+		return "", err
+	}
+	if html == "" {
+		return "", errors.New("llm response empty")
+	}
+	return html, nil
+}
@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// maxEmbedBatch caps the texts sent in one /v1/embeddings request.
+const maxEmbedBatch = 32
+
+// EmbeddingModel returns the configured embedding model; empty when
+// semantic search is off.
+func (c *Client) EmbeddingModel() string {
+	if c == nil {
+		return ""
+	}
+	return c.embeddingModel
+}
+
+// CanEmbed reports whether Embed can be used.
+func (c *Client) CanEmbed() bool {
+	return c.Available() && c.embeddingModel != ""
+}
+
+// Embed returns a vector for each of texts from the embedding model. It
+// uses the OpenAI-compatible /v1/embeddings route and falls back to
+// Ollama's /api/embeddings, one text at a time, when the endpoint has no
+// such route. Embedding requests do not count towards the circuit breaker,
+// so a missing embedding model never pauses page composition.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if !c.CanEmbed() {
+		return nil, ErrUnavailable
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxEmbedBatch {
+		batch := texts[start:min(start+maxEmbedBatch, len(texts))]
+		var (
+			out [][]float32
+			err error
+		)
+		if !c.ollamaEmbeddings.Load() {
+			out, err = c.embedOpenAI(ctx, batch)
+			var httpErr *HTTPError
+			if errors.As(err, &httpErr) && (httpErr.Status == http.StatusNotFound || httpErr.Status == http.StatusMethodNotAllowed) && c.endpoint != EndpointAzure {
+				c.ollamaEmbeddings.Store(true)
+			}
+		}
+		if c.ollamaEmbeddings.Load() {
+			out, err = c.embedOllama(ctx, batch)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("embed: %w", err)
+		}
+		if len(out) != len(batch) {
+			return nil, fmt.Errorf("embed: got %d vectors for %d texts", len(out), len(batch))
+		}
+		vectors = append(vectors, out...)
+	}
+	return vectors, nil
+}
+
+func (c *Client) embedOpenAI(ctx context.Context, texts []string) ([][]float32, error) {
+	encoded, err := json.Marshal(struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}{c.embeddingModel, texts})
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	var parsed struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	err = c.retry.do(ctx, func() error {
+		parsed.Data = nil
+		return c.postJSON(ctx, c.embeddingsURL(), encoded, &parsed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(parsed.Data, func(i, j int) bool { return parsed.Data[i].Index < parsed.Data[j].Index })
+	vectors := make([][]float32, 0, len(parsed.Data))
+	for _, d := range parsed.Data {
+		vectors = append(vectors, d.Embedding)
+	}
+	return vectors, nil
+}
+
+func (c *Client) embedOllama(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		encoded, err := json.Marshal(struct {
+			Model  string `json:"model"`
+			Prompt string `json:"prompt"`
+		}{c.embeddingModel, text})
+		if err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+
+		var parsed struct {
+			Embedding []float32 `json:"embedding"`
+		}
+		err = c.retry.do(ctx, func() error {
+			parsed.Embedding = nil
+			return c.postJSON(ctx, c.apiRoot()+"/api/embeddings", encoded, &parsed)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(parsed.Embedding) == 0 {
+			return nil, errors.New("empty embedding")
+		}
+		vectors = append(vectors, parsed.Embedding)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Endpoint is the kind of API a Client talks to.
+type Endpoint string
+
+const (
+	// EndpointAuto picks the kind from the base URL's host, and the
+	// OpenAI API for hosts it does not know.
+	EndpointAuto Endpoint = ""
+	// EndpointOpenAI is the OpenAI API and servers compatible with it,
+	// such as Ollama, llama.cpp and vLLM.
+	EndpointOpenAI Endpoint = "openai"
+	// EndpointAzure is Azure OpenAI: the model names a deployment, each
+	// request carries an api-version and the key goes in an api-key header.
+	EndpointAzure Endpoint = "azure"
+	// EndpointOpenRouter is OpenRouter: requests name the app and carry
+	// provider routing preferences, and replies name the model and
+	// upstream provider that wrote them.
+	EndpointOpenRouter Endpoint = "openrouter"
+	// EndpointLlamaCpp is the native /completion route of llama.cpp's
+	// server, for builds without the OpenAI-compatible one: messages are
+	// laid out in the model's prompt format, and tools and images are not
+	// sent.
+	EndpointLlamaCpp Endpoint = "llamacpp"
+)
+
+// Endpoints lists every Endpoint, in settings order.
+var Endpoints = []Endpoint{EndpointAuto, EndpointOpenAI, EndpointAzure, EndpointOpenRouter, EndpointLlamaCpp}
+
+// DefaultAzureAPIVersion is the api-version sent to Azure OpenAI when the
+// base URL names none, as in https://example.openai.azure.com/?api-version=2024-06-01.
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// errNoDeployments is what ListModels says for Azure OpenAI, whose
+// deployments cannot be listed with an API key.
+var errNoDeployments = errors.New("Azure OpenAI does not list deployments; enter the deployment name as the model")
+
+// Label names e for the settings dialog.
+func (e Endpoint) Label() string {
+	switch e {
+	case EndpointOpenAI:
+		return "OpenAI-compatible"
+	case EndpointAzure:
+		return "Azure OpenAI"
+	case EndpointOpenRouter:
+		return "OpenRouter"
+	case EndpointLlamaCpp:
+		return "llama.cpp /completion"
+	default:
+		return "Automatic"
+	}
+}
+
+// ParseEndpoint returns the Endpoint named name, or EndpointAuto for names
+// it does not know.
+func ParseEndpoint(name string) Endpoint {
+	for _, e := range Endpoints {
+		if string(e) == name {
+			return e
+		}
+	}
+	return EndpointAuto
+}
+
+// resolveEndpoint returns the kind of API at baseURL: e, unless it is
+// EndpointAuto.
+func resolveEndpoint(e Endpoint, baseURL string) Endpoint {
+	if e != EndpointAuto {
+		return e
+	}
+	parsed, err := url.Parse(baseURL)
+	switch {
+	case err != nil:
+		return EndpointOpenAI
+	case strings.HasSuffix(strings.ToLower(parsed.Hostname()), ".openai.azure.com"):
+		return EndpointAzure
+	case openRouterHost(parsed.Hostname()):
+		return EndpointOpenRouter
+	default:
+		return EndpointOpenAI
+	}
+}
+
+// azureBase splits an Azure OpenAI base URL into the resource address,
+// without any /openai path, and the api-version it names.
+func azureBase(baseURL string) (root, apiVersion string) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return strings.TrimRight(baseURL, "/"), DefaultAzureAPIVersion
+	}
+	apiVersion = parsed.Query().Get("api-version")
+	if apiVersion == "" {
+		apiVersion = DefaultAzureAPIVersion
+	}
+	path, _, _ := strings.Cut(parsed.Path, "/openai")
+	parsed.Path, parsed.RawPath, parsed.RawQuery, parsed.Fragment = strings.TrimRight(path, "/"), "", "", ""
+	return parsed.String(), apiVersion
+}
+
+// azureURL returns the address of an Azure OpenAI operation, such as
+// "models" or "deployments/gpt-4o/chat/completions".
+func (c *Client) azureURL(operation string) string {
+	return c.baseURL + "/openai/" + operation + "?api-version=" + url.QueryEscape(c.apiVersion)
+}
+
+// deploymentURL returns the address of operation, such as "embeddings",
+// on an Azure OpenAI deployment.
+func (c *Client) deploymentURL(deployment, operation string) string {
+	return c.azureURL("deployments/" + url.PathEscape(deployment) + "/" + operation)
+}
+
+// embeddingsURL returns the address of the OpenAI-style embeddings route.
+func (c *Client) embeddingsURL() string {
+	if c.endpoint == EndpointAzure {
+		return c.deploymentURL(c.embeddingModel, "embeddings")
+	}
+	return c.apiRoot() + "/v1/embeddings"
+}
+
+// authorize adds the API key to req, if there is one, and for OpenRouter
+// the headers naming the app.
+func (c *Client) authorize(req *http.Request) {
+	switch {
+	case c.apiKey == "":
+	case c.endpoint == EndpointAzure:
+		req.Header.Set("api-key", c.apiKey)
+	default:
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if c.endpoint == EndpointOpenRouter {
+		req.Header.Set("X-Title", c.appTitle)
+		if c.appURL != "" {
+			req.Header.Set("HTTP-Referer", c.appURL)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package llm
+
+import "chimera/internal/scraper"
+
+// Estimate is the expected prompt size and price of a request.
+type Estimate struct {
+	// InputTokens approximates the prompt tokens sent, system prompts and
+	// few-shot examples included.
+	InputTokens int
+	// Cost is InputTokens priced at Config.InputPrice; zero when no price
+	// is configured.
+	Cost float64
+}
+
+// InputPrice returns the configured price per 1000 prompt tokens.
+func (c *Client) InputPrice() float64 {
+	if c == nil {
+		return 0
+	}
+	return c.inputPrice
+}
+
+// EstimatePage estimates the prompt GeneratePage would send for data with
+// opts, without sending it: the page prompt and the examples that fit, or
+// every part and the page shell when the page has to be chunked. The
+// reply is not counted, and the estimate is zero when the prompt template
+// fails.
+func (c *Client) EstimatePage(data *scraper.Result, opts PageOptions) Estimate {
+	if !c.Available() {
+		return Estimate{}
+	}
+	system := EstimateTokens(c.systemPrompt)
+	prompt, err := c.buildPrompt(data, opts)
+	if err != nil {
+		return Estimate{}
+	}
+
+	var tokens int
+	if !c.needsChunking(prompt) {
+		tokens = system + EstimateTokens(prompt)
+		for _, ex := range c.fitExamples(prompt, c.fewShot(opts)) {
+			tokens += EstimateTokens(ex.Input) + EstimateTokens(ex.Output)
+		}
+	} else {
+		chunks := chunkResult(data, c.chunkBudget(data, opts))
+		for i, chunk := range chunks {
+			tokens += system + EstimateTokens(buildSectionPrompt(chunk, i+1, len(chunks), opts))
+		}
+		tokens += system + EstimateTokens(buildShellPrompt(data, chunks, opts))
+	}
+	return Estimate{InputTokens: tokens, Cost: float64(tokens) / 1000 * c.inputPrice}
+}
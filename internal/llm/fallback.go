@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"net/url"
+)
+
+// Provider is an endpoint a Client falls back to when the ones before it
+// fail a request.
+type Provider struct {
+	// Name labels the provider in status messages; empty uses its model
+	// and host.
+	Name    string
+	BaseURL string
+	Model   string
+	APIKey  string
+}
+
+// Label returns p's name, or its model and host when it has none.
+func (p Provider) Label() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	host := p.BaseURL
+	if parsed, err := url.Parse(p.BaseURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	if p.Model == "" {
+		return host
+	}
+	return p.Model + " at " + host
+}
+
+// FallbackNotifier is called when failed could not answer a request, with
+// the error it gave, before next is asked instead.
+type FallbackNotifier func(failed, next Provider, err error)
+
+type fallbackNotifierKey struct{}
+
+// WithFallbackNotifier returns a context that reports switches to a
+// fallback provider to fn.
+func WithFallbackNotifier(ctx context.Context, fn FallbackNotifier) context.Context {
+	return context.WithValue(ctx, fallbackNotifierKey{}, fn)
+}
+
+func fallbackNotifier(ctx context.Context) FallbackNotifier {
+	fn, _ := ctx.Value(fallbackNotifierKey{}).(FallbackNotifier)
+	return fn
+}
+
+// newFallbacks builds a client per provider with cfg's other settings,
+// skipping providers without a base URL. The kind of API of each is told
+// from its host.
+func newFallbacks(cfg Config) []*Client {
+	var clients []*Client
+	for _, p := range cfg.Fallbacks {
+		if p.BaseURL == "" {
+			continue
+		}
+		sub := cfg
+		sub.BaseURL, sub.Model, sub.APIKey = p.BaseURL, p.Model, p.APIKey
+		sub.Endpoint, sub.Fallbacks = EndpointAuto, nil
+		client := NewClient(sub)
+		client.name = p.Name
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// Provider describes the client's endpoint.
+func (c *Client) Provider() Provider {
+	if c == nil {
+		return Provider{}
+	}
+	return Provider{Name: c.name, BaseURL: c.baseURL, Model: c.model, APIKey: c.apiKey}
+}
+
+// Fallbacks returns the providers tried, in order, when the client's own
+// endpoint fails a request.
+func (c *Client) Fallbacks() []Provider {
+	if c == nil {
+		return nil
+	}
+	providers := make([]Provider, len(c.fallbacks))
+	for i, next := range c.fallbacks {
+		providers[i] = next.Provider()
+	}
+	return providers
+}
+
+// failOver reports whether a request that failed with err should be sent
+// to the next provider: it did fail, it was not cancelled, and it was not
+// turned down for an option the caller asks again without.
+func failOver(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil && !unsupported(err)
+}
+
+// adapt returns payload for c: its model, and without images unless c's
+// model accepts them.
+func (c *Client) adapt(payload chatCompletionRequest) chatCompletionRequest {
+	payload.Model = c.model
+	if c.vision {
+		return payload
+	}
+	messages := make([]chatMessage, len(payload.Messages))
+	for i, message := range payload.Messages {
+		message.Images = nil
+		messages[i] = message
+	}
+	payload.Messages = messages
+	return payload
+}
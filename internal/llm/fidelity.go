@@ -0,0 +1,253 @@
+package llm
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"chimera/internal/scraper"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// keySentences caps the sentences of a page checked in a composition:
+	// the first sentence of each of its first substantial paragraphs.
+	keySentences = 10
+	// keySentenceWords is the fewest words a key sentence has.
+	keySentenceWords = 8
+	// keptWordShare is the share of a key sentence's words a composition
+	// must contain for the sentence to count as kept, paraphrased or not.
+	keptWordShare = 0.6
+	// fidelityListed caps the links named in Fidelity.Problems.
+	fidelityListed = 3
+)
+
+// textURLPattern finds URLs written out in the text of a page.
+var textURLPattern = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+`)
+
+// Fidelity compares a composition with the page it was composed from.
+type Fidelity struct {
+	// MissingLinks are links in the text of the page that the composition
+	// leaves out.
+	MissingLinks []string
+	// UnknownLinks are links in the composition to URLs that neither the
+	// page nor the related pages mention; the model may have made them up.
+	UnknownLinks []string
+	// KeySentences is how many of the page's key sentences were looked
+	// for, and KeptSentences how many of them the composition carries.
+	KeySentences  int
+	KeptSentences int
+}
+
+// Suspect reports whether the composition drops or invents links, or
+// leaves out most of the page's key sentences.
+func (f Fidelity) Suspect() bool {
+	return len(f.MissingLinks) > 0 || len(f.UnknownLinks) > 0 || f.KeptSentences*2 < f.KeySentences
+}
+
+// Problems describes what makes f Suspect, one sentence each.
+func (f Fidelity) Problems() []string {
+	var problems []string
+	if n := len(f.UnknownLinks); n > 0 {
+		problems = append(problems, fmt.Sprintf("%s not on the original page: %s", plural(n, "link"), listLinks(f.UnknownLinks)))
+	}
+	if n := len(f.MissingLinks); n > 0 {
+		problems = append(problems, fmt.Sprintf("%s from the text left out: %s", plural(n, "link"), listLinks(f.MissingLinks)))
+	}
+	if f.KeptSentences*2 < f.KeySentences {
+		problems = append(problems, fmt.Sprintf("Only %d of %d key sentences kept", f.KeptSentences, f.KeySentences))
+	}
+	return problems
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func listLinks(links []string) string {
+	if len(links) <= fidelityListed {
+		return strings.Join(links, ", ")
+	}
+	return strings.Join(links[:fidelityListed], ", ") + fmt.Sprintf(" and %d more", len(links)-fidelityListed)
+}
+
+// CheckFidelity compares the composition html with data, the page it was
+// composed from, and related, the linked pages it was given too. Links are
+// compared without fragments or trailing slashes; sentences by their
+// words, so faithful paraphrases pass.
+func CheckFidelity(html string, data *scraper.Result, related []*scraper.Result) (Fidelity, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return Fidelity{}, fmt.Errorf("parse composition: %w", err)
+	}
+	base, err := url.Parse(data.FinalURL)
+	if err != nil || data.FinalURL == "" {
+		base, _ = url.Parse(data.SourceURL)
+	}
+
+	composed := make(map[string]bool)
+	var order []string
+	doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		if key, ok := linkKey(base, href); ok && !composed[key] {
+			composed[key] = true
+			order = append(order, key)
+		}
+	})
+
+	var f Fidelity
+	known := knownLinks(base, data, related)
+	for _, key := range order {
+		if !known[key] {
+			f.UnknownLinks = append(f.UnknownLinks, key)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, link := range data.Links {
+		key, ok := linkKey(base, link.Href)
+		if !ok || link.Class != scraper.LinkContent || composed[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		f.MissingLinks = append(f.MissingLinks, key)
+	}
+
+	doc.Find("script, style, noscript").Remove()
+	words := make(map[string]bool)
+	for _, w := range significantWords(doc.Text()) {
+		words[w] = true
+	}
+	for _, sentence := range pickKeySentences(data.Paragraphs) {
+		f.KeySentences++
+		want := significantWords(sentence)
+		found := 0
+		for _, w := range want {
+			if words[w] {
+				found++
+			}
+		}
+		if float64(found) >= keptWordShare*float64(len(want)) {
+			f.KeptSentences++
+		}
+	}
+	return f, nil
+}
+
+// knownLinks returns the keys of every URL the composition may link to:
+// the page itself, its links and URLs written in its text, the same for
+// each related page, and the root of the page's site.
+func knownLinks(base *url.URL, data *scraper.Result, related []*scraper.Result) map[string]bool {
+	known := make(map[string]bool)
+	add := func(href string) {
+		if key, ok := linkKey(base, href); ok {
+			known[key] = true
+		}
+	}
+	for _, page := range append([]*scraper.Result{data}, related...) {
+		if page == nil {
+			continue
+		}
+		for _, href := range append([]string{page.SourceURL, page.FinalURL, page.Canonical, page.NextPage}, page.Redirects...) {
+			add(href)
+		}
+		for _, href := range page.JoinedPages {
+			add(href)
+		}
+		for _, link := range page.Links {
+			add(link.Href)
+		}
+		texts := append([]string{page.Description}, page.Paragraphs...)
+		for _, note := range page.Footnotes {
+			texts = append(texts, note.Text)
+		}
+		for _, block := range page.CodeBlocks {
+			texts = append(texts, block.Code)
+		}
+		for _, text := range texts {
+			for _, href := range textURLPattern.FindAllString(text, -1) {
+				add(strings.TrimRight(href, ".,;:!?"))
+			}
+		}
+	}
+	if base != nil && base.Host != "" {
+		add(base.Scheme + "://" + base.Host + "/")
+	}
+	return known
+}
+
+// linkKey resolves href against base and normalises it for comparison;
+// ok is false for links that do not leave the page, such as fragments,
+// and for schemes other than http and https.
+func linkKey(base *url.URL, href string) (string, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return "", false
+	}
+	resolved, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		resolved = base.ResolveReference(resolved)
+	}
+	if resolved.Scheme != "http" && resolved.Scheme != "https" || resolved.Host == "" {
+		return "", false
+	}
+	resolved.Fragment = ""
+	resolved.RawFragment = ""
+	resolved.Host = strings.ToLower(resolved.Host)
+	resolved.Path = strings.TrimSuffix(resolved.Path, "/")
+	resolved.RawPath = ""
+	return resolved.String(), true
+}
+
+// pickKeySentences returns the first sentence of each of the first
+// keySentences paragraphs that has one of at least keySentenceWords words.
+func pickKeySentences(paragraphs []string) []string {
+	var sentences []string
+	for _, p := range paragraphs {
+		if len(sentences) == keySentences {
+			break
+		}
+		sentence := firstSentence(p)
+		if len(strings.Fields(sentence)) >= keySentenceWords {
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences
+}
+
+func firstSentence(paragraph string) string {
+	paragraph = strings.TrimSpace(paragraph)
+	for i, r := range paragraph {
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		next := paragraph[i+1:]
+		if next == "" || next[0] == ' ' || next[0] == '\n' {
+			return paragraph[:i+1]
+		}
+	}
+	return paragraph
+}
+
+// significantWords returns the lowercased words of text that carry
+// meaning, leaving out short ones such as articles and prepositions.
+func significantWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, w := range fields {
+		if len([]rune(w)) >= 4 {
+			words = append(words, w)
+		}
+	}
+	return words
+}
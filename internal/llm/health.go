@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Health is what Ping found out about the endpoint.
+type Health struct {
+	// Reachable reports that the endpoint answered at all.
+	Reachable bool
+	// Authorized reports that it accepted the API key, or needs none.
+	Authorized bool
+	// Latency is how long the answer took.
+	Latency time.Duration
+	// Err says what went wrong; nil when the endpoint can be used.
+	Err error
+}
+
+// OK reports whether the endpoint can be used.
+func (h Health) OK() bool {
+	return h.Err == nil
+}
+
+// String describes h in a few words for the status bar or a tooltip.
+func (h Health) String() string {
+	switch {
+	case h.OK():
+		return fmt.Sprintf("Connected, %d ms", h.Latency.Milliseconds())
+	case h.Reachable && !h.Authorized:
+		return fmt.Sprintf("API key rejected: %v", h.Err)
+	case h.Reachable:
+		return fmt.Sprintf("Endpoint answered with an error: %v", h.Err)
+	default:
+		return fmt.Sprintf("Cannot reach the endpoint: %v", h.Err)
+	}
+}
+
+// Ping checks that the endpoint answers and accepts the API key by listing
+// its models, on /v1/models or, for Ollama, /api/tags, and measures how
+// long that takes. It is not retried and does not count towards the
+// circuit breaker.
+func (c *Client) Ping(ctx context.Context) Health {
+	if !c.Available() {
+		return Health{Err: ErrUnavailable}
+	}
+	start := time.Now()
+	status, err := c.probe(ctx, c.healthURL())
+	if err == nil && status == http.StatusNotFound && c.endpoint != EndpointAzure {
+		start = time.Now()
+		status, err = c.probe(ctx, c.apiRoot()+"/api/tags")
+	}
+	h := Health{Latency: time.Since(start)}
+	if err != nil {
+		h.Err = err
+		return h
+	}
+	h.Reachable = true
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		h.Err = &HTTPError{Status: status}
+	case status >= 500:
+		h.Authorized = true
+		h.Err = &HTTPError{Status: status}
+	default:
+		// Servers without a models route still show they are up.
+		h.Authorized = true
+	}
+	return h
+}
+
+// healthURL returns the address Ping asks: llama.cpp's /health, which
+// also tells whether the model is still loading, or the model list.
+func (c *Client) healthURL() string {
+	if c.endpoint == EndpointLlamaCpp {
+		return c.apiRoot() + "/health"
+	}
+	return c.modelsURL()
+}
+
+// modelsURL returns the address of the endpoint's model list.
+func (c *Client) modelsURL() string {
+	if c.endpoint == EndpointAzure {
+		return c.azureURL("models")
+	}
+	return c.apiRoot() + "/v1/models"
+}
+
+// probe sends a GET to endpoint and returns the status, discarding the body.
+func (c *Client) probe(ctx context.Context, endpoint string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	c.authorize(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return 0, errors.New("no answer in time")
+		}
+		// The request's method and URL only clutter the message shown.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return 0, urlErr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultPromptFormat is the prompt format used with llama.cpp's
+// /completion route when Config.PromptFormat is empty or unknown.
+const DefaultPromptFormat = "chatml"
+
+// PromptFormats lists the names Config.PromptFormat accepts, the default
+// first.
+var PromptFormats = []string{"chatml", "llama3", "mistral", "gemma", "alpaca"}
+
+// promptFormat lays chat messages out as the single prompt of a completion,
+// the way a model was trained to read them.
+type promptFormat struct {
+	// system, user and assistant lay out a message of that role, its
+	// content in place of %s. Formats without a system role leave system
+	// empty; the system message then opens the first user message.
+	system, user, assistant string
+	// reply opens the model's turn at the end of the prompt.
+	reply string
+	// stop ends the model's turn.
+	stop []string
+}
+
+var promptFormats = map[string]promptFormat{
+	"chatml": {
+		system:    "<|im_start|>system\n%s<|im_end|>\n",
+		user:      "<|im_start|>user\n%s<|im_end|>\n",
+		assistant: "<|im_start|>assistant\n%s<|im_end|>\n",
+		reply:     "<|im_start|>assistant\n",
+		stop:      []string{"<|im_end|>"},
+	},
+	"llama3": {
+		system:    "<|start_header_id|>system<|end_header_id|>\n\n%s<|eot_id|>",
+		user:      "<|start_header_id|>user<|end_header_id|>\n\n%s<|eot_id|>",
+		assistant: "<|start_header_id|>assistant<|end_header_id|>\n\n%s<|eot_id|>",
+		reply:     "<|start_header_id|>assistant<|end_header_id|>\n\n",
+		stop:      []string{"<|eot_id|>"},
+	},
+	"mistral": {
+		user:      "[INST] %s [/INST]",
+		assistant: " %s</s>",
+		stop:      []string{"</s>", "[INST]"},
+	},
+	"gemma": {
+		user:      "<start_of_turn>user\n%s<end_of_turn>\n",
+		assistant: "<start_of_turn>model\n%s<end_of_turn>\n",
+		reply:     "<start_of_turn>model\n",
+		stop:      []string{"<end_of_turn>"},
+	},
+	"alpaca": {
+		system:    "%s\n\n",
+		user:      "### Instruction:\n%s\n\n",
+		assistant: "### Response:\n%s\n\n",
+		reply:     "### Response:\n",
+		stop:      []string{"### Instruction:"},
+	},
+}
+
+// lookupPromptFormat returns the format named name, or the default one.
+func lookupPromptFormat(name string) promptFormat {
+	if format, ok := promptFormats[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return format
+	}
+	return promptFormats[DefaultPromptFormat]
+}
+
+// render lays messages out as a prompt ending with the model's turn.
+// Images and tool calls are left out; the route takes neither.
+func (f promptFormat) render(messages []chatMessage) string {
+	var builder strings.Builder
+	var pending string
+	for _, message := range messages {
+		content := message.Content
+		switch message.Role {
+		case "system":
+			if f.system == "" {
+				pending = content
+				continue
+			}
+			fmt.Fprintf(&builder, f.system, content)
+		case "assistant":
+			fmt.Fprintf(&builder, f.assistant, content)
+		default:
+			if pending != "" {
+				content, pending = pending+"\n\n"+content, ""
+			}
+			fmt.Fprintf(&builder, f.user, content)
+		}
+	}
+	builder.WriteString(f.reply)
+	return builder.String()
+}
+
+// completionRequest is the body of a request to llama.cpp's /completion.
+type completionRequest struct {
+	Prompt      string         `json:"prompt"`
+	NPredict    int            `json:"n_predict,omitempty"`
+	Temperature float64        `json:"temperature,omitempty"`
+	Stop        []string       `json:"stop,omitempty"`
+	CachePrompt bool           `json:"cache_prompt"`
+	JSONSchema  map[string]any `json:"json_schema,omitempty"`
+}
+
+type completionResponse struct {
+	Content string `json:"content"`
+	Model   string `json:"model"`
+}
+
+// encodeChat encodes payload for c's completions route: as it is, or for
+// llama.cpp's /completion as a prompt in c's format, keeping its JSON
+// schema but not its tools.
+func (c *Client) encodeChat(payload chatCompletionRequest) ([]byte, error) {
+	if c.endpoint != EndpointLlamaCpp {
+		return json.Marshal(payload)
+	}
+	request := completionRequest{
+		Prompt:      c.promptFormat.render(payload.Messages),
+		NPredict:    payload.MaxTokens,
+		Temperature: payload.Temperature,
+		Stop:        c.promptFormat.stop,
+		CachePrompt: true,
+	}
+	if format := payload.ResponseFormat; format != nil && format.JSONSchema != nil {
+		request.JSONSchema = format.JSONSchema.Schema
+	}
+	return json.Marshal(request)
+}
+
+// postChatJSON posts an encodeChat body to c's completions route once and
+// returns the reply as a chat completion.
+func (c *Client) postChatJSON(ctx context.Context, body []byte) (chatCompletionResponse, error) {
+	var parsed chatCompletionResponse
+	if c.endpoint != EndpointLlamaCpp {
+		err := c.postJSON(ctx, c.completionsURL(), body, &parsed)
+		return parsed, err
+	}
+	var completion completionResponse
+	if err := c.postJSON(ctx, c.completionsURL(), body, &completion); err != nil {
+		return parsed, err
+	}
+	parsed.Choices = []chatChoice{{Message: chatMessage{Role: "assistant", Content: completion.Content}}}
+	return parsed, nil
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ListModels returns the model names offered by the endpoint. It queries the
+// OpenAI-compatible /v1/models route and falls back to Ollama's /api/tags.
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	if !c.Available() {
+		return nil, ErrUnavailable
+	}
+	if c.endpoint == EndpointAzure {
+		return nil, errNoDeployments
+	}
+
+	var openAI struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	err := c.getJSON(ctx, c.modelsURL(), &openAI)
+	if err == nil && len(openAI.Data) > 0 {
+		names := make([]string, 0, len(openAI.Data))
+		for _, m := range openAI.Data {
+			names = append(names, m.ID)
+		}
+		return sortModels(names), nil
+	}
+
+	var ollama struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if ollamaErr := c.getJSON(ctx, c.apiRoot()+"/api/tags", &ollama); ollamaErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("list models: %w", err)
+		}
+		return nil, fmt.Errorf("list models: %w", ollamaErr)
+	}
+	names := make([]string, 0, len(ollama.Models))
+	for _, m := range ollama.Models {
+		names = append(names, m.Name)
+	}
+	return sortModels(names), nil
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	c.authorize(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return &HTTPError{Status: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode llm response: %w", err)
+	}
+	return nil
+}
+
+// apiRoot strips any /v1, /v1/chat/completions or llama.cpp /completion
+// suffix from the base URL.
+func (c *Client) apiRoot() string {
+	root := strings.TrimRight(c.baseURL, "/")
+	root = strings.TrimSuffix(root, "/completion")
+	root = strings.TrimSuffix(root, "/chat/completions")
+	return strings.TrimSuffix(root, "/v1")
+}
+
+func sortModels(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	out := names[:0]
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
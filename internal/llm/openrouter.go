@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultAppTitle is the X-Title OpenRouter lists requests under when
+// Config.AppTitle is empty.
+const DefaultAppTitle = "Chimera"
+
+// Routing holds OpenRouter's provider routing preferences.
+type Routing struct {
+	// Order names the upstream providers to try first, in order, such as
+	// "Anthropic" or "Together".
+	Order []string
+	// Sort ranks the other providers by "price", "throughput" or
+	// "latency"; empty leaves it to OpenRouter's load balancing.
+	Sort string
+	// Only keeps requests to the providers in Order.
+	Only bool
+	// DenyDataCollection skips providers that may store or train on
+	// prompts.
+	DenyDataCollection bool
+}
+
+// RoutingSorts lists the values Routing.Sort accepts, the default first.
+var RoutingSorts = []string{"", "price", "throughput", "latency"}
+
+// IsZero reports whether r leaves routing to OpenRouter.
+func (r Routing) IsZero() bool {
+	return len(r.Order) == 0 && r.Sort == "" && !r.Only && !r.DenyDataCollection
+}
+
+// providerPreferences is the "provider" member of an OpenRouter request.
+type providerPreferences struct {
+	Order          []string `json:"order,omitempty"`
+	Sort           string   `json:"sort,omitempty"`
+	AllowFallbacks *bool    `json:"allow_fallbacks,omitempty"`
+	DataCollection string   `json:"data_collection,omitempty"`
+}
+
+// preferences returns r as sent to OpenRouter; nil when r is zero.
+func (r Routing) preferences() *providerPreferences {
+	if r.IsZero() {
+		return nil
+	}
+	prefs := &providerPreferences{Order: r.Order, Sort: r.Sort}
+	if r.Only && len(r.Order) > 0 {
+		allow := false
+		prefs.AllowFallbacks = &allow
+	}
+	if r.DenyDataCollection {
+		prefs.DataCollection = "deny"
+	}
+	return prefs
+}
+
+// Served tells which model answered a request, as the endpoint reports it.
+type Served struct {
+	// Model is the model that wrote the reply, such as
+	// "anthropic/claude-3.5-sonnet" for a request to "openrouter/auto".
+	Model string
+	// Provider is the upstream provider OpenRouter routed the request
+	// to, such as "Anthropic".
+	Provider string
+}
+
+// ServedNotifier is called with each reply's Served from an OpenRouter
+// endpoint.
+type ServedNotifier func(served Served)
+
+type servedNotifierKey struct{}
+
+// WithServedNotifier returns a context that reports which model and
+// upstream provider OpenRouter answered requests with to fn.
+func WithServedNotifier(ctx context.Context, fn ServedNotifier) context.Context {
+	return context.WithValue(ctx, servedNotifierKey{}, fn)
+}
+
+func servedNotifier(ctx context.Context) ServedNotifier {
+	fn, _ := ctx.Value(servedNotifierKey{}).(ServedNotifier)
+	return fn
+}
+
+// reportServed passes what OpenRouter says served parsed on to ctx's
+// notifier.
+func (c *Client) reportServed(ctx context.Context, parsed chatCompletionResponse) {
+	if c.endpoint != EndpointOpenRouter || parsed.Model == "" && parsed.Provider == "" {
+		return
+	}
+	if notify := servedNotifier(ctx); notify != nil {
+		notify(Served{Model: parsed.Model, Provider: parsed.Provider})
+	}
+}
+
+// openRouterHost reports whether host is OpenRouter's.
+func openRouterHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "openrouter.ai" || strings.HasSuffix(host, ".openrouter.ai")
+}
@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// reasoningTags are the wrappers reasoning models put around their chain of thought.
+var reasoningTags = []string{"think", "thinking", "reasoning", "reflection"}
+
+// fencePattern matches a Markdown code fence with an optional language tag.
+var fencePattern = regexp.MustCompile("(?s)```[a-zA-Z0-9_-]*[ \t]*\r?\n?(.*?)(?:```|$)")
+
+// sanitizeLLMOutput extracts the HTML from a model reply. It drops reasoning
+// sections, picks the most relevant fenced block when the reply is fenced,
+// and trims any prose around the outermost document. Fences inside an
+// unfenced document, such as a code sample in a <pre>, are kept.
+func sanitizeLLMOutput(content string) string {
+	text := stripReasoning(content)
+	if fenced(text) {
+		text = pickFencedBlock(text)
+	}
+	if doc, ok := outermostDocument(text); ok {
+		return doc
+	}
+	return strings.TrimSpace(text)
+}
+
+// stripReasoning removes <think>-style blocks. A closing tag without an
+// opening one drops everything before it; an unterminated block keeps only the
+// document that follows it, if any.
+func stripReasoning(text string) string {
+	for _, tag := range reasoningTags {
+		for {
+			stripped, ok := stripReasoningTag(text, tag)
+			if !ok {
+				break
+			}
+			text = stripped
+		}
+	}
+	return text
+}
+
+func stripReasoningTag(text, tag string) (string, bool) {
+	open, closing := "<"+tag+">", "</"+tag+">"
+	lower := strings.ToLower(text)
+	start := strings.Index(lower, open)
+	end := strings.Index(lower, closing)
+
+	switch {
+	case end >= 0 && (start < 0 || start > end):
+		// The chat template already consumed the opening tag.
+		return text[end+len(closing):], true
+	case start >= 0 && end > start:
+		return text[:start] + text[end+len(closing):], true
+	case start >= 0:
+		rest := text[start+len(open):]
+		if doc, ok := outermostDocument(rest); ok {
+			return text[:start] + doc, true
+		}
+		return text[:start], true
+	default:
+		return text, false
+	}
+}
+
+// fenced reports whether the page in text is inside a fenced block: text
+// starts with a fence, or has no document outside its fences.
+func fenced(text string) bool {
+	if strings.HasPrefix(strings.TrimSpace(text), "```") {
+		return true
+	}
+	if !fencePattern.MatchString(text) {
+		return false
+	}
+	_, ok := outermostDocument(fencePattern.ReplaceAllString(text, ""))
+	return !ok
+}
+
+// pickFencedBlock returns the contents of the fenced block most likely to
+// hold the page: the first containing an <html> or <body> element, otherwise
+// the longest. Text without fences is returned unchanged.
+func pickFencedBlock(text string) string {
+	matches := fencePattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	best := ""
+	for _, m := range matches {
+		block := strings.TrimSpace(m[1])
+		lower := strings.ToLower(block)
+		if strings.Contains(lower, "<html") || strings.Contains(lower, "<body") {
+			return block
+		}
+		if len(block) > len(best) {
+			best = block
+		}
+	}
+	return best
+}
+
+// outermostDocument returns the text from the doctype or first <html> tag to
+// the last </html>, dropping any prose before or after it.
+func outermostDocument(text string) (string, bool) {
+	lower := strings.ToLower(text)
+	start := strings.Index(lower, "<!doctype html")
+	if start < 0 {
+		start = strings.Index(lower, "<html")
+	}
+	if start < 0 {
+		return "", false
+	}
+
+	end := strings.LastIndex(lower, "</html>")
+	if end < start {
+		// Truncated reply: keep everything after the start tag.
+		return strings.TrimSpace(text[start:]), true
+	}
+	return strings.TrimSpace(text[start : end+len("</html>")]), true
+}
@@ -0,0 +1,35 @@
+package llm
+
+import "testing"
+
+func TestOutput_SanitizeLLMOutput(t *testing.T) {
+	const page = "<!DOCTYPE html><html><body><p>Hi</p></body></html>"
+	const withSample = "<!DOCTYPE html><html><body><pre><code>\n```go\nfmt.Println(1)\n```\n</code></pre></body></html>"
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain document", page, page},
+		{"prose around document", "Sure, here it is:\n" + page + "\nEnjoy!", page},
+		{"reasoning preamble", "Let me lay the page out first.\n\n```html\n" + page + "\n```\nDone.", page},
+		{"fenced", "```html\n" + page + "\n```", page},
+		{"unterminated fence", "```html\n" + page, page},
+		{"longest of several fences", "```css\np{}\n```\n```html\n" + page + "\n```", page},
+		{"unfenced with inner fence", withSample, withSample},
+		{"prose and inner fence", "Here you go:\n" + withSample, withSample},
+		{"think block", "<think>The user wants ```html``` output.</think>\n" + page, page},
+		{"think block then fence", "<think>plan</think>\n```html\n" + page + "\n```", page},
+		{"closing think only", "planning the layout</think>" + page, page},
+		{"unterminated think", "<think>still planning " + page, page},
+		{"fragment", "  <p>Hi</p>\n", "<p>Hi</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeLLMOutput(tt.content); got != tt.want {
+				t.Errorf("sanitizeLLMOutput(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
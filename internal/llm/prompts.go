@@ -0,0 +1,214 @@
+package llm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"chimera/internal/scraper"
+	"chimera/internal/storage"
+)
+
+// promptDir is where prompt templates live in the configuration backend,
+// one "<name>.tmpl" file each.
+const promptDir = "prompts/"
+
+// SystemTemplate names the template of the system prompt.
+const SystemTemplate = "system"
+
+// defaultTemplates are the built-in prompt templates, written to the
+// configuration directory the first time prompts are loaded so they can
+// be edited.
+var defaultTemplates = map[string]string{
+	SystemTemplate: DefaultSystemPrompt + "\n",
+
+	"compose": `You are a helpful assistant that converts scraped website data into clean HTML.
+Study the information, infer the primary theme or purpose of the source page, and reflect it in the layout and copy.
+Reimagine the page with modern styling and structure while faithfully preserving all information, wording, lists, tables, media references, and outbound links.
+Do not summarise or omit details—represent the source content in full, simply with improved presentation.
+Use semantic HTML5, include a descriptive hero or title section, themed subsections, and contextual highlights that match the inferred theme.
+Ensure every original link is present and clickable, and reference the original source prominently.
+When an author or publication date is given, credit them beneath the title.
+{{ .Rules }}{{ .Hints }}
+{{ .Source }}{{ .Related }}
+Return only raw HTML inside <html> tags.
+`,
+
+	"summarize": `You are a helpful assistant that converts scraped website data into clean HTML.
+Study the information, infer the primary theme or purpose of the source page, and reflect it in the layout and copy.
+Summarise the page into a concise, well-structured digest: a short overview followed by the key points, keeping important figures, names, and the most relevant links.
+Use semantic HTML5 with a descriptive title section and reference the original source prominently.
+When an author or publication date is given, credit them beneath the title.
+{{ .Rules }}{{ .Hints }}
+{{ .Source }}{{ .Related }}
+Return only raw HTML inside <html> tags.
+`,
+
+	"translate": `You are a helpful assistant that converts scraped website data into clean HTML.
+Study the information, infer the primary theme or purpose of the source page, and reflect it in the layout and copy.
+Translate every piece of text into the requested language while preserving all information, structure, lists, tables, media references, and outbound links.
+Do not summarise or omit details. Keep link targets unchanged and reference the original source prominently.
+When an author or publication date is given, credit them beneath the title.
+{{ .Rules }}{{ .Hints }}
+{{ .Source }}{{ .Related }}
+Return only raw HTML inside <html> tags.
+`,
+
+	"eli5": `You are a helpful assistant that converts scraped website data into clean HTML.
+Study the information, infer the primary theme or purpose of the source page, and reflect it in the layout and copy.
+Explain the page so a curious ten-year-old could follow it: short sentences, everyday words, and concrete examples or analogies, defining any term that cannot be avoided.
+Keep every fact accurate, cover the main points in the page's order, and link to the most relevant pages it mentions.
+Use semantic HTML5 with a friendly title section and reference the original source prominently.
+When an author or publication date is given, credit them beneath the title.
+{{ .Rules }}{{ .Hints }}
+{{ .Source }}{{ .Related }}
+Return only raw HTML inside <html> tags.
+`,
+}
+
+// PromptData is what a page prompt template is executed with.
+type PromptData struct {
+	// Page is the scraped page, for templates that lay out its fields
+	// themselves.
+	Page *scraper.Result
+	// Task names the action the prompt is for, such as "summarize".
+	Task string
+	// Language, Note, Typography and Lite are the PageOptions fields of
+	// the same name.
+	Language   string
+	Note       string
+	Typography string
+	Lite       bool
+	// Rules asks the model to keep anchors, footnotes, code and formulas
+	// intact and not to use code fences, one instruction per line.
+	Rules string
+	// Hints are the instructions for Language, Note, Typography and Lite,
+	// one per line; empty when none is set.
+	Hints string
+	// Source is the page data as the built-in prompts present it, and
+	// Related the extracts of linked pages, empty when there are none.
+	Source  string
+	Related string
+}
+
+// Prompts is a set of named prompt templates. A nil *Prompts holds the
+// built-in ones.
+type Prompts struct {
+	templates map[string]*template.Template
+}
+
+// DefaultPrompts returns the built-in prompt templates.
+func DefaultPrompts() *Prompts {
+	p := &Prompts{templates: make(map[string]*template.Template, len(defaultTemplates))}
+	for name, text := range defaultTemplates {
+		p.templates[name] = template.Must(template.New(name).Parse(text))
+	}
+	return p
+}
+
+// LoadPrompts reads the prompt templates kept in backend, first writing the
+// built-in ones that are missing so they can be edited. Any other
+// "<name>.tmpl" file adds a template called name. A template that does not
+// parse is reported in the error and its built-in one, if any, is used
+// instead. The returned Prompts is usable either way.
+func LoadPrompts(backend storage.Backend) (*Prompts, error) {
+	p := DefaultPrompts()
+	if backend == nil {
+		return p, nil
+	}
+
+	var errs []error
+	for name, text := range defaultTemplates {
+		key := promptDir + name + ".tmpl"
+		if _, err := backend.Get(key); errors.Is(err, storage.ErrNotFound) {
+			if err := backend.Put(key, []byte(text)); err != nil {
+				errs = append(errs, fmt.Errorf("write prompt %s: %w", name, err))
+			}
+		}
+	}
+
+	keys, err := backend.List(promptDir)
+	if err != nil {
+		return p, errors.Join(append(errs, fmt.Errorf("list prompts: %w", err))...)
+	}
+	for _, key := range keys {
+		rel := strings.TrimPrefix(key, promptDir)
+		name := strings.TrimSuffix(rel, ".tmpl")
+		if !strings.HasSuffix(rel, ".tmpl") || name == "" || strings.Contains(rel, "/") {
+			continue
+		}
+		text, err := backend.Get(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read prompt %s: %w", name, err))
+			continue
+		}
+		tmpl, err := template.New(name).Parse(string(text))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parse prompt %s: %w", name, err))
+			continue
+		}
+		p.templates[name] = tmpl
+	}
+	return p, errors.Join(errs...)
+}
+
+// Names returns the names of the page prompt templates, sorted, leaving out
+// the system prompt.
+func (p *Prompts) Names() []string {
+	if p == nil {
+		p = DefaultPrompts()
+	}
+	names := make([]string, 0, len(p.templates))
+	for name := range p.templates {
+		if name != SystemTemplate {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// System returns the system prompt from its template, or
+// DefaultSystemPrompt when the template is missing, fails or is empty.
+func (p *Prompts) System() string {
+	if p == nil || p.templates[SystemTemplate] == nil {
+		return DefaultSystemPrompt
+	}
+	var out bytes.Buffer
+	if err := p.templates[SystemTemplate].Execute(&out, nil); err != nil {
+		return DefaultSystemPrompt
+	}
+	if text := strings.TrimSpace(out.String()); text != "" {
+		return text
+	}
+	return DefaultSystemPrompt
+}
+
+// resolve returns the template a prompt for task asks for by name: name
+// itself when p holds it, the template of task otherwise.
+func (p *Prompts) resolve(name string, task Task) string {
+	if name == "" || name == SystemTemplate || p.templates[name] == nil {
+		return task.String()
+	}
+	return name
+}
+
+// render executes the template resolve picks with data.
+func (p *Prompts) render(name string, task Task, data PromptData) (string, error) {
+	if p == nil {
+		p = DefaultPrompts()
+	}
+	name = p.resolve(name, task)
+	tmpl := p.templates[name]
+	if tmpl == nil {
+		return "", fmt.Errorf("no prompt template %q", name)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", name, err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"chimera/internal/examples"
+	"chimera/internal/storage"
+)
+
+func TestPrompts_FewShotFromExamples(t *testing.T) {
+	backend, err := storage.NewFiles(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Put(promptDir+"brief.tmpl", []byte("{{ .Source }}")); err != nil {
+		t.Fatal(err)
+	}
+	prompts, err := LoadPrompts(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := examples.NewStoreWith(backend)
+	added := time.Now()
+	for i, template := range []string{"summarize", "summarize", "brief", "summarize", "summarize", "compose"} {
+		_, err := saved.Add(examples.Example{
+			ID:       fmt.Sprint(i),
+			Template: template,
+			Input:    fmt.Sprintf("input %d", i),
+			Output:   fmt.Sprintf(" output %d\n", i),
+			AddedAt:  added.Add(time.Duration(i) * time.Second),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := NewClient(Config{BaseURL: "http://localhost", Prompts: prompts, Examples: saved})
+	tests := []struct {
+		name string
+		opts PageOptions
+		want []string
+	}{
+		{"most recent of the task's template", PageOptions{Task: TaskSummarize}, []string{"input 1", "input 3", "input 4"}},
+		{"chosen template", PageOptions{Task: TaskSummarize, Template: "brief"}, []string{"input 2"}},
+		{"missing template falls back to the task", PageOptions{Task: TaskCompose, Template: "gone"}, []string{"input 5"}},
+		{"none saved", PageOptions{Task: TaskTranslate}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inputs []string
+			for _, e := range client.fewShot(tt.opts) {
+				inputs = append(inputs, e.Input)
+				if e.Output != "output "+e.Input[len("input "):] {
+					t.Errorf("output %q does not match input %q", e.Output, e.Input)
+				}
+			}
+			if !slices.Equal(inputs, tt.want) {
+				t.Errorf("fewShot = %q, want %q", inputs, tt.want)
+			}
+		})
+	}
+
+	if got := NewClient(Config{BaseURL: "http://localhost"}).fewShot(PageOptions{Task: TaskSummarize}); got != nil {
+		t.Errorf("fewShot without a store = %v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"sync"
+
+	"chimera/internal/gate"
+)
+
+// defaultMaxInFlight is how many requests a provider is sent at once when
+// Config.MaxInFlight is not set.
+const defaultMaxInFlight = 2
+
+// QueueNotifier is called while a request waits for provider, with its
+// place in line: 1 when it is sent next.
+type QueueNotifier func(provider Provider, position int)
+
+type queueNotifierKey struct{}
+
+// WithQueueNotifier returns a context that reports the place in line of
+// requests waiting for a busy provider to fn.
+func WithQueueNotifier(ctx context.Context, fn QueueNotifier) context.Context {
+	return context.WithValue(ctx, queueNotifierKey{}, fn)
+}
+
+func queueNotifier(ctx context.Context) QueueNotifier {
+	fn, _ := ctx.Value(queueNotifierKey{}).(QueueNotifier)
+	return fn
+}
+
+// queues holds a gate per provider base URL, shared by every Client of
+// the provider, so tabs, batch jobs and clients rebuilt by saving settings
+// wait in the same line.
+var queues = struct {
+	sync.Mutex
+	byBaseURL map[string]*gate.Gate
+}{byBaseURL: make(map[string]*gate.Gate)}
+
+// queueFor returns the gate of the provider at baseURL, letting limit
+// requests run at once; zero or less uses defaultMaxInFlight.
+func queueFor(baseURL string, limit int) *gate.Gate {
+	if limit <= 0 {
+		limit = defaultMaxInFlight
+	}
+	queues.Lock()
+	defer queues.Unlock()
+	g, ok := queues.byBaseURL[baseURL]
+	if !ok {
+		g = gate.New(limit)
+		queues.byBaseURL[baseURL] = g
+	}
+	g.SetLimit(limit)
+	return g
+}
+
+// enqueue waits until a request may be sent to provider through g,
+// reporting its place in line to ctx's notifier, and returns the function
+// that frees its slot. It gives up when ctx is done.
+func enqueue(ctx context.Context, g *gate.Gate, provider Provider) (func(), error) {
+	var moved func(position int)
+	if notify := queueNotifier(ctx); notify != nil {
+		moved = func(position int) { notify(provider, position) }
+	}
+	return g.Acquire(ctx, gate.Interactive, moved)
+}
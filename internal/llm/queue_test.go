@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueue_ReportsPlaceInLine(t *testing.T) {
+	g := queueFor("http://queue.test", 1)
+	if queueFor("http://queue.test", 1) != g {
+		t.Fatal("clients of one provider got different queues")
+	}
+	provider := Provider{Name: "local", BaseURL: "http://queue.test"}
+
+	release, err := enqueue(context.Background(), g, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	positions := make(chan int, 4)
+	ctx := WithQueueNotifier(context.Background(), func(p Provider, position int) {
+		if p != provider {
+			t.Errorf("notified for %+v", p)
+		}
+		positions <- position
+	})
+	sent := make(chan struct{})
+	go func() {
+		next, err := enqueue(ctx, g, provider)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		next()
+		close(sent)
+	}()
+
+	select {
+	case position := <-positions:
+		if position != 1 {
+			t.Errorf("position = %d, want 1", position)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("place in line not reported")
+	}
+	release()
+	select {
+	case <-sent:
+	case <-time.After(2 * time.Second):
+		t.Fatal("waiting request was not sent")
+	}
+}
+
+func TestQueue_FreesSlotDuringBackoff(t *testing.T) {
+	var hits atomic.Int32
+	failed := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			close(failed)
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{BaseURL: srv.URL, Model: "m", MaxInFlight: 1, Retries: 1, RetryBackoff: time.Second})
+	payload := chatCompletionRequest{Model: "m", Messages: []chatMessage{{Role: "user", Content: "hi"}}}
+	retried := make(chan error, 1)
+	go func() {
+		_, err := c.sendChat(context.Background(), payload)
+		retried <- err
+	}()
+	<-failed
+
+	start := time.Now()
+	if _, err := c.sendChat(context.Background(), payload); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited > 500*time.Millisecond {
+		t.Errorf("request waited %v for a slot held across a retry's backoff", waited)
+	}
+	if err := <-retried; err != nil {
+		t.Errorf("retried request: %v", err)
+	}
+}
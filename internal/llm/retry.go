@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"chimera/internal/retry"
+)
+
+type retryPolicy struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+func newRetryPolicy(cfg Config) retryPolicy {
+	policy := retryPolicy{
+		attempts:   cfg.Retries,
+		backoff:    cfg.RetryBackoff,
+		maxBackoff: cfg.RetryMaxBackoff,
+	}
+	if policy.attempts < 0 {
+		policy.attempts = 0
+	}
+	if policy.backoff <= 0 {
+		policy.backoff = time.Second
+	}
+	if policy.maxBackoff < policy.backoff {
+		policy.maxBackoff = 16 * policy.backoff
+	}
+	return policy
+}
+
+// do runs fn, retrying transient HTTP failures with exponential backoff and jitter.
+func (p retryPolicy) do(ctx context.Context, fn func() error) error {
+	backoff := p.backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > p.attempts || ctx.Err() != nil {
+			return err
+		}
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || !httpErr.Transient() {
+			return err
+		}
+
+		delay := time.Duration(float64(backoff) * (0.8 + 0.4*rand.Float64()))
+		if httpErr.RetryAfter > retry.MaxAfter {
+			return err
+		}
+		if httpErr.RetryAfter > delay {
+			delay = httpErr.RetryAfter
+		}
+
+		retry.Notify(ctx, attempt+1, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > p.maxBackoff {
+			backoff = p.maxBackoff
+		}
+	}
+}
+
+// Transient reports whether the status is worth retrying (429 or a 5xx gateway/server error).
+func (e *HTTPError) Transient() bool {
+	switch e.Status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// jsonSystemPrompt is the system message of structured requests, which
+// must not get the HTML instructions of the page system prompt.
+const jsonSystemPrompt = "You are a precise assistant that answers only with JSON matching the schema you are given. Do not add commentary or Markdown code fences."
+
+// Schema describes the JSON a structured request asks for.
+type Schema struct {
+	// Name identifies the schema to the endpoint, such as "facts"; letters,
+	// digits, underscores and dashes only.
+	Name string
+	// Definition is a JSON Schema, such as
+	// {"type": "object", "properties": {...}, "required": [...]}.
+	Definition map[string]any
+	// Strict asks the endpoint to hold the reply to Definition exactly.
+	// OpenAI then needs every property required and additionalProperties
+	// false on every object.
+	Strict bool
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict,omitempty"`
+}
+
+// CompleteJSON sends prompt asking for a reply that matches schema and
+// decodes it into out. The schema is passed as a json_schema response
+// format and also written into the prompt, so models on endpoints without
+// structured output know what to produce. An endpoint that rejects the
+// response format is asked again without it, and not sent it again.
+func (c *Client) CompleteJSON(ctx context.Context, prompt string, schema Schema, out any) error {
+	if !c.Available() {
+		return ErrUnavailable
+	}
+	definition, err := json.Marshal(schema.Definition)
+	if err != nil {
+		return fmt.Errorf("encode schema: %w", err)
+	}
+
+	request := chatCompletionRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: jsonSystemPrompt},
+			{Role: "user", Content: prompt + "\n\nReply with only a JSON value matching this JSON Schema:\n" + string(definition)},
+		},
+		Temperature: 0.1,
+	}
+	useFormat := !c.noResponseFormat.Load()
+	if useFormat {
+		request.ResponseFormat = &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: &jsonSchema{Name: schema.Name, Schema: schema.Definition, Strict: schema.Strict},
+		}
+	}
+
+	parsed, err := c.postChat(ctx, request)
+	if useFormat && unsupported(err) {
+		c.noResponseFormat.Store(true)
+		request.ResponseFormat = nil
+		parsed, err = c.postChat(ctx, request)
+	}
+	if err != nil {
+		return err
+	}
+	return DecodeJSONReply(parsed.FirstMessage(), out)
+}
+
+// unsupported reports whether err is the endpoint turning down a request
+// option it does not support, such as a response format or tools.
+func unsupported(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	return httpErr.Status == http.StatusBadRequest || httpErr.Status == http.StatusUnprocessableEntity
+}
+
+// DecodeJSONReply decodes the JSON in a model reply into out. Reasoning
+// sections, code fences and prose before the first object or array are
+// skipped, as is anything after the value.
+func DecodeJSONReply(reply string, out any) error {
+	text := pickFencedBlock(stripReasoning(reply))
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return errors.New("llm reply holds no JSON")
+	}
+	if err := json.NewDecoder(strings.NewReader(text[start:])).Decode(out); err != nil {
+		return fmt.Errorf("decode llm reply: %w", err)
+	}
+	return nil
+}
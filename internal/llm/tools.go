@@ -0,0 +1,190 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+
+	"chimera/internal/scraper"
+)
+
+const (
+	// DefaultFetchHops is how many pages a model may read per composition
+	// when FetchTool.MaxHops is zero.
+	DefaultFetchHops = 3
+	// DefaultFetchBytes is how much page text is passed back to the model
+	// per composition when FetchTool.MaxBytes is zero.
+	DefaultFetchBytes = 48 << 10
+)
+
+// FetchTool offers the model a fetch_url tool while it composes a page, so
+// it can read a linked FAQ or the next page of an article. Only links of
+// the page that stay on its origin may be fetched.
+type FetchTool struct {
+	// Fetch scrapes the page at target; (*scraper.Scraper).Scrape fits.
+	Fetch func(ctx context.Context, target string) (*scraper.Result, error)
+	// MaxHops caps the pages read and MaxBytes the page text passed back;
+	// zero uses DefaultFetchHops and DefaultFetchBytes.
+	MaxHops  int
+	MaxBytes int
+	// Fetched collects the pages the model read, in order.
+	Fetched []*scraper.Result
+}
+
+type tool struct {
+	Type     string       `json:"type"`
+	Function toolFunction `json:"function"`
+}
+
+type toolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type toolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// fetchTools declares the fetch_url tool in the OpenAI tools format.
+var fetchTools = []tool{{
+	Type: "function",
+	Function: toolFunction{
+		Name:        "fetch_url",
+		Description: "Read another page of the same site, such as a linked FAQ or the next page of an article, when the page you are composing refers to content you need. Only links listed in the page data can be fetched. Returns the page's data in the same format as the source.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"url": map[string]any{"type": "string", "description": "A link from the page data, absolute or relative to the page."},
+			},
+			"required": []string{"url"},
+		},
+	},
+}}
+
+// fetchSession tracks the tool calls of one composition.
+type fetchSession struct {
+	tool    *FetchTool
+	base    *url.URL
+	allowed map[string]bool
+	hops    int
+	bytes   int
+}
+
+// newFetchSession prepares tool for composing data; nil when tool is.
+func newFetchSession(tool *FetchTool, data *scraper.Result) *fetchSession {
+	if tool == nil || tool.Fetch == nil {
+		return nil
+	}
+	base, err := url.Parse(data.FinalURL)
+	if err != nil || data.FinalURL == "" {
+		base, _ = url.Parse(data.SourceURL)
+	}
+	s := &fetchSession{tool: tool, base: base, allowed: make(map[string]bool)}
+	hrefs := append([]string{data.NextPage}, data.JoinedPages...)
+	for _, link := range data.Links {
+		hrefs = append(hrefs, link.Href)
+	}
+	for _, href := range hrefs {
+		if key, ok := s.sameOrigin(href); ok {
+			s.allowed[key] = true
+		}
+	}
+	return s
+}
+
+// sameOrigin returns the key of href, resolved against the page, when it
+// shares the page's scheme and host.
+func (s *fetchSession) sameOrigin(href string) (string, bool) {
+	key, ok := linkKey(s.base, href)
+	if !ok || s.base == nil {
+		return "", false
+	}
+	parsed, err := url.Parse(key)
+	if err != nil || parsed.Scheme != s.base.Scheme || parsed.Host != s.base.Host {
+		return "", false
+	}
+	return key, true
+}
+
+// limits returns the tool's budgets, defaults filled in.
+func (s *fetchSession) limits() (hops, bytes int) {
+	hops, bytes = s.tool.MaxHops, s.tool.MaxBytes
+	if hops <= 0 {
+		hops = DefaultFetchHops
+	}
+	if bytes <= 0 {
+		bytes = DefaultFetchBytes
+	}
+	return hops, bytes
+}
+
+// open reports whether the model may still fetch pages.
+func (s *fetchSession) open() bool {
+	if s == nil || len(s.allowed) == 0 {
+		return false
+	}
+	hops, bytes := s.limits()
+	return s.hops < hops && s.bytes < bytes
+}
+
+// run carries out call and returns what to tell the model: the fetched
+// page's data, cut to the byte budget left, or why nothing was fetched.
+func (s *fetchSession) run(ctx context.Context, call toolCall) string {
+	if call.Function.Name != "fetch_url" {
+		return fmt.Sprintf("Unknown tool %q.", call.Function.Name)
+	}
+	if !s.open() {
+		return "The fetch budget for this page is used up; compose with what you have."
+	}
+	var args struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+		return fmt.Sprintf("Invalid arguments: %v.", err)
+	}
+	key, ok := s.sameOrigin(args.URL)
+	if !ok || !s.allowed[key] {
+		return "Only links from the page data that stay on the same site can be fetched."
+	}
+
+	s.hops++
+	target, _ := url.Parse(args.URL)
+	target = s.base.ResolveReference(target)
+	target.Fragment = ""
+	result, err := s.tool.Fetch(ctx, target.String())
+	if err != nil {
+		return fmt.Sprintf("Fetching %s failed: %v.", args.URL, err)
+	}
+	s.tool.Fetched = append(s.tool.Fetched, result)
+
+	text := SourceText(result)
+	_, budget := s.limits()
+	if left := budget - s.bytes; len(text) > left {
+		text = cutBytes(text, left) + "\n[Cut short: the fetch budget is used up.]"
+	}
+	s.bytes += len(text)
+	return text
+}
+
+// cutBytes returns the longest prefix of text of at most n bytes that ends
+// on a character boundary.
+func cutBytes(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if n >= len(text) {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
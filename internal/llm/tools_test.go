@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"chimera/internal/scraper"
+)
+
+func newToolCall(name, arguments string) toolCall {
+	var call toolCall
+	call.ID = "call-" + name
+	call.Function.Name = name
+	call.Function.Arguments = arguments
+	return call
+}
+
+func fetchCall(target string) toolCall {
+	return newToolCall("fetch_url", fmt.Sprintf(`{"url":%q}`, target))
+}
+
+func TestFetchSession_Run(t *testing.T) {
+	page := &scraper.Result{
+		SourceURL: "https://example.com/article",
+		FinalURL:  "https://example.com/article/",
+		NextPage:  "https://example.com/article?page=2",
+		Links: []scraper.Link{
+			{Href: "/faq"},
+			{Href: "other"},
+			{Href: "https://evil.example/faq"},
+			{Href: "http://example.com/insecure"},
+		},
+	}
+	var fetched []string
+	tool := &FetchTool{Fetch: func(ctx context.Context, target string) (*scraper.Result, error) {
+		fetched = append(fetched, target)
+		return &scraper.Result{SourceURL: target, Paragraphs: []string{"Text of " + target}}, nil
+	}}
+	s := newFetchSession(tool, page)
+
+	tests := []struct {
+		name   string
+		call   toolCall
+		want   string
+		target string
+	}{
+		{"page link", fetchCall("/faq"), "Text of https://example.com/faq", "https://example.com/faq"},
+		{"link relative to the final URL", fetchCall("other"), "Text of https://example.com/article/other", "https://example.com/article/other"},
+		{"link not on the page", fetchCall("/admin"), "Only links from the page data", ""},
+		{"other host", fetchCall("https://evil.example/faq"), "Only links from the page data", ""},
+		{"other scheme", fetchCall("http://example.com/insecure"), "Only links from the page data", ""},
+		{"unknown tool", newToolCall("rm", "{}"), `Unknown tool "rm"`, ""},
+		{"bad arguments", newToolCall("fetch_url", "{"), "Invalid arguments", ""},
+		{"next page", fetchCall("https://example.com/article?page=2#top"), "Text of https://example.com/article?page=2", "https://example.com/article?page=2"},
+		{"hops used up", fetchCall("/faq"), "budget for this page is used up", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched = nil
+			if got := s.run(context.Background(), tt.call); !strings.Contains(got, tt.want) {
+				t.Errorf("run = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.target != "" && (len(fetched) != 1 || fetched[0] != tt.target) {
+				t.Errorf("fetched %q, want %q", fetched, tt.target)
+			} else if tt.target == "" && len(fetched) != 0 {
+				t.Errorf("fetched %q, want nothing", fetched)
+			}
+		})
+	}
+	if s.hops != DefaultFetchHops || len(tool.Fetched) != DefaultFetchHops {
+		t.Errorf("hops = %d, fetched %d pages; want %d", s.hops, len(tool.Fetched), DefaultFetchHops)
+	}
+}
+
+func TestFetchSession_ByteBudget(t *testing.T) {
+	text := strings.Repeat("é", 100)
+	tool := &FetchTool{MaxHops: 10, MaxBytes: 301, Fetch: func(ctx context.Context, target string) (*scraper.Result, error) {
+		return &scraper.Result{Paragraphs: []string{text}}, nil
+	}}
+	s := newFetchSession(tool, &scraper.Result{SourceURL: "https://example.com/", Links: []scraper.Link{{Href: "/a"}}})
+
+	var got []string
+	for s.open() {
+		got = append(got, s.run(context.Background(), fetchCall("/a")))
+	}
+	if len(got) < 2 {
+		t.Fatalf("%d fetches before the budget ran out, want a cut one after the first", len(got))
+	}
+	last := got[len(got)-1]
+	if !strings.HasSuffix(last, "[Cut short: the fetch budget is used up.]") {
+		t.Errorf("last fetch not marked as cut: %q", last)
+	}
+	for i, text := range got {
+		if !utf8.ValidString(text) {
+			t.Errorf("fetch %d split a character: %q", i, text)
+		}
+	}
+	if s.bytes < tool.MaxBytes {
+		t.Errorf("bytes = %d, want the budget of %d used up", s.bytes, tool.MaxBytes)
+	}
+}
+
+func TestFetchSession_CutBytes(t *testing.T) {
+	tests := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{"hello", 3, "hel"},
+		{"hello", 10, "hello"},
+		{"hello", 0, ""},
+		{"héllo", 2, "h"},
+		{"héllo", 3, "hé"},
+		{"日本語", 5, "日"},
+		{"日本語", 6, "日本"},
+		{"🙂x", 3, ""},
+	}
+	for _, tt := range tests {
+		if got := cutBytes(tt.text, tt.n); got != tt.want {
+			t.Errorf("cutBytes(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFetchSession_FinalTurnKeepsTools(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []chatCompletionRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request chatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		requests = append(requests, request)
+		first := len(requests) == 1
+		mu.Unlock()
+		if first {
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"","tool_calls":[{"id":"1","type":"function","function":{"name":"fetch_url","arguments":"{\"url\":\"/faq\"}"}}]}}]}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"<p>done</p>"}}]}`))
+	}))
+	defer srv.Close()
+
+	tool := &FetchTool{MaxHops: 1, Fetch: func(ctx context.Context, target string) (*scraper.Result, error) {
+		return &scraper.Result{SourceURL: target}, nil
+	}}
+	c := NewClient(Config{BaseURL: srv.URL, Model: "m"})
+	fetch := newFetchSession(tool, &scraper.Result{SourceURL: "https://example.com/", Links: []scraper.Link{{Href: "/faq"}}})
+	got, err := c.converse(context.Background(), "compose", nil, nil, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "done") {
+		t.Errorf("converse = %q", got)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("%d requests, want 2", len(requests))
+	}
+	if len(requests[0].Tools) != 1 || requests[0].ToolChoice != "" {
+		t.Errorf("first request tools = %d, choice %q; want the tool offered", len(requests[0].Tools), requests[0].ToolChoice)
+	}
+	final := requests[1]
+	if len(final.Tools) != 1 || final.ToolChoice != "none" {
+		t.Errorf("final request tools = %d, choice %q; want the tool declared with choice none", len(final.Tools), final.ToolChoice)
+	}
+	if n := len(final.Messages); n < 2 || final.Messages[n-1].Role != "tool" || final.Messages[n-2].ToolCalls == nil {
+		t.Errorf("final request lacks the tool call and its result: %+v", final.Messages)
+	}
+}
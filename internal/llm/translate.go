@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxTranslateBatch caps the blocks per request; longer lists make models
+// more likely to merge or drop items.
+const maxTranslateBatch = 40
+
+// TranslateBlocks translates each block into language and returns the
+// translations in the same order. Blocks are sent as JSON arrays in batches
+// that fit the context window; footnote and formula markers are kept.
+func (c *Client) TranslateBlocks(ctx context.Context, blocks []string, language string) ([]string, error) {
+	if !c.Available() {
+		return nil, ErrUnavailable
+	}
+
+	budget := 0
+	if c.contextTokens > 0 {
+		budget = c.promptBudget() - EstimateTokens(c.systemPrompt) - EstimateTokens(buildTranslatePrompt(nil, language))
+	}
+
+	out := make([]string, 0, len(blocks))
+	for start := 0; start < len(blocks); {
+		end, used := start, 0
+		for end < len(blocks) && end-start < maxTranslateBatch {
+			cost := EstimateTokens(blocks[end]) + 2
+			if end > start && budget > 0 && used+cost > budget {
+				break
+			}
+			used += cost
+			end++
+		}
+
+		translated, err := c.translateBatch(ctx, blocks[start:end], language)
+		if err != nil {
+			return nil, fmt.Errorf("translate blocks %d–%d of %d: %w", start+1, end, len(blocks), err)
+		}
+		out = append(out, translated...)
+		start = end
+	}
+	return out, nil
+}
+
+func (c *Client) translateBatch(ctx context.Context, blocks []string, language string) ([]string, error) {
+	messages := []chatMessage{
+		{Role: "system", Content: c.systemPrompt},
+		{Role: "user", Content: buildTranslatePrompt(blocks, language)},
+	}
+	parsed, err := c.postChat(ctx, chatCompletionRequest{
+		Model:       c.model,
+		Messages:    messages,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var translated []string
+	if err := DecodeJSONReply(parsed.FirstMessage(), &translated); err != nil {
+		return nil, fmt.Errorf("decode translations: %w", err)
+	}
+	if len(translated) != len(blocks) {
+		return nil, fmt.Errorf("llm returned %d translations for %d blocks", len(translated), len(blocks))
+	}
+	return translated, nil
+}
+
+func buildTranslatePrompt(blocks []string, language string) string {
+	var builder strings.Builder
+	builder.WriteString("Translate each string in the JSON array below into " + language + ".\n")
+	builder.WriteString("Reply with only a JSON array of the translated strings, in the same order and with exactly as many items.\n")
+	builder.WriteString("Keep markers such as [^1] and [math:2], URLs, code, numbers and names unchanged. Do not add commentary.\n\n")
+	encoded, _ := json.Marshal(blocks)
+	builder.Write(encoded)
+	return builder.String()
+}
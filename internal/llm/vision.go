@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// Vision reports whether the configured model accepts images, so pages
+// can be sent with a screenshot.
+func (c *Client) Vision() bool {
+	return c.Available() && c.vision
+}
+
+// seesImages reports whether model is one of visionModels, ignoring case;
+// an entry without a ":tag" matches every tag of that model.
+func seesImages(model string, visionModels []string) bool {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return false
+	}
+	for _, name := range visionModels {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if model == name || !strings.Contains(name, ":") && strings.HasPrefix(model, name+":") {
+			return true
+		}
+	}
+	return false
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+// MarshalJSON writes a message with images in the OpenAI vision format:
+// its content becomes a text part followed by an image_url part per image,
+// holding it as a data: URL. Messages without images keep plain content.
+func (m chatMessage) MarshalJSON() ([]byte, error) {
+	type message struct {
+		Role       string     `json:"role"`
+		Content    any        `json:"content"`
+		ToolCalls  []toolCall `json:"tool_calls,omitempty"`
+		ToolCallID string     `json:"tool_call_id,omitempty"`
+	}
+	if len(m.Images) == 0 {
+		return json.Marshal(message{m.Role, m.Content, m.ToolCalls, m.ToolCallID})
+	}
+	parts := make([]contentPart, 0, 1+len(m.Images))
+	parts = append(parts, contentPart{Type: "text", Text: m.Content})
+	for _, image := range m.Images {
+		parts = append(parts, contentPart{
+			Type:     "image_url",
+			ImageURL: &imageURL{URL: "data:image/png;base64," + base64.StdEncoding.EncodeToString(image)},
+		})
+	}
+	return json.Marshal(message{m.Role, parts, m.ToolCalls, m.ToolCallID})
+}
@@ -0,0 +1,81 @@
+package locale
+
+import (
+	"os"
+	"strings"
+)
+
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"bg": "Bulgarian",
+	"ca": "Catalan",
+	"cs": "Czech",
+	"da": "Danish",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"et": "Estonian",
+	"fa": "Persian",
+	"fi": "Finnish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"hr": "Croatian",
+	"hu": "Hungarian",
+	"id": "Indonesian",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"lt": "Lithuanian",
+	"lv": "Latvian",
+	"nb": "Norwegian",
+	"nl": "Dutch",
+	"nn": "Norwegian",
+	"no": "Norwegian",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ro": "Romanian",
+	"ru": "Russian",
+	"sk": "Slovak",
+	"sl": "Slovenian",
+	"sr": "Serbian",
+	"sv": "Swedish",
+	"th": "Thai",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"vi": "Vietnamese",
+	"zh": "Chinese",
+}
+
+// Code returns the ISO 639-1 code of the UI language taken from LC_ALL,
+// LC_MESSAGES or LANG, defaulting to "en".
+func Code() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" || value == "C" || value == "POSIX" || strings.HasPrefix(value, "C.") {
+			continue
+		}
+		code := strings.ToLower(value)
+		if idx := strings.IndexAny(code, "_.@-"); idx >= 0 {
+			code = code[:idx]
+		}
+		if code != "" {
+			return code
+		}
+	}
+	return "en"
+}
+
+// Language returns the English name of the UI language, e.g. "German".
+func Language() string {
+	return Name(Code())
+}
+
+// Name maps a language code to its English name, returning the code itself when unknown.
+func Name(code string) string {
+	if name, ok := languageNames[strings.ToLower(code)]; ok {
+		return name
+	}
+	return code
+}
@@ -0,0 +1,123 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is one captured log entry.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Attrs holds the record's attributes formatted as key=value pairs.
+	Attrs string
+}
+
+// Buffer keeps the most recent log records in memory.
+type Buffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewBuffer returns a Buffer holding up to capacity records.
+func NewBuffer(capacity int) *Buffer {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &Buffer{records: make([]Record, capacity)}
+}
+
+// Records returns the buffered records, oldest first.
+func (b *Buffer) Records() []Record {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		return append([]Record(nil), b.records[:b.next]...)
+	}
+	out := make([]Record, 0, len(b.records))
+	out = append(out, b.records[b.next:]...)
+	return append(out, b.records[:b.next]...)
+}
+
+func (b *Buffer) add(r Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = r
+	b.next++
+	if b.next == len(b.records) {
+		b.next = 0
+		b.full = true
+	}
+}
+
+// handler copies every record into a Buffer before passing it on.
+type handler struct {
+	next   slog.Handler
+	buf    *Buffer
+	prefix string
+}
+
+// NewHandler returns a slog.Handler that records into buf and forwards to next.
+func NewHandler(next slog.Handler, buf *Buffer) slog.Handler {
+	return &handler{next: next, buf: buf}
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	var attrs []string
+	if h.prefix != "" {
+		attrs = append(attrs, h.prefix)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, formatAttr("", a))
+		return true
+	})
+	h.buf.add(Record{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: strings.Join(attrs, " ")})
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	parts := make([]string, 0, len(attrs)+1)
+	if h.prefix != "" {
+		parts = append(parts, h.prefix)
+	}
+	for _, a := range attrs {
+		parts = append(parts, formatAttr("", a))
+	}
+	return &handler{next: h.next.WithAttrs(attrs), buf: h.buf, prefix: strings.Join(parts, " ")}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), buf: h.buf, prefix: h.prefix}
+}
+
+func formatAttr(group string, a slog.Attr) string {
+	key := a.Key
+	if group != "" {
+		key = group + "." + key
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		parts := make([]string, 0, len(a.Value.Group()))
+		for _, sub := range a.Value.Group() {
+			parts = append(parts, formatAttr(key, sub))
+		}
+		return strings.Join(parts, " ")
+	}
+	return fmt.Sprintf("%s=%v", key, a.Value.Resolve().Any())
+}
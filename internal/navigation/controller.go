@@ -0,0 +1,317 @@
+package navigation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"chimera/internal/cache"
+	"chimera/internal/llm"
+	"chimera/internal/render"
+	"chimera/internal/retry"
+	"chimera/internal/scraper"
+)
+
+// Scraper fetches pages. *scraper.Scraper implements it.
+type Scraper interface {
+	Scrape(ctx context.Context, target string) (*scraper.Result, error)
+	Related(ctx context.Context, result *scraper.Result, limit int) []*scraper.Result
+}
+
+// Composer writes pages with an LLM. *llm.Client implements it.
+type Composer interface {
+	Available() bool
+	Model() string
+	Paused() time.Duration
+	GeneratePage(ctx context.Context, data *scraper.Result, opts llm.PageOptions) (string, error)
+}
+
+// Compositions caches composed pages. *cache.Store implements it.
+type Compositions interface {
+	Pinned(url string) (cache.Composition, bool, error)
+	Add(c cache.Composition) (cache.Composition, error)
+	Pin(url, id string) error
+}
+
+// Controller turns an address into a page to show: it scrapes the page,
+// then shows a pinned composition, composes it with the LLM or lays it out
+// in reader mode, and caches new compositions. It knows nothing about the
+// window it is shown in, so it runs the same with fakes of its parts.
+type Controller struct {
+	Scraper      Scraper
+	Compositions Compositions
+	// LLM returns the client to compose with; saving settings replaces it.
+	LLM func() Composer
+}
+
+// Request describes how to show a scraped page.
+type Request struct {
+	// Key identifies the page in the composition cache.
+	Key string
+	// UseLLM asks for Options.Task to be done by the LLM; otherwise, or
+	// when no LLM is available, the page is shown in reader mode.
+	UseLLM  bool
+	Options llm.PageOptions
+	// Reader styles the page shown in reader mode.
+	Reader render.Options
+	// Recompose composes again even when a composition is pinned; Repin
+	// pins the new one.
+	Recompose bool
+	Repin     bool
+	// EnrichLinks is how many linked pages a composition reads too; zero
+	// reads none.
+	EnrichLinks int
+	// FetchLinks lets the model read same-site pages the page links to
+	// while composing it.
+	FetchLinks bool
+	// Screenshot, when set, captures the original page for a model that
+	// can see. It is only called when a composition is written; when it
+	// fails, the page is composed from its text alone.
+	Screenshot func(ctx context.Context) ([]byte, error)
+	// Status reports progress; nil discards it.
+	Status func(text string)
+}
+
+func (r Request) status(text string) {
+	if r.Status != nil {
+		r.Status(text)
+	}
+}
+
+// Outcome is the page a Request produced.
+type Outcome struct {
+	HTML string
+	// Model is the model that wrote HTML; empty in reader mode.
+	Model string
+	// Provider labels the fallback provider that wrote HTML; empty when
+	// the configured endpoint did.
+	Provider string
+	// Upstream is the provider OpenRouter routed the request to, when it
+	// says; Model is then the model it reports.
+	Upstream string
+	// Composition is the cached composition shown, pinned or just stored;
+	// its ID is empty when none is.
+	Composition cache.Composition
+	// FromPin marks a pinned composition shown instead of composing.
+	FromPin bool
+	// RateLimited and Paused explain why reader mode was shown although
+	// the LLM was asked for.
+	RateLimited bool
+	Paused      time.Duration
+	// Fidelity compares a page just composed with the scraped page; it is
+	// zero for pinned compositions, other tasks and reader mode.
+	Fidelity llm.Fidelity
+}
+
+// ComposeError is an LLM failure that left nothing to show.
+type ComposeError struct {
+	Err error
+}
+
+func (e *ComposeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ComposeError) Unwrap() error {
+	return e.Err
+}
+
+// Fetch scrapes target, reporting retries through status.
+func (c *Controller) Fetch(ctx context.Context, target string, status func(text string)) (*scraper.Result, error) {
+	ctx = retry.WithNotifier(ctx, func(attempt int, err error) {
+		slog.Warn("scrape retry", "url", target, "attempt", attempt, "err", err)
+		if status != nil {
+			status(fmt.Sprintf("Retrying — attempt %d (%v)", attempt, err))
+		}
+	})
+	return c.Scraper.Scrape(ctx, target)
+}
+
+// Show produces the page for result as req asks, for every task. LLM
+// failures other than rate limits and a paused client are returned as a
+// *ComposeError; rate limits and pauses fall back to reader mode, as noted
+// in the Outcome.
+func (c *Controller) Show(ctx context.Context, req Request, result *scraper.Result) (Outcome, error) {
+	var client Composer
+	if c.LLM != nil {
+		client = c.LLM()
+	}
+	useLLM := req.UseLLM && client != nil && client.Available()
+	task := req.Options.Task
+
+	ctx, watch := watchLLM(ctx, req, result)
+	if useLLM && task == llm.TaskCompose && !req.Recompose {
+		if pinned, ok, err := c.Compositions.Pinned(req.Key); err != nil {
+			slog.Warn("load pinned composition", "url", req.Key, "err", err)
+		} else if ok {
+			return Outcome{HTML: pinned.HTML, Model: pinned.Model, Composition: pinned, FromPin: true}, nil
+		}
+	}
+
+	var outcome Outcome
+	if req.UseLLM && client != nil {
+		outcome.Paused = client.Paused()
+	}
+	if useLLM && outcome.Paused == 0 {
+		var html string
+		var related []*scraper.Result
+		var err error
+		if task == llm.TaskCompose {
+			html, related, err = c.compose(ctx, client, req, result)
+		} else {
+			req.status("Asking the LLM...")
+			html, err = client.GeneratePage(ctx, result, req.Options)
+		}
+		if ctx.Err() != nil {
+			return Outcome{}, ctx.Err()
+		}
+		if err == nil && task != llm.TaskCompose {
+			return watch.outcome(Outcome{HTML: html, Model: client.Model()}), nil
+		}
+		if err == nil {
+			composed := watch.outcome(Outcome{HTML: html, Model: client.Model()})
+			slog.Info("composed", "url", result.SourceURL, "model", composed.Model, "bytes", len(html))
+			fidelity, err := llm.CheckFidelity(html, result, related)
+			if err != nil {
+				slog.Warn("check composition", "url", result.SourceURL, "err", err)
+			} else if fidelity.Suspect() {
+				slog.Warn("composition strays from the page", "url", result.SourceURL, "missing_links", len(fidelity.MissingLinks), "unknown_links", len(fidelity.UnknownLinks), "key_sentences", fidelity.KeySentences, "kept", fidelity.KeptSentences)
+			}
+			composed.Composition = c.store(req, result, html, composed.Model)
+			composed.Fidelity = fidelity
+			return composed, nil
+		}
+
+		var circuitErr *llm.CircuitOpenError
+		switch {
+		case llm.IsRateLimited(err):
+			slog.Warn("llm rate limited; falling back to reader mode", "url", result.SourceURL, "task", task, "err", err)
+			outcome.RateLimited = true
+		case errors.As(err, &circuitErr):
+			outcome.Paused = circuitErr.RetryIn
+		default:
+			return Outcome{}, &ComposeError{Err: err}
+		}
+	}
+
+	html, err := render.Simple(result, req.Reader)
+	if err != nil {
+		return Outcome{}, fmt.Errorf("render: %w", err)
+	}
+	outcome.HTML = html
+	if req.UseLLM && outcome.Paused > 0 {
+		slog.Warn("llm paused; showing reader mode", "url", result.SourceURL, "retry_in", outcome.Paused.Round(time.Second))
+	}
+	return outcome, nil
+}
+
+// llmWatch remembers who answered the LLM requests for a page: the last
+// fallback provider that took over, and the model and upstream provider
+// OpenRouter last reported.
+type llmWatch struct {
+	fallback *llm.Provider
+	served   llm.Served
+}
+
+// watchLLM returns a context that reports retries, switches to a fallback
+// provider and waits for a busy one through req's status, and records the
+// switches and what OpenRouter reports in the returned watch.
+func watchLLM(ctx context.Context, req Request, result *scraper.Result) (context.Context, *llmWatch) {
+	watch := &llmWatch{}
+	ctx = retry.WithNotifier(ctx, func(attempt int, err error) {
+		slog.Warn("llm retry", "url", result.SourceURL, "attempt", attempt, "err", err)
+		req.status(fmt.Sprintf("LLM busy — retrying (attempt %d, %v)", attempt, err))
+	})
+	ctx = llm.WithFallbackNotifier(ctx, func(failed, next llm.Provider, err error) {
+		slog.Warn("llm provider failed; trying the next", "url", result.SourceURL, "provider", failed.Label(), "next", next.Label(), "err", err)
+		req.status(fmt.Sprintf("%s failed (%v) — trying %s...", failed.Label(), err, next.Label()))
+		watch.fallback = &next
+	})
+	ctx = llm.WithQueueNotifier(ctx, func(provider llm.Provider, position int) {
+		req.status(fmt.Sprintf("Waiting for %s — number %d in line...", provider.Label(), position))
+	})
+	ctx = llm.WithServedNotifier(ctx, func(served llm.Served) {
+		watch.served = served
+	})
+	return ctx, watch
+}
+
+// outcome credits o to the fallback provider, if one took over, and to the
+// model and upstream provider OpenRouter reported.
+func (w *llmWatch) outcome(o Outcome) Outcome {
+	if w.fallback != nil {
+		o.Provider = w.fallback.Label()
+		if w.fallback.Model != "" {
+			o.Model = w.fallback.Model
+		}
+	}
+	if w.served.Model != "" {
+		o.Model = w.served.Model
+	}
+	o.Upstream = w.served.Provider
+	return o
+}
+
+// compose asks client for a composition of result, first reading linked
+// pages and capturing the page when req asks for it. It returns the linked
+// pages read too, by it or by the model.
+func (c *Controller) compose(ctx context.Context, client Composer, req Request, result *scraper.Result) (string, []*scraper.Result, error) {
+	opts := req.Options
+	if req.EnrichLinks > 0 {
+		req.status("Reading linked pages...")
+		opts.Related = c.Scraper.Related(ctx, result, req.EnrichLinks)
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+	}
+	if req.Screenshot != nil {
+		req.status("Capturing the page for the model...")
+		png, err := req.Screenshot(ctx)
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+		if err != nil {
+			slog.Warn("capture page", "url", result.SourceURL, "err", err)
+		} else {
+			opts.Screenshot = png
+		}
+	}
+	if req.FetchLinks {
+		opts.Fetch = &llm.FetchTool{Fetch: func(ctx context.Context, target string) (*scraper.Result, error) {
+			req.status(fmt.Sprintf("The model is reading %s...", target))
+			return c.Scraper.Scrape(ctx, target)
+		}}
+	}
+
+	req.status("Composing...")
+	html, err := client.GeneratePage(ctx, result, opts)
+	related := opts.Related
+	if opts.Fetch != nil {
+		related = append(related, opts.Fetch.Fetched...)
+	}
+	return html, related, err
+}
+
+// store caches a new composition of result, pinning it when req asks, and
+// returns it; its ID is empty when it could not be stored.
+func (c *Controller) store(req Request, result *scraper.Result, html, model string) cache.Composition {
+	stored, err := c.Compositions.Add(cache.Composition{
+		URL:          req.Key,
+		HTML:         html,
+		Model:        model,
+		ETag:         result.Validators.ETag,
+		LastModified: result.Validators.LastModified,
+	})
+	if err != nil {
+		slog.Warn("store composition", "url", result.SourceURL, "err", err)
+		return cache.Composition{}
+	}
+	if req.Repin {
+		if err := c.Compositions.Pin(req.Key, stored.ID); err != nil {
+			slog.Warn("pin composition", "url", req.Key, "err", err)
+		}
+	}
+	return stored
+}
@@ -0,0 +1,316 @@
+package navigation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"chimera/internal/cache"
+	"chimera/internal/llm"
+	"chimera/internal/render"
+	"chimera/internal/scraper"
+)
+
+type fakeScraper struct {
+	pages map[string]*scraper.Result
+}
+
+func (s *fakeScraper) Scrape(ctx context.Context, target string) (*scraper.Result, error) {
+	if page, ok := s.pages[target]; ok {
+		return page, nil
+	}
+	return nil, fmt.Errorf("no page at %s", target)
+}
+
+func (s *fakeScraper) Related(ctx context.Context, result *scraper.Result, limit int) []*scraper.Result {
+	return nil
+}
+
+// fakeComposer answers with html, or fails with err; block makes it wait
+// for its context to end instead.
+type fakeComposer struct {
+	html   string
+	err    error
+	paused time.Duration
+	block  bool
+
+	calls int
+	opts  llm.PageOptions
+}
+
+func (c *fakeComposer) Available() bool       { return true }
+func (c *fakeComposer) Model() string         { return "fake-model" }
+func (c *fakeComposer) Paused() time.Duration { return c.paused }
+func (c *fakeComposer) GeneratePage(ctx context.Context, data *scraper.Result, opts llm.PageOptions) (string, error) {
+	c.calls++
+	c.opts = opts
+	if c.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return c.html, c.err
+}
+
+type fakeCompositions struct {
+	pinned map[string]cache.Composition
+	added  []cache.Composition
+	pins   []string
+}
+
+func (f *fakeCompositions) Pinned(url string) (cache.Composition, bool, error) {
+	c, ok := f.pinned[url]
+	return c, ok, nil
+}
+
+func (f *fakeCompositions) Add(c cache.Composition) (cache.Composition, error) {
+	c.ID = fmt.Sprintf("c%d", len(f.added)+1)
+	f.added = append(f.added, c)
+	return c, nil
+}
+
+func (f *fakeCompositions) Pin(url, id string) error {
+	f.pins = append(f.pins, url+"#"+id)
+	return nil
+}
+
+const composedHTML = "<!DOCTYPE html><html><body><h1>Composed</h1></body></html>"
+
+func testPage() *scraper.Result {
+	return &scraper.Result{
+		SourceURL:  "https://example.com/a",
+		FinalURL:   "https://example.com/a",
+		Title:      "A page",
+		Paragraphs: []string{"A paragraph long enough to be shown in reader mode."},
+	}
+}
+
+func newController(composer *fakeComposer, compositions *fakeCompositions) *Controller {
+	return &Controller{
+		Scraper:      &fakeScraper{},
+		Compositions: compositions,
+		LLM:          func() Composer { return composer },
+	}
+}
+
+func composeRequest() Request {
+	return Request{Key: "https://example.com/a", UseLLM: true, Options: llm.PageOptions{Task: llm.TaskCompose}}
+}
+
+func readerHTML(t *testing.T, page *scraper.Result) string {
+	t.Helper()
+	html, err := render.Simple(page, render.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return html
+}
+
+func TestController_ShowComposesAndStores(t *testing.T) {
+	composer := &fakeComposer{html: composedHTML}
+	compositions := &fakeCompositions{}
+	c := newController(composer, compositions)
+
+	outcome, err := c.Show(context.Background(), composeRequest(), testPage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.HTML != composedHTML || outcome.Model != "fake-model" || outcome.FromPin {
+		t.Errorf("outcome = %+v", outcome)
+	}
+	if len(compositions.added) != 1 || outcome.Composition.ID != "c1" {
+		t.Errorf("stored %d compositions, outcome composition %q", len(compositions.added), outcome.Composition.ID)
+	}
+	if len(compositions.pins) != 0 {
+		t.Errorf("pinned %v without Repin", compositions.pins)
+	}
+}
+
+func TestController_ShowPinned(t *testing.T) {
+	pinned := cache.Composition{ID: "p1", URL: "https://example.com/a", HTML: "<p>pinned</p>", Model: "old-model", Pinned: true}
+	tests := []struct {
+		name      string
+		recompose bool
+		repin     bool
+		wantPin   bool
+		wantPins  []string
+	}{
+		{name: "pinned", wantPin: true},
+		{name: "recompose", recompose: true},
+		{name: "recompose and repin", recompose: true, repin: true, wantPins: []string{"https://example.com/a#c1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composer := &fakeComposer{html: composedHTML}
+			compositions := &fakeCompositions{pinned: map[string]cache.Composition{pinned.URL: pinned}}
+			c := newController(composer, compositions)
+
+			req := composeRequest()
+			req.Recompose, req.Repin = tt.recompose, tt.repin
+			outcome, err := c.Show(context.Background(), req, testPage())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if outcome.FromPin != tt.wantPin {
+				t.Errorf("FromPin = %v, want %v", outcome.FromPin, tt.wantPin)
+			}
+			if tt.wantPin {
+				if outcome.HTML != pinned.HTML || outcome.Model != "old-model" || composer.calls != 0 {
+					t.Errorf("outcome = %+v after %d LLM calls", outcome, composer.calls)
+				}
+				return
+			}
+			if outcome.HTML != composedHTML || composer.calls != 1 {
+				t.Errorf("outcome HTML %q after %d LLM calls", outcome.HTML, composer.calls)
+			}
+			if strings.Join(compositions.pins, ",") != strings.Join(tt.wantPins, ",") {
+				t.Errorf("pins = %v, want %v", compositions.pins, tt.wantPins)
+			}
+		})
+	}
+}
+
+func TestController_ShowFallsBackToReaderMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		composer    *fakeComposer
+		task        llm.Task
+		rateLimited bool
+		paused      time.Duration
+	}{
+		{name: "rate limited", composer: &fakeComposer{err: &llm.HTTPError{Status: http.StatusTooManyRequests}}, rateLimited: true},
+		{name: "rate limited summary", composer: &fakeComposer{err: &llm.HTTPError{Status: http.StatusTooManyRequests}}, task: llm.TaskSummarize, rateLimited: true},
+		{name: "circuit open", composer: &fakeComposer{err: &llm.CircuitOpenError{RetryIn: 30 * time.Second}}, paused: 30 * time.Second},
+		{name: "circuit open translation", composer: &fakeComposer{err: &llm.CircuitOpenError{RetryIn: 30 * time.Second}}, task: llm.TaskTranslate, paused: 30 * time.Second},
+		{name: "paused", composer: &fakeComposer{html: composedHTML, paused: time.Minute}, paused: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compositions := &fakeCompositions{}
+			c := newController(tt.composer, compositions)
+			page := testPage()
+
+			req := composeRequest()
+			req.Options.Task = tt.task
+			outcome, err := c.Show(context.Background(), req, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if outcome.HTML != readerHTML(t, page) || outcome.Model != "" {
+				t.Errorf("outcome is not reader mode: model %q", outcome.Model)
+			}
+			if outcome.RateLimited != tt.rateLimited || outcome.Paused != tt.paused {
+				t.Errorf("RateLimited = %v, Paused = %v; want %v, %v", outcome.RateLimited, outcome.Paused, tt.rateLimited, tt.paused)
+			}
+			if tt.paused == time.Minute && tt.composer.calls != 0 {
+				t.Errorf("paused client was asked %d times", tt.composer.calls)
+			}
+			if len(compositions.added) != 0 {
+				t.Errorf("stored %d compositions", len(compositions.added))
+			}
+		})
+	}
+}
+
+func TestController_ShowComposeError(t *testing.T) {
+	failure := &llm.HTTPError{Status: http.StatusInternalServerError}
+	for _, task := range []llm.Task{llm.TaskCompose, llm.TaskSummarize} {
+		composer := &fakeComposer{err: failure}
+		c := newController(composer, &fakeCompositions{})
+
+		req := composeRequest()
+		req.Options.Task = task
+		_, err := c.Show(context.Background(), req, testPage())
+		var composeErr *ComposeError
+		if !errors.As(err, &composeErr) || !errors.Is(err, failure) {
+			t.Errorf("task %v: err = %v, want a ComposeError wrapping %v", task, err, failure)
+		}
+	}
+}
+
+func TestController_ShowCancelled(t *testing.T) {
+	composer := &fakeComposer{block: true}
+	compositions := &fakeCompositions{}
+	c := newController(composer, compositions)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := composeRequest()
+	req.Status = func(text string) {
+		if text == "Composing..." {
+			cancel()
+		}
+	}
+	_, err := c.Show(ctx, req, testPage())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	var composeErr *ComposeError
+	if errors.As(err, &composeErr) {
+		t.Errorf("cancellation reported as a ComposeError")
+	}
+	if len(compositions.added) != 0 {
+		t.Errorf("stored %d compositions", len(compositions.added))
+	}
+}
+
+func TestController_ShowScreenshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		capture func(ctx context.Context) ([]byte, error)
+		want    []byte
+	}{
+		{name: "captured", capture: func(ctx context.Context) ([]byte, error) { return []byte("png"), nil }, want: []byte("png")},
+		{name: "failed", capture: func(ctx context.Context) ([]byte, error) { return nil, errors.New("view crashed") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composer := &fakeComposer{html: composedHTML}
+			c := newController(composer, &fakeCompositions{})
+
+			req := composeRequest()
+			req.Screenshot = tt.capture
+			outcome, err := c.Show(context.Background(), req, testPage())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if outcome.HTML != composedHTML {
+				t.Errorf("outcome HTML = %q", outcome.HTML)
+			}
+			if string(composer.opts.Screenshot) != string(tt.want) {
+				t.Errorf("screenshot sent = %q, want %q", composer.opts.Screenshot, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_ShowReaderModeWithoutLLM(t *testing.T) {
+	composer := &fakeComposer{html: composedHTML}
+	c := newController(composer, &fakeCompositions{})
+	page := testPage()
+
+	req := composeRequest()
+	req.UseLLM = false
+	outcome, err := c.Show(context.Background(), req, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.HTML != readerHTML(t, page) || composer.calls != 0 {
+		t.Errorf("outcome is not reader mode after %d LLM calls", composer.calls)
+	}
+}
+
+func TestController_Fetch(t *testing.T) {
+	page := testPage()
+	c := &Controller{Scraper: &fakeScraper{pages: map[string]*scraper.Result{page.SourceURL: page}}}
+
+	got, err := c.Fetch(context.Background(), page.SourceURL, nil)
+	if err != nil || got != page {
+		t.Errorf("Fetch = %v, %v; want the scraped page", got, err)
+	}
+	if _, err := c.Fetch(context.Background(), "https://example.com/missing", nil); err == nil {
+		t.Error("Fetch of a missing page succeeded")
+	}
+}
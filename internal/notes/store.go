@@ -0,0 +1,120 @@
+package notes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"chimera/internal/storage"
+)
+
+// Note is a user instruction attached to a page URL.
+type Note struct {
+	Text      string    `json:"text"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store persists notes keyed by URL as JSON in a storage.Backend, by default below
+// the user's configuration directory.
+type Store struct {
+	backend storage.Backend
+	mu      sync.RWMutex
+}
+
+// NewStore builds a Store below the user's configuration directory.
+func NewStore(appID string) (*Store, error) {
+	backend, err := storage.ConfigFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend), nil
+}
+
+// NewStoreWith builds a Store keeping its data in backend; a nil backend gives a nil Store,
+// which stores nothing.
+func NewStoreWith(backend storage.Backend) *Store {
+	if backend == nil {
+		return nil
+	}
+	return &Store{backend: backend}
+}
+
+// Get returns the note for url, if any.
+func (s *Store) Get(url string) (Note, bool, error) {
+	if s == nil {
+		return Note{}, false, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return Note{}, false, err
+	}
+	note, ok := entries[url]
+	return note, ok, nil
+}
+
+// Set stores text as the note for url; empty text removes the note.
+func (s *Store) Set(url, text string) error {
+	if s == nil {
+		return nil
+	}
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return errors.New("note URL is empty")
+	}
+	text = strings.TrimSpace(text)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = make(map[string]Note)
+	}
+
+	if text == "" {
+		delete(entries, url)
+	} else {
+		entries[url] = Note{Text: text, UpdatedAt: time.Now()}
+	}
+
+	return s.write(entries)
+}
+
+func (s *Store) read() (map[string]Note, error) {
+	bytes, err := s.backend.Get("notes.json")
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read notes: %w", err)
+	}
+
+	var entries map[string]Note
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return nil, fmt.Errorf("decode notes: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) write(entries map[string]Note) error {
+	encoded, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode notes: %w", err)
+	}
+
+	if err := s.backend.Put("notes.json", encoded); err != nil {
+		return fmt.Errorf("write notes: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package procstat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the kernel USER_HZ, which is 100 on all mainstream Linux builds.
+const clockTicks = 100
+
+// Process describes resource usage of a single child process.
+type Process struct {
+	PID        int
+	PPID       int
+	Name       string
+	RSSBytes   int64
+	CPUPercent float64
+}
+
+// Kind classifies WebKit helper processes by executable name.
+func (p Process) Kind() string {
+	switch {
+	case strings.Contains(p.Name, "WebProcess"):
+		return "web"
+	case strings.Contains(p.Name, "NetworkProcess"):
+		return "network"
+	case strings.Contains(p.Name, "GPUProcess"):
+		return "gpu"
+	default:
+		return "other"
+	}
+}
+
+type sample struct {
+	ppid  int
+	name  string
+	ticks int64
+}
+
+// WebKitProcesses lists WebKit helper processes descending from the current
+// process, measuring CPU usage over interval.
+func WebKitProcesses(ctx context.Context, interval time.Duration) ([]Process, error) {
+	before, err := snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(interval)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+
+	after, err := snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	self := os.Getpid()
+	var procs []Process
+	for pid, s := range after {
+		if !strings.HasPrefix(s.name, "WebKit") || !descendsFrom(after, pid, self) {
+			continue
+		}
+
+		p := Process{PID: pid, PPID: s.ppid, Name: s.name, RSSBytes: residentBytes(pid)}
+		if prev, ok := before[pid]; ok && interval > 0 {
+			used := float64(s.ticks-prev.ticks) / clockTicks
+			p.CPUPercent = 100 * used / interval.Seconds()
+		}
+		procs = append(procs, p)
+	}
+
+	sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+	return procs, nil
+}
+
+func descendsFrom(procs map[int]sample, pid, ancestor int) bool {
+	for depth := 0; depth < 32 && pid > 1; depth++ {
+		s, ok := procs[pid]
+		if !ok {
+			return false
+		}
+		if s.ppid == ancestor {
+			return true
+		}
+		pid = s.ppid
+	}
+	return false
+}
+
+func snapshot() (map[int]sample, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("read /proc: %w", err)
+	}
+
+	procs := make(map[int]sample)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		s, err := readStat(pid)
+		if err != nil {
+			continue
+		}
+		procs[pid] = s
+	}
+	if len(procs) == 0 {
+		return nil, errors.New("no processes found in /proc")
+	}
+	return procs, nil
+}
+
+func readStat(pid int) (sample, error) {
+	raw, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return sample{}, err
+	}
+
+	stat := string(raw)
+	open := strings.IndexByte(stat, '(')
+	closing := strings.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return sample{}, errors.New("malformed stat")
+	}
+
+	// Fields after the command name start at "state" (field 3).
+	fields := strings.Fields(stat[closing+1:])
+	if len(fields) < 13 {
+		return sample{}, errors.New("short stat")
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+
+	return sample{ppid: ppid, name: commandName(pid, stat[open+1:closing]), ticks: utime + stime}, nil
+}
+
+// commandName prefers the executable name from cmdline, since comm is truncated to 15 bytes.
+func commandName(pid int, comm string) string {
+	raw, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil || len(raw) == 0 {
+		return comm
+	}
+	arg0, _, _ := strings.Cut(string(raw), "\x00")
+	if arg0 == "" {
+		return comm
+	}
+	return filepath.Base(arg0)
+}
+
+func residentBytes(pid int) int64 {
+	raw, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if !strings.HasPrefix(line, "VmRSS:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0
+		}
+		kb, _ := strconv.ParseInt(fields[1], 10, 64)
+		return kb * 1024
+	}
+	return 0
+}
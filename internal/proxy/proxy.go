@@ -0,0 +1,52 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Parse validates a proxy URL. Supported schemes are http, https, socks5 and
+// socks5h; socks5 also resolves host names through the proxy, as Tor needs.
+// An empty value returns nil.
+func Parse(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse proxy URL: %w", err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+	return parsed, nil
+}
+
+// Func returns a Transport.Proxy function for raw. An empty value defers to
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables. An invalid
+// value fails every request rather than silently connecting directly.
+func Func(raw string) func(*http.Request) (*url.URL, error) {
+	parsed, err := Parse(raw)
+	switch {
+	case err != nil:
+		return func(*http.Request) (*url.URL, error) { return nil, err }
+	case parsed == nil:
+		return http.ProxyFromEnvironment
+	}
+	return http.ProxyURL(parsed)
+}
+
+// Transport returns a copy of http.DefaultTransport that connects through raw.
+func Transport(raw string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = Func(raw)
+	return transport
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// Token patterns shared by the highlighted language families. Strings come
+// first so comment markers inside them are left alone.
+const (
+	quotedStrings  = `"(?:\\.|[^"\\\n])*"|'(?:\\.|[^'\\\n])*'`
+	slashComments  = `//[^\n]*|/\*[\s\S]*?\*/`
+	hashComments   = `#[^\n]*`
+	dashComments   = `--[^\n]*`
+	markupComments = `<!--[\s\S]*?-->`
+)
+
+var (
+	cLikeTokens  = regexp.MustCompile("(" + quotedStrings + "|`[^`]*`)|(" + slashComments + ")")
+	hashTokens   = regexp.MustCompile("(" + quotedStrings + ")|(" + hashComments + ")")
+	dashTokens   = regexp.MustCompile("(" + quotedStrings + ")|(" + dashComments + ")")
+	markupTokens = regexp.MustCompile("(" + quotedStrings + ")|(" + markupComments + ")")
+)
+
+// codeFamilies maps language hints to the token pattern used to highlight them.
+var codeFamilies = map[string]*regexp.Regexp{
+	"go": cLikeTokens, "golang": cLikeTokens, "c": cLikeTokens, "cpp": cLikeTokens, "c++": cLikeTokens,
+	"java": cLikeTokens, "kotlin": cLikeTokens, "scala": cLikeTokens, "swift": cLikeTokens,
+	"javascript": cLikeTokens, "js": cLikeTokens, "jsx": cLikeTokens, "typescript": cLikeTokens,
+	"ts": cLikeTokens, "tsx": cLikeTokens, "rust": cLikeTokens, "rs": cLikeTokens,
+	"csharp": cLikeTokens, "cs": cLikeTokens, "php": cLikeTokens, "dart": cLikeTokens, "css": cLikeTokens,
+	"python": hashTokens, "py": hashTokens, "ruby": hashTokens, "rb": hashTokens, "perl": hashTokens,
+	"shell": hashTokens, "sh": hashTokens, "bash": hashTokens, "zsh": hashTokens, "console": hashTokens,
+	"yaml": hashTokens, "yml": hashTokens, "toml": hashTokens, "r": hashTokens, "dockerfile": hashTokens,
+	"makefile": hashTokens, "make": hashTokens,
+	"sql": dashTokens, "lua": dashTokens, "haskell": dashTokens, "hs": dashTokens,
+	"html": markupTokens, "xml": markupTokens, "svg": markupTokens,
+}
+
+// highlightCode escapes code and marks its strings and comments with the
+// "s" and "c" classes for languages the reader recognises.
+func highlightCode(language, code string) template.HTML {
+	pattern, ok := codeFamilies[strings.ToLower(language)]
+	if !ok {
+		return template.HTML(template.HTMLEscapeString(code))
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, m := range pattern.FindAllStringSubmatchIndex(code, -1) {
+		builder.WriteString(template.HTMLEscapeString(code[last:m[0]]))
+		class := "c"
+		if m[2] >= 0 {
+			class = "s"
+		}
+		builder.WriteString(`<span class="` + class + `">`)
+		builder.WriteString(template.HTMLEscapeString(code[m[0]:m[1]]))
+		builder.WriteString(`</span>`)
+		last = m[1]
+	}
+	builder.WriteString(template.HTMLEscapeString(code[last:]))
+	return template.HTML(builder.String())
+}
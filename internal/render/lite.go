@@ -0,0 +1,51 @@
+package render
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// lowMemoryKB is the total memory below which lite rendering is used automatically.
+const lowMemoryKB = 4 * 1024 * 1024
+
+// Rendering modes accepted by ResolveLite.
+const (
+	RenderingAuto = ""
+	RenderingFull = "full"
+	RenderingLite = "lite"
+)
+
+// ResolveLite decides whether lite rendering applies for the given mode,
+// consulting the system memory size in automatic mode.
+func ResolveLite(mode string) bool {
+	switch mode {
+	case RenderingLite:
+		return true
+	case RenderingFull:
+		return false
+	default:
+		return LowMemory()
+	}
+}
+
+// LowMemory reports whether the machine has less than 4 GiB of RAM according to /proc/meminfo.
+func LowMemory() bool {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		return err == nil && kb < lowMemoryKB
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package render
+
+import (
+	"html/template"
+	"strings"
+	"time"
+
+	"chimera/internal/locale"
+	"chimera/internal/scraper"
+)
+
+var simpleTmpl = template.Must(template.New("simple").Funcs(template.FuncMap{
+	"formatTime": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("02 Jan 2006 15:04 MST")
+	},
+	"formatDate": func(t time.Time) string {
+		return t.Format("02 Jan 2006")
+	},
+	"paragraph":   paragraphHTML,
+	"paragraphID": scraper.ParagraphID,
+	"langName":    locale.Name,
+	"highlight":   highlightCode,
+	"outline":     scraper.Outline,
+	"textLinks":   func(links []scraper.Link) []scraper.Link { return filterLinks(links, true) },
+	"otherLinks":  func(links []scraper.Link) []scraper.Link { return filterLinks(links, false) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>{{ if .Title }}{{ .Title }} — Chimera{{ else }}Chimera Summary{{ end }}</title>
+{{ with .Typography }}<style>:root { {{ . }} }</style>
+{{ end }}{{ if .Lite }}<style>
+html { font-size: var(--reader-size, 100%); }
+body { font-family: var(--reader-font, sans-serif); line-height: var(--reader-line-height, normal); margin: 0 auto; max-width: var(--reader-width, 860px); padding: 1rem; background: #fff; color: #1d2433; }
+p { text-align: var(--reader-align, start); hyphens: var(--reader-hyphens, manual); }
+header { border-bottom: 1px solid #d4d9e2; margin-bottom: 1rem; }
+h1 { margin: 0 0 .5rem 0; font-size: 1.8rem; }
+section { margin-bottom: 1.5rem; }
+h2 { font-size: 1.3rem; }
+ul { padding-left: 1.2rem; }
+ul.footnotes { list-style: none; padding-left: 0; }
+math[display="block"], code.tex.display { display: block; margin: .8rem 0; text-align: center; }
+pre.code { font-family: monospace; background: #f0f2f6; padding: .75rem; overflow-x: auto; }
+pre.code .c { color: #6a7384; font-style: italic; }
+pre.code .s { color: #1d7a46; }
+.lang { font-family: monospace; color: #5b6576; }
+.toc { list-style: none; }
+.toc summary { cursor: pointer; }
+a { color: #2b5dcc; }
+img { max-width: 100%; height: auto; }
+small { color: #5b6576; }
+</style>{{ else }}<style>
+html { font-size: var(--reader-size, 100%); }
+body { font-family: var(--reader-font, "Inter", "Segoe UI", sans-serif); line-height: var(--reader-line-height, normal); margin: 0 auto; max-width: var(--reader-width, 960px); padding: 2rem; background: #f5f7fb; color: #1d2433; }
+p { text-align: var(--reader-align, start); hyphens: var(--reader-hyphens, manual); }
+header { border-bottom: 1px solid #d4d9e2; margin-bottom: 1.5rem; padding-bottom: 1rem; }
+h1 { margin: 0 0 .5rem 0; font-size: 2.4rem; }
+section { margin-bottom: 2rem; background: #fff; border-radius: 12px; padding: 1.5rem; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+h2 { font-size: 1.5rem; margin-top: 0; }
+ul { padding-left: 1.2rem; }
+ul.footnotes { list-style: none; padding-left: 0; }
+math[display="block"], code.tex.display { display: block; margin: .8rem 0; text-align: center; }
+pre.code { font-family: "JetBrains Mono", "Fira Code", monospace; font-size: .9rem; line-height: 1.45; background: #1d2433; color: #e6e9f0; padding: 1rem 1.2rem; border-radius: 8px; overflow-x: auto; tab-size: 4; }
+pre.code .c { color: #8b95a8; font-style: italic; }
+pre.code .s { color: #a5d6a7; }
+.lang { display: inline-block; margin-bottom: .3rem; font-family: monospace; font-size: .8rem; color: #5b6576; text-transform: uppercase; letter-spacing: .05em; }
+.toc { list-style: none; }
+.toc ul.toc { padding-left: 1.2rem; }
+.toc summary { cursor: pointer; }
+.toc summary::marker { color: #5b6576; }
+a { color: #2b5dcc; text-decoration: none; }
+a:hover { text-decoration: underline; }
+small { color: #5b6576; }
+</style>{{ end }}
+</head>
+<body>
+<header>
+  <h1>{{ if .Title }}{{ .Title }}{{ else }}Scraped Summary{{ end }}</h1>
+  <small>Source: <a href="{{ .SourceURL }}">{{ .SourceURL }}</a>{{ if .FetchedAt }} • {{ formatTime .FetchedAt }}{{ end }}</small>
+  {{ if or .Author (not .PublishedAt.IsZero) }}<p class="byline">{{ if .Author }}By {{ .Author }}{{ end }}{{ if and .Author (not .PublishedAt.IsZero) }} • {{ end }}{{ if not .PublishedAt.IsZero }}Published {{ formatDate .PublishedAt }}{{ end }}</p>{{ end }}
+  {{ if .Words }}<p><small>{{ .Words }} words • {{ .ReadingMinutes }} min read{{ if .Language }} • {{ langName .Language }}{{ end }}{{ if .JoinedPages }} • includes {{ len .JoinedPages }} more {{ if eq (len .JoinedPages) 1 }}page{{ else }}pages{{ end }}{{ end }}</small></p>{{ end }}
+  {{ if .Description }}<p>{{ .Description }}</p>{{ end }}
+</header>
+<section>
+  <h2>Contents</h2>
+  {{ if .Headings }}
+  {{ template "outline" outline .Headings }}
+  {{ else }}<p>No major headings detected.</p>{{ end }}
+</section>
+<section>
+  <h2>Highlights</h2>
+  {{ if .Paragraphs }}
+  {{ range .Paragraphs }}<p id="{{ paragraphID . }}">{{ paragraph $.Formulas . }}</p>{{ end }}
+  {{ else }}<p>Not enough textual content found.</p>{{ end }}
+  {{ if .NextPage }}<p><a href="{{ .NextPage }}">Next page →</a></p>{{ end }}
+</section>
+{{ if .CodeBlocks }}<section>
+  <h2>Code</h2>
+  {{ range .CodeBlocks }}<div class="code-block">{{ if .Language }}<span class="lang">{{ .Language }}</span>{{ end }}<pre class="code"><code{{ if .Language }} class="language-{{ .Language }}"{{ end }}>{{ highlight .Language .Code }}</code></pre></div>{{ end }}
+</section>{{ end }}
+{{ if .Footnotes }}<section>
+  <h2>Notes</h2>
+  <ul class="footnotes">
+    {{ range .Footnotes }}<li id="fn-{{ .Label }}"><strong>{{ .Label }}.</strong> {{ .Text }} <a href="#fnref-{{ .Label }}" aria-label="Back to reference">↩</a></li>{{ end }}
+  </ul>
+</section>{{ end }}
+<section>
+  <h2>Links</h2>
+  {{ with textLinks .Links }}
+  <ul>
+    {{ range . }}<li><a href="{{ .Href }}" target="_blank" rel="noopener">{{ .Text }}</a></li>{{ end }}
+  </ul>
+  {{ else }}<p>No links in the page text.</p>{{ end }}
+  {{ with otherLinks .Links }}
+  <details class="other-links"><summary>Navigation, footer and other links ({{ len . }})</summary>
+  <ul>
+    {{ range . }}<li><a href="{{ .Href }}" target="_blank" rel="noopener">{{ .Text }}</a> <small>{{ .Class }}</small></li>{{ end }}
+  </ul>
+  </details>{{ end }}
+</section>
+</body>
+</html>
+{{ define "outline" }}<ul class="toc">
+    {{ range . }}<li id="{{ .ID }}">{{ if .Children }}<details open><summary><a href="#{{ .ID }}">{{ .Text }}</a></summary>{{ template "outline" .Children }}</details>{{ else }}<a href="#{{ .ID }}">{{ .Text }}</a>{{ end }}</li>{{ end }}
+  </ul>{{ end }}`))
+
+// filterLinks keeps the links from the page text when content is set, and
+// the navigation, footer, external and anchor links otherwise. Unclassified
+// links count as text.
+func filterLinks(links []scraper.Link, content bool) []scraper.Link {
+	var kept []scraper.Link
+	for _, link := range links {
+		inText := link.Class == scraper.LinkContent || link.Class == ""
+		if inText == content {
+			kept = append(kept, link)
+		}
+	}
+	return kept
+}
+
+// paragraphHTML escapes a paragraph and expands its markers: [^label]
+// becomes a superscript link to the matching note and [math:id] the formula.
+func paragraphHTML(formulas []scraper.Formula, paragraph string) template.HTML {
+	escaped := template.HTMLEscapeString(paragraph)
+	escaped = scraper.FootnoteRefPattern.ReplaceAllString(escaped, `<sup id="fnref-$1"><a href="#fn-$1">$1</a></sup>`)
+	escaped = scraper.MathRefPattern.ReplaceAllStringFunc(escaped, func(marker string) string {
+		id := scraper.MathRefPattern.FindStringSubmatch(marker)[1]
+		for _, f := range formulas {
+			if f.ID == id {
+				return formulaHTML(f)
+			}
+		}
+		return marker
+	})
+	return template.HTML(escaped)
+}
+
+// formulaHTML renders f as its sanitised MathML, which WebKit displays
+// natively, or as TeX source when the page gave no MathML.
+func formulaHTML(f scraper.Formula) string {
+	if f.MathML != "" {
+		return f.MathML
+	}
+	if f.Display {
+		return `<code class="tex display">` + template.HTMLEscapeString(f.TeX) + `</code>`
+	}
+	return `<code class="tex">` + template.HTMLEscapeString(f.TeX) + `</code>`
+}
+
+// Options adjusts the reader output.
+type Options struct {
+	// Lite selects plain styling without shadows, rounded cards or web fonts for low-spec machines.
+	Lite bool
+	// Typography overrides the text style.
+	Typography Typography
+}
+
+type readerData struct {
+	*scraper.Result
+	Lite       bool
+	Typography template.CSS
+}
+
+// Simple renders a scrape result with the built-in reader template.
+func Simple(data *scraper.Result, opts Options) (string, error) {
+	var builder strings.Builder
+	if err := simpleTmpl.Execute(&builder, readerData{Result: data, Lite: opts.Lite, Typography: opts.Typography.Variables()}); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,192 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// droppedElements are removed from compositions with everything inside
+// them: active content, embedded documents and form controls.
+const droppedElements = "script, noscript, template, iframe, frame, frameset, object, embed, applet, " +
+	"portal, base, input, button, select, textarea, option, optgroup, datalist, dialog"
+
+// allowedElements are the HTML elements of a static document. Others are
+// replaced by what they contain.
+var allowedElements = setOf(
+	"html", "head", "body", "title", "meta", "link", "style",
+	"header", "footer", "main", "nav", "section", "article", "aside", "address", "hgroup",
+	"h1", "h2", "h3", "h4", "h5", "h6", "p", "div", "span", "br", "hr", "wbr",
+	"a", "em", "strong", "b", "i", "u", "s", "small", "mark", "sub", "sup", "abbr", "cite", "q",
+	"dfn", "time", "data", "code", "kbd", "samp", "var", "pre", "blockquote", "del", "ins",
+	"ul", "ol", "li", "dl", "dt", "dd", "menu",
+	"figure", "figcaption", "img", "picture", "source", "details", "summary",
+	"table", "caption", "colgroup", "col", "thead", "tbody", "tfoot", "tr", "th", "td",
+	"svg", "math",
+)
+
+// allowedAttributes are kept on every HTML element, as are aria-* and
+// data-* attributes.
+var allowedAttributes = setOf("class", "id", "title", "lang", "dir", "style", "role", "hidden", "translate")
+
+// elementAttributes are kept on the HTML elements they belong to.
+var elementAttributes = map[string]map[string]bool{
+	"a":          setOf("href", "name", "rel", "hreflang"),
+	"img":        setOf("src", "srcset", "sizes", "alt", "width", "height", "loading", "decoding"),
+	"source":     setOf("srcset", "type", "media", "sizes"),
+	"link":       setOf("rel", "href", "media", "type"),
+	"meta":       setOf("charset", "name", "content"),
+	"style":      setOf("media"),
+	"ol":         setOf("start", "reversed", "type"),
+	"li":         setOf("value"),
+	"td":         setOf("colspan", "rowspan", "headers"),
+	"th":         setOf("colspan", "rowspan", "headers", "scope", "abbr"),
+	"col":        setOf("span"),
+	"colgroup":   setOf("span"),
+	"time":       setOf("datetime"),
+	"data":       setOf("value"),
+	"blockquote": setOf("cite"),
+	"q":          setOf("cite"),
+	"del":        setOf("cite", "datetime"),
+	"ins":        setOf("cite", "datetime"),
+	"details":    setOf("open"),
+}
+
+// urlAttributes hold URLs, kept only with a safe scheme.
+var urlAttributes = setOf("href", "src", "srcset", "cite", "xlink:href", "action", "formaction", "poster", "background")
+
+// Sanitize reduces html, a page written by the LLM, to a static document:
+// scripts, embedded documents, forms and event handlers are removed, other
+// elements and attributes off an allowlist are dropped, and links and
+// sources must be relative or use http, https or mailto; images may also
+// be data: URLs. Inline SVG and MathML keep their own attributes except
+// event handlers and unsafe URLs.
+func Sanitize(html string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return "", fmt.Errorf("parse composition: %w", err)
+	}
+	doc.Find(droppedElements).Remove()
+	doc.Find(`meta[http-equiv], link:not([rel~="stylesheet"])`).Remove()
+
+	doc.Find("*").Each(func(_ int, sel *goquery.Selection) {
+		node := sel.Nodes[0]
+		foreign := node.Namespace == "svg" || node.Namespace == "math"
+		name := strings.ToLower(node.Data)
+		if !foreign && !allowedElements[name] {
+			sel.ReplaceWithSelection(sel.Contents())
+			return
+		}
+		if foreign && (name == "foreignobject" || name == "script" || name == "animate" || name == "set") {
+			sel.Remove()
+			return
+		}
+
+		kept := node.Attr[:0]
+		for _, attr := range node.Attr {
+			key := strings.ToLower(attr.Key)
+			if attr.Namespace == "xlink" {
+				key = "xlink:" + key
+			}
+			switch {
+			case strings.HasPrefix(key, "on"):
+				continue
+			case urlAttributes[key] && !safeURL(key, attr.Val, name == "img"):
+				continue
+			case !foreign && !allowedAttribute(name, key):
+				continue
+			}
+			kept = append(kept, attr)
+		}
+		node.Attr = kept
+	})
+
+	out, err := doc.Html()
+	if err != nil {
+		return "", fmt.Errorf("render composition: %w", err)
+	}
+	return out, nil
+}
+
+func allowedAttribute(element, key string) bool {
+	return allowedAttributes[key] || elementAttributes[element][key] ||
+		strings.HasPrefix(key, "aria-") || strings.HasPrefix(key, "data-")
+}
+
+// safeURL reports whether value, the value of the URL attribute key, is
+// relative or uses a scheme that cannot run code. data: URLs pass only for
+// the src of an image, and not for SVG images, which may hold scripts.
+func safeURL(key, value string, image bool) bool {
+	if key == "srcset" {
+		// Candidates are "URL width" pairs separated by commas.
+		for _, candidate := range strings.Split(value, ",") {
+			if fields := strings.Fields(candidate); len(fields) > 0 && !safeURL("src", fields[0], false) {
+				return false
+			}
+		}
+		return true
+	}
+	value = strings.ToLower(strings.TrimSpace(value))
+	scheme, _, found := strings.Cut(value, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+	switch scheme {
+	case "http", "https", "mailto":
+		return true
+	case "data":
+		return image && key == "src" && strings.HasPrefix(value, "data:image/") && !strings.HasPrefix(value, "data:image/svg")
+	}
+	return false
+}
+
+// Policy returns a Content-Security-Policy for a sanitised composition:
+// only scripts carrying nonce run, nothing is fetched by scripts, forms or
+// frames, and styles, fonts, images and media load from data: URLs, and
+// from the web too unless blockExternal is set.
+func Policy(nonce string, blockExternal bool) string {
+	sources := "data:"
+	if !blockExternal {
+		sources += " https: http:"
+	}
+	return strings.Join([]string{
+		"default-src 'none'",
+		"script-src 'nonce-" + nonce + "'",
+		"style-src 'unsafe-inline' " + sources,
+		"img-src " + sources,
+		"font-src " + sources,
+		"media-src " + sources,
+		"form-action 'none'",
+		"base-uri 'none'",
+	}, "; ")
+}
+
+// WithPolicy puts policy in html as the first element of its head.
+func WithPolicy(html, policy string) string {
+	meta := `<meta http-equiv="Content-Security-Policy" content="` + strings.ReplaceAll(policy, `"`, "&quot;") + `">`
+	lower := strings.ToLower(html)
+	for from := 0; ; {
+		i := strings.Index(lower[from:], "<head")
+		if i < 0 {
+			return meta + html
+		}
+		i += from + len("<head")
+		// "<header" is not the head.
+		if i < len(lower) && (lower[i] == '>' || lower[i] == '/' || strings.ContainsRune(" \t\n\r\f", rune(lower[i]))) {
+			if end := strings.Index(lower[i:], ">"); end >= 0 {
+				at := i + end + 1
+				return html[:at] + meta + html[at:]
+			}
+		}
+		from = i
+	}
+}
+
+func setOf(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,148 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize_RemovesActiveContent(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		gone []string
+		kept []string
+	}{
+		{
+			name: "script",
+			html: `<p>text</p><script>alert(1)</script><ScRiPt SRC="https://evil.example/x.js"></ScRiPt>`,
+			gone: []string{"<script", "alert(1)", "evil.example"},
+			kept: []string{"<p>text</p>"},
+		},
+		{
+			name: "event handlers",
+			html: `<p onclick="alert(1)" OnMouseOver="alert(2)" class="lead">text</p><img src="a.png" onerror="alert(3)">`,
+			gone: []string{"onclick", "onmouseover", "onerror", "alert"},
+			kept: []string{`class="lead"`, `src="a.png"`},
+		},
+		{
+			name: "javascript URLs",
+			html: `<a href="javascript:alert(1)">a</a><a href="JaVaScRiPt:alert(2)">b</a><a href=" javascript:alert(3)">c</a>`,
+			gone: []string{"javascript", "alert"},
+			kept: []string{"<a>a</a>", "<a>b</a>", "<a>c</a>"},
+		},
+		{
+			name: "entity-encoded javascript URLs",
+			html: `<a href="&#106;avascript:alert(1)">a</a><a href="&#x6A;&#x61;vascript&colon;alert(2)">b</a><a href="java&#9;script:alert(3)">c</a>`,
+			gone: []string{"avascript", "alert"},
+			kept: []string{"<a>a</a>", "<a>b</a>", "<a>c</a>"},
+		},
+		{
+			name: "safe URLs",
+			html: `<a href="https://example.com/">a</a><a href="/relative?q=1">b</a><a href="mailto:me@example.com">c</a><a href="#top">d</a>`,
+			kept: []string{`href="https://example.com/"`, `href="/relative?q=1"`, `href="mailto:me@example.com"`, `href="#top"`},
+		},
+		{
+			name: "data images",
+			html: `<img src="data:image/png;base64,iVBORw0KGgo=" alt="png"><img src="data:image/svg+xml;base64,PHN2Zz4=" alt="svg"><img src="DATA:IMAGE/SVG+XML,&lt;svg onload=alert(1)&gt;" alt="upper"><a href="data:text/html,x">link</a>`,
+			gone: []string{"svg+xml", "SVG+XML", "data:text/html"},
+			kept: []string{`src="data:image/png;base64,iVBORw0KGgo="`, `alt="svg"`, `alt="upper"`, "<a>link</a>"},
+		},
+		{
+			name: "data images in srcset",
+			html: `<img srcset="data:image/png;base64,AAAA 1x, https://example.com/b.png 2x" alt="a">`,
+			gone: []string{"srcset"},
+		},
+		{
+			name: "svg xlink:href",
+			html: `<svg><a xlink:href="javascript:alert(1)"><text>x</text></a><use xlink:href="#icon"></use><use href="data:image/svg+xml,x"></use></svg>`,
+			gone: []string{"javascript", "alert", "data:image/svg+xml"},
+			kept: []string{`xlink:href="#icon"`, "<text>x</text>"},
+		},
+		{
+			name: "svg scripts and animation",
+			html: `<svg><script>alert(1)</script><animate attributeName="href" to="javascript:alert(2)"></animate><set attributeName="onload" to="alert(3)"></set><foreignObject><p>inside</p></foreignObject><circle r="4" onload="alert(4)"></circle></svg>`,
+			gone: []string{"<script", "<animate", "<set", "foreignobject", "inside", "alert"},
+			kept: []string{`<circle r="4">`},
+		},
+		{
+			name: "meta refresh",
+			html: `<html><head><meta http-equiv="refresh" content="0;url=https://evil.example/"><META HTTP-EQUIV="Refresh" content="1"><meta charset="utf-8"></head><body></body></html>`,
+			gone: []string{"refresh", "Refresh", "evil.example"},
+			kept: []string{`<meta charset="utf-8"/>`},
+		},
+		{
+			name: "base",
+			html: `<html><head><base href="https://evil.example/"><BASE target="_blank"></head><body><a href="page">a</a></body></html>`,
+			gone: []string{"<base", "evil.example", "_blank"},
+			kept: []string{`<a href="page">a</a>`},
+		},
+		{
+			name: "frames and forms",
+			html: `<iframe src="https://example.com/"></iframe><form action="https://evil.example/"><input name="q"><button formaction="javascript:x">go</button></form>`,
+			gone: []string{"<iframe", "<form", "<input", "<button", "evil.example", "javascript"},
+		},
+		{
+			name: "links other than stylesheets",
+			html: `<html><head><link rel="stylesheet" href="a.css"><link rel="prefetch" href="https://evil.example/"><link rel="import" href="b.html"></head></html>`,
+			gone: []string{"prefetch", "evil.example", "import"},
+			kept: []string{`<link rel="stylesheet" href="a.css"/>`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Sanitize(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.gone {
+				if strings.Contains(got, s) {
+					t.Errorf("output contains %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.kept {
+				if !strings.Contains(got, s) {
+					t.Errorf("output lacks %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitize_WithPolicy(t *testing.T) {
+	policy := Policy("abc", true)
+	meta := `<meta http-equiv="Content-Security-Policy" content="` + policy + `">`
+	tests := []struct {
+		name     string
+		html     string
+		sanitize bool
+		// after is what the meta tag must directly follow; empty puts it first.
+		after string
+	}{
+		{"head", `<html><head><title>t</title></head><body><p>x</p></body></html>`, false, "<html><head>"},
+		{"head with attributes", `<html><HEAD lang="en"><title>t</title></HEAD></html>`, false, `<html><HEAD lang="en">`},
+		{"no head", `<p>x</p>`, false, ""},
+		{"header but no head", `<header><h1>x</h1></header>`, false, ""},
+		{"sanitised fragment", `<p>x</p>`, true, "<html><head>"},
+		{"policy set by the page", `<html><head><meta http-equiv="Content-Security-Policy" content="default-src *"></head></html>`, true, "<html><head>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := tt.html
+			if tt.sanitize {
+				sanitized, err := Sanitize(html)
+				if err != nil {
+					t.Fatal(err)
+				}
+				html = sanitized
+			}
+			got := WithPolicy(html, policy)
+
+			if n := strings.Count(strings.ToLower(got), "content-security-policy"); n != 1 {
+				t.Errorf("%d policy meta tags, want 1:\n%s", n, got)
+			}
+			if !strings.HasPrefix(got, tt.after+meta) {
+				t.Errorf("policy meta tag not right after %q:\n%s", tt.after, got)
+			}
+		})
+	}
+}
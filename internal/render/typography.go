@@ -0,0 +1,93 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+// Typography is the reader's preferred text style. Zero fields keep the
+// reader template's defaults.
+type Typography struct {
+	// FontFamily is a CSS font-family list, such as "Atkinson Hyperlegible,
+	// sans-serif". Quotes and other punctuation are dropped.
+	FontFamily string
+	// FontSize is the base font size in pixels; headings scale with it.
+	FontSize int
+	// LineHeight is the line height as a multiple of the font size.
+	LineHeight float64
+	// Width is the widest the text column grows, in pixels.
+	Width int
+	// Justify aligns paragraphs to both edges, with hyphenation.
+	Justify bool
+}
+
+// IsZero reports whether t changes nothing.
+func (t Typography) IsZero() bool {
+	return t == Typography{}
+}
+
+// Variables returns the CSS custom properties the reader template reads
+// its text style from, for a :root rule; empty when t is zero.
+func (t Typography) Variables() template.CSS {
+	var vars []string
+	if family := fontFamily(t.FontFamily); family != "" {
+		vars = append(vars, "--reader-font: "+family)
+	}
+	if t.FontSize > 0 {
+		vars = append(vars, fmt.Sprintf("--reader-size: %dpx", t.FontSize))
+	}
+	if t.LineHeight > 0 {
+		vars = append(vars, "--reader-line-height: "+strconv.FormatFloat(t.LineHeight, 'f', -1, 64))
+	}
+	if t.Width > 0 {
+		vars = append(vars, fmt.Sprintf("--reader-width: %dpx", t.Width))
+	}
+	if t.Justify {
+		vars = append(vars, "--reader-align: justify", "--reader-hyphens: auto")
+	}
+	if len(vars) == 0 {
+		return ""
+	}
+	return template.CSS(strings.Join(vars, "; ") + ";")
+}
+
+// Describe puts t in words for the LLM prompt; empty when t is zero.
+func (t Typography) Describe() string {
+	var parts []string
+	if family := fontFamily(t.FontFamily); family != "" {
+		parts = append(parts, "the font "+family)
+	}
+	if t.FontSize > 0 {
+		parts = append(parts, fmt.Sprintf("a base font size of %dpx", t.FontSize))
+	}
+	if t.LineHeight > 0 {
+		parts = append(parts, "a line height of "+strconv.FormatFloat(t.LineHeight, 'f', -1, 64))
+	}
+	if t.Width > 0 {
+		parts = append(parts, fmt.Sprintf("a text column at most %dpx wide", t.Width))
+	}
+	if t.Justify {
+		parts = append(parts, "justified, hyphenated paragraphs")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Use " + strings.Join(parts, ", ") + "."
+}
+
+// fontFamily keeps the characters an unquoted font-family list needs, so a
+// family typed in settings cannot close the rule it is put in.
+func fontFamily(family string) string {
+	family = strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ' || r == ',' || r == '-' || r == '_':
+			return r
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r > 0x7f:
+			return r
+		}
+		return -1
+	}, family)
+	return strings.TrimSpace(family)
+}
@@ -0,0 +1,49 @@
+// Package retry holds what the scraper and the LLM client share about
+// retrying HTTP requests: how Retry-After is read and bounded, and how
+// retries are reported to the caller.
+package retry
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxAfter bounds how long a server may ask us to wait before we give up retrying.
+const MaxAfter = 2 * time.Minute
+
+// Notifier is called before each retry with the upcoming attempt number and the error that caused it.
+type Notifier func(attempt int, err error)
+
+type notifierKey struct{}
+
+// WithNotifier returns a context that reports the retries of requests made
+// with it to fn, page fetches and LLM requests alike.
+func WithNotifier(ctx context.Context, fn Notifier) context.Context {
+	return context.WithValue(ctx, notifierKey{}, fn)
+}
+
+// Notify reports the retry of attempt after err to ctx's notifier, if it has one.
+func Notify(ctx context.Context, attempt int, err error) {
+	if fn, _ := ctx.Value(notifierKey{}).(Notifier); fn != nil {
+		fn(attempt, err)
+	}
+}
+
+// ParseAfter reads a Retry-After header, given in seconds or as an HTTP
+// date. Missing, invalid and past values give zero.
+func ParseAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetry_ParseAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{value: ""},
+		{value: "0"},
+		{value: "-5"},
+		{value: "soon"},
+		{value: "120", min: 2 * time.Minute, max: 2 * time.Minute},
+		{value: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), min: 59 * time.Minute, max: time.Hour},
+		{value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)},
+	}
+	for _, tt := range tests {
+		if got := ParseAfter(tt.value); got < tt.min || got > tt.max {
+			t.Errorf("ParseAfter(%q) = %v, want between %v and %v", tt.value, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRetry_Notify(t *testing.T) {
+	Notify(context.Background(), 2, errors.New("no notifier"))
+
+	var attempts []int
+	ctx := WithNotifier(context.Background(), func(attempt int, err error) {
+		attempts = append(attempts, attempt)
+	})
+	Notify(ctx, 2, errors.New("busy"))
+	Notify(ctx, 3, errors.New("busy"))
+	if len(attempts) != 2 || attempts[0] != 2 || attempts[1] != 3 {
+		t.Errorf("notified attempts %v, want [2 3]", attempts)
+	}
+}
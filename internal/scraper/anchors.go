@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxSlugRunes bounds the ids derived from heading text.
+const maxSlugRunes = 64
+
+// anchorHeadings gives every heading a unique ID, keeping the page's own id
+// so links to its fragments still land, and deriving one from the text otherwise.
+func anchorHeadings(headings []Heading) []Heading {
+	used := make(map[string]bool)
+	for i, h := range headings {
+		id := h.ID
+		if id == "" || strings.ContainsFunc(id, unicode.IsSpace) {
+			id = slug(h.Text)
+		}
+		base := id
+		for n := 2; used[id]; n++ {
+			id = base + "-" + strconv.Itoa(n)
+		}
+		used[id] = true
+		headings[i].ID = id
+	}
+	return headings
+}
+
+// ParagraphID returns a stable id for a paragraph, derived from its text so
+// it survives re-scraping as long as the paragraph is unchanged.
+func ParagraphID(text string) string {
+	sum := sha1.Sum([]byte(text))
+	return "p-" + hex.EncodeToString(sum[:4])
+}
+
+func slug(text string) string {
+	var builder strings.Builder
+	dash := false
+	runes := 0
+	for _, r := range strings.ToLower(text) {
+		if runes == maxSlugRunes {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && builder.Len() > 0 {
+				builder.WriteByte('-')
+				runes++
+			}
+			builder.WriteRune(r)
+			runes++
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if builder.Len() == 0 {
+		return "section"
+	}
+	return builder.String()
+}
@@ -0,0 +1,62 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"chimera/internal/retry"
+)
+
+// ErrAssetTooLarge is returned by Asset for bodies over its size limit.
+var ErrAssetTooLarge = errors.New("asset too large")
+
+// Asset downloads a resource referenced by a page, such as an image, through
+// the scraper's client, so it shares its proxy, headers and rate limits.
+// Bodies longer than limit bytes are refused. The content type is the one
+// the server declared.
+func (s *Scraper) Asset(ctx context.Context, target string, limit int64) (data []byte, contentType string, err error) {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, "", fmt.Errorf("invalid asset URL %q", target)
+	}
+	if err := s.limiter.Wait(ctx, parsed.Host); err != nil {
+		return nil, "", fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("build request: %w", err)
+	}
+	s.setHeaders(req)
+	req.Header.Set("Accept", "image/avif,image/webp,image/*;q=0.9,*/*;q=0.5")
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+
+	resp, err := s.send(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch asset: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, "", &StatusError{Code: resp.StatusCode, RetryAfter: retry.ParseAfter(resp.Header.Get("Retry-After"))}
+	}
+	if resp.ContentLength > limit {
+		return nil, "", ErrAssetTooLarge
+	}
+	reader, err := decodeBody(resp)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err = io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read asset: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, "", ErrAssetTooLarge
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+)
+
+// ProgressNotifier is called by ScrapeAll as each page finishes, with the
+// number of pages done so far, the total, the page's URL and its error, if any.
+// Calls are serialised, so done increases by one each time.
+type ProgressNotifier func(done, total int, target string, err error)
+
+type progressNotifierKey struct{}
+
+// WithProgressNotifier returns a context that reports ScrapeAll progress to fn.
+func WithProgressNotifier(ctx context.Context, fn ProgressNotifier) context.Context {
+	return context.WithValue(ctx, progressNotifierKey{}, fn)
+}
+
+func progressNotifier(ctx context.Context) ProgressNotifier {
+	fn, _ := ctx.Value(progressNotifierKey{}).(ProgressNotifier)
+	return fn
+}
+
+// ScrapeAll scrapes urls with at most concurrency pages in flight; zero or
+// less scrapes them all at once. Results and errors line up with urls: for
+// each index exactly one of them is set. Requests share the Scraper's
+// per-host rate limit, so pages on the same host still queue behind each
+// other. Pages not started before ctx is done fail with ctx's error.
+func (s *Scraper) ScrapeAll(ctx context.Context, urls []string, concurrency int) ([]*Result, []error) {
+	results := make([]*Result, len(urls))
+	errs := make([]error, len(urls))
+	if len(urls) == 0 {
+		return results, errs
+	}
+	if concurrency <= 0 || concurrency > len(urls) {
+		concurrency = len(urls)
+	}
+
+	notify := progressNotifier(ctx)
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	report := func(i int) {
+		if notify == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		notify(done, len(urls), urls[i], errs[i])
+	}
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range urls {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			report(i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i], errs[i] = s.Scrape(ctx, target)
+			report(i)
+		}(i, target)
+	}
+	wg.Wait()
+	return results, errs
+}
@@ -0,0 +1,58 @@
+package scraper
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// boilerplateSelectors match page chrome removed everywhere: menus, cookie
+// consent widgets and ad slots.
+var boilerplateSelectors = []string{
+	"nav", "menu", "[role='navigation']", "dialog", "[aria-modal='true']",
+	// Cookie consent banners, generic and from known consent managers.
+	"[id*='cookie-banner']", "[class*='cookie-banner']",
+	"[id*='cookie-consent']", "[class*='cookie-consent']",
+	"[id*='cookie-notice']", "[class*='cookie-notice']",
+	"[id*='cookie-law']", "[class*='cookie-law']",
+	"[id*='gdpr']", "[class*='gdpr']", "[aria-label*='cookie' i]",
+	// Ad containers.
+	"ins.adsbygoogle", "[id^='google_ads']", "[id^='div-gpt-ad']", "[data-ad]", "[data-ad-slot]",
+	"[data-ad-unit]", ".ad", ".ads", ".advert", ".advertisement", ".ad-container", ".ad-slot",
+	".ad-banner", ".sponsored", ".promo-banner", "[aria-label='Advertisement' i]",
+}
+
+// outerChromeSelectors match page chrome removed only outside the main
+// content, since an article's own footer and asides often carry its byline,
+// tags or side notes.
+var outerChromeSelectors = []string{
+	"footer", "aside", "[role='contentinfo']", "[role='complementary']", "[role='banner']",
+}
+
+// SetStripBoilerplate turns the removal of page chrome before extraction on
+// or off for subsequent scrapes; see Config.StripBoilerplate.
+func (s *Scraper) SetStripBoilerplate(on bool) {
+	s.clean.Store(on)
+}
+
+// withoutBoilerplate returns a copy of doc without navigation, footers,
+// sidebars, cookie banners and ads, for extracting paragraphs, headings and
+// links. doc itself is left as is.
+func withoutBoilerplate(doc *goquery.Document) *goquery.Document {
+	clean := goquery.NewDocumentFromNode(doc.Clone().Nodes[0])
+
+	clean.Find(strings.Join(boilerplateSelectors, ", ")).Remove()
+	for _, rule := range consentRules {
+		if rule.selector != "" {
+			clean.Find(rule.selector).Not("script").Remove()
+		}
+	}
+
+	hasRoot := clean.Find(contentSelector).Length() > 0
+	clean.Find(strings.Join(outerChromeSelectors, ", ")).Each(func(_ int, sel *goquery.Selection) {
+		if !hasRoot || sel.Closest(contentSelector).Length() == 0 {
+			sel.Remove()
+		}
+	})
+	return clean
+}
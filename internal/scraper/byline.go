@@ -0,0 +1,240 @@
+package scraper
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxAuthorRunes bounds an author name; longer bylines are prose, not names.
+const maxAuthorRunes = 100
+
+var bylinePattern = regexp.MustCompile(`(?i)^(?:written\s+)?by\s+(.+)$`)
+
+// articleTypes are the JSON-LD types whose author and datePublished describe the page.
+var articleTypes = map[string]bool{
+	"Article": true, "NewsArticle": true, "BlogPosting": true, "Report": true,
+	"ScholarlyArticle": true, "TechArticle": true, "AnalysisNewsArticle": true,
+	"OpinionNewsArticle": true, "ReviewNewsArticle": true, "WebPage": true,
+}
+
+var authorMeta = []string{
+	"meta[name='author']",
+	"meta[property='article:author']",
+	"meta[name='parsely-author']",
+	"meta[name='sailthru.author']",
+	"meta[name='dc.creator']",
+	"meta[name='DC.creator']",
+}
+
+var siteNameMeta = []string{
+	"meta[property='og:site_name']",
+	"meta[name='application-name']",
+	"meta[name='apple-mobile-web-app-title']",
+}
+
+var dateMeta = []string{
+	"meta[property='article:published_time']",
+	"meta[itemprop='datePublished']",
+	"meta[name='parsely-pub-date']",
+	"meta[name='sailthru.date']",
+	"meta[name='publish-date']",
+	"meta[name='pubdate']",
+	"meta[name='dc.date']",
+	"meta[name='DC.date']",
+	"meta[name='date']",
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"2 January 2006",
+}
+
+// extractByline fills result.Author and result.PublishedAt from JSON-LD,
+// meta tags, <time> elements and byline text, in that order of preference.
+func extractByline(result *Result, doc *goquery.Document) {
+	author, published := jsonLDByline(doc)
+
+	if author == "" {
+		for _, selector := range authorMeta {
+			if content, ok := doc.Find(selector).First().Attr("content"); ok {
+				if author = cleanAuthor(content); author != "" {
+					break
+				}
+			}
+		}
+	}
+	if author == "" {
+		author = markupAuthor(doc)
+	}
+
+	if published.IsZero() {
+		for _, selector := range dateMeta {
+			if content, ok := doc.Find(selector).First().Attr("content"); ok {
+				if published = parseDate(content); !published.IsZero() {
+					break
+				}
+			}
+		}
+	}
+	if published.IsZero() {
+		published = timeElement(doc)
+	}
+
+	result.Author = author
+	result.PublishedAt = published
+}
+
+func jsonLDByline(doc *goquery.Document) (string, time.Time) {
+	var (
+		author    string
+		published time.Time
+	)
+	doc.Find("script[type='application/ld+json']").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if json.Unmarshal([]byte(s.Text()), &data) != nil {
+			return true
+		}
+		for _, node := range jsonLDNodes(data) {
+			if !articleTypes[jsonLDType(node)] {
+				continue
+			}
+			if author == "" {
+				author = jsonLDAuthor(node["author"])
+			}
+			if published.IsZero() {
+				if date, ok := node["datePublished"].(string); ok {
+					published = parseDate(date)
+				}
+			}
+		}
+		return author == "" || published.IsZero()
+	})
+	return author, published
+}
+
+// jsonLDNodes flattens top-level arrays and @graph lists into objects.
+func jsonLDNodes(data any) []map[string]any {
+	switch v := data.(type) {
+	case []any:
+		var nodes []map[string]any
+		for _, item := range v {
+			nodes = append(nodes, jsonLDNodes(item)...)
+		}
+		return nodes
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			return append([]map[string]any{v}, jsonLDNodes(graph)...)
+		}
+		return []map[string]any{v}
+	}
+	return nil
+}
+
+func jsonLDType(node map[string]any) string {
+	switch t := node["@type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && articleTypes[s] {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func jsonLDAuthor(value any) string {
+	switch v := value.(type) {
+	case string:
+		return cleanAuthor(v)
+	case map[string]any:
+		if name, ok := v["name"].(string); ok {
+			return cleanAuthor(name)
+		}
+	case []any:
+		var names []string
+		for _, item := range v {
+			if name := jsonLDAuthor(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// markupAuthor looks for microdata, rel="author" links and "By …" bylines.
+func markupAuthor(doc *goquery.Document) string {
+	var author string
+	doc.Find("[itemprop='author'], a[rel='author'], .byline, .author, .author-name, [class*='byline']").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		text := ""
+		if content, ok := s.Attr("content"); ok {
+			text = content
+		} else if name := s.Find("[itemprop='name']").First(); name.Length() > 0 {
+			text = name.Text()
+		} else {
+			text = s.Text()
+		}
+		author = cleanAuthor(text)
+		return author == ""
+	})
+	return author
+}
+
+func timeElement(doc *goquery.Document) time.Time {
+	for _, selector := range []string{"time[itemprop='datePublished']", "time[pubdate]", "article time[datetime]", "time[datetime]"} {
+		if value, ok := doc.Find(selector).First().Attr("datetime"); ok {
+			if published := parseDate(value); !published.IsZero() {
+				return published
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// cleanAuthor normalises whitespace and rejects values that are URLs or prose.
+func cleanAuthor(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if m := bylinePattern.FindStringSubmatch(name); m != nil {
+		name = m[1]
+	}
+	if name == "" || strings.Contains(name, "://") || strings.HasPrefix(name, "@") || len([]rune(name)) > maxAuthorRunes {
+		return ""
+	}
+	return name
+}
+
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// siteName returns the site's name from its meta tags, or "".
+func siteName(doc *goquery.Document) string {
+	for _, selector := range siteNameMeta {
+		if content, ok := doc.Find(selector).First().Attr("content"); ok {
+			if name := strings.Join(strings.Fields(content), " "); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
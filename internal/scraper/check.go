@@ -0,0 +1,84 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"chimera/internal/retry"
+)
+
+// CheckResult reports how a URL answered a Check.
+type CheckResult struct {
+	// Status is the HTTP status of the final response; zero when a refused
+	// cross-origin redirect ended the check.
+	Status int
+	// FinalURL is where the URL ends up after redirects.
+	FinalURL string
+}
+
+// Check asks whether target still answers, without downloading or parsing
+// it. It sends a HEAD request and falls back to GET for servers that do not
+// support HEAD. Failures are returned as *FetchError, like Scrape; a redirect
+// to another origin refused by SameOriginRedirects is reported as a move, not
+// a failure.
+func (s *Scraper) Check(ctx context.Context, target string) (CheckResult, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || !parsed.IsAbs() {
+		return CheckResult{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	result, err := s.checkOnce(ctx, parsed, http.MethodHead)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && headUnsupported(statusErr.Code) {
+		result, err = s.checkOnce(ctx, parsed, http.MethodGet)
+	}
+
+	var redirErr *RedirectError
+	if errors.As(err, &redirErr) && errors.Is(err, ErrCrossOriginRedirect) {
+		return CheckResult{FinalURL: redirErr.To}, nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return CheckResult{}, err
+		}
+		return CheckResult{}, &FetchError{Kind: classifyFailure(err), URL: target, Err: err}
+	}
+	return result, nil
+}
+
+func (s *Scraper) checkOnce(ctx context.Context, target *url.URL, method string) (CheckResult, error) {
+	if err := s.limiter.Wait(ctx, target.Host); err != nil {
+		return CheckResult{}, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), nil)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("build request: %w", err)
+	}
+	s.setHeaders(req)
+
+	resp, err := s.send(req)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("check document: %w", err)
+	}
+	// The body of a GET fallback is not needed; closing it early drops the connection.
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return CheckResult{}, &StatusError{Code: resp.StatusCode, RetryAfter: retry.ParseAfter(resp.Header.Get("Retry-After"))}
+	}
+	return CheckResult{Status: resp.StatusCode, FinalURL: resp.Request.URL.String()}, nil
+}
+
+// headUnsupported reports whether code is an answer some servers give to
+// HEAD requests while serving GET normally.
+func headUnsupported(code int) bool {
+	switch code {
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented, http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return false
+}
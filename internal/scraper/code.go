@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// CodeBlock is a preformatted code sample with its language, if the page names one.
+type CodeBlock struct {
+	Language string `json:"language,omitempty"`
+	Code     string `json:"code"`
+}
+
+// codeClassPrefixes mark the language in class names used by highlight.js,
+// Prism, GitHub and Pygments-based generators.
+var codeClassPrefixes = []string{"language-", "lang-", "highlight-source-", "highlight-"}
+
+// collectCode returns the first limit <pre> blocks of doc with their
+// language hints. Whitespace inside a block is kept as is.
+func collectCode(doc *goquery.Document, limit int) []CodeBlock {
+	var blocks []CodeBlock
+	doc.Find("pre").EachWithBreak(func(_ int, pre *goquery.Selection) bool {
+		if pre.ParentsFiltered("pre").Length() > 0 {
+			return true
+		}
+		code := strings.Trim(pre.Text(), "\n")
+		if strings.TrimSpace(code) == "" {
+			return true
+		}
+
+		language := ""
+		for _, sel := range []*goquery.Selection{pre.Find("code").First(), pre, pre.Parent()} {
+			if language = codeLanguage(sel); language != "" {
+				break
+			}
+		}
+		blocks = append(blocks, CodeBlock{Language: language, Code: code})
+		return len(blocks) < limit
+	})
+	return blocks
+}
+
+func codeLanguage(sel *goquery.Selection) string {
+	if sel.Length() == 0 {
+		return ""
+	}
+	if lang, ok := sel.Attr("data-lang"); ok && lang != "" {
+		return strings.ToLower(lang)
+	}
+	class, _ := sel.Attr("class")
+	for _, name := range strings.Fields(class) {
+		for _, prefix := range codeClassPrefixes {
+			if lang, ok := strings.CutPrefix(name, prefix); ok && lang != "" {
+				return strings.ToLower(lang)
+			}
+		}
+	}
+	return ""
+}
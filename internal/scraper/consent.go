@@ -0,0 +1,119 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// consentTextThreshold is the visible text length, outside the consent
+// banner, below which a page is treated as a consent wall rather than an
+// article with a banner on top.
+const consentTextThreshold = 600
+
+// consentRule recognises one consent manager and the cookies that record
+// consent with it.
+type consentRule struct {
+	name string
+	// selector matches the manager's banner or loader in the page.
+	selector string
+	// hosts are interstitial hosts the manager redirects to before the page.
+	hosts   []string
+	cookies func(now time.Time) string
+}
+
+var consentRules = []consentRule{
+	{
+		name:    "Google",
+		hosts:   []string{"consent.google.com", "consent.youtube.com"},
+		cookies: fixedCookies("SOCS=CAI; CONSENT=YES+"),
+	},
+	{
+		name:     "OneTrust",
+		selector: "#onetrust-consent-sdk, #onetrust-banner-sdk",
+		cookies: func(now time.Time) string {
+			stamp := now.UTC().Format(time.RFC3339)
+			groups := url.QueryEscape("C0001:1,C0002:1,C0003:1,C0004:1")
+			return "OptanonAlertBoxClosed=" + stamp + "; OptanonConsent=isGpcEnabled=0&datestamp=" + url.QueryEscape(stamp) + "&groups=" + groups
+		},
+	},
+	{
+		name:     "Cookiebot",
+		selector: "#CybotCookiebotDialog, script[src*='consent.cookiebot.com']",
+		cookies:  fixedCookies("CookieConsent={stamp:%27-1%27%2Cnecessary:true%2Cpreferences:true%2Cstatistics:true%2Cmarketing:true%2Cver:1}"),
+	},
+	{
+		name:     "Complianz",
+		selector: "#cmplz-cookiebanner-container, .cmplz-cookiebanner",
+		cookies:  fixedCookies("cmplz_banner-status=dismissed; cmplz_functional=allow; cmplz_preferences=allow; cmplz_statistics=allow; cmplz_marketing=allow"),
+	},
+	{
+		name:     "CookieYes",
+		selector: "#cookie-law-info-bar, .cky-consent-container",
+		cookies:  fixedCookies("viewed_cookie_policy=yes; cookielawinfo-checkbox-necessary=yes; cookielawinfo-checkbox-analytics=yes; cookieyes-consent=consent:yes,action:yes"),
+	},
+	{
+		name:     "Cookie Consent",
+		selector: ".cc-window, .cc-banner",
+		cookies:  fixedCookies("cookieconsent_status=dismiss"),
+	},
+}
+
+func fixedCookies(cookies string) func(time.Time) string {
+	return func(time.Time) string { return cookies }
+}
+
+// consentWall reports the consent manager whose interstitial doc (served
+// from final) shows instead of the page, or nil if it is not a consent wall.
+func consentWall(final *url.URL, doc *goquery.Document) *consentRule {
+	host := strings.ToLower(final.Hostname())
+	for i, rule := range consentRules {
+		for _, h := range rule.hosts {
+			if host == h {
+				return &consentRules[i]
+			}
+		}
+	}
+	if doc == nil {
+		return nil
+	}
+
+	body := doc.Find("body").Clone()
+	var found *consentRule
+	for i, rule := range consentRules {
+		if rule.selector == "" {
+			continue
+		}
+		matched := body.Find(rule.selector)
+		if matched.Length() == 0 && doc.Find("head").Find(rule.selector).Length() == 0 {
+			continue
+		}
+		if found == nil {
+			found = &consentRules[i]
+		}
+		matched.Remove()
+	}
+	if found == nil {
+		return nil
+	}
+	body.Find("script, style, noscript, template, svg").Remove()
+	if len(strings.Join(strings.Fields(body.Text()), " ")) >= consentTextThreshold {
+		return nil
+	}
+	return found
+}
+
+type consentCookieKey struct{}
+
+// withConsentCookies returns a context whose fetches also send cookies.
+func withConsentCookies(ctx context.Context, cookies string) context.Context {
+	return context.WithValue(ctx, consentCookieKey{}, cookies)
+}
+
+func consentCookies(ctx context.Context) string {
+	cookies, _ := ctx.Value(consentCookieKey{}).(string)
+	return cookies
+}
@@ -0,0 +1,185 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Crawl defaults, used when CrawlOptions leaves a field at zero.
+const (
+	defaultCrawlDepth       = 2
+	defaultCrawlPages       = 20
+	defaultCrawlConcurrency = 4
+)
+
+// CrawlOptions bounds a crawl.
+type CrawlOptions struct {
+	// Depth is how many links away from the start page to follow; the start
+	// page is depth 0. Defaults to 2.
+	Depth int
+	// MaxPages caps the number of pages scraped, the start page included.
+	// Defaults to 20.
+	MaxPages int
+	// PathPrefix keeps the crawl below a path, such as "/docs/guide/". When
+	// empty, the directory of the start page is used, so a crawl started on
+	// a documentation page stays in its section.
+	PathPrefix string
+	// Concurrency is the number of pages fetched at once. Defaults to 4.
+	Concurrency int
+	// UseSitemap adds the pages in the site's sitemap below PathPrefix to
+	// the first level of links, so pages nothing links to are found too.
+	UseSitemap bool
+}
+
+// SiteMap is the outcome of a crawl, in breadth-first order.
+type SiteMap struct {
+	// Root is the start URL.
+	Root  string
+	Pages []CrawledPage
+}
+
+// CrawledPage is one page reached by a crawl.
+type CrawledPage struct {
+	URL string
+	// Depth is the number of links followed from the start page.
+	Depth int
+	// Parent is the page the crawl found this one on; empty for the start page.
+	Parent string
+	// Exactly one of Result and Err is set.
+	Result *Result
+	Err    error
+}
+
+// Results returns the pages that were scraped successfully.
+func (m *SiteMap) Results() []*Result {
+	var results []*Result
+	for _, page := range m.Pages {
+		if page.Result != nil {
+			results = append(results, page.Result)
+		}
+	}
+	return results
+}
+
+// Crawl scrapes start and follows its same-origin links, breadth first,
+// until opts.Depth or opts.MaxPages is reached. Only the links kept by the
+// Scraper's link limit are followed, and never footer or in-page links.
+// Pages that fail are recorded on the site map and the crawl continues;
+// Crawl itself fails only when start is invalid or cannot be scraped. A
+// progress notifier on ctx is called as with ScrapeAll, counting per level.
+func (s *Scraper) Crawl(ctx context.Context, start string, opts CrawlOptions) (*SiteMap, error) {
+	root, err := url.Parse(start)
+	if err != nil || (root.Scheme != "http" && root.Scheme != "https") {
+		return nil, fmt.Errorf("invalid crawl start URL %q", start)
+	}
+	opts = opts.withDefaults(root)
+
+	site := &SiteMap{Root: start}
+	seen := map[string]bool{stripFragment(root): true}
+	level := []CrawledPage{{URL: stripFragment(root)}}
+
+	for depth := 0; len(level) > 0; depth++ {
+		if room := opts.MaxPages - len(site.Pages); len(level) > room {
+			level = level[:room]
+		}
+		targets := make([]string, len(level))
+		for i, page := range level {
+			targets[i] = page.URL
+		}
+		results, errs := s.ScrapeAll(ctx, targets, opts.Concurrency)
+		for i := range level {
+			level[i].Depth = depth
+			level[i].Result, level[i].Err = results[i], errs[i]
+		}
+		site.Pages = append(site.Pages, level...)
+
+		if depth == 0 && level[0].Err != nil {
+			return nil, level[0].Err
+		}
+		if err := ctx.Err(); err != nil {
+			return site, err
+		}
+		if depth == opts.Depth || len(site.Pages) >= opts.MaxPages {
+			break
+		}
+
+		var next []CrawledPage
+		if depth == 0 && opts.UseSitemap {
+			for _, link := range s.sitemapLinks(ctx, root, opts.PathPrefix) {
+				if !seen[link] {
+					seen[link] = true
+					next = append(next, CrawledPage{URL: link, Parent: root.String()})
+				}
+			}
+		}
+		for _, page := range level {
+			for _, link := range crawlLinks(page.Result, root, opts.PathPrefix) {
+				if !seen[link] {
+					seen[link] = true
+					next = append(next, CrawledPage{URL: link, Parent: page.URL})
+				}
+			}
+		}
+		level = next
+	}
+	return site, nil
+}
+
+func (o CrawlOptions) withDefaults(root *url.URL) CrawlOptions {
+	if o.Depth <= 0 {
+		o.Depth = defaultCrawlDepth
+	}
+	if o.MaxPages <= 0 {
+		o.MaxPages = defaultCrawlPages
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultCrawlConcurrency
+	}
+	if o.PathPrefix == "" {
+		o.PathPrefix = root.Path[:strings.LastIndex(root.Path, "/")+1]
+	}
+	return o
+}
+
+// crawlLinks returns result's links to root's origin below prefix, in rank
+// order and without fragments.
+func crawlLinks(result *Result, root *url.URL, prefix string) []string {
+	if result == nil {
+		return nil
+	}
+	links := append([]Link(nil), result.Links...)
+	rankLinks(links)
+
+	var out []string
+	for _, link := range links {
+		if link.Class == LinkFooter || link.Class == LinkAnchor {
+			continue
+		}
+		parsed, err := url.Parse(link.Href)
+		if err != nil || !sameOrigin(root, parsed) || !strings.HasPrefix(parsed.Path, prefix) {
+			continue
+		}
+		out = append(out, stripFragment(parsed))
+	}
+	return out
+}
+
+// sitemapLinks returns the pages in root's sitemap on its origin below
+// prefix, without fragments. A site without a sitemap has none.
+func (s *Scraper) sitemapLinks(ctx context.Context, root *url.URL, prefix string) []string {
+	pages, err := s.Sitemap(ctx, root.String())
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, page := range pages {
+		parsed, err := url.Parse(page.Loc)
+		if err != nil || !sameOrigin(root, parsed) || !strings.HasPrefix(parsed.Path, prefix) {
+			continue
+		}
+		out = append(out, stripFragment(parsed))
+	}
+	return out
+}
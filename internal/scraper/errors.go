@@ -0,0 +1,108 @@
+package scraper
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// FailureKind classifies why a page could not be fetched.
+type FailureKind int
+
+const (
+	// FailureOther covers errors that fit no other kind.
+	FailureOther FailureKind = iota
+	// FailureDNS means the host name could not be resolved.
+	FailureDNS
+	// FailureConnect means the server refused or could not be reached.
+	FailureConnect
+	// FailureTLS means the TLS handshake or certificate check failed.
+	FailureTLS
+	// FailureTimeout means the server did not answer in time.
+	FailureTimeout
+	// FailureStatus means the server answered with an HTTP error status.
+	FailureStatus
+	// FailureRedirect means a redirect was refused; see RedirectError.
+	FailureRedirect
+)
+
+func (k FailureKind) String() string {
+	switch k {
+	case FailureDNS:
+		return "DNS lookup failed"
+	case FailureConnect:
+		return "connection failed"
+	case FailureTLS:
+		return "TLS handshake failed"
+	case FailureTimeout:
+		return "timed out"
+	case FailureStatus:
+		return "HTTP error"
+	case FailureRedirect:
+		return "redirect refused"
+	default:
+		return "fetch failed"
+	}
+}
+
+// FetchError is returned by Scrape when the document could not be downloaded.
+type FetchError struct {
+	Kind FailureKind
+	URL  string
+	Err  error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status code for FailureStatus errors, or zero.
+func (e *FetchError) Status() int {
+	var statusErr *StatusError
+	if errors.As(e.Err, &statusErr) {
+		return statusErr.Code
+	}
+	return 0
+}
+
+func classifyFailure(err error) FailureKind {
+	var (
+		statusErr *StatusError
+		redirErr  *RedirectError
+		dnsErr    *net.DNSError
+		certErr   *tls.CertificateVerificationError
+		unknownCA x509.UnknownAuthorityError
+		hostErr   x509.HostnameError
+		invalid   x509.CertificateInvalidError
+		recordErr tls.RecordHeaderError
+		alertErr  tls.AlertError
+		netErr    net.Error
+		opErr     *net.OpError
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return FailureStatus
+	case errors.As(err, &redirErr):
+		return FailureRedirect
+	case errors.As(err, &dnsErr):
+		return FailureDNS
+	case errors.As(err, &certErr), errors.As(err, &unknownCA), errors.As(err, &hostErr),
+		errors.As(err, &invalid), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return FailureTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return FailureTimeout
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EHOSTUNREACH),
+		errors.Is(err, syscall.ENETUNREACH), errors.Is(err, syscall.ECONNRESET),
+		errors.As(err, &opErr) && opErr.Op == "dial":
+		return FailureConnect
+	}
+	return FailureOther
+}
@@ -0,0 +1,208 @@
+package scraper
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"chimera/internal/retry"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding is sent explicitly so servers may pick Brotli. Setting it
+// turns off the transport's transparent gzip handling, so decodeBody covers both.
+const acceptEncoding = "gzip, br"
+
+// document is a fetched response body and its metadata.
+type document struct {
+	body      []byte
+	header    http.Header
+	attempts  int
+	finalURL  *url.URL
+	redirects []string
+}
+
+// StatusError reports an HTTP error status from the fetched document.
+type StatusError struct {
+	Code       int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.Code)
+}
+
+func (s *Scraper) fetch(ctx context.Context, target *url.URL) (*document, error) {
+	backoff := s.retry.backoff
+	for attempt := 1; ; attempt++ {
+		doc, err := s.fetchOnce(ctx, target)
+		if err == nil {
+			doc.attempts = attempt
+			return doc, nil
+		}
+		if attempt > s.retry.attempts || !isTransient(err) || ctx.Err() != nil {
+			if attempt > 1 {
+				return nil, fmt.Errorf("after %d attempts: %w", attempt, err)
+			}
+			return nil, err
+		}
+
+		delay := s.retry.jittered(backoff)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			if statusErr.RetryAfter > retry.MaxAfter {
+				return nil, fmt.Errorf("after %d attempts: %w", attempt, err)
+			}
+			if statusErr.RetryAfter > delay {
+				delay = statusErr.RetryAfter
+			}
+		}
+
+		retry.Notify(ctx, attempt+1, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > s.retry.maxBackoff {
+			backoff = s.retry.maxBackoff
+		}
+	}
+}
+
+func (s *Scraper) fetchOnce(ctx context.Context, target *url.URL) (*document, error) {
+	if err := s.limiter.Wait(ctx, target.Host); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	chain := &redirectChain{}
+	req, err := http.NewRequestWithContext(withRedirectChain(ctx, chain), http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	s.setHeaders(req)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if cookies := consentCookies(ctx); cookies != "" {
+		if existing := req.Header.Get("Cookie"); existing != "" {
+			cookies = existing + "; " + cookies
+		}
+		req.Header.Set("Cookie", cookies)
+	}
+
+	resp, err := s.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, &StatusError{Code: resp.StatusCode, RetryAfter: retry.ParseAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	reader, err := decodeBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(reader, 4*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	return &document{body: body, header: resp.Header, finalURL: resp.Request.URL, redirects: chain.urls}, nil
+}
+
+// decodeBody undoes the response's Content-Encoding. Brotli is decoded even
+// when it was not requested, since some CDNs send it regardless.
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+		return resp.Body, nil
+	case "br":
+		return brotli.NewReader(resp.Body), nil
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(resp.Body)
+		if errors.Is(err, io.EOF) {
+			return strings.NewReader(""), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode gzip body: %w", err)
+		}
+		return reader, nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+type retryPolicy struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	jitter     float64
+}
+
+func newRetryPolicy(cfg Config) retryPolicy {
+	policy := retryPolicy{
+		attempts:   cfg.Retries,
+		backoff:    cfg.RetryBackoff,
+		maxBackoff: cfg.RetryMaxBackoff,
+		jitter:     cfg.RetryJitter,
+	}
+	if policy.attempts < 0 {
+		policy.attempts = 0
+	}
+	if policy.backoff <= 0 {
+		policy.backoff = 500 * time.Millisecond
+	}
+	if policy.maxBackoff < policy.backoff {
+		policy.maxBackoff = 16 * policy.backoff
+	}
+	if policy.jitter < 0 {
+		policy.jitter = 0
+	}
+	if policy.jitter > 1 {
+		policy.jitter = 1
+	}
+	return policy
+}
+
+func (p retryPolicy) jittered(d time.Duration) time.Duration {
+	if p.jitter == 0 {
+		return d
+	}
+	spread := float64(d) * p.jitter
+	return time.Duration(float64(d) - spread + rand.Float64()*2*spread)
+}
+
+func isTransient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.Code {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests,
+			http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,135 @@
+package scraper
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// footnoteAttr marks note bodies so their text is not collected as paragraphs.
+const footnoteAttr = "data-chimera-footnote"
+
+var (
+	// FootnoteRefPattern matches the [^label] markers that stand in for
+	// footnote references inside Result.Paragraphs.
+	FootnoteRefPattern = regexp.MustCompile(`\[\^([A-Za-z0-9_-]+)\]`)
+
+	footnoteHrefPattern = regexp.MustCompile(`(?i)^#(?:fn|footnote|note|endnote|cite_note|ftn|en)[-_:]?`)
+	footnoteBacklinks   = "a[role='doc-backlink'], .mw-cite-backlink, a.footnote-backref, a.reversefootnote, a[href^='#fnref'], a[href^='#ref']"
+)
+
+// Footnote is a note referenced from the text by a [^Label] marker.
+type Footnote struct {
+	Label string `json:"label"`
+	Text  string `json:"text"`
+}
+
+// markFootnotes replaces footnote references in doc with [^label] markers
+// and returns the notes they point to, in order of first reference.
+func markFootnotes(doc *goquery.Document) []Footnote {
+	targets := make(map[string]*goquery.Selection)
+	doc.Find("[id]").Each(func(_ int, s *goquery.Selection) {
+		id, _ := s.Attr("id")
+		if _, ok := targets[id]; !ok {
+			targets[id] = s
+		}
+	})
+
+	labels := make(map[string]string) // target id → label
+	used := make(map[string]bool)
+	var notes []Footnote
+	doc.Find("a[href^='#']").Each(func(_ int, ref *goquery.Selection) {
+		if ref.Is(footnoteBacklinks) || !isFootnoteRef(ref) {
+			return
+		}
+		href, _ := ref.Attr("href")
+		id := strings.TrimPrefix(href, "#")
+		target, ok := targets[id]
+		if !ok {
+			return
+		}
+
+		label, ok := labels[id]
+		if !ok {
+			text := footnoteText(target)
+			if text == "" {
+				return
+			}
+			label = footnoteLabel(ref.Text())
+			if label == "" || used[label] {
+				label = strconv.Itoa(len(notes) + 1)
+			}
+			labels[id] = label
+			used[label] = true
+			target.SetAttr(footnoteAttr, label)
+			notes = append(notes, Footnote{Label: label, Text: text})
+		}
+
+		marker := html.EscapeString("[^" + label + "]")
+		if sup := ref.Parent(); goquery.NodeName(sup) == "sup" && sup.Children().Length() == 1 {
+			sup.ReplaceWithHtml(marker)
+		} else {
+			ref.ReplaceWithHtml(marker)
+		}
+	})
+	return notes
+}
+
+func isFootnoteRef(ref *goquery.Selection) bool {
+	if role, _ := ref.Attr("role"); role == "doc-noteref" {
+		return true
+	}
+	if class, _ := ref.Attr("class"); strings.Contains(class, "footnote-ref") || strings.Contains(class, "fnref") {
+		return true
+	}
+	href, _ := ref.Attr("href")
+	if footnoteHrefPattern.MatchString(href) {
+		return true
+	}
+	return goquery.NodeName(ref.Parent()) == "sup" && footnoteLabel(ref.Text()) != ""
+}
+
+// footnoteLabel reduces reference text such as "[12]" to "12"; it returns ""
+// for text that is not a short label.
+func footnoteLabel(text string) string {
+	text = strings.Trim(strings.TrimSpace(text), "[]() ")
+	if text == "" || len(text) > 8 {
+		return ""
+	}
+	for _, r := range text {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-' || r == '_') {
+			return ""
+		}
+	}
+	return text
+}
+
+func footnoteText(target *goquery.Selection) string {
+	body := target.Clone()
+	body.Find(footnoteBacklinks).Remove()
+	text := strings.Join(strings.Fields(body.Text()), " ")
+	return strings.TrimSpace(strings.Trim(text, "^↩ "))
+}
+
+// referencedFootnotes keeps the notes whose markers appear in paragraphs.
+func referencedFootnotes(notes []Footnote, paragraphs []string) []Footnote {
+	if len(notes) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, p := range paragraphs {
+		for _, m := range FootnoteRefPattern.FindAllStringSubmatch(p, -1) {
+			seen[m[1]] = true
+		}
+	}
+	var kept []Footnote
+	for _, note := range notes {
+		if seen[note.Label] {
+			kept = append(kept, note)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"net/http"
+	"strings"
+)
+
+// UserAgentPreset is a named User-Agent offered in the settings.
+type UserAgentPreset struct {
+	Name  string
+	Value string
+}
+
+// UserAgentPresets are realistic browser agents for sites that block the default one.
+var UserAgentPresets = []UserAgentPreset{
+	{Name: "Firefox on Linux", Value: "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"},
+	{Name: "Chrome on Windows", Value: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"},
+	{Name: "Safari on macOS", Value: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15"},
+	{Name: "Chrome on Android", Value: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36"},
+}
+
+// RequestOptions customises the headers sent with page and robots.txt requests.
+type RequestOptions struct {
+	// UserAgent replaces the default ChimeraScraper agent when set.
+	UserAgent string
+	// AcceptLanguage is sent with every request; when empty it is derived
+	// from the user's locale.
+	AcceptLanguage string
+	// Sites overrides the agent and adds headers per domain, keyed by the
+	// lower-cased host without a leading "www.".
+	Sites map[string]SiteRequest
+	// SkipConsentWalls fetches a page again with consent cookies when a known
+	// consent manager's interstitial stands in for its content.
+	SkipConsentWalls bool
+}
+
+// SiteRequest holds the request overrides for one domain.
+type SiteRequest struct {
+	UserAgent string
+	Headers   map[string]string
+}
+
+// SetRequestOptions replaces the request headers used by subsequent fetches.
+func (s *Scraper) SetRequestOptions(opts RequestOptions) {
+	s.request.Store(&opts)
+}
+
+func (s *Scraper) setHeaders(req *http.Request) {
+	opts := s.request.Load()
+	if opts == nil {
+		opts = &RequestOptions{}
+	}
+	req.Header.Set("Accept", acceptHeader)
+
+	language := opts.AcceptLanguage
+	if language == "" {
+		language = localeLanguage()
+	}
+	if language != "" {
+		req.Header.Set("Accept-Language", language)
+	}
+
+	agent := userAgent
+	if opts.UserAgent != "" {
+		agent = opts.UserAgent
+	}
+	site := opts.Sites[strings.TrimPrefix(strings.ToLower(req.URL.Hostname()), "www.")]
+	if site.UserAgent != "" {
+		agent = site.UserAgent
+	}
+	req.Header.Set("User-Agent", agent)
+	for name, value := range clientHints(agent) {
+		req.Header.Set(name, value)
+	}
+	for name, value := range site.Headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,47 @@
+package scraper
+
+// normalizeHeadings rewrites heading levels into a consistent outline: the
+// first heading becomes level 1 and no heading is more than one level deeper
+// than the one before it, so H1→H4 reads as H1→H2. Headings that return to a
+// shallower source level close the deeper sections they follow.
+func normalizeHeadings(headings []Heading) []Heading {
+	// open holds the source levels of the sections enclosing the current heading.
+	var open []int
+	for i, h := range headings {
+		for len(open) > 0 && open[len(open)-1] >= h.Level {
+			open = open[:len(open)-1]
+		}
+		open = append(open, h.Level)
+		headings[i].Level = len(open)
+	}
+	return headings
+}
+
+// OutlineNode is a heading with the headings nested below it.
+type OutlineNode struct {
+	Heading
+	Children []*OutlineNode
+}
+
+// Outline arranges headings, in document order with normalised levels, into
+// a tree: each heading holds the deeper headings that follow it.
+func Outline(headings []Heading) []*OutlineNode {
+	var (
+		roots []*OutlineNode
+		open  []*OutlineNode
+	)
+	for _, h := range headings {
+		node := &OutlineNode{Heading: h}
+		for len(open) > 0 && open[len(open)-1].Level >= h.Level {
+			open = open[:len(open)-1]
+		}
+		if len(open) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := open[len(open)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		open = append(open, node)
+	}
+	return roots
+}
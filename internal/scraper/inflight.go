@@ -0,0 +1,90 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"chimera/internal/gate"
+)
+
+// defaultMaxInFlight is the number of requests in flight when
+// Config.MaxInFlight is not set.
+const defaultMaxInFlight = 8
+
+// Priority orders requests competing for the Scraper's in-flight slots.
+type Priority int
+
+const (
+	// PriorityInteractive is for pages someone is waiting for. It is the
+	// default for contexts without a priority.
+	PriorityInteractive Priority = iota
+	// PriorityBackground is for work nobody is watching, such as checking
+	// watched pages. It gets a slot only while no interactive request is
+	// waiting, and never the last free one, so it cannot delay a page the
+	// user opens.
+	PriorityBackground
+)
+
+func (p Priority) String() string {
+	if p == PriorityBackground {
+		return "background"
+	}
+	return "interactive"
+}
+
+func (p Priority) class() gate.Class {
+	if p == PriorityBackground {
+		return gate.Background
+	}
+	return gate.Interactive
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose requests are sent with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityOf(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p == PriorityBackground {
+		return p
+	}
+	return PriorityInteractive
+}
+
+// Load describes the requests competing for the Scraper's in-flight slots.
+type Load = gate.Load
+
+// Load reports the in-flight requests by priority.
+func (s *Scraper) Load() Load {
+	return s.gate.Load()
+}
+
+// send performs req once an in-flight slot for its context's priority is
+// free. The slot is held until the response body is closed.
+func (s *Scraper) send(req *http.Request) (*http.Response, error) {
+	release, err := s.gate.Acquire(req.Context(), priorityOf(req.Context()).class(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &slotBody{ReadCloser: resp.Body, release: sync.OnceFunc(release)}
+	return resp, nil
+}
+
+type slotBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *slotBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,56 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ResultSchema is the version of the JSON form of Result, written as its
+// "schema" member. It changes only when a member is renamed or changes
+// meaning; new members are added without a new version, so readers should
+// ignore members they do not know.
+const ResultSchema = 1
+
+// ErrResultSchema is returned when decoding a Result written by a newer
+// schema than ResultSchema.
+var ErrResultSchema = errors.New("unsupported result schema")
+
+// plainResult has Result's fields without its JSON methods.
+type plainResult Result
+
+// resultJSON is the JSON form of Result. PublishedAt shadows the embedded
+// field so an unknown date is left out rather than written as year one.
+type resultJSON struct {
+	Schema      int        `json:"schema"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	*plainResult
+}
+
+// MarshalJSON encodes r with its schema version.
+func (r Result) MarshalJSON() ([]byte, error) {
+	out := resultJSON{Schema: ResultSchema, plainResult: (*plainResult)(&r)}
+	if !r.PublishedAt.IsZero() {
+		out.PublishedAt = &r.PublishedAt
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a Result written by MarshalJSON. Documents without
+// a schema member are read as the current version.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var decoded Result
+	in := resultJSON{plainResult: (*plainResult)(&decoded)}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in.Schema > ResultSchema {
+		return fmt.Errorf("%w: %d, newest known is %d", ErrResultSchema, in.Schema, ResultSchema)
+	}
+	if in.PublishedAt != nil {
+		decoded.PublishedAt = *in.PublishedAt
+	}
+	*r = decoded
+	return nil
+}
@@ -0,0 +1,56 @@
+package scraper
+
+import "unicode/utf8"
+
+// Defaults for the Limits fields left at zero.
+const (
+	defaultMaxItems     = 10
+	defaultHeadingDepth = 6
+	defaultMinParagraph = 40
+)
+
+// Limits caps what is extracted from a page. Zero count fields fall back to
+// Config.MaxItems.
+type Limits struct {
+	Headings   int
+	Paragraphs int
+	CodeBlocks int
+	Links      int
+	// HeadingDepth is the deepest heading level collected, from 1 to 6: 3
+	// reads h1–h3. Zero reads all six.
+	HeadingDepth int
+	// MinParagraph is the length in characters below which an HTML or PDF
+	// paragraph is skipped as a fragment. Zero uses 40.
+	MinParagraph int
+}
+
+// SetLimits replaces the extraction limits used by subsequent scrapes.
+func (s *Scraper) SetLimits(limits Limits) {
+	resolved := limits.withDefaults(s.maxItems)
+	s.limits.Store(&resolved)
+}
+
+// Limits returns the extraction limits in effect, with defaults filled in.
+func (s *Scraper) Limits() Limits {
+	return *s.limits.Load()
+}
+
+func (l Limits) withDefaults(maxItems int) Limits {
+	for _, n := range []*int{&l.Headings, &l.Paragraphs, &l.CodeBlocks, &l.Links} {
+		if *n <= 0 {
+			*n = maxItems
+		}
+	}
+	if l.HeadingDepth <= 0 || l.HeadingDepth > 6 {
+		l.HeadingDepth = defaultHeadingDepth
+	}
+	if l.MinParagraph <= 0 {
+		l.MinParagraph = defaultMinParagraph
+	}
+	return l
+}
+
+// shortParagraph reports whether text is below the minimum paragraph length.
+func (l Limits) shortParagraph(text string) bool {
+	return utf8.RuneCountInString(text) < l.MinParagraph
+}
@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// LinkClass says where on the page a link was found.
+type LinkClass string
+
+const (
+	// LinkContent is a link in the page's main text.
+	LinkContent LinkClass = "content"
+	// LinkNavigation is a menu, header, breadcrumb or sidebar link to the same site.
+	LinkNavigation LinkClass = "navigation"
+	// LinkFooter is a link in the page footer.
+	LinkFooter LinkClass = "footer"
+	// LinkExternal is a link to another site outside the main text, such as
+	// a share button or a sidebar advertisement.
+	LinkExternal LinkClass = "external"
+	// LinkAnchor jumps within the page itself.
+	LinkAnchor LinkClass = "anchor"
+)
+
+// linkRank orders classes by how much they say about the page; links are
+// capped in this order so menus do not crowd out the text's own links.
+var linkRank = map[LinkClass]int{
+	LinkContent:    0,
+	LinkExternal:   1,
+	LinkNavigation: 2,
+	LinkAnchor:     3,
+	LinkFooter:     4,
+}
+
+const (
+	navigationSelector = "nav, header, menu, [role='navigation'], [role='banner'], .breadcrumb, .breadcrumbs, .menu"
+	footerSelector     = "footer, [role='contentinfo']"
+	asideSelector      = "aside, [role='complementary']"
+	contentSelector    = "article, main, [role='main']"
+	// textSelector marks links in running text on pages without a content root.
+	textSelector = "p, li, blockquote, dd, td, figcaption"
+)
+
+// classifyLink decides the class of the link sel, which points at resolved.
+// hasRoot reports whether the page marks its main content with an article
+// or main element.
+func classifyLink(base, resolved *url.URL, sel *goquery.Selection, hasRoot bool) LinkClass {
+	if resolved != nil && resolved.Fragment != "" && samePage(base, resolved) {
+		return LinkAnchor
+	}
+	if sel.Closest(navigationSelector).Length() > 0 {
+		return LinkNavigation
+	}
+	if sel.Closest(footerSelector).Length() > 0 {
+		return LinkFooter
+	}
+	if sel.Closest(asideSelector).Length() == 0 {
+		if hasRoot && sel.Closest(contentSelector).Length() > 0 {
+			return LinkContent
+		}
+		if !hasRoot && sel.Closest(textSelector).Length() > 0 {
+			return LinkContent
+		}
+	}
+	if resolved != nil && resolved.Host != "" && !strings.EqualFold(resolved.Hostname(), base.Hostname()) {
+		return LinkExternal
+	}
+	return LinkNavigation
+}
+
+// textLinkClass classifies a link from a text or PDF document, which has no
+// page chrome: every link is content unless it jumps within the document.
+func textLinkClass(base *url.URL, href string) LinkClass {
+	if resolved, err := base.Parse(href); err == nil && resolved.Fragment != "" && samePage(base, resolved) {
+		return LinkAnchor
+	}
+	return LinkContent
+}
+
+func samePage(a, b *url.URL) bool {
+	return strings.EqualFold(a.Host, b.Host) && a.Path == b.Path && a.RawQuery == b.RawQuery
+}
+
+// rankLinks orders links by class, keeping document order within a class.
+func rankLinks(links []Link) {
+	sort.SliceStable(links, func(i, j int) bool {
+		return linkRank[links[i].Class] < linkRank[links[j].Class]
+	})
+}
@@ -0,0 +1,122 @@
+package scraper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// acceptHeader asks for HTML first, then the other formats the scraper can
+// read, the way a browser does for a navigation.
+const acceptHeader = "text/html,application/xhtml+xml,application/xml;q=0.9,application/pdf;q=0.8,text/plain;q=0.7,*/*;q=0.5"
+
+// localeLanguage is the Accept-Language derived from the environment once.
+var localeLanguage = sync.OnceValue(func() string {
+	return acceptLanguageFromLocale(os.Getenv)
+})
+
+// acceptLanguageFromLocale builds an Accept-Language value from the POSIX
+// locale variables: LANGUAGE's priority list when set, otherwise the first of
+// LC_ALL, LC_MESSAGES and LANG. Each language is followed by its base
+// language ("de-AT" by "de") and English comes last, with descending
+// weights. It returns "" for the C and POSIX locales.
+func acceptLanguageFromLocale(getenv func(string) string) string {
+	var tags []string
+	if list := getenv("LANGUAGE"); list != "" {
+		for _, entry := range strings.Split(list, ":") {
+			tags = append(tags, localeTag(entry))
+		}
+	}
+	if len(tags) == 0 || tags[0] == "" {
+		tags = nil
+		for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if value := getenv(name); value != "" {
+				tags = append(tags, localeTag(value))
+				break
+			}
+		}
+	}
+
+	var ordered []string
+	seen := map[string]bool{}
+	add := func(tag string) {
+		if tag != "" && !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			ordered = append(ordered, tag)
+		}
+	}
+	for _, tag := range tags {
+		add(tag)
+		base, _, _ := strings.Cut(tag, "-")
+		add(base)
+	}
+	if len(ordered) == 0 {
+		return ""
+	}
+	add("en")
+
+	parts := make([]string, 0, len(ordered))
+	for i, tag := range ordered {
+		q := 10 - i
+		if q < 1 {
+			q = 1
+		}
+		if i == 0 {
+			parts = append(parts, tag)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s;q=0.%d", tag, q))
+	}
+	return strings.Join(parts, ",")
+}
+
+// localeTag turns a locale name such as "pt_BR.UTF-8" or "sr_RS@latin" into
+// a language tag ("pt-BR"). The C and POSIX locales have none.
+func localeTag(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.TrimSpace(locale)
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	lang, region, _ := strings.Cut(locale, "_")
+	if region == "" {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "-" + strings.ToUpper(region)
+}
+
+// clientHints returns the low-entropy User-Agent client hints Chromium sends
+// alongside agent, or nil when agent is not a Chromium browser. Sites that
+// check the hints against the agent otherwise treat the request as a bot.
+func clientHints(agent string) map[string]string {
+	_, rest, ok := strings.Cut(agent, "Chrome/")
+	if !ok || strings.Contains(agent, "Edg/") || strings.Contains(agent, "OPR/") {
+		return nil
+	}
+	major, _, _ := strings.Cut(rest, ".")
+
+	platform := "Unknown"
+	switch {
+	case strings.Contains(agent, "Android"):
+		platform = "Android"
+	case strings.Contains(agent, "Windows"):
+		platform = "Windows"
+	case strings.Contains(agent, "Mac OS X"):
+		platform = "macOS"
+	case strings.Contains(agent, "CrOS"):
+		platform = "Chrome OS"
+	case strings.Contains(agent, "Linux"):
+		platform = "Linux"
+	}
+	mobile := "?0"
+	if strings.Contains(agent, "Mobile") {
+		mobile = "?1"
+	}
+	return map[string]string{
+		"Sec-CH-UA":          fmt.Sprintf(`"Chromium";v="%s", "Google Chrome";v="%s", "Not/A)Brand";v="8"`, major, major),
+		"Sec-CH-UA-Mobile":   mobile,
+		"Sec-CH-UA-Platform": `"` + platform + `"`,
+	}
+}
@@ -0,0 +1,159 @@
+package scraper
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// MathRefPattern matches the [math:id] markers that stand in for formulas
+// inside Result.Paragraphs.
+var MathRefPattern = regexp.MustCompile(`\[math:(\d+)\]`)
+
+// Formula is a mathematical expression referenced from the text by a
+// [math:ID] marker. MathML is sanitised presentation markup; TeX is the
+// source the page gave, if any. At least one of them is set.
+type Formula struct {
+	ID      string `json:"id"`
+	TeX     string `json:"tex,omitempty"`
+	MathML  string `json:"mathml,omitempty"`
+	Display bool   `json:"display,omitempty"`
+}
+
+// mathElements are the MathML elements kept by sanitizeMathML.
+var mathElements = map[string]bool{
+	"math": true, "semantics": true, "mrow": true, "mi": true, "mn": true, "mo": true,
+	"ms": true, "mtext": true, "mspace": true, "mfrac": true, "msqrt": true, "mroot": true,
+	"mstyle": true, "merror": true, "mpadded": true, "mphantom": true, "mfenced": true,
+	"menclose": true, "msub": true, "msup": true, "msubsup": true, "munder": true,
+	"mover": true, "munderover": true, "mmultiscripts": true, "mprescripts": true,
+	"none": true, "mtable": true, "mtr": true, "mtd": true, "mlabeledtr": true,
+}
+
+// mathAttributes are the presentation attributes kept by sanitizeMathML.
+var mathAttributes = map[string]bool{
+	"display": true, "mathvariant": true, "mathsize": true, "displaystyle": true,
+	"scriptlevel": true, "stretchy": true, "fence": true, "separator": true,
+	"accent": true, "accentunder": true, "largeop": true, "movablelimits": true,
+	"symmetric": true, "form": true, "lspace": true, "rspace": true, "minsize": true,
+	"maxsize": true, "width": true, "height": true, "depth": true, "linethickness": true,
+	"notation": true, "open": true, "close": true, "separators": true,
+	"columnalign": true, "rowalign": true, "columnspan": true, "rowspan": true,
+	"columnlines": true, "rowlines": true, "frame": true,
+}
+
+// markMath replaces MathML, KaTeX, MathJax and math/tex script elements in
+// doc with [math:id] markers and returns the formulas. Display formulas
+// outside a paragraph get one of their own so they keep their place in the text.
+func markMath(doc *goquery.Document) []Formula {
+	var formulas []Formula
+	replace := func(sel *goquery.Selection, f Formula) {
+		if f.TeX == "" && f.MathML == "" {
+			sel.Remove()
+			return
+		}
+		f.ID = strconv.Itoa(len(formulas) + 1)
+		formulas = append(formulas, f)
+		marker := html.EscapeString("[math:" + f.ID + "]")
+		if f.Display && sel.Closest("p").Length() == 0 {
+			marker = "<p>" + marker + "</p>"
+		}
+		sel.ReplaceWithHtml(marker)
+	}
+
+	// KaTeX and MathJax 3 keep accessible MathML next to their visual output.
+	doc.Find(".katex-display, .katex, mjx-container").Each(func(_ int, sel *goquery.Selection) {
+		if sel.ParentsFiltered(".katex-display").Length() > 0 {
+			return
+		}
+		display := sel.HasClass("katex-display") || sel.AttrOr("display", "") == "true"
+		replace(sel, mathFormula(sel.Find("math").First(), display))
+	})
+
+	// MathJax 2 keeps the TeX source in a script after its rendered output.
+	doc.Find(".MathJax_Preview, .MathJax, .MathJax_Display, .MathJax_SVG, .MathJax_CHTML").Remove()
+	doc.Find("script[type^='math/tex']").Each(func(_ int, sel *goquery.Selection) {
+		typ, _ := sel.Attr("type")
+		replace(sel, Formula{TeX: strings.TrimSpace(sel.Text()), Display: strings.Contains(typ, "mode=display")})
+	})
+
+	doc.Find("math").Each(func(_ int, sel *goquery.Selection) {
+		replace(sel, mathFormula(sel, sel.AttrOr("display", "") == "block"))
+	})
+	return formulas
+}
+
+func mathFormula(math *goquery.Selection, display bool) Formula {
+	if math.Length() == 0 {
+		return Formula{}
+	}
+	f := Formula{Display: display, MathML: sanitizeMathML(math, display)}
+	if tex := math.Find("annotation[encoding='application/x-tex']").First(); tex.Length() > 0 {
+		f.TeX = strings.TrimSpace(tex.Text())
+	} else {
+		f.TeX = strings.TrimSpace(math.AttrOr("alttext", ""))
+	}
+	return f
+}
+
+// sanitizeMathML rebuilds math from presentation elements and attributes
+// only, dropping annotations, links, scripts and event handlers.
+func sanitizeMathML(math *goquery.Selection, display bool) string {
+	var builder strings.Builder
+	builder.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if display {
+		builder.WriteString(` display="block"`)
+	}
+	builder.WriteString(">")
+	math.Contents().Each(func(_ int, child *goquery.Selection) {
+		writeMathNode(&builder, child)
+	})
+	builder.WriteString("</math>")
+	return builder.String()
+}
+
+func writeMathNode(builder *strings.Builder, sel *goquery.Selection) {
+	name := goquery.NodeName(sel)
+	if name == "#text" {
+		builder.WriteString(html.EscapeString(sel.Text()))
+		return
+	}
+	if !mathElements[name] {
+		return
+	}
+
+	builder.WriteString("<" + name)
+	for _, attr := range sel.Nodes[0].Attr {
+		if mathAttributes[attr.Key] {
+			builder.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+		}
+	}
+	builder.WriteString(">")
+	sel.Contents().Each(func(_ int, child *goquery.Selection) {
+		writeMathNode(builder, child)
+	})
+	builder.WriteString("</" + name + ">")
+}
+
+// referencedFormulas keeps the formulas whose markers appear in paragraphs.
+func referencedFormulas(formulas []Formula, paragraphs []string) []Formula {
+	if len(formulas) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, p := range paragraphs {
+		for _, m := range MathRefPattern.FindAllStringSubmatch(p, -1) {
+			seen[m[1]] = true
+		}
+	}
+	var kept []Formula
+	for _, f := range formulas {
+		if seen[f.ID] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
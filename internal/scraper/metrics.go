@@ -0,0 +1,117 @@
+package scraper
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// wordsPerMinute is the reading speed behind Result.ReadingMinutes.
+const wordsPerMinute = 230
+
+// minGuessHits is how many stop words must match before a language is guessed.
+const minGuessHits = 5
+
+// stopWords are frequent short words that identify a language in running text.
+var stopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "was", "this"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "ein", "eine", "auf", "für", "sich"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "dans", "pour", "que", "qui", "pas"},
+	"es": {"el", "la", "los", "las", "y", "es", "en", "que", "una", "por", "para", "con"},
+	"it": {"il", "la", "che", "di", "e", "è", "per", "una", "sono", "con", "non", "gli"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "voor", "met", "zijn", "op"},
+	"pt": {"o", "a", "os", "as", "e", "é", "que", "do", "da", "em", "um", "não"},
+	"sv": {"och", "att", "det", "som", "en", "är", "på", "för", "med", "inte", "av", "till"},
+}
+
+// measure fills the word count, reading time and language of result from
+// its full text; declared is the language the page states, if any.
+func measure(result *Result, text, declared string) {
+	result.Words = countWords(text)
+	result.ReadingMinutes = (result.Words + wordsPerMinute - 1) / wordsPerMinute
+	result.Language = languageCode(declared)
+	if result.Language == "" {
+		result.Language = guessLanguage(text)
+	}
+}
+
+// pageText returns the readable text of doc, preferring its article or main
+// element and leaving out navigation, scripts and other chrome.
+func pageText(doc *goquery.Document) string {
+	root := doc.Find("article, main, [role='main']").First()
+	if root.Length() == 0 {
+		root = doc.Find("body")
+	}
+	root = root.Clone()
+	root.Find("script, style, noscript, template, svg, nav, header, footer, aside, form").Remove()
+	return root.Text()
+}
+
+// declaredLanguage returns the language named by the <html lang> attribute
+// or a Content-Language meta tag.
+func declaredLanguage(doc *goquery.Document) string {
+	if lang, ok := doc.Find("html").Attr("lang"); ok && strings.TrimSpace(lang) != "" {
+		return lang
+	}
+	content, _ := doc.Find("meta[http-equiv='content-language'], meta[http-equiv='Content-Language']").First().Attr("content")
+	return content
+}
+
+// countWords counts whitespace-separated words; each Han, Hiragana or
+// Katakana character counts as a word, since those scripts do not use spaces.
+func countWords(text string) int {
+	words := 0
+	for _, field := range strings.Fields(text) {
+		ideographs := 0
+		for _, r := range field {
+			if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+				ideographs++
+			}
+		}
+		if ideographs > 0 {
+			words += ideographs
+		} else {
+			words++
+		}
+	}
+	return words
+}
+
+// languageCode reduces a language tag such as "en-GB" to its primary subtag.
+func languageCode(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, ",;"); i >= 0 {
+		tag = tag[:i]
+	}
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return strings.ToLower(tag)
+}
+
+// guessLanguage picks the language whose stop words occur most often in
+// text, or "" when none occurs often enough.
+func guessLanguage(text string) string {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		counts[word]++
+	}
+
+	best, bestHits := "", 0
+	for _, code := range []string{"en", "de", "fr", "es", "it", "nl", "pt", "sv"} {
+		hits := 0
+		for _, word := range stopWords[code] {
+			hits += counts[word]
+		}
+		if hits > bestHits {
+			best, bestHits = code, hits
+		}
+	}
+	if bestHits < minGuessHits {
+		return ""
+	}
+	return best
+}
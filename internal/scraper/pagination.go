@@ -0,0 +1,184 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxJoinedPages caps the continuation pages merged into one result.
+const maxJoinedPages = 9
+
+// pageCountPattern finds "Page 2 of 5" and "page 2/5" counters.
+var pageCountPattern = regexp.MustCompile(`(?i)\bpage\s+(\d+)\s*(?:of|/)\s*(\d+)\b`)
+
+// nextTextPattern matches the text of a pager's next-page link.
+var nextTextPattern = regexp.MustCompile(`(?i)^(?:next(?:\s+page)?|continue(?:\s+reading)?|weiter|suivant|siguiente|›|»|>|→)\s*[›»>→]?$`)
+
+const pagerSelector = ".pagination, .pager, .page-numbers, .pages, [class*='paginat'], nav[aria-label*='page' i]"
+
+// SetJoinPages turns the merging of continuation pages into the first page
+// on or off for subsequent scrapes; see Result.JoinedPages.
+func (s *Scraper) SetJoinPages(on bool) {
+	s.join.Store(on)
+}
+
+// nextPage returns the continuation of an article split across pages: the
+// rel="next" link, or a pager's next-page link when the page says it is
+// one of several. It returns "" when there is none on base's origin.
+func nextPage(base *url.URL, doc *goquery.Document) string {
+	candidates := []*goquery.Selection{doc.Find("link[rel~='next' i][href], a[rel~='next' i][href]").First()}
+
+	pager := doc.Find(pagerSelector)
+	if m := pageCountPattern.FindStringSubmatch(pager.Text() + " " + pageText(doc)); m != nil {
+		current, _ := strconv.Atoi(m[1])
+		total, _ := strconv.Atoi(m[2])
+		if current < total {
+			next := strconv.Itoa(current + 1)
+			links := doc.Find("a[href]")
+			if pager.Length() > 0 {
+				links = pager.Find("a[href]")
+			}
+			candidates = append(candidates, links.FilterFunction(func(_ int, sel *goquery.Selection) bool {
+				text := strings.TrimSpace(sel.Text())
+				return text == next || nextTextPattern.MatchString(text)
+			}).First())
+		}
+	}
+	candidates = append(candidates, pager.Find("a[href]").FilterFunction(func(_ int, sel *goquery.Selection) bool {
+		return nextTextPattern.MatchString(strings.TrimSpace(sel.Text())) ||
+			nextTextPattern.MatchString(strings.TrimSpace(sel.AttrOr("aria-label", "")))
+	}).First())
+
+	for _, sel := range candidates {
+		href, ok := sel.Attr("href")
+		if !ok {
+			continue
+		}
+		resolved, err := base.Parse(strings.TrimSpace(href))
+		if err != nil || !sameOrigin(base, resolved) {
+			continue
+		}
+		resolved.Fragment = ""
+		if resolved.String() != stripFragment(base) {
+			return resolved.String()
+		}
+	}
+	return ""
+}
+
+// joinPages fetches the pages following result and appends their content,
+// up to maxJoinedPages. It stops quietly at the first page that fails, is
+// not HTML or is disallowed by robots.txt, keeping what was joined so far;
+// result.NextPage is then the first page left out.
+func (s *Scraper) joinPages(ctx context.Context, result *Result) {
+	if !s.join.Load() || result.NextPage == "" {
+		return
+	}
+	seen := map[string]bool{result.SourceURL: true, result.FinalURL: true}
+	for len(result.JoinedPages) < maxJoinedPages {
+		next := result.NextPage
+		if next == "" || seen[next] {
+			result.NextPage = ""
+			return
+		}
+		seen[next] = true
+		target, err := url.Parse(next)
+		if err != nil {
+			return
+		}
+		if robots := s.checkRobots(ctx, target); robots.Checked && !robots.Allowed && s.robotsMode == RobotsEnforce {
+			return
+		}
+		fetched, err := s.fetch(ctx, target)
+		if err != nil {
+			return
+		}
+		contentType := fetched.header.Get("Content-Type")
+		if isPDF(contentType, fetched.body) || textKind(contentType, fetched.finalURL) != "" {
+			return
+		}
+		page := &Result{}
+		if _, err := s.parseHTML(page, fetched.finalURL, fetched.body); err != nil {
+			return
+		}
+		appendPage(result, page)
+		result.JoinedPages = append(result.JoinedPages, next)
+		result.Attempts += fetched.attempts
+		result.NextPage = page.NextPage
+	}
+}
+
+// appendPage adds page's content to result. Footnote labels and formula
+// ids already used by result are renamed, with the markers pointing at them.
+func appendPage(result, page *Result) {
+	labels := make(map[string]string)
+	used := make(map[string]bool)
+	for _, note := range result.Footnotes {
+		used[note.Label] = true
+	}
+	suffix := "-p" + strconv.Itoa(len(result.JoinedPages)+2)
+	for _, note := range page.Footnotes {
+		if used[note.Label] {
+			labels[note.Label] = note.Label + suffix
+			note.Label += suffix
+		}
+		result.Footnotes = append(result.Footnotes, note)
+	}
+
+	ids := make(map[string]string)
+	nextID := 1
+	for _, f := range result.Formulas {
+		if n, err := strconv.Atoi(f.ID); err == nil && n >= nextID {
+			nextID = n + 1
+		}
+	}
+	for _, f := range page.Formulas {
+		ids[f.ID] = strconv.Itoa(nextID)
+		f.ID = ids[f.ID]
+		nextID++
+		result.Formulas = append(result.Formulas, f)
+	}
+
+	offset := len(result.Paragraphs)
+	for _, p := range page.Paragraphs {
+		p = FootnoteRefPattern.ReplaceAllStringFunc(p, func(marker string) string {
+			if label, ok := labels[FootnoteRefPattern.FindStringSubmatch(marker)[1]]; ok {
+				return "[^" + label + "]"
+			}
+			return marker
+		})
+		p = MathRefPattern.ReplaceAllStringFunc(p, func(marker string) string {
+			if id, ok := ids[MathRefPattern.FindStringSubmatch(marker)[1]]; ok {
+				return "[math:" + id + "]"
+			}
+			return marker
+		})
+		result.Paragraphs = append(result.Paragraphs, p)
+	}
+
+	for _, h := range page.Headings {
+		h.Paragraph += offset
+		result.Headings = append(result.Headings, h)
+	}
+	result.Headings = anchorHeadings(result.Headings)
+	result.CodeBlocks = append(result.CodeBlocks, page.CodeBlocks...)
+
+	known := make(map[string]bool, len(result.Links))
+	for _, link := range result.Links {
+		known[link.Href] = true
+	}
+	for _, link := range page.Links {
+		if !known[link.Href] {
+			known[link.Href] = true
+			result.Links = append(result.Links, link)
+		}
+	}
+
+	result.Words += page.Words
+	result.ReadingMinutes = (result.Words + wordsPerMinute - 1) / wordsPerMinute
+}
@@ -0,0 +1,558 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The PDF support below is a small text extractor, not a full parser: it
+// decodes Flate-compressed content streams, follows the text operators, and
+// reads link and title entries. Text in fonts with custom encodings may not
+// come out readable; such lines are dropped.
+
+var (
+	pdfObjectPattern = regexp.MustCompile(`(?s)\d+\s+\d+\s+obj\b(.*?)\bendobj`)
+	pdfStreamPattern = regexp.MustCompile(`(?s)^(.*?)\bstream\r?\n(.*)$`)
+	pdfURIPattern    = regexp.MustCompile(`/URI\s*\(`)
+	pdfTitlePattern  = regexp.MustCompile(`/Title\s*(\(|<)`)
+	pdfInfoPattern   = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+)
+
+// maxPDFStream caps a single decompressed stream.
+const maxPDFStream = 16 * 1024 * 1024
+
+// isPDF reports whether a response is a PDF by content type or magic bytes.
+func isPDF(contentType string, body []byte) bool {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "application/pdf") {
+		return true
+	}
+	return bytes.HasPrefix(body, []byte("%PDF-"))
+}
+
+// pdfLine is a run of text shown on one line at one font size.
+type pdfLine struct {
+	text string
+	size float64
+}
+
+// parsePDF fills result with the title, headings, paragraphs and links found in body.
+func parsePDF(result *Result, body []byte, limits Limits) {
+	var (
+		lines    []pdfLine
+		metadata [][]byte
+	)
+	for _, m := range pdfObjectPattern.FindAllSubmatch(body, -1) {
+		obj := m[1]
+		parts := pdfStreamPattern.FindSubmatch(obj)
+		if parts == nil {
+			metadata = append(metadata, obj)
+			continue
+		}
+		dict, data := parts[1], parts[2]
+		if end := bytes.LastIndex(data, []byte("endstream")); end >= 0 {
+			data = data[:end]
+		}
+		decoded, ok := decodePDFStream(dict, data)
+		if !ok {
+			continue
+		}
+		if bytes.Contains(dict, []byte("/ObjStm")) {
+			metadata = append(metadata, decoded)
+			continue
+		}
+		lines = append(lines, pdfTextLines(decoded)...)
+	}
+	result.Title = pdfTitle(body)
+	result.Headings, result.Paragraphs = pdfStructure(lines, limits)
+	result.Headings = anchorHeadings(normalizeHeadings(result.Headings))
+	if result.Title == "" && len(result.Headings) > 0 {
+		result.Title = result.Headings[0].Text
+	}
+	result.Links = pdfLinks(metadata, limits.Links)
+
+	var text strings.Builder
+	for _, l := range lines {
+		text.WriteString(l.text)
+		text.WriteByte('\n')
+	}
+	measure(result, text.String(), "")
+}
+
+func decodePDFStream(dict, data []byte) ([]byte, bool) {
+	if bytes.Contains(dict, []byte("/Subtype/Image")) || bytes.Contains(dict, []byte("/Subtype /Image")) {
+		return nil, false
+	}
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return data, true
+	}
+	if !bytes.Contains(dict, []byte("/FlateDecode")) {
+		return nil, false
+	}
+
+	var reader io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		reader = zr
+	} else {
+		reader = flate.NewReader(bytes.NewReader(data))
+	}
+	// Truncated streams still yield their readable prefix.
+	decoded, _ := io.ReadAll(io.LimitReader(reader, maxPDFStream))
+	return decoded, len(decoded) > 0
+}
+
+// pdfTextLines runs the text operators of a content stream.
+func pdfTextLines(content []byte) []pdfLine {
+	var (
+		lines   []pdfLine
+		current strings.Builder
+		size    = 12.0
+		scale   = 1.0
+		operand []pdfToken
+	)
+	flush := func() {
+		text := strings.Join(strings.Fields(current.String()), " ")
+		current.Reset()
+		if readableText(text) {
+			lines = append(lines, pdfLine{text: text, size: size * scale})
+		}
+	}
+
+	lex := pdfLexer{data: content}
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		if tok.kind != pdfOperator {
+			operand = append(operand, tok)
+			continue
+		}
+
+		switch tok.text {
+		case "BT", "ET", "T*":
+			flush()
+		case "Tf":
+			if n := len(operand); n >= 1 && operand[n-1].kind == pdfNumber {
+				size = operand[n-1].num
+			}
+		case "Tm":
+			if n := len(operand); n >= 6 {
+				flush()
+				if a := operand[n-6].num; a != 0 {
+					scale = abs(a)
+				}
+			}
+		case "Td", "TD":
+			if n := len(operand); n >= 2 && operand[n-1].num != 0 {
+				flush()
+			}
+		case "Tj":
+			if n := len(operand); n >= 1 {
+				current.WriteString(operand[n-1].text)
+			}
+		case "'", "\"":
+			flush()
+			if n := len(operand); n >= 1 {
+				current.WriteString(operand[n-1].text)
+			}
+		case "TJ":
+			if n := len(operand); n >= 1 {
+				current.WriteString(operand[n-1].text)
+			}
+		}
+		operand = operand[:0]
+	}
+	flush()
+	return lines
+}
+
+// pdfStructure splits lines into headings, by font size relative to the body
+// text, and paragraphs built from consecutive body lines.
+func pdfStructure(lines []pdfLine, limits Limits) ([]Heading, []string) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	body := bodySize(lines)
+	var larger []float64
+	for _, l := range lines {
+		if l.size > body*1.15 {
+			larger = append(larger, l.size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(larger)))
+	levels := map[float64]int{}
+	for _, s := range larger {
+		if _, ok := levels[s]; !ok && len(levels) < 3 {
+			levels[s] = len(levels) + 1
+		}
+	}
+
+	var (
+		headings   []Heading
+		paragraphs []string
+		current    strings.Builder
+	)
+	endParagraph := func() {
+		text := strings.TrimSpace(current.String())
+		current.Reset()
+		if !limits.shortParagraph(text) {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	for _, l := range lines {
+		if l.size > body*1.15 && len(l.text) < 200 {
+			endParagraph()
+			level, ok := levels[l.size]
+			if !ok {
+				level = 3
+			}
+			if level <= limits.HeadingDepth {
+				headings = append(headings, Heading{Level: level, Text: l.text, Paragraph: len(paragraphs)})
+			}
+			continue
+		}
+
+		if current.Len() > 0 {
+			prev := current.String()
+			if strings.HasSuffix(prev, "-") {
+				current.Reset()
+				current.WriteString(strings.TrimSuffix(prev, "-"))
+			} else {
+				current.WriteByte(' ')
+			}
+		}
+		current.WriteString(l.text)
+		if current.Len() > 600 && strings.ContainsAny(l.text[len(l.text)-1:], ".!?:") {
+			endParagraph()
+		}
+	}
+	endParagraph()
+
+	if len(headings) > limits.Headings {
+		headings = headings[:limits.Headings]
+	}
+	if len(paragraphs) > limits.Paragraphs {
+		paragraphs = paragraphs[:limits.Paragraphs]
+	}
+	return headings, paragraphs
+}
+
+// bodySize returns the font size covering the most text.
+func bodySize(lines []pdfLine) float64 {
+	weight := map[float64]int{}
+	for _, l := range lines {
+		weight[l.size] += len(l.text)
+	}
+	best, bestWeight := 12.0, -1
+	for size, w := range weight {
+		if w > bestWeight || (w == bestWeight && size < best) {
+			best, bestWeight = size, w
+		}
+	}
+	return best
+}
+
+// pdfTitle reads /Title from the document information dictionary named in
+// the trailer. Outline entries use /Title too, so other objects are ignored.
+func pdfTitle(body []byte) string {
+	ref := pdfInfoPattern.FindSubmatch(body)
+	if ref == nil {
+		return ""
+	}
+	objPattern, err := regexp.Compile(`(?s)\b` + string(ref[1]) + `\s+` + string(ref[2]) + `\s+obj\b(.*?)\bendobj`)
+	if err != nil {
+		return ""
+	}
+	info := objPattern.FindSubmatch(body)
+	if info == nil {
+		return ""
+	}
+	loc := pdfTitlePattern.FindIndex(info[1])
+	if loc == nil {
+		return ""
+	}
+	lex := pdfLexer{data: info[1], pos: loc[1] - 1}
+	if tok, ok := lex.next(); ok && tok.kind == pdfString {
+		if title := strings.TrimSpace(tok.text); readableText(title) {
+			return title
+		}
+	}
+	return ""
+}
+
+func pdfLinks(chunks [][]byte, limit int) []Link {
+	seen := map[string]struct{}{}
+	var links []Link
+	for _, chunk := range chunks {
+		for _, loc := range pdfURIPattern.FindAllIndex(chunk, -1) {
+			lex := pdfLexer{data: chunk, pos: loc[1] - 1}
+			tok, ok := lex.next()
+			if !ok || tok.kind != pdfString {
+				continue
+			}
+			href := strings.TrimSpace(tok.text)
+			if href == "" {
+				continue
+			}
+			if _, dup := seen[href]; dup {
+				continue
+			}
+			seen[href] = struct{}{}
+			links = append(links, Link{Text: href, Href: href, Class: LinkContent})
+		}
+	}
+	if len(links) > limit {
+		links = links[:limit]
+	}
+	return links
+}
+
+// readableText rejects text that is mostly control or replacement characters,
+// which is what glyph IDs in unmapped fonts decode to.
+func readableText(text string) bool {
+	if text == "" {
+		return false
+	}
+	good, total := 0, 0
+	for _, r := range text {
+		total++
+		if r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)) {
+			good++
+		}
+	}
+	return good*10 >= total*9
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+type pdfTokenKind int
+
+const (
+	pdfOperator pdfTokenKind = iota
+	pdfNumber
+	pdfString
+	pdfOther
+)
+
+type pdfToken struct {
+	kind pdfTokenKind
+	text string
+	num  float64
+}
+
+// pdfLexer tokenises PDF content streams and dictionaries.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *pdfLexer) next() (pdfToken, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return pdfToken{}, false
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return pdfToken{kind: pdfString, text: decodePDFText(l.literal())}, true
+	case c == '<' && l.peek(1) == '<', c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return pdfToken{kind: pdfOther}, true
+	case c == '<':
+		return pdfToken{kind: pdfString, text: decodePDFText(l.hex())}, true
+	case c == '[':
+		return pdfToken{kind: pdfString, text: l.array()}, true
+	case c == '/':
+		start := l.pos
+		l.pos++
+		for l.pos < len(l.data) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfToken{kind: pdfOther, text: string(l.data[start:l.pos])}, true
+	case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return pdfToken{kind: pdfOther}, true
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if n, err := strconv.ParseFloat(word, 64); err == nil {
+		return pdfToken{kind: pdfNumber, text: word, num: n}, true
+	}
+	return pdfToken{kind: pdfOperator, text: word}, true
+}
+
+func (l *pdfLexer) peek(offset int) byte {
+	if l.pos+offset < len(l.data) {
+		return l.data[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != '\f' && c != 0 {
+			return
+		}
+		l.pos++
+	}
+}
+
+// literal reads a (string) with nested parentheses and escapes.
+func (l *pdfLexer) literal() []byte {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation.
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// hex reads a <hex string>.
+func (l *pdfLexer) hex() []byte {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; isHexDigit(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
+
+// array reads a TJ array, joining its strings and turning wide negative
+// kerning into word spaces.
+func (l *pdfLexer) array() string {
+	l.pos++ // [
+	var out strings.Builder
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return out.String()
+		}
+		if l.data[l.pos] == ']' {
+			l.pos++
+			return out.String()
+		}
+		tok, ok := l.next()
+		if !ok {
+			return out.String()
+		}
+		switch tok.kind {
+		case pdfString:
+			out.WriteString(tok.text)
+		case pdfNumber:
+			if tok.num < -200 {
+				out.WriteByte(' ')
+			}
+		}
+	}
+}
+
+// decodePDFText decodes UTF-16BE strings marked with a byte order mark and
+// treats everything else as Latin-1.
+func decodePDFText(raw []byte) string {
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
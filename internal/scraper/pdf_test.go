@@ -0,0 +1,192 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pdfContent is a page content stream with a title, two section headings
+// and body text shown with Tj, TJ and '.
+const pdfContent = `BT
+/F1 24 Tf 72 720 Td (Annual Report) Tj
+ET
+BT
+/F1 12 Tf 72 690 Td
+(The first paragraph is shown with Tj and runs long enough to be kept.) Tj
+0 -14 Td
+[(Ker) -50 (ned) -400 (text) -400 (joins) 120 ( across ) (TJ arrays into one line.)] TJ
+ET
+BT
+/F1 18 Tf 72 640 Td (Results) Tj
+ET
+BT
+/F1 12 Tf 72 620 Td 14 TL
+(Revenue grew in every quarter of the year, which the tables below show.) Tj
+(Costs fell) '
+ET
+BT
+1 0 0 1 72 560 Tm /F1 18 Tf (Outlook) Tj
+ET
+BT
+/F1 12 Tf 72 540 Td (Next year is expected to be \(mostly\) like this one, only larger.) Tj
+ET
+`
+
+// buildPDF assembles a PDF with the given page content, compressed when
+// flate is set, and an information dictionary titled title.
+func buildPDF(content string, flate bool, title string) []byte {
+	var stream []byte
+	dict := ""
+	if flate {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		zw.Write([]byte(content))
+		zw.Close()
+		stream = buf.Bytes()
+		dict = "/Filter /FlateDecode "
+	} else {
+		stream = []byte(content)
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	pdf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R /Outlines 7 0 R >>\nendobj\n")
+	pdf.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	pdf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Annots [5 0 R] >>\nendobj\n")
+	fmt.Fprintf(&pdf, "4 0 obj\n<< %s/Length %d >>\nstream\n", dict, len(stream))
+	pdf.Write(stream)
+	pdf.WriteString("\nendstream\nendobj\n")
+	pdf.WriteString("5 0 obj\n<< /Type /Annot /Subtype /Link /A << /S /URI /URI (https://example.com/report) >> >>\nendobj\n")
+	fmt.Fprintf(&pdf, "6 0 obj\n<< /Title (%s) /Producer (test) >>\nendobj\n", title)
+	pdf.WriteString("7 0 obj\n<< /Title (Outline entry) >>\nendobj\n")
+	pdf.WriteString("trailer\n<< /Root 1 0 R /Info 6 0 R >>\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func testLimits() Limits {
+	return Limits{}.withDefaults(defaultMaxItems)
+}
+
+func TestPDF_Parse(t *testing.T) {
+	for _, flate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("flate=%v", flate), func(t *testing.T) {
+			result := &Result{}
+			parsePDF(result, buildPDF(pdfContent, flate, "Report 2025"), testLimits())
+
+			if result.Title != "Report 2025" {
+				t.Errorf("Title = %q, want the /Info title", result.Title)
+			}
+
+			var headings []string
+			for _, h := range result.Headings {
+				headings = append(headings, fmt.Sprintf("h%d %s", h.Level, h.Text))
+			}
+			if want := []string{"h1 Annual Report", "h2 Results", "h2 Outlook"}; !slices.Equal(headings, want) {
+				t.Errorf("Headings = %q, want %q", headings, want)
+			}
+
+			text := strings.Join(result.Paragraphs, "\n")
+			for _, want := range []string{
+				"The first paragraph is shown with Tj",
+				"Kerned text joins across TJ arrays into one line.",
+				"Revenue grew in every quarter",
+				"Costs fell",
+				"(mostly)",
+			} {
+				if !strings.Contains(text, want) {
+					t.Errorf("paragraphs lack %q:\n%s", want, text)
+				}
+			}
+
+			if len(result.Links) != 1 || result.Links[0].Href != "https://example.com/report" {
+				t.Errorf("Links = %+v, want the /URI annotation", result.Links)
+			}
+		})
+	}
+}
+
+func TestPDF_TitleFallsBackToHeading(t *testing.T) {
+	body := bytes.Replace(buildPDF(pdfContent, true, ""), []byte("/Info 6 0 R"), nil, 1)
+	result := &Result{}
+	parsePDF(result, body, testLimits())
+	if result.Title != "Annual Report" {
+		t.Errorf("Title = %q, want the first heading, not an outline entry", result.Title)
+	}
+}
+
+func TestPDF_HeadingDepth(t *testing.T) {
+	limits := testLimits()
+	limits.HeadingDepth = 1
+	result := &Result{}
+	parsePDF(result, buildPDF(pdfContent, true, "t"), limits)
+	if len(result.Headings) != 1 || result.Headings[0].Text != "Annual Report" {
+		t.Errorf("Headings = %+v, want only the h1", result.Headings)
+	}
+}
+
+func TestPDF_DamagedStreams(t *testing.T) {
+	good := buildPDF(pdfContent, true, "t")
+	start := bytes.Index(good, []byte("stream\n")) + len("stream\n")
+	end := bytes.Index(good, []byte("\nendstream"))
+
+	tests := []struct {
+		name string
+		body []byte
+		// prefix is text a readable prefix of the stream should still give.
+		prefix string
+	}{
+		{"truncated stream", slices.Concat(good[:start], good[start:start+(end-start)*2/3], good[end:]), "Annual Report"},
+		{"truncated file", good[:start+(end-start)/2], ""},
+		{"corrupt stream", slices.Concat(good[:start], bytes.Repeat([]byte{0xff}, end-start), good[end:]), ""},
+		{"flate without zlib header", slices.Concat(good[:start], []byte{0x01, 0x02, 0x03}, good[end:]), ""},
+		{"unterminated string", buildPDF("BT /F1 12 Tf (never closed Tj ET", false, "t"), ""},
+		{"unterminated array", buildPDF("BT /F1 12 Tf [(a) -300 (b", false, "t"), ""},
+		{"unterminated hex", buildPDF("BT /F1 12 Tf <48656c6c6f Tj", false, "t"), ""},
+		{"operators without operands", buildPDF("BT Tf Tm Td TJ Tj ' \" ET", false, "t"), ""},
+		{"no objects", []byte("%PDF-1.7\n"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{}
+			parsePDF(result, tt.body, testLimits())
+			if tt.prefix != "" && (len(result.Headings) == 0 || result.Headings[0].Text != tt.prefix) {
+				t.Errorf("Headings = %+v, want %q from the readable prefix", result.Headings, tt.prefix)
+			}
+		})
+	}
+}
+
+func TestPDF_DecodeStream(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte("BT (x) Tj ET"))
+	zw.Close()
+
+	tests := []struct {
+		name   string
+		dict   string
+		data   []byte
+		want   string
+		wantOK bool
+	}{
+		{"unfiltered", "/Length 12", []byte("BT (x) Tj ET"), "BT (x) Tj ET", true},
+		{"flate", "/Filter /FlateDecode", buf.Bytes(), "BT (x) Tj ET", true},
+		{"flate in an array", "/Filter [/FlateDecode]", buf.Bytes(), "BT (x) Tj ET", true},
+		{"image", "/Subtype /Image /Filter /FlateDecode", buf.Bytes(), "", false},
+		{"other filter", "/Filter /DCTDecode", []byte{0xff, 0xd8}, "", false},
+		{"empty flate", "/Filter /FlateDecode", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := decodePDFStream([]byte(tt.dict), tt.data)
+			if string(got) != tt.want || ok != tt.wantOK {
+				t.Errorf("decodePDFStream = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimiter is a per-host token bucket with an additional minimum delay
+// between consecutive requests to the same host.
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	minDelay time.Duration
+	hosts    map[string]*hostBucket
+}
+
+type hostBucket struct {
+	tokens float64
+	filled time.Time
+	next   time.Time
+}
+
+func newHostLimiter(requestsPerMinute, burst int, minDelay time.Duration) *hostLimiter {
+	if requestsPerMinute <= 0 && minDelay <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+
+	var interval time.Duration
+	if requestsPerMinute > 0 {
+		interval = time.Minute / time.Duration(requestsPerMinute)
+	}
+
+	return &hostLimiter{
+		interval: interval,
+		burst:    float64(burst),
+		minDelay: minDelay,
+		hosts:    make(map[string]*hostBucket),
+	}
+}
+
+// Wait blocks until a request to host is permitted or ctx is done.
+func (l *hostLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve(strings.ToLower(host), time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve claims the next request slot for host and returns how long the caller must wait for it.
+func (l *hostLimiter) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.hosts[host]
+	if !ok {
+		b = &hostBucket{tokens: l.burst, filled: now}
+		l.hosts[host] = b
+	}
+
+	slot := now
+	if b.next.After(slot) {
+		slot = b.next
+	}
+
+	if l.interval > 0 {
+		b.tokens += float64(now.Sub(b.filled)) / float64(l.interval)
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.filled = now
+
+		b.tokens--
+		if b.tokens < 0 {
+			ready := now.Add(time.Duration(-b.tokens * float64(l.interval)))
+			if ready.After(slot) {
+				slot = ready
+			}
+		}
+	}
+
+	b.next = slot.Add(l.minDelay)
+	return slot.Sub(now)
+}
@@ -0,0 +1,181 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+const defaultMaxRedirects = 10
+
+// ErrCrossOriginRedirect is wrapped by RedirectError when SameOriginRedirects refuses a hop.
+var ErrCrossOriginRedirect = errors.New("cross-origin redirect blocked")
+
+// ErrTooManyRedirects is wrapped by RedirectError when MaxRedirects is exceeded.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// ErrRedirectDeclined is wrapped by RedirectError when a RedirectApprover
+// refuses a redirect to another site.
+var ErrRedirectDeclined = errors.New("redirect to another site declined")
+
+// RedirectApprover decides whether a fetch may follow a redirect to another
+// site. chain lists the URLs visited so far, starting with the one
+// requested, and to is where the next hop leads. It is called from the
+// fetching goroutine and may block until the user answers; it should
+// return false once ctx is done.
+type RedirectApprover func(ctx context.Context, chain []string, to string) bool
+
+type redirectApproverKey struct{}
+
+// WithRedirectApprover returns a context whose fetches ask fn before
+// following a redirect, HTTP or client-side, to another site. Hops within a
+// site, such as example.com to www.example.com, are not asked about.
+func WithRedirectApprover(ctx context.Context, fn RedirectApprover) context.Context {
+	return context.WithValue(ctx, redirectApproverKey{}, fn)
+}
+
+func redirectApprover(ctx context.Context) RedirectApprover {
+	fn, _ := ctx.Value(redirectApproverKey{}).(RedirectApprover)
+	return fn
+}
+
+// RedirectError reports a redirect the scraper refused to follow.
+type RedirectError struct {
+	From string
+	To   string
+	Err  error
+}
+
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("%v: %s -> %s", e.Err, e.From, e.To)
+}
+
+func (e *RedirectError) Unwrap() error {
+	return e.Err
+}
+
+type redirectChain struct {
+	urls []string
+}
+
+type redirectChainKey struct{}
+
+func withRedirectChain(ctx context.Context, chain *redirectChain) context.Context {
+	return context.WithValue(ctx, redirectChainKey{}, chain)
+}
+
+type redirectPolicy struct {
+	max        atomic.Int64
+	sameOrigin atomic.Bool
+	next       func(*http.Request, []*http.Request) error
+}
+
+func newRedirectPolicy(cfg Config, next func(*http.Request, []*http.Request) error) *redirectPolicy {
+	policy := &redirectPolicy{next: next}
+	policy.set(cfg.MaxRedirects, cfg.SameOriginRedirects)
+	return policy
+}
+
+// set applies a redirect limit, where zero uses defaultMaxRedirects and a
+// negative value refuses all redirects, and the same-origin rule.
+func (p *redirectPolicy) set(limit int, sameOrigin bool) {
+	if limit == 0 {
+		limit = defaultMaxRedirects
+	}
+	p.max.Store(int64(max(limit, 0)))
+	p.sameOrigin.Store(sameOrigin)
+}
+
+// limit returns the number of redirects a fetch may follow.
+func (p *redirectPolicy) limit() int {
+	return int(p.max.Load())
+}
+
+// SetRedirects changes the redirect limit and the same-origin rule for
+// subsequent fetches, as Config.MaxRedirects and Config.SameOriginRedirects.
+func (s *Scraper) SetRedirects(limit int, sameOrigin bool) {
+	s.redirects.set(limit, sameOrigin)
+}
+
+// check is installed as the client's CheckRedirect. It enforces the limits,
+// asks the context's RedirectApprover about hops to another site and
+// records each hop in the request's redirect chain.
+func (p *redirectPolicy) check(req *http.Request, via []*http.Request) error {
+	prev := via[len(via)-1]
+	if len(via) > p.limit() {
+		return &RedirectError{From: prev.URL.String(), To: req.URL.String(), Err: ErrTooManyRedirects}
+	}
+	if p.sameOrigin.Load() && !sameOrigin(prev.URL, req.URL) {
+		return &RedirectError{From: prev.URL.String(), To: req.URL.String(), Err: ErrCrossOriginRedirect}
+	}
+	if approve := redirectApprover(req.Context()); approve != nil && !sameSite(prev.URL, req.URL) {
+		chain := make([]string, len(via))
+		for i, hop := range via {
+			chain[i] = hop.URL.String()
+		}
+		if !approve(req.Context(), chain, req.URL.String()) {
+			return &RedirectError{From: prev.URL.String(), To: req.URL.String(), Err: ErrRedirectDeclined}
+		}
+	}
+	if p.next != nil {
+		if err := p.next(req, via); err != nil {
+			return err
+		}
+	}
+
+	if chain, ok := req.Context().Value(redirectChainKey{}).(*redirectChain); ok {
+		chain.urls = append(chain.urls, prev.URL.String())
+	}
+	return nil
+}
+
+// sameOrigin reports whether to has the origin of from. An upgrade from http
+// to https on the same host and the default ports, 80 to 443, counts as the
+// same origin.
+func sameOrigin(from, to *url.URL) bool {
+	if !strings.EqualFold(from.Hostname(), to.Hostname()) {
+		return false
+	}
+	if from.Scheme == "http" && to.Scheme == "https" {
+		return effectivePort(from) == "80" && effectivePort(to) == "443"
+	}
+	return from.Scheme == to.Scheme && effectivePort(from) == effectivePort(to)
+}
+
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// sameSite reports whether two URLs belong to the same site: the same
+// registrable domain, such as example.com and docs.example.com. Two-letter
+// country domains with a generic second level, such as co.uk, keep three
+// labels.
+func sameSite(a, b *url.URL) bool {
+	return strings.EqualFold(registrableDomain(a.Hostname()), registrableDomain(b.Hostname()))
+}
+
+func registrableDomain(host string) string {
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(labels) <= 2 || net.ParseIP(host) != nil {
+		return host
+	}
+	keep := 2
+	if len(labels[len(labels)-1]) == 2 {
+		switch labels[len(labels)-2] {
+		case "co", "com", "net", "org", "gov", "ac", "edu", "ne", "or", "go":
+			keep = 3
+		}
+	}
+	return strings.Join(labels[len(labels)-keep:], ".")
+}
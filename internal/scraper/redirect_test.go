@@ -0,0 +1,30 @@
+package scraper
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRedirect_SameOrigin(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"https://example.com/a", "https://example.com/b", true},
+		{"https://example.com/a", "https://EXAMPLE.com:443/b", true},
+		{"http://example.com/a", "https://example.com/a", true},
+		{"http://example.com:80/a", "https://example.com:443/a", true},
+		{"http://example.com:8080/a", "https://example.com/a", false},
+		{"http://example.com/a", "https://example.com:8443/a", false},
+		{"https://example.com/a", "http://example.com/a", false},
+		{"https://example.com/a", "https://example.com:8443/a", false},
+		{"https://example.com/a", "https://www.example.com/a", false},
+	}
+	for _, tt := range tests {
+		from, _ := url.Parse(tt.from)
+		to, _ := url.Parse(tt.to)
+		if got := sameOrigin(from, to); got != tt.want {
+			t.Errorf("sameOrigin(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxRefreshDelay is the longest meta refresh treated as a redirect; longer
+// delays are pages that reload themselves.
+const maxRefreshDelay = 5
+
+// refreshPattern splits a refresh value such as "0; url=/next" into its
+// delay and target.
+var refreshPattern = regexp.MustCompile(`(?i)^\s*(\d+)(?:\.\d*)?\s*(?:[;,]\s*(?:url\s*=\s*)?(.*))?$`)
+
+// scriptRedirectPattern matches a script that opens by assigning a string
+// literal to location or passing one to location.replace or location.assign.
+var scriptRedirectPattern = regexp.MustCompile(`^\s*(?:(?:window|document|top|self)\.)?location(?:\.href\s*=\s*|\s*=\s*|\.(?:replace|assign)\(\s*)["']([^"'\s]+)["']`)
+
+// followClientRedirects follows meta refresh and script redirects from
+// fetched until a page stays put, under the same hop limit, origin and
+// robots.txt rules as HTTP redirects. Every hop is added to the redirects
+// of the document it returns.
+func (s *Scraper) followClientRedirects(ctx context.Context, fetched *document) (*document, error) {
+	for {
+		contentType := fetched.header.Get("Content-Type")
+		if isPDF(contentType, fetched.body) || textKind(contentType, fetched.finalURL) != "" {
+			return fetched, nil
+		}
+		from := fetched.finalURL
+		target := clientRedirect(from, fetched.body)
+		if target == nil || stripFragment(target) == stripFragment(from) {
+			return fetched, nil
+		}
+
+		if len(fetched.redirects) >= s.redirects.limit() {
+			return nil, &RedirectError{From: from.String(), To: target.String(), Err: ErrTooManyRedirects}
+		}
+		if s.redirects.sameOrigin.Load() && !sameOrigin(from, target) {
+			return nil, &RedirectError{From: from.String(), To: target.String(), Err: ErrCrossOriginRedirect}
+		}
+		if approve := redirectApprover(ctx); approve != nil && !sameSite(from, target) {
+			if !approve(ctx, append(append([]string(nil), fetched.redirects...), from.String()), target.String()) {
+				return nil, &RedirectError{From: from.String(), To: target.String(), Err: ErrRedirectDeclined}
+			}
+		}
+		if robots := s.checkRobots(ctx, target); robots.Checked && !robots.Allowed && s.robotsMode == RobotsEnforce {
+			return nil, fmt.Errorf("%w (%s)", ErrDisallowedByRobots, robots.Rule)
+		}
+
+		next, err := s.fetch(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		next.redirects = append(append(fetched.redirects, from.String()), next.redirects...)
+		next.attempts += fetched.attempts
+		fetched = next
+	}
+}
+
+// clientRedirect returns the page an HTML document sends the browser to
+// straight away: a meta refresh of at most maxRefreshDelay seconds, or, on
+// a page with almost no text of its own, an inline script that starts by
+// assigning location. It returns nil when there is no such http(s) target.
+func clientRedirect(base *url.URL, body []byte) *url.URL {
+	lower := bytes.ToLower(body)
+	if !bytes.Contains(lower, []byte("refresh")) && !bytes.Contains(lower, []byte("location")) {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var target string
+	doc.Find("meta[http-equiv='refresh' i][content]").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		m := refreshPattern.FindStringSubmatch(sel.AttrOr("content", ""))
+		if m == nil {
+			return true
+		}
+		delay, err := strconv.Atoi(m[1])
+		if err != nil || delay > maxRefreshDelay {
+			return true
+		}
+		target = strings.Trim(strings.TrimSpace(m[2]), `'"`)
+		return target == ""
+	})
+
+	if target == "" && len(strings.Join(strings.Fields(pageText(doc)), " ")) < shellTextThreshold {
+		doc.Find("script:not([src])").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+			if m := scriptRedirectPattern.FindStringSubmatch(sel.Text()); m != nil {
+				target = m[1]
+			}
+			return target == ""
+		})
+	}
+	if target == "" {
+		return nil
+	}
+
+	resolved, err := base.Parse(target)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return nil
+	}
+	return resolved
+}
@@ -0,0 +1,70 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// Related scrapes up to limit same-site pages linked from result concurrently.
+// Content links are followed first. Pages that fail to load are skipped; the
+// rest keep the order in which their links were chosen.
+func (s *Scraper) Related(ctx context.Context, result *Result, limit int) []*Result {
+	targets := internalLinks(result, limit)
+	if len(targets) == 0 {
+		return nil
+	}
+
+	pages, _ := s.ScrapeAll(ctx, targets, len(targets))
+
+	out := pages[:0]
+	for _, page := range pages {
+		if page != nil {
+			out = append(out, page)
+		}
+	}
+	return out
+}
+
+// internalLinks returns up to limit distinct http(s) links on the same host as
+// result, content links first, ignoring footer links and links back to the
+// page itself.
+func internalLinks(result *Result, limit int) []string {
+	base, err := url.Parse(result.FinalURL)
+	if err != nil || limit <= 0 {
+		return nil
+	}
+
+	seen := map[string]struct{}{stripFragment(base): {}}
+	var links []string
+	candidates := append([]Link(nil), result.Links...)
+	rankLinks(candidates)
+	for _, link := range candidates {
+		if link.Class == LinkFooter || link.Class == LinkAnchor {
+			continue
+		}
+		parsed, err := url.Parse(link.Href)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			continue
+		}
+		if !strings.EqualFold(parsed.Hostname(), base.Hostname()) {
+			continue
+		}
+		key := stripFragment(parsed)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		links = append(links, key)
+		if len(links) == limit {
+			break
+		}
+	}
+	return links
+}
+
+func stripFragment(u *url.URL) string {
+	clone := *u
+	clone.Fragment = ""
+	return clone.String()
+}
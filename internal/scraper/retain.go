@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// unsafeContentSelector matches elements dropped from Result.ContentHTML:
+// active content, embedded documents and form controls.
+const unsafeContentSelector = "script, style, noscript, template, iframe, frame, frameset, object, embed, " +
+	"applet, link, meta, base, form, input, button, select, textarea"
+
+// urlAttributes hold URLs that are resolved against the page and kept only
+// when they use a safe scheme.
+var urlAttributes = map[string]bool{"href": true, "src": true, "poster": true, "cite": true}
+
+// contentHTML returns the main content of doc, without page chrome, as a
+// sanitised HTML fragment: scripts, styles, embeds and forms are removed,
+// as are event handler and style attributes, and links and image sources
+// are made absolute. doc itself is left as is.
+func contentHTML(base *url.URL, doc *goquery.Document) string {
+	clean := withoutBoilerplate(doc)
+	root := clean.Find(contentSelector).First()
+	if root.Length() == 0 {
+		root = clean.Find("body")
+	}
+	root.Find(unsafeContentSelector).Remove()
+
+	root.Find("*").AddSelection(root).Each(func(_ int, sel *goquery.Selection) {
+		node := sel.Nodes[0]
+		kept := node.Attr[:0]
+		for _, attr := range node.Attr {
+			key := strings.ToLower(attr.Key)
+			switch {
+			case strings.HasPrefix(key, "on"), key == "style", key == "srcset", key == "srcdoc", key == "formaction":
+				continue
+			case urlAttributes[key]:
+				resolved, ok := safeURL(base, attr.Val)
+				if !ok {
+					continue
+				}
+				attr.Val = resolved
+			}
+			kept = append(kept, attr)
+		}
+		node.Attr = kept
+	})
+
+	html, err := root.Html()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(html)
+}
+
+// safeURL resolves ref against base and reports whether it is an http(s) or
+// mailto URL or a fragment of the page.
+func safeURL(base *url.URL, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, "#") {
+		return ref, true
+	}
+	resolved, err := base.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+	switch resolved.Scheme {
+	case "http", "https", "mailto":
+		return resolved.String(), true
+	}
+	return "", false
+}
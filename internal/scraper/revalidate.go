@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"chimera/internal/retry"
+)
+
+// ErrNoValidators is returned by Revalidate for a page that was served
+// without an ETag or Last-Modified header, so the server cannot be asked
+// whether it changed.
+var ErrNoValidators = errors.New("the page was served without an ETag or Last-Modified date")
+
+// Validators are the response headers that identify a version of a page.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Empty reports that the server sent no validators.
+func (v Validators) Empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+func validatorsOf(header http.Header) Validators {
+	return Validators{
+		ETag:         strings.TrimSpace(header.Get("ETag")),
+		LastModified: strings.TrimSpace(header.Get("Last-Modified")),
+	}
+}
+
+// Revalidate asks the server whether target changed since the version
+// known, with a conditional GET carrying If-None-Match and
+// If-Modified-Since. It reports no change on 304 Not Modified, or when a
+// server that ignores the conditions answers with the same ETag, and
+// returns the validators of the version the server now has. The body is
+// not read, so a changed page must be scraped again to be shown.
+func (s *Scraper) Revalidate(ctx context.Context, target string, known Validators) (changed bool, current Validators, err error) {
+	if known.Empty() {
+		return false, Validators{}, ErrNoValidators
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || !parsed.IsAbs() {
+		return false, Validators{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if err := s.limiter.Wait(ctx, parsed.Host); err != nil {
+		return false, Validators{}, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return false, Validators{}, fmt.Errorf("build request: %w", err)
+	}
+	s.setHeaders(req)
+	if known.ETag != "" {
+		req.Header.Set("If-None-Match", known.ETag)
+	}
+	if known.LastModified != "" {
+		req.Header.Set("If-Modified-Since", known.LastModified)
+	}
+
+	resp, err := s.send(req)
+	if err != nil {
+		return false, Validators{}, fmt.Errorf("revalidate document: %w", err)
+	}
+	// Only the status and headers matter; closing early drops the connection.
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		current = validatorsOf(resp.Header)
+		if current.Empty() {
+			current = known
+		}
+		return false, current, nil
+	case resp.StatusCode >= 400:
+		return false, Validators{}, &StatusError{Code: resp.StatusCode, RetryAfter: retry.ParseAfter(resp.Header.Get("Retry-After"))}
+	}
+	current = validatorsOf(resp.Header)
+	if current.ETag != "" && current.ETag == known.ETag {
+		return false, current, nil
+	}
+	return true, current, nil
+}
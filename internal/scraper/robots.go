@@ -0,0 +1,265 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RobotsMode selects how robots.txt rules are applied.
+type RobotsMode int
+
+const (
+	// RobotsIgnore skips robots.txt entirely.
+	RobotsIgnore RobotsMode = iota
+	// RobotsWarn fetches robots.txt and reports disallowed paths without refusing them.
+	RobotsWarn
+	// RobotsEnforce refuses to fetch disallowed paths.
+	RobotsEnforce
+)
+
+// ParseRobotsMode maps "warn"/"enforce" (case-insensitive) to a mode; anything else ignores robots.txt.
+func ParseRobotsMode(value string) RobotsMode {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "warn":
+		return RobotsWarn
+	case "enforce", "on", "1":
+		return RobotsEnforce
+	default:
+		return RobotsIgnore
+	}
+}
+
+// ErrDisallowedByRobots is returned in RobotsEnforce mode for paths robots.txt disallows.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// RobotsDecision records how robots.txt applied to a fetch.
+type RobotsDecision struct {
+	Checked bool `json:"checked"`
+	Allowed bool `json:"allowed"`
+	// Rule is the matching directive, e.g. "Disallow: /private". Empty when no rule matched.
+	Rule string `json:"rule,omitempty"`
+}
+
+const (
+	robotsAgent    = "chimerascraper"
+	robotsTTL      = 24 * time.Hour
+	robotsErrorTTL = time.Hour
+)
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+type robotsEntry struct {
+	rules   []robotsRule
+	expires time.Time
+}
+
+type robotsCache struct {
+	mu    sync.Mutex
+	hosts map[string]robotsEntry
+}
+
+func (s *Scraper) checkRobots(ctx context.Context, target *url.URL) RobotsDecision {
+	if s.robotsMode == RobotsIgnore {
+		return RobotsDecision{}
+	}
+
+	rules := s.robotsRules(ctx, target)
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+
+	return robotsDecide(rules, path)
+}
+
+// robotsDecide applies the longest pattern among rules matching path; an
+// Allow wins a tie with a Disallow of the same length.
+func robotsDecide(rules []robotsRule, path string) RobotsDecision {
+	decision := RobotsDecision{Checked: true, Allowed: true}
+	best := -1
+	for _, rule := range rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		length := len(rule.pattern)
+		if length > best || (length == best && rule.allow) {
+			best = length
+			decision.Allowed = rule.allow
+			if rule.allow {
+				decision.Rule = "Allow: " + rule.pattern
+			} else {
+				decision.Rule = "Disallow: " + rule.pattern
+			}
+		}
+	}
+
+	return decision
+}
+
+func (s *Scraper) robotsRules(ctx context.Context, target *url.URL) []robotsRule {
+	key := target.Scheme + "://" + strings.ToLower(target.Host)
+	now := time.Now()
+
+	s.robots.mu.Lock()
+	entry, ok := s.robots.hosts[key]
+	s.robots.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.rules
+	}
+
+	rules, err := s.fetchRobots(ctx, key+"/robots.txt", target.Host)
+	ttl := robotsTTL
+	if err != nil {
+		// An unreachable robots.txt is treated as allowing everything.
+		rules, ttl = nil, robotsErrorTTL
+	}
+
+	s.robots.mu.Lock()
+	s.robots.hosts[key] = robotsEntry{rules: rules, expires: now.Add(ttl)}
+	s.robots.mu.Unlock()
+
+	return rules
+}
+
+func (s *Scraper) fetchRobots(ctx context.Context, robotsURL, host string) ([]robotsRule, error) {
+	if err := s.limiter.Wait(ctx, host); err != nil {
+		return nil, err
+	}
+
+	// robots.txt often redirects to a CDN; that is no reason to ask the user.
+	req, err := http.NewRequestWithContext(WithRedirectApprover(ctx, nil), http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.setHeaders(req)
+	req.Header.Set("Accept", "text/plain")
+
+	resp, err := s.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("robots.txt status %d", resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, nil
+	}
+
+	return parseRobots(io.LimitReader(resp.Body, 512*1024), robotsAgent), nil
+}
+
+// parseRobots returns the rules of the group whose user-agent is the longest
+// name contained in agent, falling back to the "*" group. Groups naming
+// the same agent are combined.
+func parseRobots(r io.Reader, agent string) []robotsRule {
+	var (
+		specific, wildcard []robotsRule
+		bestMatch          int
+		groupAgents        []string
+		groupRules         []robotsRule
+		inRules            bool
+	)
+
+	endGroup := func() {
+		match, isWildcard := 0, false
+		for _, ua := range groupAgents {
+			switch {
+			case ua == "*":
+				isWildcard = true
+			case strings.Contains(agent, ua):
+				match = max(match, len(ua))
+			}
+		}
+		switch {
+		case match > bestMatch:
+			specific, bestMatch = append([]robotsRule(nil), groupRules...), match
+		case match > 0 && match == bestMatch:
+			specific = append(specific, groupRules...)
+		}
+		if isWildcard {
+			wildcard = append(wildcard, groupRules...)
+		}
+		groupAgents, groupRules, inRules = nil, nil, false
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				endGroup()
+			}
+			if value != "" {
+				groupAgents = append(groupAgents, strings.ToLower(value))
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			groupRules = append(groupRules, robotsRule{allow: key == "allow", pattern: value})
+		}
+	}
+	endGroup()
+
+	if bestMatch > 0 {
+		return specific
+	}
+	return wildcard
+}
+
+// robotsMatch reports whether path matches a robots.txt pattern supporting '*' and a trailing '$'.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	if len(parts) == 1 {
+		return !anchored || pos == len(path)
+	}
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	if anchored {
+		// The last literal must end the path, after what matched before it.
+		return strings.HasSuffix(path, last) && len(path)-len(last) >= pos
+	}
+	return strings.Contains(path[pos:], last)
+}
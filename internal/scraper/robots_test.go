@@ -0,0 +1,123 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRobots_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/private", "/private/page", true},
+		{"/private", "/public", false},
+		{"/", "/anything", true},
+		{"/*.pdf", "/docs/report.pdf", true},
+		{"/*.pdf", "/docs/report.pdf?download=1", true},
+		{"/*.pdf$", "/docs/report.pdf?download=1", false},
+		{"/*.pdf$", "/docs/report.pdf", true},
+		{"/foo$", "/foo", true},
+		{"/foo$", "/foo/foo", false},
+		{"/foo$", "/foobar", false},
+		{"/a*b*c", "/a-x-b-y-c-z", true},
+		{"/a*b*c", "/a-x-c-y-b", false},
+		{"/a*b$", "/ab", true},
+		{"/a*ab$", "/ab", false},
+		{"/*$", "/anything", true},
+		{"/search?q=*", "/search?q=go", true},
+	}
+	for _, tt := range tests {
+		if got := robotsMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("robotsMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRobots_Decide(t *testing.T) {
+	rules := []robotsRule{
+		{allow: false, pattern: "/docs"},
+		{allow: true, pattern: "/docs/public"},
+		{allow: false, pattern: "/page"},
+		{allow: true, pattern: "/page"},
+		{allow: false, pattern: "/*.zip$"},
+	}
+	tests := []struct {
+		path    string
+		allowed bool
+		rule    string
+	}{
+		{"/docs/secret", false, "Disallow: /docs"},
+		{"/docs/public/a", true, "Allow: /docs/public"},
+		{"/page", true, "Allow: /page"},
+		{"/files/a.zip", false, "Disallow: /*.zip$"},
+		{"/files/a.zip.txt", true, ""},
+		{"/other", true, ""},
+	}
+	for _, tt := range tests {
+		got := robotsDecide(rules, tt.path)
+		if got.Allowed != tt.allowed || got.Rule != tt.rule {
+			t.Errorf("robotsDecide(%q) = %+v, want allowed %v by %q", tt.path, got, tt.allowed, tt.rule)
+		}
+	}
+}
+
+func TestRobots_Parse(t *testing.T) {
+	tests := []struct {
+		name string
+		txt  string
+		want []string
+	}{
+		{
+			name: "empty agent is ignored",
+			txt:  "User-agent:\nDisallow: /\n\nUser-agent: *\nDisallow: /tmp\n",
+			want: []string{"D /tmp"},
+		},
+		{
+			name: "specific group wins over wildcard",
+			txt:  "User-agent: *\nDisallow: /\n\nUser-agent: ChimeraScraper\nDisallow: /private\n",
+			want: []string{"D /private"},
+		},
+		{
+			name: "longest matching agent wins",
+			txt:  "User-agent: chimera\nDisallow: /a\n\nUser-agent: chimerascraper\nDisallow: /b\n",
+			want: []string{"D /b"},
+		},
+		{
+			name: "groups naming the same agent combine",
+			txt:  "User-agent: chimerascraper\nDisallow: /a\n\nUser-agent: chimerascraper\nAllow: /b\n",
+			want: []string{"D /a", "A /b"},
+		},
+		{
+			name: "agents listed together share rules",
+			txt:  "User-agent: googlebot\nUser-agent: chimerascraper\nDisallow: /shared\n",
+			want: []string{"D /shared"},
+		},
+		{
+			name: "specific group without rules allows everything",
+			txt:  "User-agent: chimerascraper\nDisallow:\n\nUser-agent: *\nDisallow: /\n",
+			want: nil,
+		},
+		{
+			name: "other agents fall back to wildcard",
+			txt:  "User-agent: googlebot\nDisallow: /\n\nUser-agent: *\nDisallow: /tmp # temp files\n",
+			want: []string{"D /tmp"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, rule := range parseRobots(strings.NewReader(tt.txt), robotsAgent) {
+				kind := "D"
+				if rule.allow {
+					kind = "A"
+				}
+				got = append(got, kind+" "+rule.pattern)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Rule holds the CSS selectors that locate a site's title, main content,
+// publication date and author. Empty selectors, and selectors that match
+// nothing on a page, fall back to the generic heuristics.
+type Rule struct {
+	Title string `json:"title,omitempty"`
+	// Body selects the elements holding the article; headings, paragraphs,
+	// code and links are then extracted from them alone.
+	Body string `json:"body,omitempty"`
+	// Date is read from a datetime or content attribute, else from the text.
+	Date   string `json:"date,omitempty"`
+	Author string `json:"author,omitempty"`
+}
+
+// Rules maps a domain, lower-cased and without a leading "www.", to its rule.
+// A rule also applies to the domain's subdomains.
+type Rules map[string]Rule
+
+// LoadRules reads a rules file: a JSON object of domains to rules. A missing
+// file yields no rules.
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read extraction rules: %w", err)
+	}
+
+	var raw map[string]Rule
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode extraction rules: %w", err)
+	}
+	rules := make(Rules, len(raw))
+	for domain, rule := range raw {
+		rules[strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")] = rule
+	}
+	return rules, nil
+}
+
+// SetRules replaces the per-domain extraction rules used by subsequent scrapes.
+func (s *Scraper) SetRules(rules Rules) {
+	s.rules.Store(&rules)
+}
+
+// ruleFor returns the rule for base's host or its closest parent domain.
+func (s *Scraper) ruleFor(base *url.URL) (Rule, bool) {
+	rules := s.rules.Load()
+	if rules == nil || len(*rules) == 0 {
+		return Rule{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(base.Hostname()), "www.")
+	for host != "" {
+		if rule, ok := (*rules)[host]; ok {
+			return rule, true
+		}
+		_, host, _ = strings.Cut(host, ".")
+	}
+	return Rule{}, false
+}
+
+// ruleTitle returns the text of the rule's title element, if any.
+func ruleTitle(rule Rule, doc *goquery.Document) string {
+	if rule.Title == "" {
+		return ""
+	}
+	return strings.Join(strings.Fields(doc.Find(rule.Title).First().Text()), " ")
+}
+
+// ruleBody returns a copy of doc whose body holds only the elements matched by
+// the rule, inside a main element so their links count as content. It returns
+// nil when the rule has no body selector or it matches nothing.
+func ruleBody(rule Rule, doc *goquery.Document) *goquery.Document {
+	if rule.Body == "" || doc.Find(rule.Body).Length() == 0 {
+		return nil
+	}
+	body := goquery.NewDocumentFromNode(doc.Clone().Nodes[0])
+	matches := body.Find(rule.Body)
+	// Drop matches nested in other matches so no text is read twice.
+	matches = matches.FilterFunction(func(_ int, sel *goquery.Selection) bool {
+		return sel.ParentsFiltered(rule.Body).Length() == 0
+	})
+	root := body.Find("body")
+	root.Empty()
+	root.AppendHtml("<main></main>")
+	root.ChildrenFiltered("main").AppendSelection(matches)
+	return body
+}
+
+// applyRuleByline overrides the heuristic author and date with the rule's.
+func applyRuleByline(result *Result, rule Rule, doc *goquery.Document) {
+	if rule.Author != "" {
+		sel := doc.Find(rule.Author).First()
+		if author := cleanAuthor(sel.AttrOr("content", sel.Text())); author != "" {
+			result.Author = author
+		}
+	}
+	if rule.Date != "" {
+		sel := doc.Find(rule.Date).First()
+		for _, value := range []string{sel.AttrOr("datetime", ""), sel.AttrOr("content", ""), sel.Text()} {
+			if published := parseDate(value); !published.IsZero() {
+				result.PublishedAt = published
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRules_BodyWithBoilerplateStripped(t *testing.T) {
+	const page = `<!DOCTYPE html><html><head><title>Story</title></head><body>
+<div class="teaser"><p>A teaser paragraph from another story that the rule leaves out of the page.</p></div>
+<div class="story">
+<p>The first paragraph of the story, long enough to be kept as a paragraph.</p>
+<nav><p>Related navigation links that should never be read as part of the story text.</p></nav>
+<p>The second paragraph of the story, also long enough to be kept as one.</p>
+</div>
+</body></html>`
+
+	s := New(Config{StripBoilerplate: true, Rules: Rules{"example.com": {Body: ".story"}}})
+	base, _ := url.Parse("https://www.example.com/story")
+	result := &Result{}
+	if _, err := s.parseHTML(result, base, []byte(page)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(result.Paragraphs, "\n")
+	for _, want := range []string{"first paragraph", "second paragraph"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("paragraphs lack %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"teaser", "navigation"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("paragraphs hold %q:\n%s", unwanted, got)
+		}
+	}
+}
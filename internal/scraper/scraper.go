@@ -1,53 +1,207 @@
 package scraper
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"chimera/internal/gate"
+	"chimera/internal/proxy"
+
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
 )
 
+const userAgent = "ChimeraScraper/0.1 (+https://example.com)"
+
+// shellTextThreshold is the visible text length below which a page with
+// scripts is treated as a JavaScript shell.
+const shellTextThreshold = 200
+
 // Config controls the scraper behaviour.
 type Config struct {
 	HTTPClient *http.Client
 	Timeout    time.Duration
-	MaxItems   int
+	// MaxItems caps each kind of extracted item that Limits leaves at zero.
+	// Defaults to 10.
+	MaxItems int
+	// Limits sets per-category caps, heading depth and the minimum paragraph
+	// length; see SetLimits.
+	Limits Limits
+
+	// RequestsPerMinute caps requests to a single host; zero disables the limit.
+	RequestsPerMinute int
+	// Burst is the number of requests a host may receive back to back before
+	// RequestsPerMinute applies. Defaults to 1.
+	Burst int
+	// MinDelay is the minimum pause between two requests to the same host.
+	MinDelay time.Duration
+
+	// Robots opts into honouring robots.txt; the zero value ignores it.
+	Robots RobotsMode
+
+	// Retries is the number of extra attempts after a transient failure
+	// (408, 429, 502, 503, 504 or a network timeout). Zero disables retrying.
+	Retries int
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// subsequent retry up to RetryMaxBackoff. A Retry-After header takes
+	// precedence when it asks for a longer wait.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+	// RetryJitter randomises each delay by up to this fraction (0–1).
+	RetryJitter float64
+
+	// MaxRedirects caps how many redirects a fetch follows. Zero uses 10;
+	// a negative value refuses all redirects.
+	MaxRedirects int
+	// SameOriginRedirects refuses redirects to another origin. Upgrading
+	// from http to https on the same host is still followed.
+	SameOriginRedirects bool
+
+	// Proxy is an http, https or socks5 proxy URL; empty uses the proxy
+	// environment variables. It is ignored when HTTPClient is set.
+	Proxy string
+
+	// Request sets the User-Agent and extra headers; see SetRequestOptions.
+	Request RequestOptions
+
+	// StripBoilerplate removes navigation, footers, sidebars, cookie banners
+	// and ads before extracting headings, paragraphs and links; see
+	// SetStripBoilerplate.
+	StripBoilerplate bool
+
+	// KeepRawHTML keeps the fetched HTML on Result.RawHTML, and
+	// KeepContentHTML a sanitised fragment of its main content on
+	// Result.ContentHTML. Both are off by default to save memory.
+	KeepRawHTML     bool
+	KeepContentHTML bool
+
+	// JoinPages merges the continuation pages of articles split across
+	// several pages into the first; see SetJoinPages.
+	JoinPages bool
+
+	// Rules are per-domain selectors consulted before the generic
+	// extraction; see SetRules.
+	Rules Rules
+
+	// MaxInFlight caps the requests in flight at once across all callers;
+	// zero uses 8. Requests queue for a slot by the Priority of their
+	// context; see WithPriority.
+	MaxInFlight int
 }
 
 // Scraper fetches documents and extracts structured content.
 type Scraper struct {
 	client   *http.Client
+	proxy    atomic.Pointer[proxyFunc]
+	request  atomic.Pointer[RequestOptions]
 	maxItems int
+	limits   atomic.Pointer[Limits]
+	clean    atomic.Bool
+	rules    atomic.Pointer[Rules]
+	join     atomic.Bool
+	limiter  *hostLimiter
+	gate     *gate.Gate
+
+	keepRaw     bool
+	keepContent bool
+
+	robotsMode RobotsMode
+	robots     *robotsCache
+	retry      retryPolicy
+	redirects  *redirectPolicy
 }
 
 // Result contains the structured data extracted from a page.
 type Result struct {
-	SourceURL   string
-	Title       string
-	Description string
-	Headings    []Heading
-	Paragraphs  []string
-	Links       []Link
-	FetchedAt   time.Time
+	// SourceURL is the URL that was requested.
+	SourceURL string `json:"source_url"`
+	// FinalURL is the URL that served the document after redirects.
+	FinalURL string `json:"final_url,omitempty"`
+	// Redirects lists the URLs that redirected, in order, starting with SourceURL.
+	Redirects []string `json:"redirects,omitempty"`
+	// Canonical is the page's <link rel="canonical"> target, resolved against
+	// FinalURL; empty when the page names none.
+	Canonical   string `json:"canonical,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// SiteName is the name the site gives itself, such as "The Guardian";
+	// empty when the page does not say.
+	SiteName string `json:"site_name,omitempty"`
+	// Author and PublishedAt credit the page when it names them; either may be empty.
+	Author      string    `json:"author,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	Headings    []Heading `json:"headings,omitempty"`
+	Paragraphs  []string  `json:"paragraphs,omitempty"`
+	// Footnotes are the notes referenced from Paragraphs by [^Label] markers.
+	Footnotes []Footnote `json:"footnotes,omitempty"`
+	// CodeBlocks are the page's preformatted code samples, verbatim.
+	CodeBlocks []CodeBlock `json:"code_blocks,omitempty"`
+	// Formulas are the math expressions referenced from Paragraphs by [math:ID] markers.
+	Formulas  []Formula      `json:"formulas,omitempty"`
+	Links     []Link         `json:"links,omitempty"`
+	FetchedAt time.Time      `json:"fetched_at"`
+	Robots    RobotsDecision `json:"robots"`
+	// Validators identify the fetched version of the page; see Revalidate.
+	Validators Validators `json:"validators"`
+	// Words, ReadingMinutes and Language describe the full text of the page,
+	// not only the extracted excerpts. Language is an ISO 639-1 code, taken
+	// from the page's declaration or guessed from its text; it may be empty.
+	Words          int    `json:"words"`
+	ReadingMinutes int    `json:"reading_minutes"`
+	Language       string `json:"language,omitempty"`
+	// Attempts is the number of requests needed to fetch the page, including retries.
+	Attempts int `json:"attempts,omitempty"`
+	// NeedsScripts reports that the HTML is a script shell with little content
+	// of its own; see Rendered.
+	NeedsScripts bool `json:"needs_scripts,omitempty"`
+	// Rendered reports that the content was extracted after running scripts.
+	Rendered bool `json:"rendered,omitempty"`
+	// ConsentWall names the consent manager whose interstitial was skipped by
+	// fetching the page again with consent cookies; see RequestOptions.SkipConsentWalls.
+	ConsentWall string `json:"consent_wall,omitempty"`
+	// RawHTML is the HTML document as fetched, or as rendered for Rendered
+	// results; set only with Config.KeepRawHTML.
+	RawHTML string `json:"raw_html,omitempty"`
+	// ContentHTML is the page's main content as a sanitised HTML fragment,
+	// without page chrome, scripts or forms and with absolute links; set only
+	// with Config.KeepContentHTML.
+	ContentHTML string `json:"content_html,omitempty"`
+	// NextPage is the next page of an article split across several pages,
+	// when it was not merged into this result.
+	NextPage string `json:"next_page,omitempty"`
+	// JoinedPages lists the continuation pages whose content was appended,
+	// in order; see Config.JoinPages.
+	JoinedPages []string `json:"joined_pages,omitempty"`
 }
 
 // Heading captures a heading and its level.
 type Heading struct {
-	Level int
-	Text  string
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	// ID is the heading's anchor: the page's own id where it has one, so
+	// source #fragments resolve, or a slug of Text. It is unique per result.
+	ID string `json:"id"`
+	// Paragraph is how many of the result's Paragraphs come before the
+	// heading, so the text below it can be told apart.
+	Paragraph int `json:"paragraph,omitempty"`
 }
 
 // Link represents a hyperlink discovered during scraping.
 type Link struct {
-	Text string
-	Href string
+	Text string `json:"text"`
+	Href string `json:"href"`
+	// Class says whether the link is part of the text or of the page chrome.
+	Class LinkClass `json:"class,omitempty"`
 }
 
 // New creates a new Scraper instance with sensible defaults.
@@ -57,20 +211,60 @@ func New(cfg Config) *Scraper {
 		timeout = 15 * time.Second
 	}
 
-	client := cfg.HTTPClient
-	if client == nil {
-		client = &http.Client{Timeout: timeout}
+	s := &Scraper{}
+	s.setProxyFunc(proxy.Func(cfg.Proxy))
+	s.SetRequestOptions(cfg.Request)
+
+	transport := proxy.Transport("")
+	transport.Proxy = s.proxyFor
+	client := &http.Client{Timeout: timeout, Transport: transport}
+	if cfg.HTTPClient != nil {
+		copied := *cfg.HTTPClient
+		client = &copied
 	}
+	s.redirects = newRedirectPolicy(cfg, client.CheckRedirect)
+	client.CheckRedirect = s.redirects.check
 
 	maxItems := cfg.MaxItems
 	if maxItems <= 0 {
-		maxItems = 10
+		maxItems = defaultMaxItems
 	}
 
-	return &Scraper{
-		client:   client,
-		maxItems: maxItems,
+	s.client = client
+	s.maxItems = maxItems
+	s.SetLimits(cfg.Limits)
+	s.SetStripBoilerplate(cfg.StripBoilerplate)
+	s.SetRules(cfg.Rules)
+	s.SetJoinPages(cfg.JoinPages)
+	s.keepRaw = cfg.KeepRawHTML
+	s.keepContent = cfg.KeepContentHTML
+	s.limiter = newHostLimiter(cfg.RequestsPerMinute, cfg.Burst, cfg.MinDelay)
+	s.gate = gate.New(cmp.Or(max(cfg.MaxInFlight, 0), defaultMaxInFlight))
+	s.robotsMode = cfg.Robots
+	s.robots = &robotsCache{hosts: make(map[string]robotsEntry)}
+	s.retry = newRetryPolicy(cfg)
+	return s
+}
+
+type proxyFunc func(*http.Request) (*url.URL, error)
+
+// SetProxy switches the proxy used by subsequent requests; see Config.Proxy.
+// It has no effect when the Scraper was given its own HTTPClient.
+func (s *Scraper) SetProxy(raw string) error {
+	if _, err := proxy.Parse(raw); err != nil {
+		return err
 	}
+	s.setProxyFunc(proxy.Func(raw))
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func (s *Scraper) setProxyFunc(fn proxyFunc) {
+	s.proxy.Store(&fn)
+}
+
+func (s *Scraper) proxyFor(req *http.Request) (*url.URL, error) {
+	return (*s.proxy.Load())(req)
 }
 
 // Scrape downloads the specified URL and extracts structured content.
@@ -84,94 +278,287 @@ func (s *Scraper) Scrape(ctx context.Context, target string) (*Result, error) {
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	robots := s.checkRobots(ctx, parsed)
+	if robots.Checked && !robots.Allowed && s.robotsMode == RobotsEnforce {
+		return nil, fmt.Errorf("%w (%s)", ErrDisallowedByRobots, robots.Rule)
+	}
+
+	fetched, err := s.fetch(ctx, parsed)
+	if err == nil {
+		fetched, err = s.followClientRedirects(ctx, fetched)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("build request: %w", err)
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &FetchError{Kind: classifyFailure(err), URL: target, Err: err}
+	}
+
+	// Relative links resolve against the page that was actually served.
+	final := fetched.finalURL
+	result := &Result{
+		SourceURL:  target,
+		FinalURL:   final.String(),
+		Redirects:  fetched.redirects,
+		FetchedAt:  time.Now(),
+		Robots:     robots,
+		Attempts:   fetched.attempts,
+		Validators: validatorsOf(fetched.header),
 	}
 
-	req.Header.Set("User-Agent", "ChimeraScraper/0.1 (+https://example.com)")
+	declared := languageCode(fetched.header.Get("Content-Language"))
+	if isPDF(fetched.header.Get("Content-Type"), fetched.body) {
+		parsePDF(result, fetched.body, s.Limits())
+		if declared != "" {
+			result.Language = declared
+		}
+		return result, nil
+	}
+
+	if kind := textKind(fetched.header.Get("Content-Type"), final); kind != "" {
+		parseText(result, final, string(fetched.body), kind == "markdown", s.Limits())
+		if declared != "" {
+			result.Language = declared
+		}
+		return result, nil
+	}
 
-	resp, err := s.client.Do(req)
+	doc, err := s.parseHTML(result, final, fetched.body)
 	if err != nil {
-		return nil, fmt.Errorf("fetch document: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	if opts := s.request.Load(); opts != nil && opts.SkipConsentWalls {
+		if rule := consentWall(final, doc); rule != nil {
+			if behind := s.skipConsentWall(ctx, parsed, result, rule); behind != nil {
+				result = behind
+			}
+		}
+	}
+	s.joinPages(ctx, result)
+	return result, nil
+}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+// skipConsentWall fetches target again with rule's consent cookies and returns
+// the page behind the wall, or nil if the cookies did not get past it.
+func (s *Scraper) skipConsentWall(ctx context.Context, target *url.URL, walled *Result, rule *consentRule) *Result {
+	fetched, err := s.fetch(withConsentCookies(ctx, rule.cookies(time.Now())), target)
+	if err != nil {
+		return nil
+	}
+	final := fetched.finalURL
+	result := &Result{
+		SourceURL:   walled.SourceURL,
+		FinalURL:    final.String(),
+		Redirects:   fetched.redirects,
+		FetchedAt:   time.Now(),
+		Robots:      walled.Robots,
+		Attempts:    walled.Attempts + fetched.attempts,
+		ConsentWall: rule.name,
+		Validators:  validatorsOf(fetched.header),
+	}
+	doc, err := s.parseHTML(result, final, fetched.body)
+	if err != nil || consentWall(final, doc) != nil {
+		return nil
 	}
+	return result
+}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 4*1024*1024))
+// Rendered extracts content from html, the DOM of result's page after its
+// scripts ran, keeping the fetch details of result.
+func (s *Scraper) Rendered(result *Result, html string) (*Result, error) {
+	final, err := url.Parse(result.FinalURL)
 	if err != nil {
-		return nil, fmt.Errorf("read body: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	rendered := &Result{
+		SourceURL: result.SourceURL,
+		FinalURL:  result.FinalURL,
+		Redirects: result.Redirects,
+		FetchedAt: time.Now(),
+		Robots:    result.Robots,
+		Attempts:  result.Attempts,
+		Rendered:  true,
 	}
+	if _, err := s.parseHTML(rendered, final, []byte(html)); err != nil {
+		return nil, err
+	}
+	rendered.NeedsScripts = false
+	return rendered, nil
+}
 
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+func (s *Scraper) parseHTML(result *Result, base *url.URL, body []byte) (*goquery.Document, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("parse document: %w", err)
 	}
 
-	result := &Result{
-		SourceURL: target,
-		Title:     strings.TrimSpace(doc.Find("title").First().Text()),
-		FetchedAt: time.Now(),
+	rule, hasRule := s.ruleFor(base)
+	result.Title = strings.TrimSpace(doc.Find("title").First().Text())
+	if title := ruleTitle(rule, doc); title != "" {
+		result.Title = title
 	}
 
 	if metaDesc, ok := doc.Find("meta[name='description']").Attr("content"); ok {
 		result.Description = strings.TrimSpace(metaDesc)
 	}
+	result.SiteName = siteName(doc)
+
+	measure(result, pageText(doc), declaredLanguage(doc))
 
-	headings := collectHeadings(doc, s.maxItems)
-	paragraphs := collectParagraphs(doc, s.maxItems)
-	links := collectLinks(parsed, doc, s.maxItems)
+	if s.keepRaw {
+		result.RawHTML = string(body)
+	}
+	if s.keepContent {
+		result.ContentHTML = contentHTML(base, doc)
+	}
+
+	content := doc
+	if body := ruleBody(rule, doc); body != nil {
+		content = body
+	}
+	if s.clean.Load() {
+		content = withoutBoilerplate(content)
+	}
+	limits := s.Limits()
+	headings, headingNodes := collectHeadings(content, limits)
+	formulas := markMath(content)
+	notes := markFootnotes(content)
+	paragraphs, paragraphNodes := collectParagraphs(content, limits)
+	placeHeadings(content, headings, headingNodes, paragraphNodes)
+	code := collectCode(content, limits.CodeBlocks)
+	links := collectLinks(base, content, limits.Links)
 
 	result.Headings = headings
 	result.Paragraphs = paragraphs
+	result.Footnotes = referencedFootnotes(notes, paragraphs)
+	result.Formulas = referencedFormulas(formulas, paragraphs)
+	result.CodeBlocks = code
 	result.Links = links
+	result.NeedsScripts = isScriptShell(doc)
+	result.Canonical = canonicalURL(base, doc)
+	result.NextPage = nextPage(base, doc)
+	extractByline(result, doc)
+	if hasRule {
+		applyRuleByline(result, rule, doc)
+	}
 
-	return result, nil
+	return doc, nil
 }
 
-func collectHeadings(doc *goquery.Document, limit int) []Heading {
-	var hs []Heading
-	for level := 1; level <= 3; level++ {
-		selector := fmt.Sprintf("h%d", level)
-		doc.Find(selector).Each(func(_ int, sel *goquery.Selection) {
-			text := strings.TrimSpace(sel.Text())
-			if text == "" {
-				return
-			}
-			hs = append(hs, Heading{Level: level, Text: text})
-		})
+// canonicalURL returns the http(s) URL named by the page's canonical link.
+func canonicalURL(base *url.URL, doc *goquery.Document) string {
+	href, ok := doc.Find("link[rel='canonical']").First().Attr("href")
+	if !ok || strings.TrimSpace(href) == "" {
+		return ""
+	}
+	resolved, err := base.Parse(strings.TrimSpace(href))
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
 	}
+	resolved.Fragment = ""
+	return resolved.String()
+}
+
+// isScriptShell reports whether doc looks like a single-page app shell whose
+// content only appears once its scripts run: almost no text, but scripts.
+func isScriptShell(doc *goquery.Document) bool {
+	if doc.Find("script[src], script[type='module']").Length() == 0 {
+		return false
+	}
+	body := doc.Find("body").Clone()
+	body.Find("script, style, noscript, template, svg").Remove()
+	return len(strings.Join(strings.Fields(body.Text()), " ")) < shellTextThreshold
+}
 
-	if len(hs) > limit {
-		hs = hs[:limit]
+// collectHeadings returns the first limits.Headings headings down to
+// limits.HeadingDepth in document order, with levels normalised into a
+// consistent outline, and their nodes.
+func collectHeadings(doc *goquery.Document, limits Limits) ([]Heading, []*html.Node) {
+	selectors := make([]string, limits.HeadingDepth)
+	for i := range selectors {
+		selectors[i] = "h" + strconv.Itoa(i+1)
 	}
 
-	return hs
+	var (
+		hs    []Heading
+		nodes []*html.Node
+	)
+	doc.Find(strings.Join(selectors, ", ")).EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		text := strings.TrimSpace(sel.Text())
+		if text == "" {
+			return true
+		}
+		level := int(goquery.NodeName(sel)[1] - '0')
+		id := sel.AttrOr("id", "")
+		if id == "" {
+			// Older pages anchor headings with a named link inside them.
+			anchor := sel.Find("a[id], a[name]").First()
+			id = anchor.AttrOr("id", anchor.AttrOr("name", ""))
+		}
+		hs = append(hs, Heading{Level: level, Text: text, ID: id})
+		nodes = append(nodes, sel.Nodes[0])
+		return len(hs) < limits.Headings
+	})
+
+	return anchorHeadings(normalizeHeadings(hs)), nodes
 }
 
-func collectParagraphs(doc *goquery.Document, limit int) []string {
-	var paragraphs []string
+// collectParagraphs returns the first limits.Paragraphs paragraphs in
+// document order and their nodes.
+func collectParagraphs(doc *goquery.Document, limits Limits) ([]string, []*html.Node) {
+	var (
+		paragraphs []string
+		nodes      []*html.Node
+	)
 	doc.Find("p").Each(func(_ int, sel *goquery.Selection) {
+		if sel.Closest("["+footnoteAttr+"]").Length() > 0 {
+			return
+		}
 		text := strings.TrimSpace(sel.Text())
-		if len(text) < 40 { // skip very short fragments
+		if limits.shortParagraph(text) && !MathRefPattern.MatchString(text) { // skip very short fragments
 			return
 		}
 		paragraphs = append(paragraphs, text)
+		nodes = append(nodes, sel.Nodes[0])
 	})
 
-	if len(paragraphs) > limit {
-		paragraphs = paragraphs[:limit]
+	if len(paragraphs) > limits.Paragraphs {
+		paragraphs, nodes = paragraphs[:limits.Paragraphs], nodes[:limits.Paragraphs]
 	}
 
-	return paragraphs
+	return paragraphs, nodes
+}
+
+// placeHeadings sets each heading's Paragraph from where its node sits in
+// doc among the nodes of the collected paragraphs.
+func placeHeadings(doc *goquery.Document, headings []Heading, headingNodes, paragraphNodes []*html.Node) {
+	wanted := make(map[*html.Node]bool, len(headingNodes)+len(paragraphNodes))
+	for _, n := range headingNodes {
+		wanted[n] = true
+	}
+	for _, n := range paragraphNodes {
+		wanted[n] = true
+	}
+	order := make(map[*html.Node]int, len(wanted))
+	doc.Find("*").Each(func(i int, sel *goquery.Selection) {
+		if wanted[sel.Nodes[0]] {
+			order[sel.Nodes[0]] = i
+		}
+	})
+
+	before := 0
+	for i, n := range headingNodes {
+		for before < len(paragraphNodes) && order[paragraphNodes[before]] < order[n] {
+			before++
+		}
+		headings[i].Paragraph = before
+	}
 }
 
 func collectLinks(base *url.URL, doc *goquery.Document, limit int) []Link {
 	seen := make(map[string]struct{})
 	var links []Link
+	hasRoot := doc.Find(contentSelector).Length() > 0
 
 	doc.Find("a[href]").Each(func(_ int, sel *goquery.Selection) {
 		href, exists := sel.Attr("href")
@@ -188,6 +575,8 @@ func collectLinks(base *url.URL, doc *goquery.Document, limit int) []Link {
 		parsed, err := base.Parse(trimmed)
 		if err == nil {
 			resolved = parsed.String()
+		} else {
+			parsed = nil
 		}
 
 		if _, ok := seen[resolved]; ok {
@@ -200,9 +589,10 @@ func collectLinks(base *url.URL, doc *goquery.Document, limit int) []Link {
 			text = resolved
 		}
 
-		links = append(links, Link{Text: text, Href: resolved})
+		links = append(links, Link{Text: text, Href: resolved, Class: classifyLink(base, parsed, sel, hasRoot)})
 	})
 
+	rankLinks(links)
 	if len(links) > limit {
 		links = links[:limit]
 	}
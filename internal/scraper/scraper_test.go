@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestScraper_HeadingParagraphs(t *testing.T) {
+	const page = `<!DOCTYPE html><html><head><title>Report</title></head><body><main>
+<h1>Report</h1>
+<p>The first paragraph of the report, long enough to be kept as a paragraph.</p>
+<p>The second paragraph of the report, also long enough to be kept as one.</p>
+<section><h2>Results</h2>
+<p>Short.</p>
+<div><p>Results are described at length here, in a paragraph nested in a div.</p></div>
+</section>
+<h2>Outlook</h2>
+</main></body></html>`
+
+	const markdown = "# Report\n\nThe first paragraph of the report, long enough to be kept as a paragraph.\n\n" +
+		"The second paragraph of the report, also long enough to be kept as one.\n\n" +
+		"Results\n-------\n\nResults are described at length here, in a paragraph of its own.\n\n## Outlook\n"
+
+	base, _ := url.Parse("https://example.com/report")
+	s := New(Config{})
+	tests := []struct {
+		name  string
+		parse func(*Result)
+	}{
+		{"html", func(r *Result) {
+			if _, err := s.parseHTML(r, base, []byte(page)); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"markdown", func(r *Result) { parseText(r, base, markdown, true, s.Limits()) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{}
+			tt.parse(result)
+			if len(result.Paragraphs) != 3 {
+				t.Fatalf("Paragraphs = %q, want 3", result.Paragraphs)
+			}
+			var got []int
+			for _, h := range result.Headings {
+				got = append(got, h.Paragraph)
+			}
+			if want := []int{0, 2, 3}; !slices.Equal(got, want) {
+				t.Errorf("heading paragraphs = %v, want %v", got, want)
+			}
+		})
+	}
+
+	result := &Result{Paragraphs: []string{"a", "b"}, Headings: []Heading{{Level: 1, Text: "A", ID: "a"}}}
+	appendPage(result, &Result{Paragraphs: []string{"c"}, Headings: []Heading{{Level: 2, Text: "C", Paragraph: 0}, {Level: 2, Text: "D", Paragraph: 1}}})
+	if got := []int{result.Headings[1].Paragraph, result.Headings[2].Paragraph}; !slices.Equal(got, []int{2, 3}) {
+		t.Errorf("appended heading paragraphs = %v, want [2 3]", got)
+	}
+}
@@ -0,0 +1,179 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSitemapFiles caps the sitemaps read for a site, indexes included.
+	maxSitemapFiles = 20
+	// maxSitemapURLs caps the pages listed for a site.
+	maxSitemapURLs = 10000
+	// maxSitemapBytes caps a gzipped sitemap once decompressed.
+	maxSitemapBytes = 50 * 1024 * 1024
+)
+
+// ErrNoSitemap is returned by Sitemap when a site publishes no sitemap.
+var ErrNoSitemap = errors.New("no sitemap found")
+
+// SitemapURL is a page listed in a site's sitemap.
+type SitemapURL struct {
+	Loc string
+	// LastMod is when the site says the page last changed; zero if it does not say.
+	LastMod time.Time
+}
+
+// sitemapDocument covers both a urlset and a sitemap index.
+type sitemapDocument struct {
+	URLs     []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// Sitemap lists the pages of site, a domain such as "example.com" or any URL
+// on it, from the sitemaps named in its robots.txt, or /sitemap.xml when
+// there are none. Sitemap indexes and gzipped sitemaps are followed, up to
+// maxSitemapFiles files and maxSitemapURLs pages, in the order listed.
+func (s *Scraper) Sitemap(ctx context.Context, site string) ([]SitemapURL, error) {
+	origin, err := siteOrigin(site)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := s.sitemapLocations(ctx, origin)
+	seen := make(map[string]bool)
+	var (
+		pages  []SitemapURL
+		listed = make(map[string]bool)
+		read   int
+		last   error
+	)
+	for len(queue) > 0 && read < maxSitemapFiles && len(pages) < maxSitemapURLs {
+		loc := queue[0]
+		queue = queue[1:]
+		if seen[loc] {
+			continue
+		}
+		seen[loc] = true
+
+		doc, err := s.fetchSitemap(ctx, loc)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			last = err
+			continue
+		}
+		read++
+		for _, child := range doc.Sitemaps {
+			if resolved, ok := sitemapLoc(origin, child.Loc); ok {
+				queue = append(queue, resolved)
+			}
+		}
+		for _, entry := range doc.URLs {
+			resolved, ok := sitemapLoc(origin, entry.Loc)
+			if !ok || listed[resolved] {
+				continue
+			}
+			listed[resolved] = true
+			pages = append(pages, SitemapURL{Loc: resolved, LastMod: parseDate(entry.LastMod)})
+			if len(pages) == maxSitemapURLs {
+				break
+			}
+		}
+	}
+
+	if read == 0 {
+		if last != nil {
+			return nil, fmt.Errorf("%w for %s: %w", ErrNoSitemap, origin.Host, last)
+		}
+		return nil, fmt.Errorf("%w for %s", ErrNoSitemap, origin.Host)
+	}
+	return pages, nil
+}
+
+// siteOrigin turns a domain or URL into the https (or given) origin.
+func siteOrigin(site string) (*url.URL, error) {
+	site = strings.TrimSpace(site)
+	if !strings.Contains(site, "://") {
+		site = "https://" + site
+	}
+	parsed, err := url.Parse(site)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid site %q", site)
+	}
+	return &url.URL{Scheme: parsed.Scheme, Host: parsed.Host}, nil
+}
+
+// sitemapLocations reads the Sitemap lines of origin's robots.txt, falling
+// back to the conventional /sitemap.xml.
+func (s *Scraper) sitemapLocations(ctx context.Context, origin *url.URL) []string {
+	var locations []string
+	robots := origin.JoinPath("robots.txt")
+	if fetched, err := s.fetch(ctx, robots); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(fetched.body))
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), ":")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "sitemap") {
+				continue
+			}
+			if resolved, ok := sitemapLoc(origin, value); ok {
+				locations = append(locations, resolved)
+			}
+		}
+	}
+	if len(locations) == 0 {
+		locations = append(locations, origin.JoinPath("sitemap.xml").String())
+	}
+	return locations
+}
+
+func (s *Scraper) fetchSitemap(ctx context.Context, loc string) (*sitemapDocument, error) {
+	target, err := url.Parse(loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sitemap URL: %w", err)
+	}
+	fetched, err := s.fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader = bytes.NewReader(fetched.body)
+	if bytes.HasPrefix(fetched.body, []byte{0x1f, 0x8b}) {
+		unzipped, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("decode sitemap %s: %w", loc, err)
+		}
+		body = io.LimitReader(unzipped, maxSitemapBytes)
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse sitemap %s: %w", loc, err)
+	}
+	return &doc, nil
+}
+
+// sitemapLoc resolves a sitemap location against origin, keeping http(s)
+// URLs only.
+func sitemapLoc(origin *url.URL, loc string) (string, bool) {
+	resolved, err := origin.Parse(strings.TrimSpace(loc))
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return "", false
+	}
+	return resolved.String(), true
+}
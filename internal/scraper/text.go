@@ -0,0 +1,225 @@
+package scraper
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdLinkPattern     = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	mdAutoLinkPattern = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	bareURLPattern    = regexp.MustCompile(`https?://[^\s<>()"']+`)
+	mdEmphasisPattern = regexp.MustCompile("(\\*\\*|__|\\*|_|`)([^*_`]+)(\\*\\*|__|\\*|_|`)")
+	mdListPattern     = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+)
+
+// textKind classifies a response as plain text or Markdown, or "" for anything else.
+func textKind(contentType string, target *url.URL) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	switch mediaType {
+	case "text/markdown", "text/x-markdown":
+		return "markdown"
+	case "text/plain":
+		// Raw file hosts serve Markdown as text/plain.
+		switch strings.ToLower(path.Ext(target.Path)) {
+		case ".md", ".markdown", ".mdown":
+			return "markdown"
+		}
+		return "text"
+	}
+	return ""
+}
+
+// parseText fills result from a plain-text or Markdown body. Blank lines
+// separate paragraphs; Markdown headings, links and emphasis are interpreted.
+func parseText(result *Result, base *url.URL, body string, markdown bool, limits Limits) {
+	body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(body, "\n")
+
+	var (
+		headings   []Heading
+		paragraphs []string
+		current    []string
+		inFence    bool
+		fence      CodeBlock
+		fenceLines []string
+		code       []CodeBlock
+	)
+	endParagraph := func() {
+		if len(current) == 0 {
+			return
+		}
+		text := strings.Join(current, " ")
+		current = current[:0]
+		if markdown {
+			text = stripMarkdown(text)
+		}
+		if text = strings.Join(strings.Fields(text), " "); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if markdown && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			endParagraph()
+			if inFence {
+				fence.Code = strings.Join(fenceLines, "\n")
+				if strings.TrimSpace(fence.Code) != "" {
+					code = append(code, fence)
+				}
+			} else {
+				info := strings.Fields(strings.TrimLeft(trimmed, "`~"))
+				fence, fenceLines = CodeBlock{}, nil
+				if len(info) > 0 {
+					fence.Language = strings.ToLower(info[0])
+				}
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			fenceLines = append(fenceLines, line)
+			continue
+		}
+		if trimmed == "" {
+			endParagraph()
+			continue
+		}
+
+		if markdown {
+			if level, text, ok := atxHeading(trimmed); ok {
+				endParagraph()
+				if level <= limits.HeadingDepth {
+					headings = append(headings, Heading{Level: level, Text: stripMarkdown(text), Paragraph: len(paragraphs)})
+				}
+				continue
+			}
+			if i+1 < len(lines) && len(current) == 0 {
+				if level, ok := setextLevel(lines[i+1]); ok {
+					if level <= limits.HeadingDepth {
+						headings = append(headings, Heading{Level: level, Text: stripMarkdown(trimmed), Paragraph: len(paragraphs)})
+					}
+					lines[i+1] = ""
+					continue
+				}
+			}
+			if mdListPattern.MatchString(line) {
+				// Keep list items apart within the paragraph.
+				trimmed = "• " + mdListPattern.ReplaceAllString(line, "")
+			}
+		}
+		current = append(current, trimmed)
+	}
+	endParagraph()
+
+	switch {
+	case markdown:
+		for _, h := range headings {
+			if h.Level == 1 {
+				result.Title = h.Text
+				break
+			}
+		}
+	case len(paragraphs) > 0 && len(paragraphs[0]) <= 120:
+		result.Title = paragraphs[0]
+	}
+	if result.Title == "" {
+		result.Title = path.Base(base.Path)
+	}
+
+	if len(headings) > limits.Headings {
+		headings = headings[:limits.Headings]
+	}
+	if len(paragraphs) > limits.Paragraphs {
+		paragraphs = paragraphs[:limits.Paragraphs]
+	}
+	if len(code) > limits.CodeBlocks {
+		code = code[:limits.CodeBlocks]
+	}
+	result.Headings = anchorHeadings(normalizeHeadings(headings))
+	result.Paragraphs = paragraphs
+	result.CodeBlocks = code
+	result.Links = textLinks(base, body, markdown, limits.Links)
+	measure(result, body, "")
+}
+
+func atxHeading(line string) (int, string, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return 0, "", false
+	}
+	text := strings.TrimSpace(strings.TrimRight(line[level:], "# "))
+	if level > 3 {
+		level = 3
+	}
+	return level, text, text != ""
+}
+
+func setextLevel(underline string) (int, bool) {
+	trimmed := strings.TrimSpace(underline)
+	if len(trimmed) < 2 {
+		return 0, false
+	}
+	switch {
+	case strings.Trim(trimmed, "=") == "":
+		return 1, true
+	case strings.Trim(trimmed, "-") == "":
+		return 2, true
+	}
+	return 0, false
+}
+
+// stripMarkdown reduces inline Markdown to its text.
+func stripMarkdown(text string) string {
+	text = mdLinkPattern.ReplaceAllString(text, "$1")
+	text = mdAutoLinkPattern.ReplaceAllString(text, "$1")
+	for i := 0; i < 3; i++ {
+		text = mdEmphasisPattern.ReplaceAllString(text, "$2")
+	}
+	return strings.TrimSpace(text)
+}
+
+func textLinks(base *url.URL, body string, markdown bool, limit int) []Link {
+	seen := make(map[string]struct{})
+	var links []Link
+	add := func(text, href string) {
+		resolved := href
+		if parsed, err := base.Parse(href); err == nil {
+			resolved = parsed.String()
+		}
+		if _, ok := seen[resolved]; ok {
+			return
+		}
+		seen[resolved] = struct{}{}
+		if strings.TrimSpace(text) == "" {
+			text = resolved
+		}
+		links = append(links, Link{Text: text, Href: resolved, Class: textLinkClass(base, href)})
+	}
+
+	if markdown {
+		for _, m := range mdLinkPattern.FindAllStringSubmatch(body, -1) {
+			if !strings.HasPrefix(m[0], "!") {
+				add(stripMarkdown(m[1]), m[2])
+			}
+		}
+	}
+	for _, href := range bareURLPattern.FindAllString(body, -1) {
+		add("", strings.TrimRight(href, ".,;:!?*_`]"))
+	}
+
+	if len(links) > limit {
+		links = links[:limit]
+	}
+	return links
+}
@@ -0,0 +1,470 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"chimera/internal/scraper"
+	"chimera/internal/storage"
+)
+
+const (
+	// maxDocuments caps the index; the pages visited longest ago are dropped
+	// first. It matches the history's limit.
+	maxDocuments = 500
+	// maxTextBytes caps the text kept per page.
+	maxTextBytes = 64 * 1024
+	// titleWeight counts a term in the title as this many in the text.
+	titleWeight = 3
+	// indexPrefix is where the pages are kept in the backend.
+	indexPrefix = "search/"
+	// snippetRunes is roughly how much text a snippet shows.
+	snippetRunes = 220
+)
+
+// Document is an indexed page.
+type Document struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	VisitedAt time.Time `json:"visited_at"`
+	// Paragraphs is the page's text, headings included, capped at maxTextBytes.
+	Paragraphs []string `json:"paragraphs"`
+	// Embedding is the page's vector from EmbeddingModel, scaled to unit
+	// length; see Index.Embed.
+	Embedding      []float32 `json:"embedding,omitempty"`
+	EmbeddingModel string    `json:"embedding_model,omitempty"`
+}
+
+// Hit is a page matching a query.
+type Hit struct {
+	URL       string
+	Title     string
+	VisitedAt time.Time
+	Score     float64
+	// Snippet is a passage around the first match, split so matched terms
+	// can be highlighted.
+	Snippet []Segment
+}
+
+// Segment is part of a snippet; Match marks a matched term.
+type Segment struct {
+	Text  string
+	Match bool
+}
+
+// Index is a full-text index of visited pages, kept in memory and saved as
+// one JSON value per page in a storage.Backend, by default below the user's
+// cache directory.
+type Index struct {
+	backend storage.Backend
+
+	mu     sync.RWMutex
+	loaded bool
+	docs   map[string]*entry
+	// postings maps a term to the URLs of the pages containing it.
+	postings map[string]map[string]bool
+}
+
+type entry struct {
+	doc Document
+	// counts holds how often each term appears, title terms weighted.
+	counts map[string]int
+	length int
+}
+
+// NewIndex builds an Index below the user's cache directory. Pages are read
+// when the index is first used.
+func NewIndex(appID string) (*Index, error) {
+	backend, err := storage.CacheFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewIndexWith(backend), nil
+}
+
+// NewIndexWith builds an Index keeping its pages in backend; a nil backend
+// gives a nil Index, which indexes nothing.
+func NewIndexWith(backend storage.Backend) *Index {
+	if backend == nil {
+		return nil
+	}
+	return &Index{backend: backend}
+}
+
+// Add indexes result under url, replacing an earlier visit of the same URL.
+func (x *Index) Add(url string, result *scraper.Result) error {
+	if x == nil {
+		return nil
+	}
+	if url == "" {
+		return errors.New("indexed URL is empty")
+	}
+
+	doc := Document{URL: url, Title: strings.TrimSpace(result.Title), VisitedAt: time.Now()}
+	size := 0
+	add := func(text string) bool {
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			return true
+		}
+		if size+len(text) > maxTextBytes {
+			return false
+		}
+		size += len(text)
+		doc.Paragraphs = append(doc.Paragraphs, text)
+		return true
+	}
+	if result.Description != "" {
+		add(result.Description)
+	}
+	for _, h := range result.Headings {
+		if !add(h.Text) {
+			break
+		}
+	}
+	for _, p := range result.Paragraphs {
+		if !add(scraper.FootnoteRefPattern.ReplaceAllString(p, "")) {
+			break
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.load(); err != nil {
+		return err
+	}
+	if old, ok := x.docs[url]; ok && old.doc.Title == doc.Title && slices.Equal(old.doc.Paragraphs, doc.Paragraphs) {
+		// The text is unchanged, so its vector still fits.
+		doc.Embedding, doc.EmbeddingModel = old.doc.Embedding, old.doc.EmbeddingModel
+	}
+	if err := x.write(doc); err != nil {
+		return err
+	}
+	x.insert(doc)
+	return x.trim()
+}
+
+// Search returns up to limit pages containing every term of query, best
+// matches first. The last term also matches as a prefix, so results show
+// up while a word is being typed.
+func (x *Index) Search(query string, limit int) ([]Hit, error) {
+	if x == nil {
+		return nil, nil
+	}
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	x.mu.Lock()
+	err := x.load()
+	x.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	// Each query term expands to the indexed terms it matches.
+	expanded := make([][]string, len(terms))
+	for i, term := range terms {
+		if _, ok := x.postings[term]; ok {
+			expanded[i] = append(expanded[i], term)
+		}
+		if i == len(terms)-1 {
+			for indexed := range x.postings {
+				if indexed != term && strings.HasPrefix(indexed, term) {
+					expanded[i] = append(expanded[i], indexed)
+				}
+			}
+		}
+		if len(expanded[i]) == 0 {
+			return nil, nil
+		}
+	}
+
+	total := float64(len(x.docs))
+	scores := make(map[string]float64)
+	for i, variants := range expanded {
+		matched := make(map[string]float64)
+		for _, term := range variants {
+			idf := math.Log(1 + total/float64(len(x.postings[term])))
+			for url := range x.postings[term] {
+				e := x.docs[url]
+				tf := float64(e.counts[term]) / math.Sqrt(float64(max(e.length, 1)))
+				matched[url] += tf * idf
+			}
+		}
+		if i == 0 {
+			scores = matched
+			continue
+		}
+		for url, score := range scores {
+			if extra, ok := matched[url]; ok {
+				scores[url] = score + extra
+			} else {
+				delete(scores, url)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for url, score := range scores {
+		doc := x.docs[url].doc
+		hits = append(hits, Hit{URL: doc.URL, Title: doc.Title, VisitedAt: doc.VisitedAt, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].VisitedAt.After(hits[j].VisitedAt)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	for i := range hits {
+		hits[i].Snippet = snippet(x.docs[hits[i].URL].doc, expanded)
+	}
+	return hits, nil
+}
+
+// Len returns the number of indexed pages.
+func (x *Index) Len() (int, error) {
+	if x == nil {
+		return 0, nil
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.load(); err != nil {
+		return 0, err
+	}
+	return len(x.docs), nil
+}
+
+// load reads the saved pages on first use. The caller holds x.mu.
+func (x *Index) load() error {
+	if x.loaded {
+		return nil
+	}
+	x.docs = make(map[string]*entry)
+	x.postings = make(map[string]map[string]bool)
+
+	keys, err := x.backend.List(indexPrefix)
+	if err != nil {
+		return fmt.Errorf("list search index: %w", err)
+	}
+	for _, key := range keys {
+		bytes, err := x.backend.Get(key)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read search index: %w", err)
+		}
+		var doc Document
+		if err := json.Unmarshal(bytes, &doc); err != nil {
+			// A page that does not decode is dropped rather than failing the index.
+			x.backend.Delete(key)
+			continue
+		}
+		x.insert(doc)
+	}
+	x.loaded = true
+	return nil
+}
+
+// insert adds doc to the in-memory index. The caller holds x.mu.
+func (x *Index) insert(doc Document) {
+	x.remove(doc.URL)
+
+	e := &entry{doc: doc, counts: make(map[string]int)}
+	for _, term := range tokenize(doc.Title) {
+		e.counts[term] += titleWeight
+		e.length += titleWeight
+	}
+	for _, p := range doc.Paragraphs {
+		for _, term := range tokenize(p) {
+			e.counts[term]++
+			e.length++
+		}
+	}
+	x.docs[doc.URL] = e
+	for term := range e.counts {
+		if x.postings[term] == nil {
+			x.postings[term] = make(map[string]bool)
+		}
+		x.postings[term][doc.URL] = true
+	}
+}
+
+// remove drops url from the in-memory index. The caller holds x.mu.
+func (x *Index) remove(url string) {
+	e, ok := x.docs[url]
+	if !ok {
+		return
+	}
+	for term := range e.counts {
+		delete(x.postings[term], url)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.docs, url)
+}
+
+// trim drops the pages visited longest ago beyond maxDocuments. The caller
+// holds x.mu.
+func (x *Index) trim() error {
+	if len(x.docs) <= maxDocuments {
+		return nil
+	}
+	byAge := make([]*entry, 0, len(x.docs))
+	for _, e := range x.docs {
+		byAge = append(byAge, e)
+	}
+	sort.Slice(byAge, func(i, j int) bool { return byAge[i].doc.VisitedAt.Before(byAge[j].doc.VisitedAt) })
+	for _, e := range byAge[:len(byAge)-maxDocuments] {
+		if err := x.backend.Delete(keyFor(e.doc.URL)); err != nil {
+			return fmt.Errorf("remove indexed page: %w", err)
+		}
+		x.remove(e.doc.URL)
+	}
+	return nil
+}
+
+func (x *Index) write(doc Document) error {
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("encode indexed page: %w", err)
+	}
+
+	if err := x.backend.Put(keyFor(doc.URL), encoded); err != nil {
+		return fmt.Errorf("write indexed page: %w", err)
+	}
+	return nil
+}
+
+// keyFor returns the backend key of the page at url.
+func keyFor(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return indexPrefix + hex.EncodeToString(sum[:]) + ".json"
+}
+
+// tokenize lowercases text and splits it into words of letters and digits,
+// skipping single characters.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) > 1 {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// snippet cuts a passage of doc around the first paragraph matching any of
+// the expanded query terms, marking every matched word.
+func snippet(doc Document, expanded [][]string) []Segment {
+	wanted := make(map[string]bool)
+	for _, variants := range expanded {
+		for _, term := range variants {
+			wanted[term] = true
+		}
+	}
+	isMatch := func(word string) bool { return wanted[strings.ToLower(word)] }
+
+	for _, p := range doc.Paragraphs {
+		words := splitWords(p)
+		first := -1
+		for i, w := range words {
+			if w.word && isMatch(w.text) {
+				first = i
+				break
+			}
+		}
+		if first < 0 {
+			continue
+		}
+
+		// Start a little before the match and stop after snippetRunes.
+		start, lead := first, 0
+		for start > 0 && lead < snippetRunes/3 {
+			start--
+			lead += len([]rune(words[start].text))
+		}
+		var segments []Segment
+		if start > 0 {
+			segments = append(segments, Segment{Text: "…"})
+		}
+		length := 0
+		end := start
+		for ; end < len(words) && length < snippetRunes; end++ {
+			w := words[end]
+			length += len([]rune(w.text))
+			match := w.word && isMatch(w.text)
+			if n := len(segments); n > 0 && !segments[n-1].Match && !match {
+				segments[n-1].Text += w.text
+				continue
+			}
+			segments = append(segments, Segment{Text: w.text, Match: match})
+		}
+		if end < len(words) {
+			segments = append(segments, Segment{Text: "…"})
+		}
+		return segments
+	}
+	if len(doc.Paragraphs) > 0 {
+		text := []rune(doc.Paragraphs[0])
+		if len(text) > snippetRunes {
+			return []Segment{{Text: string(text[:snippetRunes]) + "…"}}
+		}
+		return []Segment{{Text: string(text)}}
+	}
+	return nil
+}
+
+type word struct {
+	text string
+	word bool
+}
+
+// splitWords splits text into alternating runs of word and other characters,
+// keeping every character, so the runs join back into text.
+func splitWords(text string) []word {
+	var out []word
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	start := 0
+	var current bool
+	for i, r := range text {
+		w := isWord(r)
+		if i == 0 {
+			current = w
+			continue
+		}
+		if w != current {
+			out = append(out, word{text: text[start:i], word: current})
+			start, current = i, w
+		}
+	}
+	if start < len(text) {
+		out = append(out, word{text: text[start:], word: current})
+	}
+	return out
+}
@@ -0,0 +1,194 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+// embedRunes caps the text of a page sent to the embedding model: its
+// title and the start of its text, which say what it is about.
+const embedRunes = 2000
+
+// Embedder turns texts into vectors. *llm.Client implements it.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	EmbeddingModel() string
+}
+
+// Embed stores a vector of the page indexed under url, so it can be found
+// by what it is about. Pages already embedded with the same model are left
+// alone.
+func (x *Index) Embed(ctx context.Context, url string, embedder Embedder) error {
+	if x == nil {
+		return nil
+	}
+	model := embedder.EmbeddingModel()
+
+	x.mu.Lock()
+	if err := x.load(); err != nil {
+		x.mu.Unlock()
+		return err
+	}
+	e, ok := x.docs[url]
+	if !ok || (e.doc.EmbeddingModel == model && len(e.doc.Embedding) > 0) {
+		x.mu.Unlock()
+		return nil
+	}
+	text := embeddingText(e.doc)
+	visitedAt := e.doc.VisitedAt
+	x.mu.Unlock()
+
+	// The model is asked without holding the lock; it can take a while.
+	vectors, err := embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return err
+	}
+	if len(vectors) != 1 || len(vectors[0]) == 0 {
+		return errors.New("embedding model returned no vector")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	e, ok = x.docs[url]
+	if !ok || !e.doc.VisitedAt.Equal(visitedAt) {
+		// Visited again or dropped meanwhile.
+		return nil
+	}
+	doc := e.doc
+	doc.Embedding, doc.EmbeddingModel = normalize(vectors[0]), model
+	if err := x.write(doc); err != nil {
+		return err
+	}
+	e.doc = doc
+	return nil
+}
+
+// Unembedded returns the URLs of indexed pages without a vector from the
+// embedder's model, most recently visited first.
+func (x *Index) Unembedded(embedder Embedder) ([]string, error) {
+	if x == nil {
+		return nil, nil
+	}
+	model := embedder.EmbeddingModel()
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.load(); err != nil {
+		return nil, err
+	}
+	var pending []*entry
+	for _, e := range x.docs {
+		if e.doc.EmbeddingModel != model || len(e.doc.Embedding) == 0 {
+			pending = append(pending, e)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].doc.VisitedAt.After(pending[j].doc.VisitedAt) })
+	urls := make([]string, len(pending))
+	for i, e := range pending {
+		urls[i] = e.doc.URL
+	}
+	return urls, nil
+}
+
+// About returns up to limit pages closest in meaning to query, by the cosine
+// similarity of their vectors. Only pages embedded with the embedder's model
+// are considered.
+func (x *Index) About(ctx context.Context, query string, limit int, embedder Embedder) ([]Hit, error) {
+	if x == nil {
+		return nil, nil
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	vectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 || len(vectors[0]) == 0 {
+		return nil, errors.New("embedding model returned no vector")
+	}
+	want := normalize(vectors[0])
+	model := embedder.EmbeddingModel()
+
+	x.mu.Lock()
+	err = x.load()
+	x.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var hits []Hit
+	for _, e := range x.docs {
+		if e.doc.EmbeddingModel != model || len(e.doc.Embedding) != len(want) {
+			continue
+		}
+		hits = append(hits, Hit{URL: e.doc.URL, Title: e.doc.Title, VisitedAt: e.doc.VisitedAt, Score: dot(want, e.doc.Embedding)})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	// The query words are highlighted where they happen to appear.
+	expanded := [][]string{tokenize(query)}
+	for i := range hits {
+		hits[i].Snippet = snippet(x.docs[hits[i].URL].doc, expanded)
+	}
+	return hits, nil
+}
+
+// embeddingText is what a page is embedded from.
+func embeddingText(doc Document) string {
+	var builder strings.Builder
+	builder.WriteString(doc.Title)
+	for _, p := range doc.Paragraphs {
+		if builder.Len() >= embedRunes*4 {
+			break
+		}
+		builder.WriteString("\n")
+		builder.WriteString(p)
+	}
+	return truncate(builder.String(), embedRunes)
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit])
+}
+
+// normalize scales v to unit length, so the cosine similarity of two
+// normalized vectors is their dot product.
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := math.Sqrt(sum)
+	out := make([]float32, len(v))
+	for i, f := range v {
+		out[i] = float32(float64(f) / norm)
+	}
+	return out
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
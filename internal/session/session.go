@@ -0,0 +1,216 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"chimera/internal/storage"
+)
+
+// Version is the format version written by Write. Read accepts files up to it.
+const Version = 1
+
+// ModeReader is Tab.Mode for pages shown in reader mode. Pages shown by the
+// LLM use the name of the task instead, such as "compose" or "summarize".
+const ModeReader = "reader"
+
+// Session is a snapshot of the browser window, written as JSON so it can be
+// reopened later or on another machine.
+type Session struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	// Active is the index in Tabs of the page in front.
+	Active int   `json:"active"`
+	Tabs   []Tab `json:"tabs"`
+	// CleanExit is set when the browser closed normally. The running session
+	// is saved without it, so finding it unset at startup means a crash.
+	CleanExit bool `json:"clean_exit,omitempty"`
+}
+
+// Tab is one open page.
+type Tab struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	// Mode is ModeReader or the LLM task that rendered the page.
+	Mode string `json:"mode"`
+	// ScrollY is how far down the page was scrolled, in CSS pixels.
+	ScrollY int `json:"scroll_y,omitempty"`
+}
+
+// ActiveTab returns the tab in front.
+func (s Session) ActiveTab() Tab {
+	return s.Tabs[s.Active]
+}
+
+// Write saves s to path, replacing the file.
+func Write(path string, s Session) error {
+	encoded, err := encode(s)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		return fmt.Errorf("write session: %w", err)
+	}
+	return nil
+}
+
+// Read loads a session written by Write.
+func Read(path string) (Session, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return Session{}, fmt.Errorf("read session: %w", err)
+	}
+	return decode(bytes)
+}
+
+// Store keeps the running session in a storage.Backend, by default below
+// the user's state directory, so it can be restored after a crash or at the
+// next launch.
+type Store struct {
+	backend storage.Backend
+	mu      sync.Mutex
+}
+
+// NewStore builds a Store below the user's state directory.
+func NewStore(appID string) (*Store, error) {
+	backend, err := storage.StateFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend), nil
+}
+
+// NewStoreWith builds a Store keeping the session in backend; a nil backend
+// gives a nil Store, which keeps nothing.
+func NewStoreWith(backend storage.Backend) *Store {
+	if backend == nil {
+		return nil
+	}
+	return &Store{backend: backend}
+}
+
+// Load returns the saved session; ok is false when there is none.
+func (st *Store) Load() (s Session, ok bool, err error) {
+	if st == nil {
+		return Session{}, false, nil
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	return st.load()
+}
+
+// Save replaces the saved session with s.
+func (st *Store) Save(s Session) error {
+	if st == nil {
+		return nil
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	return st.save(s)
+}
+
+// MarkClosed marks the saved session as closed normally without changing
+// its pages.
+func (st *Store) MarkClosed() error {
+	if st == nil {
+		return nil
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	s, ok, err := st.load()
+	if err != nil || !ok || s.CleanExit {
+		return err
+	}
+	s.CleanExit = true
+	return st.save(s)
+}
+
+// Remove forgets the saved session.
+func (st *Store) Remove() error {
+	if st == nil {
+		return nil
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if err := st.backend.Delete("session.json"); err != nil {
+		return fmt.Errorf("remove session: %w", err)
+	}
+	return nil
+}
+
+func (st *Store) load() (Session, bool, error) {
+	bytes, err := st.backend.Get("session.json")
+	if errors.Is(err, storage.ErrNotFound) {
+		return Session{}, false, nil
+	}
+	if err != nil {
+		return Session{}, false, fmt.Errorf("read session: %w", err)
+	}
+	s, err := decode(bytes)
+	if err != nil {
+		return Session{}, false, err
+	}
+	return s, true, nil
+}
+
+func (st *Store) save(s Session) error {
+	encoded, err := encode(s)
+	if err != nil {
+		return err
+	}
+	if err := st.backend.Put("session.json", encoded); err != nil {
+		return fmt.Errorf("write session: %w", err)
+	}
+	return nil
+}
+
+func encode(s Session) ([]byte, error) {
+	if len(s.Tabs) == 0 {
+		return nil, errors.New("session has no pages")
+	}
+	s.Version = Version
+	if s.SavedAt.IsZero() {
+		s.SavedAt = time.Now()
+	}
+	encoded, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode session: %w", err)
+	}
+	return encoded, nil
+}
+
+func decode(bytes []byte) (Session, error) {
+	var s Session
+	if err := json.Unmarshal(bytes, &s); err != nil {
+		return Session{}, fmt.Errorf("decode session: %w", err)
+	}
+	if s.Version > Version {
+		return Session{}, fmt.Errorf("session format %d is newer than this version of Chimera supports (%d)", s.Version, Version)
+	}
+	valid := s.Tabs[:0]
+	for _, tab := range s.Tabs {
+		if tab.URL != "" {
+			valid = append(valid, tab)
+		}
+	}
+	s.Tabs = valid
+	if len(s.Tabs) == 0 {
+		return Session{}, errors.New("session has no pages")
+	}
+	if s.Active < 0 || s.Active >= len(s.Tabs) {
+		s.Active = 0
+	}
+	return s, nil
+}
@@ -4,42 +4,240 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"net/url"
+	"strings"
 	"sync"
+
+	"chimera/internal/keyring"
+	"chimera/internal/storage"
 )
 
-// Data captures persisted LLM configuration options.
+// Data captures persisted LLM configuration and display options.
 type Data struct {
 	BaseURL string `json:"base_url"`
 	Model   string `json:"model"`
 	APIKey  string `json:"api_key"`
 	UseLLM  bool   `json:"use_llm"`
+	// Endpoint is the kind of API at BaseURL, such as "azure"; empty tells
+	// it from the host.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// PlaintextAPIKey keeps APIKey in this file instead of the Secret Service keyring.
+	PlaintextAPIKey bool `json:"plaintext_api_key,omitempty"`
+
+	ContextTokens int `json:"context_tokens,omitempty"`
+	// PersonalDigest opts into an LLM start page digest built from history titles and domains.
+	PersonalDigest bool `json:"personal_digest,omitempty"`
+	// Proxy is an http, https or socks5 proxy URL for page fetches and LLM requests.
+	Proxy string `json:"proxy,omitempty"`
+	// WarmUp loads the model at startup and keeps it loaded while the window is focused.
+	WarmUp bool `json:"warm_up,omitempty"`
+	// CleanLinks strips tracking parameters and unwraps redirector URLs from clicked links.
+	CleanLinks bool `json:"clean_links,omitempty"`
+
+	// UserAgent replaces the scraper's default User-Agent; AcceptLanguage is sent when set.
+	UserAgent      string `json:"user_agent,omitempty"`
+	AcceptLanguage string `json:"accept_language,omitempty"`
+
+	// NoScriptRendering turns off the offscreen render of JavaScript-only pages.
+	NoScriptRendering bool `json:"no_script_rendering,omitempty"`
+	// SkipConsentWalls retries cookie consent interstitials with consent cookies set.
+	SkipConsentWalls bool `json:"skip_consent_walls,omitempty"`
+	// LowVision enforces MinFontSize (in pixels) and a MinContrast ratio
+	// between text and its background on every rendered page; zero values
+	// use 18px and 7:1.
+	LowVision   bool    `json:"low_vision,omitempty"`
+	MinFontSize int     `json:"min_font_size,omitempty"`
+	MinContrast float64 `json:"min_contrast,omitempty"`
+	// KeepBoilerplate turns off the removal of menus, footers, sidebars,
+	// cookie banners and ads before extraction.
+	KeepBoilerplate bool `json:"keep_boilerplate,omitempty"`
+	// JoinPages merges the later pages of articles split across several
+	// pages into the first.
+	JoinPages bool `json:"join_pages,omitempty"`
+	// VisitCommand is a shell command run after each visit with the page's
+	// extracted content as JSON on its standard input.
+	VisitCommand string `json:"visit_command,omitempty"`
+	// VaultDir is the folder "Clip to vault" writes Markdown notes into, and
+	// VaultTags the comma-separated tags put in their front matter.
+	VaultDir  string `json:"vault_dir,omitempty"`
+	VaultTags string `json:"vault_tags,omitempty"`
+	// CitationFormat is "bibtex" (the default) or "csl-json", and
+	// CitationFile the bibliography that "Copy citation" also appends to.
+	CitationFormat string `json:"citation_format,omitempty"`
+	CitationFile   string `json:"citation_file,omitempty"`
+	// MaxRedirects caps the redirects a fetch follows; zero uses the
+	// scraper's default of 10 and a negative value refuses all.
+	MaxRedirects int `json:"max_redirects,omitempty"`
+	// CrossSiteRedirects is one of the Redirects constants; empty follows
+	// redirects to other sites.
+	CrossSiteRedirects string `json:"cross_site_redirects,omitempty"`
+	// MaxHeadings, MaxParagraphs, MaxCodeBlocks and MaxLinks cap what is
+	// extracted from a page; HeadingDepth is the deepest heading level read
+	// and MinParagraph the shortest paragraph kept. Zero uses the defaults.
+	MaxHeadings   int `json:"max_headings,omitempty"`
+	MaxParagraphs int `json:"max_paragraphs,omitempty"`
+	MaxCodeBlocks int `json:"max_code_blocks,omitempty"`
+	MaxLinks      int `json:"max_links,omitempty"`
+	HeadingDepth  int `json:"heading_depth,omitempty"`
+	MinParagraph  int `json:"min_paragraph,omitempty"`
+	// Startup is what opens at launch, one of the Startup constants; empty
+	// opens the start page. StartupURL is the page for StartupPage, and
+	// StartupCompose composes the opened page with the LLM.
+	Startup        string `json:"startup,omitempty"`
+	StartupURL     string `json:"startup_url,omitempty"`
+	StartupCompose bool   `json:"startup_compose,omitempty"`
+	// ReaderFont, ReaderFontSize (pixels), ReaderLineHeight, ReaderWidth
+	// (pixels) and ReaderJustify style the text of reader mode and are
+	// suggested to the LLM. Zero values keep the built-in style.
+	ReaderFont       string  `json:"reader_font,omitempty"`
+	ReaderFontSize   int     `json:"reader_font_size,omitempty"`
+	ReaderLineHeight float64 `json:"reader_line_height,omitempty"`
+	ReaderWidth      int     `json:"reader_width,omitempty"`
+	ReaderJustify    bool    `json:"reader_justify,omitempty"`
+	// BlockExternal stops rendered pages loading images, fonts and styles
+	// from the web.
+	BlockExternal bool `json:"block_external,omitempty"`
+	// SourceBase renders pages with their address as base URI, so relative
+	// links and images resolve against the site.
+	SourceBase bool `json:"source_base,omitempty"`
+	// EnrichCompose adds extracts of a few linked pages to composition prompts.
+	EnrichCompose bool `json:"enrich_compose,omitempty"`
+	// FetchLinks lets the model read same-site pages the page links to
+	// while composing, through a fetch_url tool.
+	FetchLinks bool `json:"fetch_links,omitempty"`
+	// SystemPrompt overrides the built-in LLM system prompt; empty uses the default.
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	// Prompts maps an action, such as "summarize", to the prompt template
+	// it uses; actions left out use the template named after them.
+	Prompts map[string]string `json:"prompts,omitempty"`
+	// EmbeddingModel enables searching visited pages by meaning; empty turns it off.
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+	// InputPrice is what the endpoint charges per 1000 prompt tokens; when
+	// set, reader mode shows what composing the page would cost.
+	InputPrice float64 `json:"input_price,omitempty"`
+	// VisionModels names the models that accept images; visually complex
+	// pages are composed with a screenshot when one of them is in use.
+	VisionModels []string `json:"vision_models,omitempty"`
+	// Fallbacks are the LLM providers tried, in order, when the endpoint
+	// fails a request. Their API keys are kept like APIKey.
+	Fallbacks []Provider `json:"fallbacks,omitempty"`
+	// RoutingOrder names the upstream providers OpenRouter tries first and
+	// RoutingSort how it ranks the rest ("price", "throughput" or
+	// "latency"). RoutingOnly keeps requests to RoutingOrder, and
+	// RoutingPrivate skips providers that may store prompts.
+	RoutingOrder   []string `json:"routing_order,omitempty"`
+	RoutingSort    string   `json:"routing_sort,omitempty"`
+	RoutingOnly    bool     `json:"routing_only,omitempty"`
+	RoutingPrivate bool     `json:"routing_private,omitempty"`
+	// PromptFormat is the chat template prompts are laid out in for
+	// llama.cpp's /completion endpoint, such as "llama3"; empty uses ChatML.
+	PromptFormat string `json:"prompt_format,omitempty"`
+	// MaxInFlight is how many LLM requests each provider is sent at once;
+	// zero uses the default.
+	MaxInFlight int `json:"llm_max_in_flight,omitempty"`
+
+	// Rendering is "full", "lite", or empty for automatic selection.
+	Rendering string `json:"rendering,omitempty"`
+
+	// SummaryLanguage and TranslationLanguage override the UI locale; empty follows it.
+	SummaryLanguage     string `json:"summary_language,omitempty"`
+	TranslationLanguage string `json:"translation_language,omitempty"`
+
+	// Sites holds per-domain overrides keyed by SiteKey.
+	Sites map[string]Site `json:"sites,omitempty"`
+}
+
+// Startup choices for Data.Startup; the empty string opens the start page.
+const (
+	StartupBlank       = "blank"
+	StartupLastSession = "session"
+	StartupPage        = "url"
+)
+
+// Choices for Data.CrossSiteRedirects; the empty string follows them.
+const (
+	RedirectsAsk   = "ask"
+	RedirectsBlock = "block"
+)
+
+// Site modes for Site.Mode; the empty string follows the global default.
+const (
+	SiteModeReader = "reader"
+	SiteModeLLM    = "llm"
+)
+
+// Site captures remembered preferences for one domain.
+type Site struct {
+	Mode string `json:"mode,omitempty"`
+	// Template is the prompt template used when composing, e.g. "summarize".
+	Template string `json:"template,omitempty"`
+	// Zoom is the page zoom factor; zero means 100%.
+	Zoom float64 `json:"zoom,omitempty"`
+	// UserAgent and Headers override the scraper's request headers for the domain.
+	UserAgent string            `json:"user_agent,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// Provider is an LLM endpoint to fall back to.
+type Provider struct {
+	Name    string `json:"name,omitempty"`
+	BaseURL string `json:"base_url"`
+	Model   string `json:"model"`
+	APIKey  string `json:"api_key,omitempty"`
+}
+
+// IsZero reports whether s overrides nothing.
+func (s Site) IsZero() bool {
+	return s.Mode == "" && s.Template == "" && s.Zoom == 0 && s.UserAgent == "" && len(s.Headers) == 0
 }
 
+// SiteKey returns the Sites key for a page URL: its lower-cased host without a leading "www.".
+func SiteKey(pageURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(pageURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// apiKeyAccount names the keyring entry holding the LLM API key, and
+// fallbackKeysAccount the one holding the fallback providers' keys as a JSON
+// array in provider order.
+const (
+	apiKeyAccount       = "llm-api-key"
+	fallbackKeysAccount = "llm-fallback-api-keys"
+)
+
 // Store manages reading and writing persistent settings.
 type Store struct {
-	path string
-	mu   sync.RWMutex
+	backend storage.Backend
+	mu      sync.RWMutex
+	secrets *keyring.Keyring
 }
 
 // NewStore builds a Store below the user's configuration directory.
 func NewStore(appID string) (*Store, error) {
-	dir, err := os.UserConfigDir()
+	backend, err := storage.ConfigFiles(appID)
 	if err != nil {
-		return nil, fmt.Errorf("locate config dir: %w", err)
+		return nil, err
 	}
+	return NewStoreWith(backend, appID), nil
+}
 
-	settingsDir := filepath.Join(dir, appID)
-	if err := os.MkdirAll(settingsDir, 0o700); err != nil {
-		return nil, fmt.Errorf("create settings dir: %w", err)
+// NewStoreWith builds a Store keeping the settings in backend and the API
+// keys in appID's keyring entries; a nil backend gives a nil Store, which
+// stores nothing.
+func NewStoreWith(backend storage.Backend, appID string) *Store {
+	if backend == nil {
+		return nil
 	}
-
-	path := filepath.Join(settingsDir, "settings.json")
-	return &Store{path: path}, nil
+	return &Store{backend: backend, secrets: keyring.New(appID)}
 }
 
-// Load reads settings from disk. Returns zero Data if the file does not exist.
+// Load reads the stored settings. Returns zero Data if none were saved.
+// Unless PlaintextAPIKey is set, the API keys are read from the keyring; if that
+// fails the remaining settings are returned together with the error.
 func (s *Store) Load() (Data, error) {
 	if s == nil {
 		return Data{}, nil
@@ -48,8 +246,8 @@ func (s *Store) Load() (Data, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	bytes, err := os.ReadFile(s.path)
-	if errors.Is(err, os.ErrNotExist) {
+	bytes, err := s.backend.Get("settings.json")
+	if errors.Is(err, storage.ErrNotFound) {
 		return Data{}, nil
 	}
 	if err != nil {
@@ -61,10 +259,73 @@ func (s *Store) Load() (Data, error) {
 		return Data{}, fmt.Errorf("decode settings: %w", err)
 	}
 
-	return data, nil
+	if data.PlaintextAPIKey {
+		return data, nil
+	}
+	// A key left in the file predates keyring support; Save migrates it.
+	if data.APIKey == "" {
+		key, err := s.secrets.Get(apiKeyAccount)
+		switch {
+		case errors.Is(err, keyring.ErrNotFound):
+		case err != nil:
+			return data, fmt.Errorf("read API key from keyring: %w", err)
+		default:
+			data.APIKey = key
+		}
+	}
+	return data, s.loadFallbackKeys(data.Fallbacks)
+}
+
+// loadFallbackKeys fills in the keys of providers from the keyring.
+func (s *Store) loadFallbackKeys(providers []Provider) error {
+	if len(providers) == 0 {
+		return nil
+	}
+	secret, err := s.secrets.Get(fallbackKeysAccount)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read fallback API keys from keyring: %w", err)
+	}
+	var keys []string
+	if err := json.Unmarshal([]byte(secret), &keys); err != nil {
+		return fmt.Errorf("decode fallback API keys: %w", err)
+	}
+	for i := range providers {
+		if i < len(keys) && providers[i].APIKey == "" {
+			providers[i].APIKey = keys[i]
+		}
+	}
+	return nil
+}
+
+// saveFallbackKeys stores the keys of providers in the keyring, or removes
+// the entry when none has a key.
+func (s *Store) saveFallbackKeys(providers []Provider) error {
+	keys := make([]string, len(providers))
+	var stored bool
+	for i, p := range providers {
+		keys[i] = p.APIKey
+		stored = stored || p.APIKey != ""
+	}
+	if !stored {
+		_ = s.secrets.Delete(fallbackKeysAccount)
+		return nil
+	}
+	encoded, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("encode fallback API keys: %w", err)
+	}
+	if err := s.secrets.Set(fallbackKeysAccount, "Chimera fallback LLM API keys", string(encoded)); err != nil {
+		return fmt.Errorf("store fallback API keys in keyring: %w", err)
+	}
+	return nil
 }
 
-// Save writes settings to disk atomically.
+// Save replaces the stored settings. Unless PlaintextAPIKey is set, the
+// API keys go to the keyring and are left out of the backend; if the keyring is
+// unavailable the other settings are still written and the error is returned.
 func (s *Store) Save(data Data) error {
 	if s == nil {
 		return nil
@@ -73,19 +334,38 @@ func (s *Store) Save(data Data) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	var keyErr error
+	switch {
+	case data.PlaintextAPIKey || data.APIKey == "":
+		_ = s.secrets.Delete(apiKeyAccount)
+	default:
+		if err := s.secrets.Set(apiKeyAccount, "Chimera LLM API key", data.APIKey); err != nil {
+			keyErr = fmt.Errorf("store API key in keyring: %w", err)
+		}
+	}
+	if data.PlaintextAPIKey {
+		_ = s.secrets.Delete(fallbackKeysAccount)
+	} else if err := s.saveFallbackKeys(data.Fallbacks); err != nil {
+		keyErr = errors.Join(keyErr, err)
+	}
+	if !data.PlaintextAPIKey {
+		data.APIKey = ""
+		fallbacks := make([]Provider, len(data.Fallbacks))
+		for i, p := range data.Fallbacks {
+			p.APIKey = ""
+			fallbacks[i] = p
+		}
+		data.Fallbacks = fallbacks
+	}
+
 	encoded, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encode settings: %w", err)
 	}
 
-	tmpPath := s.path + ".tmp"
-	if err := os.WriteFile(tmpPath, encoded, 0o600); err != nil {
-		return fmt.Errorf("write temp settings: %w", err)
+	if err := s.backend.Put("settings.json", encoded); err != nil {
+		return fmt.Errorf("write settings: %w", err)
 	}
 
-	if err := os.Rename(tmpPath, s.path); err != nil {
-		return fmt.Errorf("commit settings: %w", err)
-	}
-
-	return nil
+	return keyErr
 }
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFiles_PutGetList(t *testing.T) {
+	files, err := NewFiles(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackend(t, files)
+}
+
+// testBackend checks the Backend contract every implementation shares.
+func testBackend(t *testing.T, backend Backend) {
+	t.Helper()
+	if _, err := backend.Get("history/a.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing key: err = %v, want ErrNotFound", err)
+	}
+	for _, key := range []string{"history/b.json", "history/a.json", "bookmarks.json"} {
+		if err := backend.Put(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := backend.Put("history/a.json", []byte("replaced")); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := backend.Get("history/a.json"); err != nil || string(value) != "replaced" {
+		t.Errorf("Get = %q, %v; want the replaced value", value, err)
+	}
+
+	keys, err := backend.List("history/")
+	if err != nil || !slices.Equal(keys, []string{"history/a.json", "history/b.json"}) {
+		t.Errorf("List = %v, %v", keys, err)
+	}
+
+	for _, key := range []string{"history/a.json", "history/a.json", "history/b.json"} {
+		if err := backend.Delete(key); err != nil {
+			t.Errorf("Delete(%q) = %v", key, err)
+		}
+	}
+	if keys, err := backend.List(""); err != nil || !slices.Equal(keys, []string{"bookmarks.json"}) {
+		t.Errorf("List after Delete = %v, %v", keys, err)
+	}
+
+	for _, key := range []string{"", ".", "../escape", "/etc/passwd"} {
+		if err := backend.Put(key, nil); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+	}
+}
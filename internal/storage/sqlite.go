@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	// Registers the "sqlite3" driver. It is built with cgo, like the GTK
+	// and WebKit bindings the browser already needs.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLite keeps every store in one database file, each in a bucket of a
+// single key-value table.
+type SQLite struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens or creates the database at path.
+func OpenSQLite(path string) (*SQLite, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	// SQLite allows one writer at a time; a single connection queues them
+	// here instead of failing with "database is locked".
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS entries (
+	bucket TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value BLOB NOT NULL,
+	PRIMARY KEY (bucket, key)
+)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create table in %s: %w", path, err)
+	}
+	return &SQLite{db: db}, nil
+}
+
+// Bucket returns the Backend for one group of stores, such as "config" or
+// "cache". Keys in different buckets do not collide.
+func (s *SQLite) Bucket(name string) Backend {
+	return &sqliteBucket{db: s.db, name: name}
+}
+
+// Close closes the database.
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+type sqliteBucket struct {
+	db   *sql.DB
+	name string
+}
+
+func (b *sqliteBucket) Get(key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	var value []byte
+	err := b.db.QueryRow(`SELECT value FROM entries WHERE bucket = ? AND key = ?`, b.name, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func (b *sqliteBucket) Put(key string, value []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	_, err := b.db.Exec(`INSERT INTO entries (bucket, key, value) VALUES (?, ?, ?)
+ON CONFLICT (bucket, key) DO UPDATE SET value = excluded.value`, b.name, key, value)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", key, err)
+	}
+	return nil
+}
+
+func (b *sqliteBucket) Delete(key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if _, err := b.db.Exec(`DELETE FROM entries WHERE bucket = ? AND key = ?`, b.name, key); err != nil {
+		return fmt.Errorf("remove %s: %w", key, err)
+	}
+	return nil
+}
+
+func (b *sqliteBucket) List(prefix string) ([]string, error) {
+	// Keys sort after their prefix, so the scan starts there and stops at
+	// the first key without it.
+	rows, err := b.db.Query(`SELECT key FROM entries WHERE bucket = ? AND key >= ? ORDER BY key`, b.name, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", prefix, err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("list %s: %w", prefix, err)
+		}
+		if !strings.HasPrefix(key, prefix) {
+			break
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list %s: %w", prefix, err)
+	}
+	return keys, nil
+}
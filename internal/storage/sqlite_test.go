@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLite_PutGetList(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "chimera.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	testBackend(t, db.Bucket("config"))
+}
+
+func TestSQLite_Buckets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chimera.db")
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Bucket("config").Put("history.json", []byte("config")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Bucket("cache").Get("history.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get from another bucket: err = %v, want ErrNotFound", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if value, err := db.Bucket("config").Get("history.json"); err != nil || string(value) != "config" {
+		t.Errorf("Get after reopening = %q, %v", value, err)
+	}
+}
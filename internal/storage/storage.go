@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotFound is returned by Backend.Get for keys that hold no value.
+var ErrNotFound = errors.New("not found")
+
+// Backend holds values under slash-separated keys such as
+// "compositions/ab12.json". Stores keep their own locking; a Backend only
+// has to make each call atomic, so a reader never sees half a value.
+type Backend interface {
+	// Get returns the value stored under key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Put replaces the value stored under key.
+	Put(key string, value []byte) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// List returns the keys starting with prefix, sorted.
+	List(prefix string) ([]string, error)
+}
+
+// ConfigFiles returns the Backend for appID's settings-like data, such as
+// history and bookmarks: plain files below the user's configuration
+// directory.
+func ConfigFiles(appID string) (*Files, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("locate config dir: %w", err)
+	}
+	return NewFiles(filepath.Join(dir, appID))
+}
+
+// CacheFiles returns the Backend for appID's data that can be rebuilt, such
+// as compositions and archived pages: plain files below the user's cache
+// directory.
+func CacheFiles(appID string) (*Files, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("locate cache dir: %w", err)
+	}
+	return NewFiles(filepath.Join(dir, appID))
+}
+
+// StateFiles returns the Backend for appID's state that outlives a run but
+// is not worth syncing, such as the running session: plain files below
+// $XDG_STATE_HOME, or ~/.local/state when that is unset.
+func StateFiles(appID string) (*Files, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if !filepath.IsAbs(dir) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("locate state dir: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return NewFiles(filepath.Join(dir, appID))
+}
+
+// checkKey rejects keys that are empty or would escape the backend, such as
+// "../x" or "/etc/passwd".
+func checkKey(key string) error {
+	if !fs.ValidPath(key) || key == "." {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return nil
+}
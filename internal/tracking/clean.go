@@ -0,0 +1,140 @@
+package tracking
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only identify the click or campaign.
+var trackingParams = map[string]struct{}{
+	"fbclid": {}, "gclid": {}, "gclsrc": {}, "dclid": {}, "gbraid": {}, "wbraid": {},
+	"msclkid": {}, "yclid": {}, "twclid": {}, "ttclid": {}, "li_fat_id": {}, "igshid": {},
+	"mc_cid": {}, "mc_eid": {}, "_hsenc": {}, "_hsmi": {}, "mkt_tok": {}, "vero_id": {},
+	"oly_anon_id": {}, "oly_enc_id": {}, "_ga": {}, "_gl": {}, "spm": {}, "ref_src": {},
+}
+
+// trackingPrefixes match whole families such as utm_source and utm_campaign.
+var trackingPrefixes = []string{"utm_", "pk_", "mtm_", "hsa_"}
+
+// redirector describes a link-wrapping service and the parameter holding the real target.
+type redirector struct {
+	host  func(string) bool
+	path  string
+	param []string
+}
+
+var redirectors = []redirector{
+	{host: hostIs("l.facebook.com", "lm.facebook.com"), path: "/l.php", param: []string{"u"}},
+	{host: isGoogle, path: "/url", param: []string{"q", "url"}},
+	{host: hostIs("www.youtube.com", "youtube.com", "m.youtube.com"), path: "/redirect", param: []string{"q"}},
+	{host: hostIs("duckduckgo.com"), path: "/l/", param: []string{"uddg"}},
+	{host: hostIs("out.reddit.com"), param: []string{"url"}},
+	{host: hostIs("steamcommunity.com"), path: "/linkfilter/", param: []string{"url", "u"}},
+	{host: hostIs("slack-redir.net"), path: "/link", param: []string{"url"}},
+	{host: hostIs("t.umblr.com"), path: "/redirect", param: []string{"z"}},
+}
+
+// maxUnwrap bounds how many nested redirectors are resolved.
+const maxUnwrap = 3
+
+// Clean unwraps known redirector URLs and removes tracking query parameters.
+// Anything it cannot parse, or a non-http(s) link, is returned unchanged.
+func Clean(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return raw
+	}
+
+	for i := 0; i < maxUnwrap; i++ {
+		target, ok := unwrap(parsed)
+		if !ok {
+			break
+		}
+		parsed = target
+	}
+
+	query := parsed.Query()
+	removed := false
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+			removed = true
+		}
+	}
+	if removed {
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String()
+}
+
+func unwrap(u *url.URL) (*url.URL, bool) {
+	host := strings.ToLower(u.Hostname())
+	for _, r := range redirectors {
+		if !r.host(host) || (r.path != "" && u.Path != r.path) {
+			continue
+		}
+		query := u.Query()
+		for _, name := range r.param {
+			target, err := url.Parse(query.Get(name))
+			if err == nil && (target.Scheme == "http" || target.Scheme == "https") && target.Host != "" {
+				return target, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := trackingParams[key]; ok {
+		return true
+	}
+	for _, prefix := range trackingPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostIs(hosts ...string) func(string) bool {
+	return func(host string) bool {
+		for _, h := range hosts {
+			if host == h {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// isGoogle matches google.com and its country domains such as
+// www.google.de and www.google.co.uk, but not google.evil.example.
+func isGoogle(host string) bool {
+	host = strings.TrimPrefix(host, "www.")
+	suffix, ok := strings.CutPrefix(host, "google.")
+	if !ok {
+		return false
+	}
+	labels := strings.Split(suffix, ".")
+	switch len(labels) {
+	case 1:
+		return isLetters(labels[0])
+	case 2:
+		// Second-level country suffixes: co.uk, com.au, co.jp and the like.
+		return (labels[0] == "co" || labels[0] == "com") && len(labels[1]) == 2 && isLetters(labels[1])
+	}
+	return false
+}
+
+func isLetters(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package tracking
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestClean_Links(t *testing.T) {
+	target := "https://example.com/story?id=7"
+	wrap := func(prefix, param, link string) string {
+		return prefix + "?" + param + "=" + url.QueryEscape(link)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"plain link", target, target},
+		{"google", wrap("https://www.google.com/url", "q", target) + "&sa=D", target},
+		{"google url param", wrap("https://google.com/url", "url", target), target},
+		{"google country domain", wrap("https://www.google.co.uk/url", "q", target), target},
+		{"google plain tld", wrap("https://www.google.de/url", "q", target), target},
+		{"google lookalike", wrap("https://google.evil.example/url", "q", target), wrap("https://google.evil.example/url", "q", target)},
+		{"google other path", wrap("https://www.google.com/search", "q", target), wrap("https://www.google.com/search", "q", target)},
+		{"facebook", wrap("https://l.facebook.com/l.php", "u", target) + "&h=AT0", target},
+		{"duckduckgo", wrap("https://duckduckgo.com/l/", "uddg", target) + "&rut=abc", target},
+		{"non-http target", wrap("https://www.google.com/url", "q", "javascript:alert(1)"), wrap("https://www.google.com/url", "q", "javascript:alert(1)")},
+		{
+			"nested",
+			wrap("https://l.facebook.com/l.php", "u", wrap("https://www.google.com/url", "q", wrap("https://duckduckgo.com/l/", "uddg", target))),
+			target,
+		},
+		{
+			"nested deeper than maxUnwrap",
+			wrap("https://l.facebook.com/l.php", "u", wrap("https://www.google.com/url", "q", wrap("https://duckduckgo.com/l/", "uddg", wrap("https://www.google.com/url", "q", target)))),
+			wrap("https://www.google.com/url", "q", target),
+		},
+		{"utm parameters", "https://example.com/a?utm_source=x&UTM_Medium=y&id=7&utm_campaign=z", "https://example.com/a?id=7"},
+		{"click ids", "https://example.com/a?fbclid=1&gclid=2&page=3", "https://example.com/a?page=3"},
+		{"utm inside a wrapper", wrap("https://www.google.com/url", "q", target+"&utm_source=news"), target},
+		{"mailto", "mailto:me@example.com?utm_source=x", "mailto:me@example.com?utm_source=x"},
+		{"relative", "/a?utm_source=x", "/a?utm_source=x"},
+		{"unparseable", "https://example.com/%zz?utm_source=x", "https://example.com/%zz?utm_source=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Clean(tt.raw); got != tt.want {
+				t.Errorf("Clean(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClean_IsGoogle(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"google.com", true},
+		{"www.google.com", true},
+		{"www.google.de", true},
+		{"www.google.co.uk", true},
+		{"google.com.au", true},
+		{"google.evil.example", false},
+		{"google.co.evil.example", false},
+		{"google.com.evil", false},
+		{"notgoogle.com", false},
+		{"google.", false},
+		{"google.c0m", false},
+	}
+	for _, tt := range tests {
+		if got := isGoogle(tt.host); got != tt.want {
+			t.Errorf("isGoogle(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
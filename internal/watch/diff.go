@@ -0,0 +1,117 @@
+package watch
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"chimera/internal/scraper"
+)
+
+// Snapshot is the part of a page compared between checks.
+type Snapshot struct {
+	TakenAt time.Time `json:"taken_at"`
+	Title   string    `json:"title"`
+	// Headings are written with Markdown markers, such as "## Install", so
+	// a heading moved to another level counts as changed.
+	Headings   []string `json:"headings,omitempty"`
+	Paragraphs []string `json:"paragraphs,omitempty"`
+}
+
+// Diff lists what changed between two snapshots. Items are compared as
+// whole texts, so an edited paragraph shows up as one removed and one added.
+type Diff struct {
+	// Since and Found are when the old and new snapshots were taken.
+	Since time.Time `json:"since"`
+	Found time.Time `json:"found"`
+	// OldTitle and NewTitle are set when the title changed.
+	OldTitle          string   `json:"old_title,omitempty"`
+	NewTitle          string   `json:"new_title,omitempty"`
+	AddedHeadings     []string `json:"added_headings,omitempty"`
+	RemovedHeadings   []string `json:"removed_headings,omitempty"`
+	AddedParagraphs   []string `json:"added_paragraphs,omitempty"`
+	RemovedParagraphs []string `json:"removed_paragraphs,omitempty"`
+}
+
+// NewSnapshot takes the title, outline and paragraphs of result.
+func NewSnapshot(result *scraper.Result, at time.Time) Snapshot {
+	snapshot := Snapshot{TakenAt: at, Title: normalize(result.Title)}
+	for _, h := range result.Headings {
+		snapshot.Headings = append(snapshot.Headings, strings.Repeat("#", max(h.Level, 1))+" "+normalize(h.Text))
+	}
+	for _, p := range result.Paragraphs {
+		if p = normalize(p); p != "" {
+			snapshot.Paragraphs = append(snapshot.Paragraphs, p)
+		}
+	}
+	return snapshot
+}
+
+// Compare returns what changed from old to new. Reordering alone is not a change.
+func Compare(old, new Snapshot) Diff {
+	var diff Diff
+	if old.Title != new.Title {
+		diff.OldTitle, diff.NewTitle = old.Title, new.Title
+	}
+	diff.RemovedHeadings, diff.AddedHeadings = difference(old.Headings, new.Headings)
+	diff.RemovedParagraphs, diff.AddedParagraphs = difference(old.Paragraphs, new.Paragraphs)
+	return diff
+}
+
+// Empty reports that nothing changed.
+func (d Diff) Empty() bool {
+	return d.OldTitle == "" && d.NewTitle == "" &&
+		len(d.AddedHeadings) == 0 && len(d.RemovedHeadings) == 0 &&
+		len(d.AddedParagraphs) == 0 && len(d.RemovedParagraphs) == 0
+}
+
+// Summary describes the size of the change, such as "2 paragraphs added,
+// 1 heading removed".
+func (d Diff) Summary() string {
+	var parts []string
+	if d.OldTitle != d.NewTitle {
+		parts = append(parts, "title changed")
+	}
+	count := func(n int, noun, verb string) {
+		if n == 1 {
+			parts = append(parts, "1 "+noun+" "+verb)
+		} else if n > 1 {
+			parts = append(parts, strconv.Itoa(n)+" "+noun+"s "+verb)
+		}
+	}
+	count(len(d.AddedHeadings), "heading", "added")
+	count(len(d.RemovedHeadings), "heading", "removed")
+	count(len(d.AddedParagraphs), "paragraph", "added")
+	count(len(d.RemovedParagraphs), "paragraph", "removed")
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// difference returns the items only in old and only in new, in their
+// original order, counting repeated items separately.
+func difference(old, new []string) (removed, added []string) {
+	remaining := make(map[string]int, len(old))
+	for _, item := range old {
+		remaining[item]++
+	}
+	for _, item := range new {
+		if remaining[item] > 0 {
+			remaining[item]--
+			continue
+		}
+		added = append(added, item)
+	}
+	for _, item := range old {
+		if remaining[item] > 0 {
+			remaining[item]--
+			removed = append(removed, item)
+		}
+	}
+	return removed, added
+}
+
+func normalize(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
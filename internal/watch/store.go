@@ -0,0 +1,214 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"chimera/internal/storage"
+)
+
+// Watch is a page re-scraped periodically to see whether it changed.
+type Watch struct {
+	URL     string    `json:"url"`
+	Title   string    `json:"title"`
+	AddedAt time.Time `json:"added_at"`
+	// CheckedAt is when the page was last scraped.
+	CheckedAt time.Time `json:"checked_at"`
+	// Problem describes why the last check failed; empty when it succeeded.
+	Problem string `json:"problem,omitempty"`
+	// Snapshot is the content the next check is compared against.
+	Snapshot Snapshot `json:"snapshot"`
+	// Change is the last difference found; nil until the page changes.
+	Change *Diff `json:"change,omitempty"`
+	// Unseen marks a Change that has not been looked at yet.
+	Unseen bool `json:"unseen,omitempty"`
+}
+
+// Store persists watched pages as JSON in a storage.Backend, by default below
+// the user's configuration directory.
+type Store struct {
+	backend storage.Backend
+	mu      sync.RWMutex
+}
+
+// NewStore builds a Store below the user's configuration directory.
+func NewStore(appID string) (*Store, error) {
+	backend, err := storage.ConfigFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend), nil
+}
+
+// NewStoreWith builds a Store keeping its data in backend; a nil backend gives a nil Store,
+// which stores nothing.
+func NewStoreWith(backend storage.Backend) *Store {
+	if backend == nil {
+		return nil
+	}
+	return &Store{backend: backend}
+}
+
+// List returns all watched pages in the order they were added.
+func (s *Store) List() ([]Watch, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.read()
+}
+
+// Get returns the watch for url.
+func (s *Store) Get(url string) (Watch, bool, error) {
+	entries, err := s.List()
+	if err != nil {
+		return Watch{}, false, err
+	}
+	for _, w := range entries {
+		if w.URL == url {
+			return w, true, nil
+		}
+	}
+	return Watch{}, false, nil
+}
+
+// Add starts watching w.URL from w.Snapshot, replacing any existing watch
+// for the same URL.
+func (s *Store) Add(w Watch) error {
+	if s == nil {
+		return nil
+	}
+	w.URL = strings.TrimSpace(w.URL)
+	if w.URL == "" {
+		return errors.New("watch URL is empty")
+	}
+	if w.AddedAt.IsZero() {
+		w.AddedAt = time.Now()
+	}
+	if w.CheckedAt.IsZero() {
+		w.CheckedAt = w.AddedAt
+	}
+
+	return s.update(func(entries []Watch) []Watch {
+		for i := range entries {
+			if entries[i].URL == w.URL {
+				entries[i] = w
+				return entries
+			}
+		}
+		return append(entries, w)
+	})
+}
+
+// Remove stops watching url, if it is watched.
+func (s *Store) Remove(url string) error {
+	if s == nil {
+		return nil
+	}
+	return s.update(func(entries []Watch) []Watch {
+		kept := entries[:0]
+		for _, w := range entries {
+			if w.URL != url {
+				kept = append(kept, w)
+			}
+		}
+		return kept
+	})
+}
+
+// Record stores the outcome of checking url at checkedAt. A successful
+// check replaces the snapshot and, when it differs, sets the change; a
+// failed one only records the problem, so the next check compares against
+// the last content seen.
+func (s *Store) Record(url string, checkedAt time.Time, snapshot *Snapshot, checkErr error) (changed bool, err error) {
+	if s == nil {
+		return false, nil
+	}
+	err = s.update(func(entries []Watch) []Watch {
+		for i := range entries {
+			w := &entries[i]
+			if w.URL != url {
+				continue
+			}
+			w.CheckedAt = checkedAt
+			if checkErr != nil {
+				w.Problem = checkErr.Error()
+				continue
+			}
+			w.Problem = ""
+			if diff := Compare(w.Snapshot, *snapshot); !diff.Empty() {
+				diff.Since, diff.Found = w.Snapshot.TakenAt, checkedAt
+				w.Change, w.Unseen = &diff, true
+				changed = true
+			}
+			if snapshot.Title != "" {
+				w.Title = snapshot.Title
+			}
+			w.Snapshot = *snapshot
+		}
+		return entries
+	})
+	return changed, err
+}
+
+// MarkSeen clears the unseen flag of url's change.
+func (s *Store) MarkSeen(url string) error {
+	if s == nil {
+		return nil
+	}
+	return s.update(func(entries []Watch) []Watch {
+		for i := range entries {
+			if entries[i].URL == url {
+				entries[i].Unseen = false
+			}
+		}
+		return entries
+	})
+}
+
+func (s *Store) update(change func([]Watch) []Watch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	return s.write(change(entries))
+}
+
+func (s *Store) read() ([]Watch, error) {
+	bytes, err := s.backend.Get("watches.json")
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read watches: %w", err)
+	}
+
+	var entries []Watch
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return nil, fmt.Errorf("decode watches: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) write(entries []Watch) error {
+	encoded, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode watches: %w", err)
+	}
+
+	if err := s.backend.Put("watches.json", encoded); err != nil {
+		return fmt.Errorf("write watches: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"chimera/internal/session"
+	"chimera/internal/storage"
+)
+
+// DefaultName names the workspace that exists before any is created.
+const DefaultName = "Default"
+
+// Workspace is a named set of pages kept apart from the others, such as a
+// research project, the daily news or a library's documentation.
+type Workspace struct {
+	Name string `json:"name"`
+	// Mode is settings.SiteModeReader or settings.SiteModeLLM, the mode for
+	// URLs entered while the workspace is active; empty follows the LLM
+	// settings.
+	Mode string `json:"mode,omitempty"`
+	// Model replaces the configured LLM model while the workspace is
+	// active; empty keeps it.
+	Model string `json:"model,omitempty"`
+	// Tabs are the pages open when the workspace was last left, and Active
+	// the index of the one in front.
+	Tabs   []session.Tab `json:"tabs,omitempty"`
+	Active int           `json:"active,omitempty"`
+}
+
+// ActiveTab returns the page in front when w was last left; ok is false
+// when it had none.
+func (w Workspace) ActiveTab() (tab session.Tab, ok bool) {
+	if len(w.Tabs) == 0 {
+		return session.Tab{}, false
+	}
+	if w.Active < 0 || w.Active >= len(w.Tabs) {
+		return w.Tabs[0], true
+	}
+	return w.Tabs[w.Active], true
+}
+
+type state struct {
+	Current    string      `json:"current"`
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// Store persists workspaces and which one is current as JSON in a
+// storage.Backend.
+type Store struct {
+	backend storage.Backend
+	mu      sync.Mutex
+}
+
+// NewStore builds a Store below the user's configuration directory.
+func NewStore(appID string) (*Store, error) {
+	backend, err := storage.ConfigFiles(appID)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreWith(backend), nil
+}
+
+// NewStoreWith builds a Store keeping its data in backend; a nil backend gives a nil Store,
+// which keeps only the default workspace.
+func NewStoreWith(backend storage.Backend) *Store {
+	if backend == nil {
+		return nil
+	}
+	return &Store{backend: backend}
+}
+
+// List returns the workspaces in the order they were created and the one
+// that is current. A Store with none holds a single DefaultName workspace.
+func (s *Store) List() ([]Workspace, Workspace, error) {
+	if s == nil {
+		return []Workspace{{Name: DefaultName}}, Workspace{Name: DefaultName}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return nil, Workspace{}, err
+	}
+	return st.Workspaces, st.Workspaces[st.index(st.Current)], nil
+}
+
+// Put stores w in place of the workspace called name, keeping its pages
+// when w has none, or adds w when there is no such workspace. Renaming the
+// current workspace keeps it current.
+func (s *Store) Put(name string, w Workspace) error {
+	if s == nil {
+		return nil
+	}
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return errors.New("workspace name is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	for _, existing := range st.Workspaces {
+		if existing.Name == w.Name && existing.Name != name {
+			return fmt.Errorf("a workspace called %q already exists", w.Name)
+		}
+	}
+
+	replaced := false
+	for i := range st.Workspaces {
+		if st.Workspaces[i].Name != name {
+			continue
+		}
+		if len(w.Tabs) == 0 {
+			w.Tabs, w.Active = st.Workspaces[i].Tabs, st.Workspaces[i].Active
+		}
+		st.Workspaces[i] = w
+		replaced = true
+	}
+	if !replaced {
+		st.Workspaces = append(st.Workspaces, w)
+	}
+	if st.Current == name {
+		st.Current = w.Name
+	}
+	return s.write(st)
+}
+
+// Remove deletes the workspace called name. The last workspace cannot be
+// removed; when the current one is, the first left becomes current.
+func (s *Store) Remove(name string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	kept := st.Workspaces[:0]
+	for _, w := range st.Workspaces {
+		if w.Name != name {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		return errors.New("the last workspace cannot be removed")
+	}
+	st.Workspaces = kept
+	if st.Current == name {
+		st.Current = kept[0].Name
+	}
+	return s.write(st)
+}
+
+// Leave records tabs as the pages open in the workspace called name, with
+// the one at active in front.
+func (s *Store) Leave(name string, tabs []session.Tab, active int) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range st.Workspaces {
+		if st.Workspaces[i].Name == name {
+			st.Workspaces[i].Tabs, st.Workspaces[i].Active = tabs, active
+		}
+	}
+	return s.write(st)
+}
+
+// Switch makes the workspace called name current and returns it.
+func (s *Store) Switch(name string) (Workspace, error) {
+	if s == nil {
+		return Workspace{Name: DefaultName}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return Workspace{}, err
+	}
+	i := st.index(name)
+	if st.Workspaces[i].Name != name {
+		return Workspace{}, fmt.Errorf("no workspace called %q", name)
+	}
+	st.Current = name
+	if err := s.write(st); err != nil {
+		return Workspace{}, err
+	}
+	return st.Workspaces[i], nil
+}
+
+// index returns the position of the workspace called name, or 0 when there
+// is none.
+func (st state) index(name string) int {
+	for i, w := range st.Workspaces {
+		if w.Name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+func (s *Store) read() (state, error) {
+	var st state
+	bytes, err := s.backend.Get("workspaces.json")
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+	case err != nil:
+		return state{}, fmt.Errorf("read workspaces: %w", err)
+	default:
+		if err := json.Unmarshal(bytes, &st); err != nil {
+			return state{}, fmt.Errorf("decode workspaces: %w", err)
+		}
+	}
+	if len(st.Workspaces) == 0 {
+		st.Workspaces = []Workspace{{Name: DefaultName}}
+	}
+	return st, nil
+}
+
+func (s *Store) write(st state) error {
+	encoded, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode workspaces: %w", err)
+	}
+
+	if err := s.backend.Put("workspaces.json", encoded); err != nil {
+		return fmt.Errorf("write workspaces: %w", err)
+	}
+
+	return nil
+}